@@ -10,6 +10,7 @@ import (
 
 	"github.com/EmilioCliff/boffo/internal/cache"
 	"github.com/EmilioCliff/boffo/internal/handlers"
+	"github.com/EmilioCliff/boffo/internal/mpesa"
 	"github.com/EmilioCliff/boffo/internal/postgres"
 	"github.com/EmilioCliff/boffo/internal/reports"
 	"github.com/EmilioCliff/boffo/pkg"
@@ -41,10 +42,20 @@ func main() {
 
 	// create services
 	cache := cache.NewCacheClient(config.REDIS_ADDRESS, config.REDIS_PASSWORD, 1)
-	report := reports.NewReportService(postgresRepo)
+	report := reports.NewReportService(postgresRepo, config)
+	mpesaClient, err := mpesa.NewMpesaClient(config)
+	if err != nil {
+		log.Fatalf("Error creating mpesa client: %v", err)
+	}
+
+	// start report scheduler
+	scheduler := reports.NewScheduler(report)
+	if err := scheduler.Start(); err != nil {
+		log.Fatalf("Error starting report scheduler: %v", err)
+	}
 
 	// start server
-	server := handlers.NewServer(config, tokenMaker, postgresRepo, cache, report)
+	server := handlers.NewServer(config, tokenMaker, postgresRepo, cache, report, mpesaClient)
 	log.Println("starting server at address: ", config.SERVER_ADDRESS)
 	if err := server.Start(); err != nil {
 		log.Fatalf("Error starting server: %v", err)
@@ -62,6 +73,10 @@ func main() {
 		log.Fatalf("Error stopping server: %v", err)
 	}
 
+	if err := scheduler.Stop(ctx); err != nil {
+		log.Printf("Error stopping report scheduler: %v", err)
+	}
+
 	store.CloseDB()
 
 	log.Println("Server shutdown ...")
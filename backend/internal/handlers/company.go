@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/EmilioCliff/boffo/internal/repository"
 	"github.com/EmilioCliff/boffo/pkg"
@@ -14,6 +16,10 @@ type addProductBatchRequest struct {
 	Quantity      uint32  `json:"quantity" binding:"required"`
 	PurchasePrice float64 `json:"purchase_price" binding:"required,gt=0"`
 	DateReceived  string  `json:"date_received" binding:"required"`
+	// optional, batches without one are given out after every batch that has one
+	ExpiryDate string `json:"expiry_date"`
+	// the location the batch is received into, the default location when left out
+	LocationID uint32 `json:"location_id"`
 }
 
 func (s *Server) createProductBatchHandler(ctx *gin.Context) {
@@ -29,12 +35,28 @@ func (s *Server) createProductBatchHandler(ctx *gin.Context) {
 		return
 	}
 
+	var expiryDate *time.Time
+	if req.ExpiryDate != "" {
+		t, err := pkg.StrToTime(req.ExpiryDate)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid expiry_date format")))
+			return
+		}
+		if t.Before(dateReceived) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "expiry_date cannot be before date_received")))
+			return
+		}
+		expiryDate = &t
+	}
+
 	productBatch, err := s.repo.CompanyRepository.AddProductBatch(ctx, &repository.ProductBatch{
 		ProductID:     req.ProductID,
 		BatchNumber:   req.BatchNumber,
 		Quantity:      int64(req.Quantity),
 		PurchasePrice: req.PurchasePrice,
 		DateReceived:  dateReceived,
+		ExpiryDate:    expiryDate,
+		LocationID:    req.LocationID,
 	})
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -66,9 +88,10 @@ func (s *Server) listProductBatchesHandler(ctx *gin.Context) {
 			Page:     uint32(pageNo),
 			PageSize: uint32(pageSize),
 		},
-		Search:    nil,
-		ProductID: nil,
-		InStock:   nil,
+		Search:     nil,
+		ProductID:  nil,
+		LocationID: nil,
+		InStock:    nil,
 	}
 
 	if search := ctx.Query("search"); search != "" {
@@ -89,6 +112,22 @@ func (s *Server) listProductBatchesHandler(ctx *gin.Context) {
 		filter.ProductID = &productIDUint
 	}
 
+	if locationIDStr := ctx.Query("location_id"); locationIDStr != "" {
+		locationID, err := pkg.StringToUint32(locationIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid location_id format")))
+			return
+		}
+		filter.LocationID = &locationID
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "product-batches", filter.Pagination, productBatchExportColumns, func() ([]*repository.ProductBatch, *pkg.Pagination, error) {
+			return s.repo.CompanyRepository.ListProductBatches(ctx, filter)
+		})
+		return
+	}
+
 	productBatches, pagination, err := s.repo.CompanyRepository.ListProductBatches(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -104,6 +143,53 @@ type distributeStockRequest struct {
 	Quantity        uint32  `json:"quantity" binding:"required,gt=0"`
 	UnitPrice       float64 `json:"unit_price" binding:"required,gt=0"`
 	DateDistributed string  `json:"date_distributed" binding:"required"`
+	// leave the distribution uninvoiced so it can be grouped with others on one invoice
+	DeferInvoice bool   `json:"defer_invoice"`
+	DueDate      string `json:"due_date"`
+	// past the reseller's credit limit the distribution is rejected unless it is overridden
+	// with a reason or held for an admin to decide
+	OverrideReason  string `json:"override_reason"`
+	HoldIfOverLimit bool   `json:"hold_if_over_limit"`
+	// the location the stock ships from, the default location when left out
+	LocationID uint32 `json:"location_id"`
+	// FEFO, FIFO or LIFO, STOCK_ALLOCATION_STRATEGY when left out, or the batches to ship
+	// from with quantities adding up to quantity
+	AllocationStrategy string                   `json:"allocation_strategy"`
+	Batches            []batchAllocationRequest `json:"batches" binding:"omitempty,dive"`
+}
+
+type batchAllocationRequest struct {
+	BatchID  uint32 `json:"batch_id" binding:"required"`
+	Quantity uint32 `json:"quantity" binding:"required,gt=0"`
+}
+
+// stockAllocation checks the allocation strategy or batches a request asked for, a request
+// can name one or the other.
+func stockAllocation(strategy string, batches []batchAllocationRequest) (string, []repository.BatchAllocation, error) {
+	strategy = strings.ToUpper(strings.TrimSpace(strategy))
+	switch strategy {
+	case "", repository.ALLOCATION_FEFO, repository.ALLOCATION_FIFO, repository.ALLOCATION_LIFO:
+	default:
+		return "", nil, pkg.Errorf(pkg.INVALID_ERROR, "allocation_strategy must be one of FEFO, FIFO or LIFO")
+	}
+
+	if len(batches) == 0 {
+		return strategy, nil, nil
+	}
+
+	if strategy != "" {
+		return "", nil, pkg.Errorf(pkg.INVALID_ERROR, "allocation_strategy cannot be used with batches")
+	}
+
+	allocations := make([]repository.BatchAllocation, len(batches))
+	for i, batch := range batches {
+		allocations[i] = repository.BatchAllocation{
+			BatchID:  batch.BatchID,
+			Quantity: int64(batch.Quantity),
+		}
+	}
+
+	return "", allocations, nil
 }
 
 func (s *Server) createStockDistributionHandler(ctx *gin.Context) {
@@ -119,18 +205,46 @@ func (s *Server) createStockDistributionHandler(ctx *gin.Context) {
 		return
 	}
 
+	var dueDate *time.Time
+	if req.DueDate != "" {
+		date, err := pkg.StrToTime(req.DueDate)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid due_date format")))
+			return
+		}
+		dueDate = &date
+	}
+
+	strategy, batches, err := stockAllocation(req.AllocationStrategy, req.Batches)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	stockDistribution, err := s.repo.CompanyRepository.DistributeStockToReseller(ctx, &repository.StockDistribution{
-		ResellerID:      req.ResellerID,
-		ProductID:       req.ProductID,
-		Quantity:        int32(req.Quantity),
-		UnitPrice:       req.UnitPrice,
-		DateDistributed: dateDistributed,
+		ResellerID:         req.ResellerID,
+		ProductID:          req.ProductID,
+		Quantity:           int32(req.Quantity),
+		UnitPrice:          req.UnitPrice,
+		DateDistributed:    dateDistributed,
+		DeferInvoice:       req.DeferInvoice,
+		DueDate:            dueDate,
+		OverrideReason:     strings.TrimSpace(req.OverrideReason),
+		HoldOverLimit:      req.HoldIfOverLimit,
+		LocationID:         req.LocationID,
+		AllocationStrategy: strategy,
+		Batches:            batches,
 	})
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
 		return
 	}
 
+	if stockDistribution.CreditHold != nil && stockDistribution.CreditHold.Status == repository.CREDIT_HOLD_HELD {
+		ctx.JSON(http.StatusAccepted, gin.H{"data": stockDistribution.CreditHold})
+		return
+	}
+
 	ctx.JSON(http.StatusCreated, gin.H{"data": stockDistribution})
 }
 
@@ -158,6 +272,7 @@ func (s *Server) listStockDistributionsHandler(ctx *gin.Context) {
 		},
 		ResellerID: nil,
 		ProductID:  nil,
+		LocationID: nil,
 		Search:     nil,
 	}
 
@@ -183,6 +298,22 @@ func (s *Server) listStockDistributionsHandler(ctx *gin.Context) {
 		filter.ProductID = &productIDUint
 	}
 
+	if locationIDStr := ctx.Query("location_id"); locationIDStr != "" {
+		locationID, err := pkg.StringToUint32(locationIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid location_id format")))
+			return
+		}
+		filter.LocationID = &locationID
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "stock-distributions", filter.Pagination, stockDistributionExportColumns, func() ([]*repository.StockDistribution, *pkg.Pagination, error) {
+			return s.repo.CompanyRepository.ListStockDistributions(ctx, filter)
+		})
+		return
+	}
+
 	stockDistributions, pagination, err := s.repo.CompanyRepository.ListStockDistributions(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -214,8 +345,9 @@ func (s *Server) listCompanyStockHandler(ctx *gin.Context) {
 			Page:     uint32(pageNo),
 			PageSize: uint32(pageSize),
 		},
-		Search:  nil,
-		InStock: nil,
+		Search:     nil,
+		LocationID: nil,
+		InStock:    nil,
 	}
 
 	if search := ctx.Query("search"); search != "" {
@@ -227,6 +359,23 @@ func (s *Server) listCompanyStockHandler(ctx *gin.Context) {
 		filter.InStock = &inStock
 	}
 
+	// stock across every location is summed unless one is chosen
+	if locationIDStr := ctx.Query("location_id"); locationIDStr != "" {
+		locationID, err := pkg.StringToUint32(locationIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid location_id format")))
+			return
+		}
+		filter.LocationID = &locationID
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "company-stock", filter.Pagination, companyStockExportColumns, func() ([]*repository.CompanyStock, *pkg.Pagination, error) {
+			return s.repo.CompanyRepository.ListCompanyStock(ctx, filter)
+		})
+		return
+	}
+
 	companyStocks, pagination, err := s.repo.CompanyRepository.ListCompanyStock(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -235,3 +384,40 @@ func (s *Server) listCompanyStockHandler(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"data": companyStocks, "pagination": pagination})
 }
+
+var productBatchExportColumns = []exportColumn[*repository.ProductBatch]{
+	{Header: "ID", Value: func(b *repository.ProductBatch) any { return b.ID }},
+	{Header: "Batch Number", Value: func(b *repository.ProductBatch) any { return b.BatchNumber }},
+	{Header: "Product", Value: func(b *repository.ProductBatch) any { return exportProductName(b.Product) }},
+	{Header: "Location", Value: func(b *repository.ProductBatch) any { return exportLocationName(b.Location) }},
+	{Header: "Category", Value: func(b *repository.ProductBatch) any { return b.ProductCategory }},
+	{Header: "Quantity", Value: func(b *repository.ProductBatch) any { return b.Quantity }},
+	{Header: "Remaining Quantity", Value: func(b *repository.ProductBatch) any { return b.RemainingQuantity }},
+	{Header: "Purchase Price", Value: func(b *repository.ProductBatch) any { return b.PurchasePrice }},
+	{Header: "Unit Cost", Value: func(b *repository.ProductBatch) any { return b.UnitCost }},
+	{Header: "Date Received", Value: func(b *repository.ProductBatch) any { return b.DateReceived }},
+	{Header: "Expiry Date", Value: func(b *repository.ProductBatch) any { return b.ExpiryDate }},
+	{Header: "Purchase Order Line ID", Value: func(b *repository.ProductBatch) any { return exportOptionalID(b.PurchaseOrderLineID) }},
+	{Header: "Created At", Value: func(b *repository.ProductBatch) any { return b.CreatedAt }},
+}
+
+var stockDistributionExportColumns = []exportColumn[*repository.StockDistribution]{
+	{Header: "ID", Value: func(d *repository.StockDistribution) any { return d.ID }},
+	{Header: "Reseller", Value: func(d *repository.StockDistribution) any { return exportUserName(d.User) }},
+	{Header: "Reseller Phone", Value: func(d *repository.StockDistribution) any { return exportUserPhone(d.User) }},
+	{Header: "Product", Value: func(d *repository.StockDistribution) any { return exportProductName(d.Product) }},
+	{Header: "Location", Value: func(d *repository.StockDistribution) any { return exportLocationName(d.Location) }},
+	{Header: "Quantity", Value: func(d *repository.StockDistribution) any { return d.Quantity }},
+	{Header: "Unit Price", Value: func(d *repository.StockDistribution) any { return d.UnitPrice }},
+	{Header: "Total Price", Value: func(d *repository.StockDistribution) any { return d.TotalPrice }},
+	{Header: "Date Distributed", Value: func(d *repository.StockDistribution) any { return d.DateDistributed }},
+	{Header: "Invoice ID", Value: func(d *repository.StockDistribution) any { return exportOptionalID(d.InvoiceID) }},
+	{Header: "Created At", Value: func(d *repository.StockDistribution) any { return d.CreatedAt }},
+}
+
+var companyStockExportColumns = []exportColumn[*repository.CompanyStock]{
+	{Header: "Product ID", Value: func(c *repository.CompanyStock) any { return c.ProductID }},
+	{Header: "Product", Value: func(c *repository.CompanyStock) any { return exportProductName(c.Product) }},
+	{Header: "Category", Value: func(c *repository.CompanyStock) any { return c.ProductCategory }},
+	{Header: "Quantity", Value: func(c *repository.CompanyStock) any { return c.Quantity }},
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) listCreditHoldsHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.CreditHoldFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		ResellerID: nil,
+		Status:     nil,
+	}
+
+	if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+		resellerID, err := pkg.StringToUint32(resellerIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id format")))
+			return
+		}
+		filter.ResellerID = &resellerID
+	}
+
+	if status := strings.ToUpper(ctx.Query("status")); status != "" {
+		if status != repository.CREDIT_HOLD_HELD && status != repository.CREDIT_HOLD_OVERRIDDEN && status != repository.CREDIT_HOLD_REJECTED {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid status")))
+			return
+		}
+		filter.Status = &status
+	}
+
+	holds, pagination, err := s.repo.CompanyRepository.ListCreditHolds(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       holds,
+		"pagination": pagination,
+	})
+}
+
+type overrideCreditHoldRequest struct {
+	Justification string `json:"justification" binding:"required"`
+}
+
+func (s *Server) overrideCreditHoldHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req overrideCreditHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	justification := strings.TrimSpace(req.Justification)
+	if justification == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "justification is required")))
+		return
+	}
+
+	hold, err := s.repo.CompanyRepository.OverrideCreditHold(ctx, id, justification)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": hold})
+}
+
+type rejectCreditHoldRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (s *Server) rejectCreditHoldHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req rejectCreditHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reason is required")))
+		return
+	}
+
+	hold, err := s.repo.CompanyRepository.RejectCreditHold(ctx, id, reason)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": hold})
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	EXPORT_FORMAT_CSV  = "csv"
+	EXPORT_FORMAT_XLSX = "xlsx"
+
+	// rows fetched per query while exporting, only one page is held in memory at a time
+	exportPageSize = 500
+)
+
+type exportColumn[T any] struct {
+	Header string
+	Value  func(T) any
+}
+
+// exportFormat returns the requested export format, ok is false for the default json response.
+func exportFormat(ctx *gin.Context) (string, bool) {
+	format := strings.ToLower(ctx.Query("format"))
+	if format == "" || format == "json" {
+		return "", false
+	}
+
+	return format, true
+}
+
+// exportList streams every row matching the filter behind list as a csv or xlsx download.
+// list is called once per page with pagination advanced in place, so it must read the same
+// *pkg.Pagination that is passed in here.
+func exportList[T any](ctx *gin.Context, format, name string, pagination *pkg.Pagination, columns []exportColumn[T], list func() ([]T, *pkg.Pagination, error)) {
+	if format != EXPORT_FORMAT_CSV && format != EXPORT_FORMAT_XLSX {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid format: %s", format)))
+		return
+	}
+
+	pagination.Page = 1
+	pagination.PageSize = exportPageSize
+
+	// fetch the first page before writing any headers so errors can still be returned as json
+	items, page, err := list()
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	// exports can outlive the server write timeout
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("failed to clear write deadline for export: %v", err)
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	var writer tableWriter
+	if format == EXPORT_FORMAT_CSV {
+		ctx.Header("Content-Type", "text/csv")
+		writer = newCSVTableWriter(ctx.Writer)
+	} else {
+		ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		writer, err = newXLSXTableWriter(ctx.Writer)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create xlsx writer: %s", err.Error())))
+			return
+		}
+	}
+	ctx.Status(http.StatusOK)
+
+	headers := make([]any, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+	}
+
+	if err := writer.WriteRow(headers); err != nil {
+		log.Printf("failed to write %s export header: %v", name, err)
+		return
+	}
+
+	for {
+		for _, item := range items {
+			row := make([]any, len(columns))
+			for i, column := range columns {
+				row[i] = column.Value(item)
+			}
+
+			if err := writer.WriteRow(row); err != nil {
+				log.Printf("failed to write %s export row: %v", name, err)
+				return
+			}
+		}
+
+		if page == nil || !page.HasNext {
+			break
+		}
+
+		pagination.Page++
+		items, page, err = list()
+		if err != nil {
+			// the response has already started so the download is cut short instead
+			log.Printf("failed to list %s for export: %v", name, err)
+			return
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		log.Printf("failed to finish %s export: %v", name, err)
+	}
+}
+
+type tableWriter interface {
+	WriteRow(row []any) error
+	Close() error
+}
+
+type csvTableWriter struct {
+	w      *csv.Writer
+	flush  http.Flusher
+	rowNum int
+}
+
+func newCSVTableWriter(w gin.ResponseWriter) *csvTableWriter {
+	return &csvTableWriter{
+		w:     csv.NewWriter(w),
+		flush: w,
+	}
+}
+
+func (c *csvTableWriter) WriteRow(row []any) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		record[i] = exportCellString(value)
+	}
+
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+
+	// push rows to the client as they are written instead of holding them in the buffer
+	c.rowNum++
+	if c.rowNum%exportPageSize == 0 {
+		c.w.Flush()
+		c.flush.Flush()
+	}
+
+	return c.w.Error()
+}
+
+func (c *csvTableWriter) Close() error {
+	c.w.Flush()
+	c.flush.Flush()
+
+	return c.w.Error()
+}
+
+// xlsxTableWriter uses excelize's stream writer which spills rows to a temp file
+// instead of keeping the whole sheet in memory.
+type xlsxTableWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	rowNum int
+}
+
+func newXLSXTableWriter(out io.Writer) (*xlsxTableWriter, error) {
+	file := excelize.NewFile()
+
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &xlsxTableWriter{
+		out:    out,
+		file:   file,
+		stream: stream,
+	}, nil
+}
+
+func (x *xlsxTableWriter) WriteRow(row []any) error {
+	x.rowNum++
+	cell, err := excelize.CoordinatesToCellName(1, x.rowNum)
+	if err != nil {
+		return err
+	}
+
+	values := make([]any, len(row))
+	for i, value := range row {
+		// keep numbers as numbers so they can be summed in the spreadsheet
+		switch v := value.(type) {
+		case time.Time, *time.Time:
+			values[i] = exportCellString(v)
+		default:
+			values[i] = v
+		}
+	}
+
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *xlsxTableWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+
+	_, err := x.file.WriteTo(x.out)
+
+	return err
+}
+
+func exportCellString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return exportCellString(*v)
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func exportUserName(user *repository.UserShort) string {
+	if user == nil {
+		return ""
+	}
+
+	return user.Name
+}
+
+func exportUserPhone(user *repository.UserShort) string {
+	if user == nil {
+		return ""
+	}
+
+	return user.PhoneNumber
+}
+
+func exportProductName(product *repository.ProductShort) string {
+	if product == nil {
+		return ""
+	}
+
+	return product.Name
+}
+
+func exportLocationName(location *repository.LocationShort) string {
+	if location == nil {
+		return ""
+	}
+
+	return location.Name
+}
+
+func exportSupplierName(supplier *repository.SupplierShort) string {
+	if supplier == nil {
+		return ""
+	}
+
+	return supplier.Name
+}
+
+func exportOptionalID(id *uint32) any {
+	if id == nil {
+		return ""
+	}
+
+	return *id
+}
+
+func exportOptionalAmount(amount *float64) any {
+	if amount == nil {
+		return ""
+	}
+
+	return *amount
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -98,17 +99,11 @@ func (s *Server) listGoodRequestsHandler(ctx *gin.Context) {
 	}
 	payload := authPayload.(*pkg.Payload)
 
-	var goodRequests []*repository.GoodsRequest
-	var pagination *pkg.Pagination
+	listGoodRequests := s.repo.CompanyRepository.ListGoodsRequestsByAdmin
 
 	if strings.ToLower(payload.Role) != repository.ADMIN_ROLE {
 		filter.ResellerID = &payload.UserID
-
-		goodRequests, pagination, err = s.repo.ResellerRepository.ListGoodsRequestsByReseller(ctx, filter)
-		if err != nil {
-			ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
-			return
-		}
+		listGoodRequests = s.repo.ResellerRepository.ListGoodsRequestsByReseller
 	} else {
 		if resellerIdStr := ctx.Query("reseller_id"); resellerIdStr != "" {
 			resellerId, err := pkg.StringToUint32(resellerIdStr)
@@ -118,12 +113,19 @@ func (s *Server) listGoodRequestsHandler(ctx *gin.Context) {
 			}
 			filter.ResellerID = &resellerId
 		}
+	}
 
-		goodRequests, pagination, err = s.repo.CompanyRepository.ListGoodsRequestsByAdmin(ctx, filter)
-		if err != nil {
-			ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
-			return
-		}
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "goods-requests", filter.Pagination, goodsRequestExportColumns, func() ([]*repository.GoodsRequest, *pkg.Pagination, error) {
+			return listGoodRequests(ctx, filter)
+		})
+		return
+	}
+
+	goodRequests, pagination, err := listGoodRequests(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"data": goodRequests, "pagination": pagination})
@@ -204,3 +206,21 @@ func (s *Server) updateGoodRequestByAdminHandler(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"data": goodRequest})
 }
+
+var goodsRequestExportColumns = []exportColumn[*repository.GoodsRequest]{
+	{Header: "ID", Value: func(g *repository.GoodsRequest) any { return g.ID }},
+	{Header: "Reseller", Value: func(g *repository.GoodsRequest) any { return exportUserName(g.User) }},
+	{Header: "Items", Value: func(g *repository.GoodsRequest) any {
+		items := make([]string, len(g.Payload))
+		for i, item := range g.Payload {
+			items[i] = fmt.Sprintf("%s x %d @ %.2f", item.ProductName, item.Quantity, item.PriceRequested)
+		}
+		return strings.Join(items, "; ")
+	}},
+	{Header: "Status", Value: func(g *repository.GoodsRequest) any { return g.Status }},
+	{Header: "Comment", Value: func(g *repository.GoodsRequest) any { return g.Comment }},
+	{Header: "Cancelled", Value: func(g *repository.GoodsRequest) any { return g.Cancelled }},
+	{Header: "Cancelled At", Value: func(g *repository.GoodsRequest) any { return g.CancelledAt }},
+	{Header: "Updated At", Value: func(g *repository.GoodsRequest) any { return g.UpdatedAt }},
+	{Header: "Created At", Value: func(g *repository.GoodsRequest) any { return g.CreatedAt }},
+}
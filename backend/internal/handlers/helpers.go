@@ -37,7 +37,18 @@ func (s *Server) getAdminPageStatsHandler(ctx *gin.Context) {
 		return
 	}
 
-	stats, err := s.repo.CompanyRepository.GetAdminPageData(ctx, page)
+	// stock figures can be narrowed to one location, all locations are counted otherwise
+	var locationID *uint32
+	if locationIDStr := ctx.Query("location_id"); locationIDStr != "" {
+		id, err := pkg.StringToUint32(locationIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid location_id format")))
+			return
+		}
+		locationID = &id
+	}
+
+	stats, err := s.repo.CompanyRepository.GetAdminPageData(ctx, page, locationID)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
 		return
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type createInvoiceRequest struct {
+	DistributionIDs []uint32 `json:"distribution_ids" binding:"required,min=1,unique"`
+	DueDate         string   `json:"due_date"`
+}
+
+func (s *Server) createInvoiceHandler(ctx *gin.Context) {
+	var req createInvoiceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var dueDate *time.Time
+	if req.DueDate != "" {
+		date, err := pkg.StrToTime(req.DueDate)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid due_date format")))
+			return
+		}
+		dueDate = &date
+	}
+
+	invoice, err := s.repo.InvoiceRepository.CreateInvoice(ctx, req.DistributionIDs, dueDate)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": invoice})
+}
+
+func (s *Server) getInvoiceHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	invoice, err := s.repo.InvoiceRepository.GetInvoice(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	// resellers can only see their own invoices
+	if strings.ToLower(payload.Role) != repository.ADMIN_ROLE && invoice.ResellerID != payload.UserID {
+		ctx.JSON(http.StatusNotFound, errorResponse(pkg.Errorf(pkg.NOT_FOUND_ERROR, "invoice not found")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": invoice})
+}
+
+func (s *Server) listInvoicesHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.InvoiceFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		ResellerID: nil,
+		Status:     nil,
+		Open:       nil,
+		Search:     nil,
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	// if is user add the reseller_id filter
+	if strings.ToLower(payload.Role) != repository.ADMIN_ROLE {
+		filter.ResellerID = &payload.UserID
+	} else {
+		if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+			resellerID, err := pkg.StringToUint32(resellerIDStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id format")))
+				return
+			}
+			filter.ResellerID = &resellerID
+		}
+	}
+
+	if status := strings.ToUpper(ctx.Query("status")); status != "" {
+		if status != repository.INVOICE_UNPAID && status != repository.INVOICE_PARTIAL && status != repository.INVOICE_PAID {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid status")))
+			return
+		}
+		filter.Status = &status
+	}
+
+	if openStr := ctx.Query("open"); openStr != "" {
+		open := pkg.StringToBool(openStr)
+		filter.Open = &open
+	}
+
+	if search := ctx.Query("search"); search != "" {
+		filter.Search = &search
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "invoices", filter.Pagination, invoiceExportColumns, func() ([]*repository.Invoice, *pkg.Pagination, error) {
+			return s.repo.InvoiceRepository.ListInvoices(ctx, filter)
+		})
+		return
+	}
+
+	invoices, pagination, err := s.repo.InvoiceRepository.ListInvoices(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       invoices,
+		"pagination": pagination,
+	})
+}
+
+var invoiceExportColumns = []exportColumn[*repository.Invoice]{
+	{Header: "ID", Value: func(i *repository.Invoice) any { return i.ID }},
+	{Header: "Invoice Number", Value: func(i *repository.Invoice) any { return i.InvoiceNumber }},
+	{Header: "Reseller", Value: func(i *repository.Invoice) any { return exportUserName(i.User) }},
+	{Header: "Reseller Phone", Value: func(i *repository.Invoice) any { return exportUserPhone(i.User) }},
+	{Header: "Total Amount", Value: func(i *repository.Invoice) any { return i.TotalAmount }},
+	{Header: "Amount Paid", Value: func(i *repository.Invoice) any { return i.AmountPaid }},
+	{Header: "Balance", Value: func(i *repository.Invoice) any { return i.Balance }},
+	{Header: "Status", Value: func(i *repository.Invoice) any { return i.Status }},
+	{Header: "Overdue", Value: func(i *repository.Invoice) any { return i.Overdue }},
+	{Header: "Issue Date", Value: func(i *repository.Invoice) any { return i.IssueDate }},
+	{Header: "Due Date", Value: func(i *repository.Invoice) any { return i.DueDate }},
+	{Header: "Created At", Value: func(i *repository.Invoice) any { return i.CreatedAt }},
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type createLandedCostRequest struct {
+	CostType string  `json:"cost_type" binding:"required"`
+	Amount   float64 `json:"amount" binding:"required,gt=0"`
+	// QUANTITY or VALUE, the cost is split by quantity in stock when left out
+	AllocationMethod string   `json:"allocation_method"`
+	Reference        string   `json:"reference" binding:"max=100"`
+	Note             string   `json:"note"`
+	BatchIDs         []uint32 `json:"batch_ids" binding:"required,min=1"`
+}
+
+func (s *Server) createLandedCostHandler(ctx *gin.Context) {
+	var req createLandedCostRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	costType := strings.ToUpper(req.CostType)
+	switch costType {
+	case repository.LANDED_COST_FREIGHT, repository.LANDED_COST_CLEARING, repository.LANDED_COST_DUTY, repository.LANDED_COST_OTHER:
+	default:
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "cost_type must be one of FREIGHT, CLEARING, DUTY or OTHER")))
+		return
+	}
+
+	method := strings.ToUpper(req.AllocationMethod)
+	if method == "" {
+		method = repository.LANDED_COST_BY_QUANTITY
+	}
+	if method != repository.LANDED_COST_BY_QUANTITY && method != repository.LANDED_COST_BY_VALUE {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "allocation_method must be QUANTITY or VALUE")))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	cost, err := s.repo.LandedCostRepository.CreateLandedCost(ctx, &repository.LandedCost{
+		CostType:         costType,
+		Amount:           req.Amount,
+		AllocationMethod: method,
+		Reference:        strings.TrimSpace(req.Reference),
+		Note:             strings.TrimSpace(req.Note),
+		CreatedBy:        payload.UserID,
+		BatchIDs:         req.BatchIDs,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": cost})
+}
+
+func (s *Server) getLandedCostHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	cost, err := s.repo.LandedCostRepository.GetLandedCost(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": cost})
+}
+
+func (s *Server) listLandedCostsHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.LandedCostFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		CostType:  nil,
+		BatchID:   nil,
+		ProductID: nil,
+	}
+
+	if costType := strings.ToUpper(ctx.Query("cost_type")); costType != "" {
+		filter.CostType = &costType
+	}
+
+	if batchIDStr := ctx.Query("batch_id"); batchIDStr != "" {
+		batchID, err := pkg.StringToUint32(batchIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid batch_id format")))
+			return
+		}
+		filter.BatchID = &batchID
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id format")))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "landed-costs", filter.Pagination, landedCostExportColumns, func() ([]*repository.LandedCost, *pkg.Pagination, error) {
+			return s.repo.LandedCostRepository.ListLandedCosts(ctx, filter)
+		})
+		return
+	}
+
+	costs, pagination, err := s.repo.LandedCostRepository.ListLandedCosts(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       costs,
+		"pagination": pagination,
+	})
+}
+
+var landedCostExportColumns = []exportColumn[*repository.LandedCost]{
+	{Header: "ID", Value: func(c *repository.LandedCost) any { return c.ID }},
+	{Header: "Cost Type", Value: func(c *repository.LandedCost) any { return c.CostType }},
+	{Header: "Amount", Value: func(c *repository.LandedCost) any { return c.Amount }},
+	{Header: "Allocation Method", Value: func(c *repository.LandedCost) any { return c.AllocationMethod }},
+	{Header: "Batches", Value: func(c *repository.LandedCost) any { return c.BatchCount }},
+	{Header: "Reference", Value: func(c *repository.LandedCost) any { return c.Reference }},
+	{Header: "Note", Value: func(c *repository.LandedCost) any { return c.Note }},
+	{Header: "Created At", Value: func(c *repository.LandedCost) any { return c.CreatedAt }},
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type createLocationRequest struct {
+	Name    string `json:"name" binding:"required,max=100"`
+	Address string `json:"address"`
+}
+
+func (s *Server) createLocationHandler(ctx *gin.Context) {
+	var req createLocationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "name is required")))
+		return
+	}
+
+	location, err := s.repo.LocationRepository.CreateLocation(ctx, &repository.Location{
+		Name:    name,
+		Address: strings.TrimSpace(req.Address),
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": location})
+}
+
+func (s *Server) getLocationHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	location, err := s.repo.LocationRepository.GetLocation(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": location})
+}
+
+func (s *Server) updateLocationHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req repository.LocationUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "name cannot be empty")))
+			return
+		}
+		req.Name = &name
+	}
+
+	location, err := s.repo.LocationRepository.UpdateLocation(ctx, id, &req)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": location})
+}
+
+func (s *Server) listLocationsHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.LocationFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		Search: nil,
+		Active: nil,
+	}
+
+	if search := ctx.Query("search"); search != "" {
+		filter.Search = &search
+	}
+
+	if activeStr := ctx.Query("active"); activeStr != "" {
+		active := pkg.StringToBool(activeStr)
+		filter.Active = &active
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "locations", filter.Pagination, locationExportColumns, func() ([]*repository.Location, *pkg.Pagination, error) {
+			return s.repo.LocationRepository.ListLocations(ctx, filter)
+		})
+		return
+	}
+
+	locations, pagination, err := s.repo.LocationRepository.ListLocations(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       locations,
+		"pagination": pagination,
+	})
+}
+
+type transferLocationStockRequest struct {
+	FromLocationID uint32 `json:"from_location_id" binding:"required"`
+	ToLocationID   uint32 `json:"to_location_id" binding:"required"`
+	ProductID      uint32 `json:"product_id" binding:"required"`
+	Quantity       uint32 `json:"quantity" binding:"required,gt=0"`
+	// take the units from one batch instead of the batches expiring first
+	BatchID *uint32 `json:"batch_id"`
+	Note    string  `json:"note"`
+}
+
+func (s *Server) createLocationTransferHandler(ctx *gin.Context) {
+	var req transferLocationStockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	if req.FromLocationID == req.ToLocationID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "cannot transfer stock to the same location")))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	transfer, err := s.repo.LocationRepository.TransferStock(ctx, &repository.LocationTransfer{
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		ProductID:      req.ProductID,
+		BatchID:        req.BatchID,
+		Quantity:       int32(req.Quantity),
+		Note:           strings.TrimSpace(req.Note),
+		CreatedBy:      payload.UserID,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": transfer})
+}
+
+func (s *Server) listLocationTransfersHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.LocationTransferFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		LocationID: nil,
+		ProductID:  nil,
+	}
+
+	if locationIDStr := ctx.Query("location_id"); locationIDStr != "" {
+		locationID, err := pkg.StringToUint32(locationIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid location_id format")))
+			return
+		}
+		filter.LocationID = &locationID
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id format")))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "location-transfers", filter.Pagination, locationTransferExportColumns, func() ([]*repository.LocationTransfer, *pkg.Pagination, error) {
+			return s.repo.LocationRepository.ListLocationTransfers(ctx, filter)
+		})
+		return
+	}
+
+	transfers, pagination, err := s.repo.LocationRepository.ListLocationTransfers(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       transfers,
+		"pagination": pagination,
+	})
+}
+
+var locationExportColumns = []exportColumn[*repository.Location]{
+	{Header: "ID", Value: func(l *repository.Location) any { return l.ID }},
+	{Header: "Name", Value: func(l *repository.Location) any { return l.Name }},
+	{Header: "Address", Value: func(l *repository.Location) any { return l.Address }},
+	{Header: "Default", Value: func(l *repository.Location) any { return l.IsDefault }},
+	{Header: "Active", Value: func(l *repository.Location) any { return l.Active }},
+	{Header: "Total Quantity", Value: func(l *repository.Location) any { return l.TotalQuantity }},
+	{Header: "Total Value", Value: func(l *repository.Location) any { return l.TotalValue }},
+	{Header: "Created At", Value: func(l *repository.Location) any { return l.CreatedAt }},
+}
+
+var locationTransferExportColumns = []exportColumn[*repository.LocationTransfer]{
+	{Header: "ID", Value: func(t *repository.LocationTransfer) any { return t.ID }},
+	{Header: "From Location", Value: func(t *repository.LocationTransfer) any { return exportLocationName(t.FromLocation) }},
+	{Header: "To Location", Value: func(t *repository.LocationTransfer) any { return exportLocationName(t.ToLocation) }},
+	{Header: "Product", Value: func(t *repository.LocationTransfer) any { return exportProductName(t.Product) }},
+	{Header: "Batch ID", Value: func(t *repository.LocationTransfer) any { return exportOptionalID(t.BatchID) }},
+	{Header: "Quantity", Value: func(t *repository.LocationTransfer) any { return t.Quantity }},
+	{Header: "Total Value", Value: func(t *repository.LocationTransfer) any { return t.TotalValue }},
+	{Header: "Note", Value: func(t *repository.LocationTransfer) any { return t.Note }},
+	{Header: "Created At", Value: func(t *repository.LocationTransfer) any { return t.CreatedAt }},
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/internal/services"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type stkPushRequest struct {
+	// whole shillings, defaults to the outstanding balance
+	Amount      int64  `json:"amount" binding:"omitempty,gt=0"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+func (s *Server) initiateStkPushHandler(ctx *gin.Context) {
+	var req stkPushRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	if strings.ToLower(payload.Role) == repository.ADMIN_ROLE {
+		ctx.JSON(http.StatusForbidden, errorResponse(pkg.Errorf(pkg.FORBIDDEN_ERROR, "only resellers can pay with stk push")))
+		return
+	}
+
+	// the result could never be received so the request would stay pending
+	if s.config.MPESA_CALLBACK_TOKEN == "" {
+		ctx.JSON(http.StatusServiceUnavailable, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "mpesa callbacks are not configured")))
+		return
+	}
+
+	reseller, err := s.repo.ResellerRepository.GetResellerByID(ctx, payload.UserID)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	// mpesa only accepts whole shillings so any cents left on the balance are rounded up
+	outstanding := int64(math.Ceil(math.Round(reseller.Account.Balance*100) / 100))
+	if outstanding <= 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "no outstanding balance to pay")))
+		return
+	}
+
+	amount := outstanding
+	if req.Amount != 0 {
+		if req.Amount > outstanding {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "amount exceeds outstanding balance of KES %d", outstanding)))
+			return
+		}
+		amount = req.Amount
+	}
+
+	phoneNumber := req.PhoneNumber
+	if phoneNumber == "" {
+		phoneNumber = reseller.User.PhoneNumber
+	}
+
+	phoneNumber, err = mpesaPhoneNumber(phoneNumber)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	resp, err := s.mpesa.StkPush(ctx, &services.StkPushRequest{
+		PhoneNumber:      phoneNumber,
+		Amount:           amount,
+		AccountReference: fmt.Sprintf("%s%d", repository.MPESA_ACCOUNT_PREFIX, reseller.User.ID),
+		Description:      "Balance payment",
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	request, err := s.repo.MpesaRepository.CreateStkRequest(ctx, &repository.MpesaStkRequest{
+		ResellerID:        reseller.User.ID,
+		PhoneNumber:       phoneNumber,
+		Amount:            float64(amount),
+		MerchantRequestID: resp.MerchantRequestID,
+		CheckoutRequestID: resp.CheckoutRequestID,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"data":    request,
+		"message": resp.CustomerMessage,
+	})
+}
+
+func (s *Server) getStkPushHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	request, err := s.repo.MpesaRepository.GetStkRequest(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	// resellers can only follow their own requests
+	if strings.ToLower(payload.Role) != repository.ADMIN_ROLE && request.ResellerID != payload.UserID {
+		ctx.JSON(http.StatusNotFound, errorResponse(pkg.Errorf(pkg.NOT_FOUND_ERROR, "stk request not found")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": request})
+}
+
+// mpesaStkCallbackHandler receives stk push results from daraja.
+func (s *Server) mpesaStkCallbackHandler(ctx *gin.Context) {
+	if !s.validMpesaCallback(ctx) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid callback")))
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	result, err := s.mpesa.ParseStkCallback(body)
+	if err != nil {
+		log.Printf("invalid stk callback: %v", err)
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	request, err := s.repo.MpesaRepository.CompleteStkRequest(ctx, result)
+	if err != nil {
+		log.Printf("failed to complete stk request %s: %v", result.CheckoutRequestID, err)
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	log.Printf("stk request %s completed with status %s", request.CheckoutRequestID, request.Status)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"ResultCode": 0,
+		"ResultDesc": "Accepted",
+	})
+}
+
+// mpesaC2BValidationHandler is called by daraja before a paybill payment is completed.
+// Payments are accepted even when the payer can't be matched since those are kept in
+// suspense on confirmation.
+func (s *Server) mpesaC2BValidationHandler(ctx *gin.Context) {
+	if !s.validMpesaCallback(ctx) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid callback")))
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	if _, err := s.mpesa.ParseC2BTransaction(body); err != nil {
+		log.Printf("rejecting c2b transaction: %v", err)
+		ctx.JSON(http.StatusOK, gin.H{
+			"ResultCode": "C2B00016",
+			"ResultDesc": "Rejected",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"ResultCode": "0",
+		"ResultDesc": "Accepted",
+	})
+}
+
+func (s *Server) mpesaC2BConfirmationHandler(ctx *gin.Context) {
+	if !s.validMpesaCallback(ctx) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid callback")))
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	transaction, err := s.mpesa.ParseC2BTransaction(body)
+	if err != nil {
+		log.Printf("invalid c2b confirmation: %v", err)
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	transaction, err = s.repo.MpesaRepository.RecordC2BTransaction(ctx, transaction)
+	if err != nil {
+		log.Printf("failed to record c2b transaction: %v", err)
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	log.Printf("c2b transaction %s recorded with status %s", transaction.TransID, transaction.Status)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"ResultCode": "0",
+		"ResultDesc": "Accepted",
+	})
+}
+
+func (s *Server) listMpesaSuspenseHandler(ctx *gin.Context) {
+	pageNo, err := pkg.StringToInt64(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSize, err := pkg.StringToInt64(ctx.DefaultQuery("limit", "10"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	// the suspense queue by default, status=ALLOCATED shows matched transactions instead
+	status := strings.ToUpper(ctx.DefaultQuery("status", repository.MPESA_C2B_SUSPENSE))
+	if status != repository.MPESA_C2B_SUSPENSE && status != repository.MPESA_C2B_ALLOCATED {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid status: %s", status)))
+		return
+	}
+
+	filter := &repository.MpesaC2BFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		Status: &status,
+		Search: nil,
+	}
+
+	if search := ctx.Query("search"); search != "" {
+		filter.Search = &search
+	}
+
+	transactions, pagination, err := s.repo.MpesaRepository.ListC2BTransactions(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       transactions,
+		"pagination": pagination,
+	})
+}
+
+type allocateMpesaSuspenseRequest struct {
+	ResellerID uint32 `json:"reseller_id" binding:"required"`
+}
+
+func (s *Server) allocateMpesaSuspenseHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req allocateMpesaSuspenseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	transaction, err := s.repo.MpesaRepository.AllocateC2BTransaction(ctx, id, req.ResellerID)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": transaction})
+}
+
+// validMpesaCallback checks the token added to the callback urls registered with daraja and
+// that the request came from one of MPESA_CALLBACK_IPS since the callback routes are public.
+// Nothing is accepted when no token is configured.
+func (s *Server) validMpesaCallback(ctx *gin.Context) bool {
+	if s.config.MPESA_CALLBACK_TOKEN == "" {
+		return false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(ctx.Query("token")), []byte(s.config.MPESA_CALLBACK_TOKEN)) != 1 {
+		return false
+	}
+
+	if len(s.config.MPESA_CALLBACK_IPS) == 0 {
+		return true
+	}
+
+	clientIP := net.ParseIP(ctx.ClientIP())
+	if clientIP == nil {
+		return false
+	}
+
+	for _, allowed := range s.config.MPESA_CALLBACK_IPS {
+		allowed = strings.TrimSpace(allowed)
+		if _, network, err := net.ParseCIDR(allowed); err == nil {
+			if network.Contains(clientIP) {
+				return true
+			}
+			continue
+		}
+
+		if ip := net.ParseIP(allowed); ip != nil && ip.Equal(clientIP) {
+			return true
+		}
+	}
+
+	log.Printf("rejecting mpesa callback from %s", clientIP)
+
+	return false
+}
+
+// mpesaPhoneNumber converts a kenyan phone number to the 2547XXXXXXXX form daraja expects.
+func mpesaPhoneNumber(phoneNumber string) (string, error) {
+	phone := strings.NewReplacer(" ", "", "-", "", "+", "").Replace(phoneNumber)
+
+	switch {
+	case strings.HasPrefix(phone, "254"):
+	case strings.HasPrefix(phone, "0"):
+		phone = "254" + phone[1:]
+	default:
+		phone = "254" + phone
+	}
+
+	if len(phone) != 12 {
+		return "", pkg.Errorf(pkg.INVALID_ERROR, "invalid phone number: %s", phoneNumber)
+	}
+
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", pkg.Errorf(pkg.INVALID_ERROR, "invalid phone number: %s", phoneNumber)
+		}
+	}
+
+	return phone, nil
+}
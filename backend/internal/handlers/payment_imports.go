@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// statements are read into memory to be parsed
+const maxStatementFileSize = 5 << 20
+
+func (s *Server) previewPaymentImportHandler(ctx *gin.Context) {
+	source := strings.ToUpper(ctx.PostForm("source"))
+	if source != repository.PAYMENT_IMPORT_SOURCE_MPESA && source != repository.PAYMENT_IMPORT_SOURCE_BANK {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "source must be MPESA or BANK")))
+		return
+	}
+
+	// the payment method the rows are recorded with
+	method := strings.ToUpper(strings.TrimSpace(ctx.PostForm("method")))
+	if method == "" {
+		method = "MPESA"
+		if source == repository.PAYMENT_IMPORT_SOURCE_BANK {
+			method = "BANK_TRANSFER"
+		}
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "file is required: %s", err.Error())))
+		return
+	}
+
+	if fileHeader.Size > maxStatementFileSize {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "file is larger than %d MB", maxStatementFileSize>>20)))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to open file: %s", err.Error())))
+		return
+	}
+	defer file.Close()
+
+	rows, err := parsePaymentStatement(file, source)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "failed to parse statement: %s", err.Error())))
+		return
+	}
+
+	if len(rows) == 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "no payments received found in the statement")))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	paymentImport, err := s.repo.PaymentImportRepository.PreviewPaymentImport(ctx, &repository.PaymentImport{
+		Source:     source,
+		Method:     method,
+		FileName:   fileHeader.Filename,
+		UploadedBy: payload.UserID,
+		Rows:       rows,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": paymentImport})
+}
+
+func (s *Server) getPaymentImportHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	paymentImport, err := s.repo.PaymentImportRepository.GetPaymentImport(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": paymentImport})
+}
+
+func (s *Server) listPaymentImportsHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.PaymentImportFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		Status: nil,
+	}
+
+	if status := strings.ToUpper(ctx.Query("status")); status != "" {
+		if status != repository.PAYMENT_IMPORT_PREVIEW && status != repository.PAYMENT_IMPORT_COMMITTED {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid status")))
+			return
+		}
+		filter.Status = &status
+	}
+
+	paymentImports, pagination, err := s.repo.PaymentImportRepository.ListPaymentImports(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       paymentImports,
+		"pagination": pagination,
+	})
+}
+
+type commitPaymentImportRowRequest struct {
+	RowID uint32 `json:"row_id" binding:"required"`
+	// picks the reseller for unmatched rows or replaces the matched one
+	ResellerID *uint32 `json:"reseller_id"`
+}
+
+type commitPaymentImportRequest struct {
+	Rows []commitPaymentImportRowRequest `json:"rows" binding:"required,min=1,dive"`
+}
+
+func (s *Server) commitPaymentImportHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req commitPaymentImportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	decisions := make([]*repository.PaymentImportDecision, len(req.Rows))
+	for i, row := range req.Rows {
+		decisions[i] = &repository.PaymentImportDecision{
+			RowID:      row.RowID,
+			ResellerID: row.ResellerID,
+		}
+	}
+
+	paymentImport, err := s.repo.PaymentImportRepository.CommitPaymentImport(ctx, id, decisions)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": paymentImport})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type createPaymentMethodRequest struct {
+	// codes are upper case, e.g. BANK_TRANSFER, and are what payments record as their method
+	Code              string `json:"code" binding:"required,max=20"`
+	Name              string `json:"name" binding:"required"`
+	ReferenceRequired bool   `json:"reference_required"`
+	ReferencePattern  string `json:"reference_pattern"`
+	Active            *bool  `json:"active"`
+}
+
+func (s *Server) createPaymentMethodHandler(ctx *gin.Context) {
+	var req createPaymentMethodRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if !paymentMethodCodeValid(code) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "code must start with a letter and only contain letters, digits and underscores")))
+		return
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	method, err := s.repo.PaymentRepository.CreatePaymentMethod(ctx, &repository.PaymentMethod{
+		Code:              code,
+		Name:              strings.TrimSpace(req.Name),
+		ReferenceRequired: req.ReferenceRequired,
+		ReferencePattern:  strings.TrimSpace(req.ReferencePattern),
+		Active:            active,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": method})
+}
+
+func (s *Server) updatePaymentMethodHandler(ctx *gin.Context) {
+	code := strings.ToUpper(ctx.Param("code"))
+
+	var req repository.PaymentMethodUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "name cannot be empty")))
+		return
+	}
+
+	method, err := s.repo.PaymentRepository.UpdatePaymentMethod(ctx, code, &req)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": method})
+}
+
+func (s *Server) listPaymentMethodsHandler(ctx *gin.Context) {
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	// resellers only see the methods they can pay with
+	var active *bool
+	if strings.ToLower(payload.Role) != repository.ADMIN_ROLE {
+		activeOnly := true
+		active = &activeOnly
+	} else if activeStr := ctx.Query("active"); activeStr != "" {
+		activeOnly := pkg.StringToBool(activeStr)
+		active = &activeOnly
+	}
+
+	methods, err := s.repo.PaymentRepository.ListPaymentMethods(ctx, active)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": methods})
+}
+
+func paymentMethodCodeValid(code string) bool {
+	if code == "" || code[0] < 'A' || code[0] > 'Z' {
+		return false
+	}
+
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+var (
+	statementDateLayouts = []string{
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02T15:04:05Z07:00",
+		"2006-01-02",
+		"02/01/2006 15:04:05",
+		"02/01/2006 15:04",
+		"02/01/2006",
+		"02-01-2006 15:04:05",
+		"02-01-2006",
+		"02 Jan 2006",
+		"02-Jan-2006",
+		"02-Jan-06",
+	}
+
+	// resellers are asked to quote their account number, BOFFO-<id>, in bank transfer narrations
+	statementAccountPattern = regexp.MustCompile(`(?i)` + regexp.QuoteMeta(repository.MPESA_ACCOUNT_PREFIX) + `\d+`)
+	statementPhonePattern   = regexp.MustCompile(`(?:\+?254|0)[17]\d{8}`)
+)
+
+// statementColumns maps the fields read from a statement to the header names banks and
+// safaricom use for them. Headers are compared lower cased without dots.
+type statementColumns struct {
+	reference []string
+	date      []string
+	amount    []string
+	details   []string
+	status    []string
+	party     []string
+	account   []string
+	// m-pesa payments can only be told apart by their receipt number
+	requireReference bool
+}
+
+var mpesaStatementColumns = statementColumns{
+	reference: []string{"receipt no", "receipt number"},
+	date:      []string{"completion time"},
+	amount:    []string{"paid in"},
+	details:   []string{"details"},
+	status:    []string{"transaction status"},
+	party:     []string{"other party info"},
+	account:   []string{"a/c no", "account no"},
+
+	requireReference: true,
+}
+
+var bankStatementColumns = statementColumns{
+	reference: []string{"reference", "ref", "ref no", "reference number", "transaction reference", "cheque no"},
+	date:      []string{"transaction date", "date", "value date", "posting date", "trans date"},
+	amount:    []string{"credit", "credit amount", "paid in", "money in", "deposit", "deposits", "amount"},
+	details:   []string{"narration", "description", "details", "particulars", "narrative"},
+}
+
+// parsePaymentStatement reads the payments received out of an M-Pesa or bank statement csv.
+// Rows before the header are skipped as statements usually start with account details,
+// withdrawals and incomplete transactions are left out.
+func parsePaymentStatement(r io.Reader, source string) ([]*repository.PaymentImportRow, error) {
+	columns := bankStatementColumns
+	if source == repository.PAYMENT_IMPORT_SOURCE_MPESA {
+		columns = mpesaStatementColumns
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var (
+		index     map[string]int
+		rows      []*repository.PaymentImportRow
+		rowNumber int32
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+		rowNumber++
+
+		if index == nil {
+			index = statementHeader(record, columns)
+			continue
+		}
+
+		row, err := parseStatementRow(record, index, source)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", rowNumber, err)
+		}
+		if row == nil {
+			continue
+		}
+
+		row.RowNumber = rowNumber
+		rows = append(rows, row)
+	}
+
+	if index == nil {
+		return nil, fmt.Errorf("no header with a date and amount column found")
+	}
+
+	return rows, nil
+}
+
+// statementHeader returns the column index of each field when record is the header row.
+func statementHeader(record []string, columns statementColumns) map[string]int {
+	positions := make(map[string]int, len(record))
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(name, ".", "")))
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+
+	index := make(map[string]int)
+	for field, names := range map[string][]string{
+		"reference": columns.reference,
+		"date":      columns.date,
+		"amount":    columns.amount,
+		"details":   columns.details,
+		"status":    columns.status,
+		"party":     columns.party,
+		"account":   columns.account,
+	} {
+		// aliases are in order of preference, e.g. a credit column over a signed amount
+		for _, name := range names {
+			if i, ok := positions[name]; ok {
+				index[field] = i
+				break
+			}
+		}
+	}
+
+	_, hasDate := index["date"]
+	_, hasAmount := index["amount"]
+	if !hasDate || !hasAmount {
+		return nil
+	}
+
+	if _, ok := index["reference"]; !ok && columns.requireReference {
+		return nil
+	}
+
+	return index
+}
+
+// parseStatementRow returns nil for rows that are not payments received.
+func parseStatementRow(record []string, index map[string]int, source string) (*repository.PaymentImportRow, error) {
+	value := func(field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	if status := value("status"); status != "" && !strings.EqualFold(status, "completed") {
+		return nil, nil
+	}
+
+	amountStr := value("amount")
+	if amountStr == "" {
+		return nil, nil
+	}
+
+	amount, err := parseStatementAmount(amountStr)
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	datePaid, err := parseStatementDate(value("date"))
+	if err != nil {
+		return nil, err
+	}
+
+	row := &repository.PaymentImportRow{
+		Reference:     strings.ToUpper(value("reference")),
+		Amount:        amount,
+		DatePaid:      datePaid,
+		Details:       value("details"),
+		AccountNumber: value("account"),
+	}
+
+	if source == repository.PAYMENT_IMPORT_SOURCE_MPESA {
+		// other party info reads "254712345678 - JOHN DOE"
+		phone, name, found := strings.Cut(value("party"), " - ")
+		if !found {
+			name = phone
+			phone = ""
+		}
+		row.PayerPhone = strings.TrimSpace(phone)
+		row.PayerName = strings.TrimSpace(name)
+	} else {
+		row.AccountNumber = strings.ToUpper(statementAccountPattern.FindString(row.Details))
+		row.PayerPhone = statementPhonePattern.FindString(row.Details)
+	}
+
+	return row, nil
+}
+
+func parseStatementAmount(s string) (float64, error) {
+	s = strings.NewReplacer(",", "", " ", "", "KES", "", "KSh", "", "Ksh", "").Replace(s)
+
+	amount, err := strconv.ParseFloat(s, 64)
+	// ParseFloat accepts NaN and Inf, which would slip past the amount checks
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	return amount, nil
+}
+
+func parseStatementDate(s string) (time.Time, error) {
+	for _, layout := range statementDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
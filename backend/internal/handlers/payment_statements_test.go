@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func TestParsePaymentStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		csv     string
+		want    []repository.PaymentImportRow
+		wantErr string
+	}{
+		{
+			name:   "mpesa statement",
+			source: repository.PAYMENT_IMPORT_SOURCE_MPESA,
+			csv: `Customer Name:,BOFFO LTD
+Receipt No.,Completion Time,Details,Transaction Status,Paid In,Withdrawn,Balance,Other Party Info,A/C No.
+sgh1abc2de,2024-03-05 14:22:10,Pay Bill from 254712345678,Completed,"1,500.00",,10000.00,254712345678 - JANE DOE,BOFFO-12
+SGH3FGH4IJ,2024-03-05 15:00:00,Pay Bill from 254700000000,Failed,200.00,,10000.00,254700000000 - JOHN DOE,BOFFO-7
+SGH5KLM6NO,2024-03-06 09:00:00,Business Payment to bank,Completed,,3000.00,7000.00,BANK,
+SGH7PQR8ST,2024-03-06 10:30:00,Pay Bill from 254711111111,Completed,250,,7250.00,MARY,BOFFO-3
+`,
+			want: []repository.PaymentImportRow{
+				{
+					RowNumber:     3,
+					Reference:     "SGH1ABC2DE",
+					Amount:        1500,
+					DatePaid:      time.Date(2024, 3, 5, 14, 22, 10, 0, time.Local),
+					Details:       "Pay Bill from 254712345678",
+					AccountNumber: "BOFFO-12",
+					PayerPhone:    "254712345678",
+					PayerName:     "JANE DOE",
+				},
+				{
+					RowNumber:     6,
+					Reference:     "SGH7PQR8ST",
+					Amount:        250,
+					DatePaid:      time.Date(2024, 3, 6, 10, 30, 0, 0, time.Local),
+					Details:       "Pay Bill from 254711111111",
+					AccountNumber: "BOFFO-3",
+					PayerName:     "MARY",
+				},
+			},
+		},
+		{
+			name:   "bank statement",
+			source: repository.PAYMENT_IMPORT_SOURCE_BANK,
+			csv: `Account,0123456789
+Trans Date,Narration,Ref No,Debit,Credit,Balance
+05/03/2024,Transfer boffo-12 from 0712345678,FT123,,"KES 2,000.50",12000.50
+06/03/2024,Charges,FT124,50.00,,11950.50
+07-Mar-2024,Cash deposit,FT125,,400,12350.50
+`,
+			want: []repository.PaymentImportRow{
+				{
+					RowNumber:     3,
+					Reference:     "FT123",
+					Amount:        2000.5,
+					DatePaid:      time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local),
+					Details:       "Transfer boffo-12 from 0712345678",
+					AccountNumber: "BOFFO-12",
+					PayerPhone:    "0712345678",
+				},
+				{
+					RowNumber: 5,
+					Reference: "FT125",
+					Amount:    400,
+					DatePaid:  time.Date(2024, 3, 7, 0, 0, 0, 0, time.Local),
+					Details:   "Cash deposit",
+				},
+			},
+		},
+		{
+			name:   "bank statement with a signed amount column",
+			source: repository.PAYMENT_IMPORT_SOURCE_BANK,
+			csv: `Date,Description,Amount
+2024-03-05,Deposit,100
+2024-03-06,Withdrawal,-100
+`,
+			want: []repository.PaymentImportRow{
+				{
+					RowNumber: 2,
+					Amount:    100,
+					DatePaid:  time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local),
+					Details:   "Deposit",
+				},
+			},
+		},
+		{
+			name:    "mpesa statement without receipt numbers",
+			source:  repository.PAYMENT_IMPORT_SOURCE_MPESA,
+			csv:     "Completion Time,Paid In\n2024-03-05 14:22:10,100\n",
+			wantErr: "no header with a date and amount column found",
+		},
+		{
+			name:    "bad date",
+			source:  repository.PAYMENT_IMPORT_SOURCE_BANK,
+			csv:     "Date,Credit\n2024-03-05,100\n5th March,100\n",
+			wantErr: `row 3: invalid date "5th March"`,
+		},
+		{
+			name:    "bad amount",
+			source:  repository.PAYMENT_IMPORT_SOURCE_BANK,
+			csv:     "Date,Credit\n2024-03-05,1O0\n",
+			wantErr: `row 2: invalid amount "1O0"`,
+		},
+		{
+			name:    "nan amount",
+			source:  repository.PAYMENT_IMPORT_SOURCE_BANK,
+			csv:     "Date,Credit\n2024-03-05,NaN\n",
+			wantErr: `row 2: invalid amount "NaN"`,
+		},
+		{
+			name:    "infinite amount",
+			source:  repository.PAYMENT_IMPORT_SOURCE_MPESA,
+			csv:     "Receipt No.,Completion Time,Transaction Status,Paid In\nSGH1,2024-03-05 14:22:10,Completed,+Inf\n",
+			wantErr: `row 2: invalid amount "+Inf"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := parsePaymentStatement(strings.NewReader(tc.csv), tc.source)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("got error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(rows) != len(tc.want) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(tc.want))
+			}
+			for i, row := range rows {
+				want := tc.want[i]
+				if !row.DatePaid.Equal(want.DatePaid) {
+					t.Errorf("row %d: got date %v, want %v", i, row.DatePaid, want.DatePaid)
+				}
+				row.DatePaid, want.DatePaid = time.Time{}, time.Time{}
+				if *row != want {
+					t.Errorf("row %d: got %+v, want %+v", i, *row, want)
+				}
+			}
+		})
+	}
+}
@@ -9,12 +9,19 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+type paymentAllocationRequest struct {
+	InvoiceID uint32  `json:"invoice_id" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+}
+
 type createPaymentRequest struct {
 	ResellerID uint32  `json:"reseller_id" binding:"required"`
 	Amount     float64 `json:"amount" binding:"required,gt=0"`
-	Method     string  `json:"method" binding:"required,oneof=MPESA CASH"`
+	Method     string  `json:"method" binding:"required"`
 	Reference  string  `json:"reference"`
 	DatePaid   string  `json:"date_paid" binding:"required,datetime=2006-01-02"`
+	// invoices to settle first, the rest of the payment settles the oldest open invoices
+	Allocations []paymentAllocationRequest `json:"allocations" binding:"omitempty,dive"`
 }
 
 func (s *Server) createPaymentByAdmin(ctx *gin.Context) {
@@ -30,13 +37,22 @@ func (s *Server) createPaymentByAdmin(ctx *gin.Context) {
 		return
 	}
 
+	allocations := make([]*repository.PaymentAllocation, len(req.Allocations))
+	for i, allocation := range req.Allocations {
+		allocations[i] = &repository.PaymentAllocation{
+			InvoiceID: allocation.InvoiceID,
+			Amount:    allocation.Amount,
+		}
+	}
+
 	payment, err := s.repo.PaymentRepository.CreatePayment(ctx, &repository.Payment{
-		ResellerID: req.ResellerID,
-		Amount:     req.Amount,
-		Method:     req.Method,
-		Reference:  req.Reference,
-		RecordedBy: "ADMIN",
-		DatePaid:   datePaid,
+		ResellerID:  req.ResellerID,
+		Amount:      req.Amount,
+		Method:      strings.ToUpper(strings.TrimSpace(req.Method)),
+		Reference:   strings.TrimSpace(req.Reference),
+		RecordedBy:  "ADMIN",
+		DatePaid:    datePaid,
+		Allocations: allocations,
 	})
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -46,6 +62,38 @@ func (s *Server) createPaymentByAdmin(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, gin.H{"data": payment})
 }
 
+type reversePaymentRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (s *Server) reversePaymentHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req reversePaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reason is required")))
+		return
+	}
+
+	reversal, err := s.repo.PaymentRepository.ReversePayment(ctx, id, reason)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": reversal})
+}
+
 func (s *Server) listPaymentsHandler(ctx *gin.Context) {
 	pageNoStr := ctx.DefaultQuery("page", "1")
 	pageNo, err := pkg.StringToInt64(pageNoStr)
@@ -101,7 +149,7 @@ func (s *Server) listPaymentsHandler(ctx *gin.Context) {
 		filter.Search = &search
 	}
 
-	if method := ctx.Query("method"); method != "" {
+	if method := strings.ToUpper(ctx.Query("method")); method != "" {
 		filter.Method = &method
 	}
 
@@ -127,6 +175,13 @@ func (s *Server) listPaymentsHandler(ctx *gin.Context) {
 		filter.DateTo = &dateTo
 	}
 
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "payments", filter.Pagination, paymentExportColumns, func() ([]*repository.Payment, *pkg.Pagination, error) {
+			return s.repo.PaymentRepository.ListPayments(ctx, filter)
+		})
+		return
+	}
+
 	payments, pagination, err := s.repo.PaymentRepository.ListPayments(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -138,3 +193,18 @@ func (s *Server) listPaymentsHandler(ctx *gin.Context) {
 		"pagination": pagination,
 	})
 }
+
+var paymentExportColumns = []exportColumn[*repository.Payment]{
+	{Header: "ID", Value: func(p *repository.Payment) any { return p.ID }},
+	{Header: "Reseller", Value: func(p *repository.Payment) any { return exportUserName(p.User) }},
+	{Header: "Reseller Phone", Value: func(p *repository.Payment) any { return exportUserPhone(p.User) }},
+	{Header: "Amount", Value: func(p *repository.Payment) any { return p.Amount }},
+	{Header: "Method", Value: func(p *repository.Payment) any { return p.Method }},
+	{Header: "Reference", Value: func(p *repository.Payment) any { return p.Reference }},
+	{Header: "Recorded By", Value: func(p *repository.Payment) any { return p.RecordedBy }},
+	{Header: "Reversal Of", Value: func(p *repository.Payment) any { return exportOptionalID(p.ReversalOf) }},
+	{Header: "Reversal Reason", Value: func(p *repository.Payment) any { return p.ReversalReason }},
+	{Header: "Reversed By", Value: func(p *repository.Payment) any { return exportOptionalID(p.ReversedBy) }},
+	{Header: "Date Paid", Value: func(p *repository.Payment) any { return p.DatePaid }},
+	{Header: "Created At", Value: func(p *repository.Payment) any { return p.CreatedAt }},
+}
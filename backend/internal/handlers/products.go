@@ -135,6 +135,13 @@ func (s *Server) listProductsHandler(ctx *gin.Context) {
 		filter.Status = &status
 	}
 
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "products", filter.Pagination, productExportColumns, func() ([]*repository.Product, *pkg.Pagination, error) {
+			return s.repo.ProductsRepository.List(ctx, filter)
+		})
+		return
+	}
+
 	products, pagination, err := s.repo.ProductsRepository.List(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -147,6 +154,17 @@ func (s *Server) listProductsHandler(ctx *gin.Context) {
 	})
 }
 
+var productExportColumns = []exportColumn[*repository.Product]{
+	{Header: "ID", Value: func(p *repository.Product) any { return p.ID }},
+	{Header: "Name", Value: func(p *repository.Product) any { return p.Name }},
+	{Header: "Description", Value: func(p *repository.Product) any { return p.Description }},
+	{Header: "Category", Value: func(p *repository.Product) any { return p.Category }},
+	{Header: "Unit", Value: func(p *repository.Product) any { return p.Unit }},
+	{Header: "Price", Value: func(p *repository.Product) any { return p.Price }},
+	{Header: "Low Stock Threshold", Value: func(p *repository.Product) any { return p.LowStockThreshold }},
+	{Header: "Created At", Value: func(p *repository.Product) any { return p.CreatedAt }},
+}
+
 // type stockUpdateRequest struct {
 // 	Quantity    int64   `json:"quantity" binding:"required,gt=0"`
 // 	Note        *string `json:"note"`
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type purchaseOrderLineRequest struct {
+	ProductID     uint32  `json:"product_id" binding:"required"`
+	Quantity      uint32  `json:"quantity" binding:"required,gt=0"`
+	ExpectedPrice float64 `json:"expected_price" binding:"required,gt=0"`
+}
+
+type createPurchaseOrderRequest struct {
+	SupplierID   uint32                     `json:"supplier_id" binding:"required"`
+	ExpectedDate string                     `json:"expected_date"`
+	Note         string                     `json:"note"`
+	Lines        []purchaseOrderLineRequest `json:"lines" binding:"dive"`
+}
+
+func (s *Server) createPurchaseOrderHandler(ctx *gin.Context) {
+	var req createPurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var expectedDate *time.Time
+	if req.ExpectedDate != "" {
+		t, err := pkg.StrToTime(req.ExpectedDate)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid expected_date format")))
+			return
+		}
+		expectedDate = &t
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	order, err := s.repo.PurchaseOrderRepository.CreatePurchaseOrder(ctx, &repository.PurchaseOrder{
+		SupplierID:   req.SupplierID,
+		ExpectedDate: expectedDate,
+		Note:         strings.TrimSpace(req.Note),
+		CreatedBy:    payload.UserID,
+		Lines:        purchaseOrderLines(req.Lines),
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": order})
+}
+
+func (s *Server) getPurchaseOrderHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	order, err := s.repo.PurchaseOrderRepository.GetPurchaseOrder(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": order})
+}
+
+type updatePurchaseOrderRequest struct {
+	SupplierID   *uint32 `json:"supplier_id"`
+	ExpectedDate *string `json:"expected_date"`
+	Note         *string `json:"note"`
+	// replaces every line on the order when given
+	Lines []purchaseOrderLineRequest `json:"lines" binding:"omitempty,dive"`
+}
+
+func (s *Server) updatePurchaseOrderHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req updatePurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	update := &repository.PurchaseOrderUpdate{
+		SupplierID: req.SupplierID,
+		Note:       req.Note,
+	}
+
+	if req.ExpectedDate != nil {
+		t, err := pkg.StrToTime(*req.ExpectedDate)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid expected_date format")))
+			return
+		}
+		update.ExpectedDate = &t
+	}
+
+	if req.Lines != nil {
+		update.Lines = purchaseOrderLines(req.Lines)
+	}
+
+	order, err := s.repo.PurchaseOrderRepository.UpdatePurchaseOrder(ctx, id, update)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": order})
+}
+
+func (s *Server) sendPurchaseOrderHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	order, err := s.repo.PurchaseOrderRepository.SendPurchaseOrder(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": order})
+}
+
+type closePurchaseOrderRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (s *Server) closePurchaseOrderHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req closePurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reason is required")))
+		return
+	}
+
+	order, err := s.repo.PurchaseOrderRepository.ClosePurchaseOrder(ctx, id, reason)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": order})
+}
+
+type receivePurchaseOrderLineRequest struct {
+	BatchNumber string `json:"batch_number" binding:"required"`
+	Quantity    uint32 `json:"quantity" binding:"required,gt=0"`
+	// the price agreed on the order line is used when left out
+	PurchasePrice float64 `json:"purchase_price" binding:"gte=0"`
+	DateReceived  string  `json:"date_received" binding:"required"`
+	ExpiryDate    string  `json:"expiry_date"`
+	// the location the batch is received into, the default location when left out
+	LocationID uint32 `json:"location_id"`
+}
+
+func (s *Server) receivePurchaseOrderLineHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	lineID, err := pkg.StringToUint32(ctx.Param("line_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid line_id format")))
+		return
+	}
+
+	var req receivePurchaseOrderLineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	dateReceived, err := pkg.StrToTime(req.DateReceived)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid date_received format")))
+		return
+	}
+
+	var expiryDate *time.Time
+	if req.ExpiryDate != "" {
+		t, err := pkg.StrToTime(req.ExpiryDate)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid expiry_date format")))
+			return
+		}
+		if t.Before(dateReceived) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "expiry_date cannot be before date_received")))
+			return
+		}
+		expiryDate = &t
+	}
+
+	order, err := s.repo.PurchaseOrderRepository.ReceivePurchaseOrderLine(ctx, id, lineID, &repository.ProductBatch{
+		BatchNumber:   strings.TrimSpace(req.BatchNumber),
+		Quantity:      int64(req.Quantity),
+		PurchasePrice: req.PurchasePrice,
+		DateReceived:  dateReceived,
+		ExpiryDate:    expiryDate,
+		LocationID:    req.LocationID,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": order})
+}
+
+func (s *Server) listPurchaseOrdersHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.PurchaseOrderFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		SupplierID: nil,
+		Status:     nil,
+		ProductID:  nil,
+	}
+
+	if supplierIDStr := ctx.Query("supplier_id"); supplierIDStr != "" {
+		supplierID, err := pkg.StringToUint32(supplierIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid supplier_id format")))
+			return
+		}
+		filter.SupplierID = &supplierID
+	}
+
+	if status := strings.ToUpper(ctx.Query("status")); status != "" {
+		switch status {
+		case repository.PURCHASE_ORDER_DRAFT, repository.PURCHASE_ORDER_SENT, repository.PURCHASE_ORDER_PARTIALLY_RECEIVED, repository.PURCHASE_ORDER_RECEIVED, repository.PURCHASE_ORDER_CLOSED:
+			filter.Status = &status
+		default:
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid status")))
+			return
+		}
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id format")))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "purchase-orders", filter.Pagination, purchaseOrderExportColumns, func() ([]*repository.PurchaseOrder, *pkg.Pagination, error) {
+			return s.repo.PurchaseOrderRepository.ListPurchaseOrders(ctx, filter)
+		})
+		return
+	}
+
+	orders, pagination, err := s.repo.PurchaseOrderRepository.ListPurchaseOrders(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       orders,
+		"pagination": pagination,
+	})
+}
+
+func purchaseOrderLines(req []purchaseOrderLineRequest) []*repository.PurchaseOrderLine {
+	lines := make([]*repository.PurchaseOrderLine, len(req))
+	for i, line := range req {
+		lines[i] = &repository.PurchaseOrderLine{
+			ProductID:       line.ProductID,
+			QuantityOrdered: int64(line.Quantity),
+			ExpectedPrice:   line.ExpectedPrice,
+		}
+	}
+
+	return lines
+}
+
+var purchaseOrderExportColumns = []exportColumn[*repository.PurchaseOrder]{
+	{Header: "ID", Value: func(o *repository.PurchaseOrder) any { return o.ID }},
+	{Header: "Supplier", Value: func(o *repository.PurchaseOrder) any { return exportSupplierName(o.Supplier) }},
+	{Header: "Status", Value: func(o *repository.PurchaseOrder) any { return o.Status }},
+	{Header: "Expected Date", Value: func(o *repository.PurchaseOrder) any { return o.ExpectedDate }},
+	{Header: "Quantity Ordered", Value: func(o *repository.PurchaseOrder) any { return o.QuantityOrdered }},
+	{Header: "Quantity Received", Value: func(o *repository.PurchaseOrder) any { return o.QuantityReceived }},
+	{Header: "Total Value", Value: func(o *repository.PurchaseOrder) any { return o.TotalValue }},
+	{Header: "Note", Value: func(o *repository.PurchaseOrder) any { return o.Note }},
+	{Header: "Sent At", Value: func(o *repository.PurchaseOrder) any { return o.SentAt }},
+	{Header: "Received At", Value: func(o *repository.PurchaseOrder) any { return o.ReceivedAt }},
+	{Header: "Created At", Value: func(o *repository.PurchaseOrder) any { return o.CreatedAt }},
+}
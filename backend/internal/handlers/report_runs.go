@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type createReportRunRequest struct {
+	ReportType string `json:"report_type" binding:"required,oneof=RESELLER_BALANCES PAYMENTS_SUMMARY STOCK_LISTING STOCK_AUDIT"`
+	DateFrom   string `json:"date_from" binding:"omitempty,datetime=2006-01-02"`
+	DateTo     string `json:"date_to" binding:"omitempty,datetime=2006-01-02"`
+	AsOf       string `json:"as_of" binding:"omitempty,datetime=2006-01-02"`
+}
+
+func (s *Server) createReportRunHandler(ctx *gin.Context) {
+	var req createReportRunRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var params repository.ReportRunParams
+	var err error
+	if params.DateFrom, err = parseOptionalDate(req.DateFrom, "date_from"); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if params.DateTo, err = parseOptionalDate(req.DateTo, "date_to"); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if params.AsOf, err = parseOptionalDate(req.AsOf, "as_of"); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// as_of is a date, report the stock position at the end of that day
+	if params.AsOf != nil {
+		asOf := params.AsOf.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		params.AsOf = &asOf
+	}
+
+	run, err := s.report.CreateReportRun(ctx, req.ReportType, params, repository.REPORT_TRIGGER_ADMIN)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	s.executeReportRun(run.ID)
+
+	ctx.JSON(http.StatusAccepted, gin.H{"data": run})
+}
+
+func (s *Server) listReportRunsHandler(ctx *gin.Context) {
+	pageNo, err := pkg.StringToInt64(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSize, err := pkg.StringToInt64(ctx.DefaultQuery("limit", "10"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.ReportRunFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		ReportType: nil,
+		Status:     nil,
+	}
+
+	if reportType := ctx.Query("report_type"); reportType != "" {
+		reportType = strings.ToUpper(reportType)
+		filter.ReportType = &reportType
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		status = strings.ToUpper(status)
+		filter.Status = &status
+	}
+
+	runs, pagination, err := s.report.ListReportRuns(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       runs,
+		"pagination": pagination,
+	})
+}
+
+func (s *Server) getReportRunHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid report run ID: %s", err.Error())))
+		return
+	}
+
+	run, err := s.report.GetReportRun(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": run})
+}
+
+func (s *Server) downloadReportRunHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid report run ID: %s", err.Error())))
+		return
+	}
+
+	run, path, err := s.report.ReportRunFile(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		ctx.JSON(http.StatusNotFound, errorResponse(pkg.Errorf(pkg.NOT_FOUND_ERROR, "report file for run %d is missing", run.ID)))
+		return
+	}
+
+	ctx.Header("Content-Type", run.ContentType)
+	ctx.FileAttachment(path, run.FileName)
+}
+
+func (s *Server) retryReportRunHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid report run ID: %s", err.Error())))
+		return
+	}
+
+	run, err := s.report.GetReportRun(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	if run.Status != repository.REPORT_RUN_FAILED {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "only failed report runs can be retried, run %d is %s", run.ID, run.Status)))
+		return
+	}
+
+	s.executeReportRun(run.ID)
+
+	ctx.JSON(http.StatusAccepted, gin.H{"data": run})
+}
+
+func parseOptionalDate(value, name string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := pkg.StrToTime(value)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid %s format", name)
+	}
+
+	return &date, nil
+}
+
+// executeReportRun generates the report in the background, the outcome is recorded on the run.
+func (s *Server) executeReportRun(id uint32) {
+	go func() {
+		if _, err := s.report.ExecuteReportRun(context.Background(), id); err != nil {
+			log.Printf("report run %d failed: %v", id, err)
+		}
+	}()
+}
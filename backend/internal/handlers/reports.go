@@ -1 +1,367 @@
 package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getResellerStatementHandler(ctx *gin.Context) {
+	resellerID, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller ID: %s", err.Error())))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	// resellers can only view their own statement
+	if strings.ToLower(payload.Role) != repository.ADMIN_ROLE && payload.UserID != resellerID {
+		ctx.JSON(http.StatusForbidden, errorResponse(pkg.Errorf(pkg.FORBIDDEN_ERROR, "resellers can only access their own statement")))
+		return
+	}
+
+	dateFrom, dateTo, err := parseReportPeriod(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filter := &repository.StatementFilter{
+		ResellerID: resellerID,
+		DateFrom:   dateFrom,
+		DateTo:     dateTo,
+	}
+
+	if strings.ToLower(ctx.DefaultQuery("format", "json")) == "pdf" {
+		file, err := s.report.ResellerStatementPDF(ctx, filter)
+		if err != nil {
+			ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+			return
+		}
+
+		filename := fmt.Sprintf("statement-%d-%s-%s.pdf", resellerID, filter.DateFrom.Format("20060102"), filter.DateTo.Format("20060102"))
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		ctx.Data(http.StatusOK, "application/pdf", file)
+
+		return
+	}
+
+	statement, err := s.report.ResellerStatement(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": statement})
+}
+
+func (s *Server) getProfitAndLossHandler(ctx *gin.Context) {
+	dateFrom, dateTo, err := parseReportPeriod(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filter := &repository.ProfitAndLossFilter{
+		DateFrom:  dateFrom,
+		DateTo:    dateTo,
+		Category:  nil,
+		ProductID: nil,
+	}
+
+	if category := ctx.Query("category"); category != "" {
+		filter.Category = &category
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id: %s", err.Error())))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	report, err := s.report.ProfitAndLoss(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+func (s *Server) getInventoryValuationHandler(ctx *gin.Context) {
+	filter := &repository.InventoryValuationFilter{
+		AsOf:       time.Now(),
+		ResellerID: nil,
+		ProductID:  nil,
+	}
+
+	if asOfStr := ctx.Query("as_of"); asOfStr != "" {
+		asOf, err := pkg.StrToTime(asOfStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid as_of format")))
+			return
+		}
+
+		// a plain date means the stock held at the end of that day
+		if len(asOfStr) == len(time.DateOnly) {
+			asOf = asOf.Add(24*time.Hour - time.Nanosecond)
+		}
+		filter.AsOf = asOf
+	}
+
+	if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+		resellerID, err := pkg.StringToUint32(resellerIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id: %s", err.Error())))
+			return
+		}
+		filter.ResellerID = &resellerID
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id: %s", err.Error())))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	report, err := s.report.InventoryValuation(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+func (s *Server) getReceivablesAgingHandler(ctx *gin.Context) {
+	filter := &repository.ReceivablesAgingFilter{
+		ResellerID: nil,
+	}
+
+	if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+		resellerID, err := pkg.StringToUint32(resellerIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id: %s", err.Error())))
+			return
+		}
+		filter.ResellerID = &resellerID
+	}
+
+	report, err := s.report.ReceivablesAging(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+func (s *Server) traceBatchHandler(ctx *gin.Context) {
+	batchNumber := strings.TrimSpace(ctx.Param("batch_number"))
+	if batchNumber == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "batch number is required")))
+		return
+	}
+
+	traces, err := s.report.TraceBatch(ctx, batchNumber)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": traces})
+}
+
+func (s *Server) getAnalyticsHandler(ctx *gin.Context) {
+	dateFrom, dateTo, err := parseReportPeriod(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filter := &repository.AnalyticsFilter{
+		Interval:   strings.ToLower(ctx.DefaultQuery("interval", repository.ANALYTICS_INTERVAL_DAY)),
+		GroupBy:    nil,
+		DateFrom:   dateFrom,
+		DateTo:     dateTo,
+		ResellerID: nil,
+		ProductID:  nil,
+		Category:   nil,
+	}
+
+	switch filter.Interval {
+	case repository.ANALYTICS_INTERVAL_DAY, repository.ANALYTICS_INTERVAL_WEEK, repository.ANALYTICS_INTERVAL_MONTH:
+	default:
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "interval must be one of day, week or month")))
+		return
+	}
+
+	if groupBy := strings.ToLower(ctx.Query("group_by")); groupBy != "" {
+		switch groupBy {
+		case repository.ANALYTICS_GROUP_PRODUCT, repository.ANALYTICS_GROUP_CATEGORY, repository.ANALYTICS_GROUP_RESELLER:
+			filter.GroupBy = &groupBy
+		default:
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "group_by must be one of product, category or reseller")))
+			return
+		}
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	// resellers only see their own figures and never the company cost behind them
+	filter.CompanyCosts = strings.ToLower(payload.Role) == repository.ADMIN_ROLE
+	if !filter.CompanyCosts {
+		filter.ResellerID = &payload.UserID
+	} else if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+		resellerID, err := pkg.StringToUint32(resellerIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id: %s", err.Error())))
+			return
+		}
+		filter.ResellerID = &resellerID
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id: %s", err.Error())))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	if category := ctx.Query("category"); category != "" {
+		filter.Category = &category
+	}
+
+	analytics, err := s.report.Analytics(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": analytics})
+}
+
+func (s *Server) getPurchaseOrderFillRateHandler(ctx *gin.Context) {
+	dateFrom, dateTo, err := parseReportPeriod(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filter := &repository.PurchaseOrderFillRateFilter{
+		DateFrom:   dateFrom,
+		DateTo:     dateTo,
+		SupplierID: nil,
+	}
+
+	if supplierIDStr := ctx.Query("supplier_id"); supplierIDStr != "" {
+		supplierID, err := pkg.StringToUint32(supplierIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid supplier_id: %s", err.Error())))
+			return
+		}
+		filter.SupplierID = &supplierID
+	}
+
+	report, err := s.report.PurchaseOrderFillRate(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+func (s *Server) getPurchasePriceVarianceHandler(ctx *gin.Context) {
+	dateFrom, dateTo, err := parseReportPeriod(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filter := &repository.PurchasePriceVarianceFilter{
+		DateFrom:   dateFrom,
+		DateTo:     dateTo,
+		SupplierID: nil,
+		ProductID:  nil,
+	}
+
+	if supplierIDStr := ctx.Query("supplier_id"); supplierIDStr != "" {
+		supplierID, err := pkg.StringToUint32(supplierIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid supplier_id: %s", err.Error())))
+			return
+		}
+		filter.SupplierID = &supplierID
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id: %s", err.Error())))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	report, err := s.report.PurchasePriceVariance(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// parseReportPeriod reads date_from and date_to from the query, defaulting to the current month.
+func parseReportPeriod(ctx *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	dateFrom := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	dateTo := now
+
+	if dateFromStr := ctx.Query("date_from"); dateFromStr != "" {
+		date, err := pkg.StrToTime(dateFromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, pkg.Errorf(pkg.INVALID_ERROR, "invalid date_from format")
+		}
+		dateFrom = date
+	}
+
+	if dateToStr := ctx.Query("date_to"); dateToStr != "" {
+		date, err := pkg.StrToTime(dateToStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, pkg.Errorf(pkg.INVALID_ERROR, "invalid date_to format")
+		}
+		dateTo = date
+	}
+
+	if dateFrom.After(dateTo) {
+		return time.Time{}, time.Time{}, pkg.Errorf(pkg.INVALID_ERROR, "date_from cannot be after date_to")
+	}
+
+	return dateFrom, dateTo, nil
+}
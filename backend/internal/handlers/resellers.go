@@ -14,6 +14,10 @@ type createResellerSaleRequest struct {
 	Quantity     uint32  `json:"quantity" binding:"required,min=1"`
 	SellingPrice float64 `json:"selling_price" binding:"required,gt=0"`
 	DateSold     string  `json:"date_sold" binding:"required"`
+	// FEFO, FIFO or LIFO, STOCK_ALLOCATION_STRATEGY when left out, or the source batches to
+	// sell from with quantities adding up to quantity
+	AllocationStrategy string                   `json:"allocation_strategy"`
+	Batches            []batchAllocationRequest `json:"batches" binding:"omitempty,dive"`
 }
 
 func (s *Server) createSaleHandler(ctx *gin.Context) {
@@ -29,6 +33,12 @@ func (s *Server) createSaleHandler(ctx *gin.Context) {
 		return
 	}
 
+	strategy, batches, err := stockAllocation(req.AllocationStrategy, req.Batches)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	authPayload, ok := ctx.Get(authorizationPayloadKey)
 	if !ok {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
@@ -42,11 +52,13 @@ func (s *Server) createSaleHandler(ctx *gin.Context) {
 	}
 
 	resellerSale, err := s.repo.ResellerRepository.CreateResellerSale(ctx, &repository.ResellerSale{
-		ResellerID:   payload.UserID,
-		ProductID:    req.ProductID,
-		Quantity:     int32(req.Quantity),
-		SellingPrice: req.SellingPrice,
-		DateSold:     dateSold,
+		ResellerID:         payload.UserID,
+		ProductID:          req.ProductID,
+		Quantity:           int32(req.Quantity),
+		SellingPrice:       req.SellingPrice,
+		DateSold:           dateSold,
+		AllocationStrategy: strategy,
+		Batches:            batches,
 		User: &repository.UserShort{
 			Name: payload.Name,
 		},
@@ -75,6 +87,33 @@ func (s *Server) getResellerByIDHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"data": reseller})
 }
 
+type updateCreditLimitRequest struct {
+	// null removes the reseller's credit limit
+	CreditLimit *float64 `json:"credit_limit" binding:"omitempty,gte=0"`
+}
+
+func (s *Server) updateCreditLimitHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller ID: %s", err.Error())))
+		return
+	}
+
+	var req updateCreditLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	account, err := s.repo.ResellerRepository.UpdateCreditLimit(ctx, id, req.CreditLimit)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": account})
+}
+
 func (s *Server) listSalesHandler(ctx *gin.Context) {
 	pageNoStr := ctx.DefaultQuery("page", "1")
 	pageNo, err := pkg.StringToInt64(pageNoStr)
@@ -130,6 +169,13 @@ func (s *Server) listSalesHandler(ctx *gin.Context) {
 		}
 	}
 
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "sales", filter.Pagination, resellerSaleExportColumns, func() ([]*repository.ResellerSale, *pkg.Pagination, error) {
+			return s.repo.ResellerRepository.ListResellerSales(ctx, filter)
+		})
+		return
+	}
+
 	resellerSales, pagination, err := s.repo.ResellerRepository.ListResellerSales(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -195,6 +241,13 @@ func (s *Server) listResellerStockHandler(ctx *gin.Context) {
 		}
 	}
 
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "reseller-stock", filter.Pagination, resellerStockExportColumns, func() ([]*repository.ResellerStock, *pkg.Pagination, error) {
+			return s.repo.ResellerRepository.ListResellerStock(ctx, filter)
+		})
+		return
+	}
+
 	resellerStocks, pagination, err := s.repo.ResellerRepository.ListResellerStock(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -264,12 +317,28 @@ func (s *Server) listResellersHandler(ctx *gin.Context) {
 			PageSize: uint32(pageSize),
 		},
 		Search: nil,
+		Sort:   nil,
 	}
 
 	if search := ctx.Query("search"); search != "" {
 		filter.Search = &search
 	}
 
+	if sort := ctx.Query("sort"); sort != "" {
+		if sort != "most_overdue" && sort != "credit_utilisation" {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid sort: %s", sort)))
+			return
+		}
+		filter.Sort = &sort
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "resellers", filter.Pagination, resellerExportColumns, func() ([]*repository.Reseller, *pkg.Pagination, error) {
+			return s.repo.ResellerRepository.ListResellersWithAccount(ctx, filter)
+		})
+		return
+	}
+
 	resellers, pagination, err := s.repo.ResellerRepository.ListResellersWithAccount(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -278,3 +347,45 @@ func (s *Server) listResellersHandler(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"data": resellers, "pagination": pagination})
 }
+
+var resellerSaleExportColumns = []exportColumn[*repository.ResellerSale]{
+	{Header: "ID", Value: func(r *repository.ResellerSale) any { return r.ID }},
+	{Header: "Reseller", Value: func(r *repository.ResellerSale) any { return exportUserName(r.User) }},
+	{Header: "Product", Value: func(r *repository.ResellerSale) any { return exportProductName(r.Product) }},
+	{Header: "Category", Value: func(r *repository.ResellerSale) any { return r.ProductCategory }},
+	{Header: "Quantity", Value: func(r *repository.ResellerSale) any { return r.Quantity }},
+	{Header: "Selling Price", Value: func(r *repository.ResellerSale) any { return r.SellingPrice }},
+	{Header: "Total Amount", Value: func(r *repository.ResellerSale) any { return r.TotalAmount }},
+	{Header: "Date Sold", Value: func(r *repository.ResellerSale) any { return r.DateSold }},
+	{Header: "Created At", Value: func(r *repository.ResellerSale) any { return r.CreatedAt }},
+}
+
+var resellerStockExportColumns = []exportColumn[*repository.ResellerStock]{
+	{Header: "Reseller", Value: func(r *repository.ResellerStock) any { return exportUserName(r.User) }},
+	{Header: "Product ID", Value: func(r *repository.ResellerStock) any { return r.ProductID }},
+	{Header: "Product", Value: func(r *repository.ResellerStock) any { return exportProductName(r.Product) }},
+	{Header: "Category", Value: func(r *repository.ResellerStock) any { return r.ProductCategory }},
+	{Header: "Quantity", Value: func(r *repository.ResellerStock) any { return r.Quantity }},
+	{Header: "Low Stock Threshold", Value: func(r *repository.ResellerStock) any { return r.LowStockThreshold }},
+}
+
+var resellerExportColumns = []exportColumn[*repository.Reseller]{
+	{Header: "ID", Value: func(r *repository.Reseller) any { return r.User.ID }},
+	{Header: "Name", Value: func(r *repository.Reseller) any { return r.User.Name }},
+	{Header: "Phone Number", Value: func(r *repository.Reseller) any { return r.User.PhoneNumber }},
+	{Header: "Email", Value: func(r *repository.Reseller) any { return r.User.Email }},
+	{Header: "Current Stock Units", Value: func(r *repository.Reseller) any { return r.Account.TotalStockReceived }},
+	{Header: "Total Value Received", Value: func(r *repository.Reseller) any { return r.Account.TotalValueReceived }},
+	{Header: "Total Sales Value", Value: func(r *repository.Reseller) any { return r.Account.TotalSalesValue }},
+	{Header: "Total Paid", Value: func(r *repository.Reseller) any { return r.Account.TotalPaid }},
+	{Header: "Balance", Value: func(r *repository.Reseller) any { return r.Account.Balance }},
+	{Header: "Credit Limit", Value: func(r *repository.Reseller) any { return exportOptionalAmount(r.Account.CreditLimit) }},
+	{Header: "Credit Headroom", Value: func(r *repository.Reseller) any { return exportOptionalAmount(r.Account.CreditHeadroom) }},
+	{Header: "Credit Utilisation (%)", Value: func(r *repository.Reseller) any { return exportOptionalAmount(r.Account.CreditUtilisation) }},
+	{Header: "Days Overdue", Value: func(r *repository.Reseller) any {
+		if r.Aging == nil {
+			return nil
+		}
+		return r.Aging.DaysOverdue
+	}},
+}
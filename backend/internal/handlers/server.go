@@ -27,15 +27,23 @@ type Server struct {
 
 	cache  services.CacheService
 	report services.ReportService
+	mpesa  services.MpesaService
 }
 
-func NewServer(config pkg.Config, tokenMaker pkg.JWTMaker, repo *postgres.PostgresRepo, cache services.CacheService, report services.ReportService) *Server {
+func NewServer(config pkg.Config, tokenMaker pkg.JWTMaker, repo *postgres.PostgresRepo, cache services.CacheService, report services.ReportService, mpesa services.MpesaService) *Server {
 	if config.ENVIRONMENT == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	r := gin.Default()
 
+	// the client address decides which daraja callbacks are accepted so it is only taken from
+	// X-Forwarded-For when set by a trusted proxy
+	if err := r.SetTrustedProxies(config.TRUSTED_PROXIES); err != nil {
+		log.Printf("invalid TRUSTED_PROXIES, trusting no proxies: %v", err)
+		_ = r.SetTrustedProxies(nil)
+	}
+
 	s := &Server{
 		router: r,
 		ln:     nil,
@@ -46,6 +54,7 @@ func NewServer(config pkg.Config, tokenMaker pkg.JWTMaker, repo *postgres.Postgr
 
 		cache:  cache,
 		report: report,
+		mpesa:  mpesa,
 	}
 
 	s.setUpRoutes()
@@ -108,10 +117,54 @@ func (s *Server) setUpRoutes() {
 	adminGroup.POST("/company/stock-distributions", s.createStockDistributionHandler)
 	adminCacheGroup.GET("/company/stock-distributions", s.listStockDistributionsHandler)
 	adminCacheGroup.GET("/company/stock", s.listCompanyStockHandler)
+	adminGroup.GET("/company/credit-holds", s.listCreditHoldsHandler)
+	adminGroup.POST("/company/credit-holds/:id/override", s.overrideCreditHoldHandler)
+	adminGroup.POST("/company/credit-holds/:id/reject", s.rejectCreditHoldHandler)
+	adminGroup.POST("/company/stock-returns", s.createStockReturnHandler)
+	adminGroup.GET("/company/stock-returns", s.listStockReturnsHandler)
+	adminGroup.POST("/company/stock-adjustments", s.createStockAdjustmentHandler)
+	adminGroup.GET("/company/stock-adjustments", s.listStockAdjustmentsHandler)
+	adminGroup.POST("/company/stocktakes", s.createStocktakeHandler)
+	adminGroup.GET("/company/stocktakes", s.listStocktakesHandler)
+	adminGroup.GET("/company/stocktakes/:id", s.getStocktakeHandler)
+	adminGroup.PUT("/company/stocktakes/:id/counts", s.recordStocktakeCountsHandler)
+	adminGroup.POST("/company/stocktakes/:id/post", s.postStocktakeHandler)
+	adminGroup.POST("/company/stocktakes/:id/cancel", s.cancelStocktakeHandler)
+	adminGroup.POST("/company/landed-costs", s.createLandedCostHandler)
+	adminGroup.GET("/company/landed-costs", s.listLandedCostsHandler)
+	adminGroup.GET("/company/landed-costs/:id", s.getLandedCostHandler)
+
+	// locations routes
+	adminGroup.POST("/locations", s.createLocationHandler)
+	adminGroup.GET("/locations", s.listLocationsHandler)
+	adminGroup.POST("/locations/transfers", s.createLocationTransferHandler)
+	adminGroup.GET("/locations/transfers", s.listLocationTransfersHandler)
+	adminGroup.GET("/locations/:id", s.getLocationHandler)
+	adminGroup.PUT("/locations/:id", s.updateLocationHandler)
+
+	// purchasing routes
+	adminGroup.POST("/suppliers", s.createSupplierHandler)
+	adminGroup.GET("/suppliers", s.listSuppliersHandler)
+	adminGroup.GET("/suppliers/:id", s.getSupplierHandler)
+	adminGroup.PUT("/suppliers/:id", s.updateSupplierHandler)
+	adminGroup.POST("/purchase-orders", s.createPurchaseOrderHandler)
+	adminGroup.GET("/purchase-orders", s.listPurchaseOrdersHandler)
+	adminGroup.GET("/purchase-orders/:id", s.getPurchaseOrderHandler)
+	adminGroup.PUT("/purchase-orders/:id", s.updatePurchaseOrderHandler)
+	adminGroup.POST("/purchase-orders/:id/send", s.sendPurchaseOrderHandler)
+	adminGroup.POST("/purchase-orders/:id/close", s.closePurchaseOrderHandler)
+	adminGroup.POST("/purchase-orders/:id/lines/:line_id/receive", s.receivePurchaseOrderLineHandler)
 
 	// resellers routes
 	adminCacheGroup.GET("/admin/resellers", s.listResellersHandler)
 	adminCacheGroup.GET("/admin/resellers/:id", s.getResellerByIDHandler)
+	adminGroup.PUT("/admin/resellers/:id/credit-limit", s.updateCreditLimitHandler)
+	authGroup.POST("/resellers/stock-transfers", s.createStockTransferHandler)
+	authGroup.GET("/resellers/stock-transfers", s.listStockTransfersHandler)
+	authGroup.POST("/resellers/stock-transfers/:id/accept", s.acceptStockTransferHandler)
+	authGroup.POST("/resellers/stock-transfers/:id/decline", s.declineStockTransferHandler)
+	adminGroup.POST("/admin/stock-transfers/:id/approve", s.approveStockTransferHandler)
+	adminGroup.POST("/admin/stock-transfers/:id/reject", s.rejectStockTransferHandler)
 	authGroup.POST("/resellers", s.createSaleHandler)
 	cacheGroup.GET("/resellers", s.listSalesHandler)
 	cacheGroup.GET("/resellers/stock", s.listResellerStockHandler)
@@ -127,9 +180,39 @@ func (s *Server) setUpRoutes() {
 	// payments routes
 	adminGroup.POST("/payments", s.createPaymentByAdmin)
 	cacheGroup.GET("/payments", s.listPaymentsHandler)
+	adminGroup.POST("/payments/:id/reverse", s.reversePaymentHandler)
+	adminGroup.POST("/payments/imports", s.previewPaymentImportHandler)
+	adminGroup.GET("/payments/imports", s.listPaymentImportsHandler)
+	adminGroup.GET("/payments/imports/:id", s.getPaymentImportHandler)
+	adminGroup.POST("/payments/imports/:id/commit", s.commitPaymentImportHandler)
+	authGroup.POST("/payments/mpesa/stk-push", s.initiateStkPushHandler)
+	authGroup.GET("/payments/mpesa/stk-push/:id", s.getStkPushHandler)
+	// daraja callbacks are public so they are only served once a callback token is set
+	if s.config.MPESA_CALLBACK_TOKEN != "" {
+		v1.POST("/payments/mpesa/callback", s.mpesaStkCallbackHandler)
+		v1.POST("/payments/mpesa/c2b/validation", s.mpesaC2BValidationHandler)
+		v1.POST("/payments/mpesa/c2b/confirmation", s.mpesaC2BConfirmationHandler)
+	} else {
+		log.Println("MPESA_CALLBACK_TOKEN is not set, mpesa callbacks are disabled")
+	}
+	adminGroup.GET("/admin/payments/suspense", s.listMpesaSuspenseHandler)
+	adminGroup.POST("/admin/payments/suspense/:id/allocate", s.allocateMpesaSuspenseHandler)
+
+	// payment methods routes
+	adminGroup.POST("/payment-methods", s.createPaymentMethodHandler)
+	adminGroup.PUT("/payment-methods/:code", s.updatePaymentMethodHandler)
+	authGroup.GET("/payment-methods", s.listPaymentMethodsHandler)
+
+	// invoices routes
+	adminGroup.POST("/invoices", s.createInvoiceHandler)
+	cacheGroup.GET("/invoices", s.listInvoicesHandler)
+	authGroup.GET("/invoices/:id", s.getInvoiceHandler)
 
 	// stock movements routes
 	cacheGroup.GET("/stock-movements", s.listStockMovementsHandler)
+	adminGroup.GET("/stock-movements/audit", s.auditStockHandler)
+	adminGroup.POST("/stock-movements/audit/rebuild", s.rebuildStockHandler)
+	authGroup.GET("/stock-movements/near-expiry", s.listNearExpiryStockHandler)
 
 	// helper routes
 	cacheGroup.GET("/resellers/page-data/:page", s.getResellerPageStatsHandler)
@@ -141,6 +224,19 @@ func (s *Server) setUpRoutes() {
 	// adminCacheGroup.GET("/admin/stats", s.getAdminStatsHandler)
 
 	// reports routes
+	authGroup.GET("/reports/resellers/:id/statement", s.getResellerStatementHandler)
+	adminGroup.GET("/reports/profit-and-loss", s.getProfitAndLossHandler)
+	adminGroup.GET("/reports/inventory-valuation", s.getInventoryValuationHandler)
+	adminGroup.GET("/reports/receivables-aging", s.getReceivablesAgingHandler)
+	adminGroup.GET("/reports/batches/:batch_number/trace", s.traceBatchHandler)
+	adminGroup.GET("/reports/purchase-orders/fill-rate", s.getPurchaseOrderFillRateHandler)
+	adminGroup.GET("/reports/purchase-price-variance", s.getPurchasePriceVarianceHandler)
+	authGroup.GET("/reports/analytics", s.getAnalyticsHandler)
+	adminGroup.GET("/reports/runs", s.listReportRunsHandler)
+	adminGroup.POST("/reports/runs", s.createReportRunHandler)
+	adminGroup.GET("/reports/runs/:id", s.getReportRunHandler)
+	adminGroup.GET("/reports/runs/:id/download", s.downloadReportRunHandler)
+	adminGroup.POST("/reports/runs/:id/retry", s.retryReportRunHandler)
 
 	s.srv = &http.Server{
 		Addr:         s.config.SERVER_ADDRESS,
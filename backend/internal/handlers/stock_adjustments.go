@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+var stockAdjustmentReasons = []string{
+	repository.ADJUSTMENT_DAMAGED,
+	repository.ADJUSTMENT_EXPIRED,
+	repository.ADJUSTMENT_THEFT,
+	repository.ADJUSTMENT_COUNT_CORRECTION,
+}
+
+type adjustStockRequest struct {
+	OwnerType  string  `json:"owner_type" binding:"required"`
+	ResellerID *uint32 `json:"reseller_id"`
+	ProductID  uint32  `json:"product_id" binding:"required"`
+	// negative to write stock off, positive to put back units found in a count
+	Quantity int32   `json:"quantity" binding:"required,ne=0"`
+	BatchID  *uint32 `json:"batch_id"`
+	Reason   string  `json:"reason" binding:"required"`
+	Note     string  `json:"note"`
+	// company stock only, the default location when left out
+	LocationID *uint32 `json:"location_id"`
+}
+
+func (s *Server) createStockAdjustmentHandler(ctx *gin.Context) {
+	var req adjustStockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	ownerType, ok := parseStockOwnerType(req.OwnerType)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "owner_type must be COMPANY or RESELLER")))
+		return
+	}
+
+	if ownerType == repository.STOCK_OWNER_RESELLER && req.ResellerID == nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reseller_id is required for reseller stock")))
+		return
+	}
+	if ownerType == repository.STOCK_OWNER_COMPANY {
+		req.ResellerID = nil
+	} else {
+		req.LocationID = nil
+	}
+
+	reason, ok := parseStockAdjustmentReason(req.Reason)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reason must be one of %s", strings.Join(stockAdjustmentReasons, ", "))))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	adjustment, err := s.repo.CompanyRepository.AdjustStock(ctx, &repository.StockAdjustment{
+		OwnerType:  ownerType,
+		ResellerID: req.ResellerID,
+		ProductID:  req.ProductID,
+		BatchID:    req.BatchID,
+		Quantity:   req.Quantity,
+		Reason:     reason,
+		Note:       strings.TrimSpace(req.Note),
+		CreatedBy:  payload.UserID,
+		LocationID: req.LocationID,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": adjustment})
+}
+
+func (s *Server) listStockAdjustmentsHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.StockAdjustmentFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		OwnerType:  nil,
+		ResellerID: nil,
+		ProductID:  nil,
+		Reason:     nil,
+	}
+
+	if ownerTypeStr := ctx.Query("owner_type"); ownerTypeStr != "" {
+		ownerType, ok := parseStockOwnerType(ownerTypeStr)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid owner_type")))
+			return
+		}
+		filter.OwnerType = &ownerType
+	}
+
+	if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+		resellerID, err := pkg.StringToUint32(resellerIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id format")))
+			return
+		}
+		filter.ResellerID = &resellerID
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id format")))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	if reasonStr := ctx.Query("reason"); reasonStr != "" {
+		reason, ok := parseStockAdjustmentReason(reasonStr)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reason")))
+			return
+		}
+		filter.Reason = &reason
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "stock-adjustments", filter.Pagination, stockAdjustmentExportColumns, func() ([]*repository.StockAdjustment, *pkg.Pagination, error) {
+			return s.repo.CompanyRepository.ListStockAdjustments(ctx, filter)
+		})
+		return
+	}
+
+	adjustments, pagination, err := s.repo.CompanyRepository.ListStockAdjustments(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       adjustments,
+		"pagination": pagination,
+	})
+}
+
+func parseStockOwnerType(s string) (string, bool) {
+	ownerType := strings.ToUpper(strings.TrimSpace(s))
+	if ownerType != repository.STOCK_OWNER_COMPANY && ownerType != repository.STOCK_OWNER_RESELLER {
+		return "", false
+	}
+
+	return ownerType, true
+}
+
+func parseStockAdjustmentReason(s string) (string, bool) {
+	reason := strings.ToUpper(strings.TrimSpace(s))
+	for _, r := range stockAdjustmentReasons {
+		if reason == r {
+			return reason, true
+		}
+	}
+
+	return "", false
+}
+
+var stockAdjustmentExportColumns = []exportColumn[*repository.StockAdjustment]{
+	{Header: "ID", Value: func(a *repository.StockAdjustment) any { return a.ID }},
+	{Header: "Owner", Value: func(a *repository.StockAdjustment) any { return a.OwnerType }},
+	{Header: "Reseller", Value: func(a *repository.StockAdjustment) any { return exportUserName(a.User) }},
+	{Header: "Reseller Phone", Value: func(a *repository.StockAdjustment) any { return exportUserPhone(a.User) }},
+	{Header: "Product", Value: func(a *repository.StockAdjustment) any { return exportProductName(a.Product) }},
+	{Header: "Batch ID", Value: func(a *repository.StockAdjustment) any { return exportOptionalID(a.BatchID) }},
+	{Header: "Quantity", Value: func(a *repository.StockAdjustment) any { return a.Quantity }},
+	{Header: "Total Value", Value: func(a *repository.StockAdjustment) any { return a.TotalValue }},
+	{Header: "Reason", Value: func(a *repository.StockAdjustment) any { return a.Reason }},
+	{Header: "Note", Value: func(a *repository.StockAdjustment) any { return a.Note }},
+	{Header: "Created At", Value: func(a *repository.StockAdjustment) any { return a.CreatedAt }},
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) auditStockHandler(ctx *gin.Context) {
+	audit, err := s.repo.StockAuditRepository.AuditStock(ctx)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": audit})
+}
+
+func (s *Server) rebuildStockHandler(ctx *gin.Context) {
+	audit, err := s.repo.StockAuditRepository.RebuildStock(ctx)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": audit})
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) listNearExpiryStockHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	days, err := pkg.StringToInt64(ctx.DefaultQuery("days", "30"))
+	if err != nil || days < 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "days must be a positive number")))
+		return
+	}
+
+	filter := &repository.NearExpiryStockFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		Days:       int32(days),
+		OwnerType:  nil,
+		ResellerID: nil,
+		ProductID:  nil,
+		LocationID: nil,
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	// resellers only see their own stock
+	if strings.ToLower(payload.Role) != repository.ADMIN_ROLE {
+		ownerType := repository.STOCK_OWNER_RESELLER
+		filter.OwnerType = &ownerType
+		filter.ResellerID = &payload.UserID
+	} else {
+		if ownerTypeStr := ctx.Query("owner_type"); ownerTypeStr != "" {
+			ownerType, ok := parseStockOwnerType(ownerTypeStr)
+			if !ok {
+				ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid owner_type")))
+				return
+			}
+			filter.OwnerType = &ownerType
+		}
+
+		if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+			resellerID, err := pkg.StringToUint32(resellerIDStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id format")))
+				return
+			}
+			filter.ResellerID = &resellerID
+		}
+
+		if locationIDStr := ctx.Query("location_id"); locationIDStr != "" {
+			locationID, err := pkg.StringToUint32(locationIDStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid location_id format")))
+				return
+			}
+			filter.LocationID = &locationID
+		}
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id format")))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "near-expiry-stock", filter.Pagination, nearExpiryStockExportColumns, func() ([]*repository.NearExpiryStock, *pkg.Pagination, error) {
+			return s.repo.CompanyRepository.ListNearExpiryStock(ctx, filter)
+		})
+		return
+	}
+
+	layers, pagination, err := s.repo.CompanyRepository.ListNearExpiryStock(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       layers,
+		"pagination": pagination,
+	})
+}
+
+var nearExpiryStockExportColumns = []exportColumn[*repository.NearExpiryStock]{
+	{Header: "Owner", Value: func(l *repository.NearExpiryStock) any { return l.OwnerType }},
+	{Header: "Reseller", Value: func(l *repository.NearExpiryStock) any { return exportUserName(l.User) }},
+	{Header: "Reseller Phone", Value: func(l *repository.NearExpiryStock) any { return exportUserPhone(l.User) }},
+	{Header: "Product", Value: func(l *repository.NearExpiryStock) any { return exportProductName(l.Product) }},
+	{Header: "Batch ID", Value: func(l *repository.NearExpiryStock) any { return l.BatchID }},
+	{Header: "Batch Number", Value: func(l *repository.NearExpiryStock) any { return l.BatchNumber }},
+	{Header: "Expiry Date", Value: func(l *repository.NearExpiryStock) any { return l.ExpiryDate }},
+	{Header: "Days To Expiry", Value: func(l *repository.NearExpiryStock) any { return l.DaysToExpiry }},
+	{Header: "Remaining Quantity", Value: func(l *repository.NearExpiryStock) any { return l.RemainingQuantity }},
+	{Header: "Unit Cost", Value: func(l *repository.NearExpiryStock) any { return l.UnitCost }},
+	{Header: "Total Value", Value: func(l *repository.NearExpiryStock) any { return l.TotalValue }},
+	{Header: "Location", Value: func(l *repository.NearExpiryStock) any { return exportLocationName(l.Location) }},
+}
@@ -37,6 +37,7 @@ func (s *Server) listStockMovementsHandler(ctx *gin.Context) {
 		OwnerID:      nil,
 		MovementType: nil,
 		Source:       nil,
+		LocationID:   nil,
 	}
 
 	authPayload, ok := ctx.Get(authorizationPayloadKey)
@@ -63,6 +64,15 @@ func (s *Server) listStockMovementsHandler(ctx *gin.Context) {
 			}
 			filter.OwnerID = &ownerID
 		}
+
+		if locationIDStr := ctx.Query("location_id"); locationIDStr != "" {
+			locationID, err := pkg.StringToUint32(locationIDStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid location_id format")))
+				return
+			}
+			filter.LocationID = &locationID
+		}
 	}
 
 	if search := ctx.Query("search"); search != "" {
@@ -86,6 +96,13 @@ func (s *Server) listStockMovementsHandler(ctx *gin.Context) {
 		filter.Source = &source
 	}
 
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "stock-movements", filter.Pagination, stockMovementExportColumns, func() ([]*repository.StockMovement, *pkg.Pagination, error) {
+			return s.repo.StockMovementRepository.List(ctx.Request.Context(), filter)
+		})
+		return
+	}
+
 	stockMovements, pagination, err := s.repo.StockMovementRepository.List(ctx.Request.Context(), filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -97,3 +114,18 @@ func (s *Server) listStockMovementsHandler(ctx *gin.Context) {
 		"pagination": pagination,
 	})
 }
+
+var stockMovementExportColumns = []exportColumn[*repository.StockMovement]{
+	{Header: "ID", Value: func(m *repository.StockMovement) any { return m.ID }},
+	{Header: "Product", Value: func(m *repository.StockMovement) any { return exportProductName(m.Product) }},
+	{Header: "Category", Value: func(m *repository.StockMovement) any { return m.ProductCategory }},
+	{Header: "Owner Type", Value: func(m *repository.StockMovement) any { return m.OwnerType }},
+	{Header: "Owner", Value: func(m *repository.StockMovement) any { return exportUserName(m.User) }},
+	{Header: "Location", Value: func(m *repository.StockMovement) any { return exportLocationName(m.Location) }},
+	{Header: "Movement Type", Value: func(m *repository.StockMovement) any { return m.MovementType }},
+	{Header: "Source", Value: func(m *repository.StockMovement) any { return m.Source }},
+	{Header: "Quantity", Value: func(m *repository.StockMovement) any { return m.Quantity }},
+	{Header: "Unit Price", Value: func(m *repository.StockMovement) any { return m.UnitPrice }},
+	{Header: "Note", Value: func(m *repository.StockMovement) any { return m.Note }},
+	{Header: "Created At", Value: func(m *repository.StockMovement) any { return m.CreatedAt }},
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type returnStockRequest struct {
+	ResellerID uint32 `json:"reseller_id" binding:"required"`
+	ProductID  uint32 `json:"product_id" binding:"required"`
+	Quantity   uint32 `json:"quantity" binding:"required,gt=0"`
+	// take the units from one batch instead of the reseller's newest stock first
+	BatchID      *uint32 `json:"batch_id"`
+	Reason       string  `json:"reason" binding:"required"`
+	DateReturned string  `json:"date_returned" binding:"required"`
+	// the location the stock goes back into, the default location when left out
+	LocationID uint32 `json:"location_id"`
+}
+
+func (s *Server) createStockReturnHandler(ctx *gin.Context) {
+	var req returnStockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reason is required")))
+		return
+	}
+
+	dateReturned, err := pkg.StrToTime(req.DateReturned)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid date_returned format")))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	stockReturn, err := s.repo.CompanyRepository.ReturnStock(ctx, &repository.StockReturn{
+		ResellerID:   req.ResellerID,
+		ProductID:    req.ProductID,
+		BatchID:      req.BatchID,
+		Quantity:     int32(req.Quantity),
+		Reason:       reason,
+		DateReturned: dateReturned,
+		CreatedBy:    payload.UserID,
+		LocationID:   req.LocationID,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": stockReturn})
+}
+
+func (s *Server) listStockReturnsHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.StockReturnFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		ResellerID: nil,
+		ProductID:  nil,
+	}
+
+	if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+		resellerID, err := pkg.StringToUint32(resellerIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id format")))
+			return
+		}
+		filter.ResellerID = &resellerID
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id format")))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "stock-returns", filter.Pagination, stockReturnExportColumns, func() ([]*repository.StockReturn, *pkg.Pagination, error) {
+			return s.repo.CompanyRepository.ListStockReturns(ctx, filter)
+		})
+		return
+	}
+
+	stockReturns, pagination, err := s.repo.CompanyRepository.ListStockReturns(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       stockReturns,
+		"pagination": pagination,
+	})
+}
+
+var stockReturnExportColumns = []exportColumn[*repository.StockReturn]{
+	{Header: "ID", Value: func(r *repository.StockReturn) any { return r.ID }},
+	{Header: "Reseller", Value: func(r *repository.StockReturn) any { return exportUserName(r.User) }},
+	{Header: "Reseller Phone", Value: func(r *repository.StockReturn) any { return exportUserPhone(r.User) }},
+	{Header: "Product", Value: func(r *repository.StockReturn) any { return exportProductName(r.Product) }},
+	{Header: "Batch ID", Value: func(r *repository.StockReturn) any { return exportOptionalID(r.BatchID) }},
+	{Header: "Quantity", Value: func(r *repository.StockReturn) any { return r.Quantity }},
+	{Header: "Total Value", Value: func(r *repository.StockReturn) any { return r.TotalValue }},
+	{Header: "Reason", Value: func(r *repository.StockReturn) any { return r.Reason }},
+	{Header: "Date Returned", Value: func(r *repository.StockReturn) any { return r.DateReturned }},
+	{Header: "Created At", Value: func(r *repository.StockReturn) any { return r.CreatedAt }},
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type transferStockRequest struct {
+	// required for admins, resellers can only transfer their own stock
+	FromResellerID *uint32 `json:"from_reseller_id"`
+	ToResellerID   uint32  `json:"to_reseller_id" binding:"required"`
+	ProductID      uint32  `json:"product_id" binding:"required"`
+	Quantity       uint32  `json:"quantity" binding:"required,gt=0"`
+	// take the units from one batch instead of the sender's oldest stock first
+	BatchID *uint32 `json:"batch_id"`
+	Note    string  `json:"note"`
+}
+
+func (s *Server) createStockTransferHandler(ctx *gin.Context) {
+	var req transferStockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	// the receiver is invoiced for the stock, so a reseller's transfer waits for them to
+	// accept it or an admin to approve it
+	fromResellerID := payload.UserID
+	requireApproval := true
+	if strings.ToLower(payload.Role) == repository.ADMIN_ROLE {
+		if req.FromResellerID == nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "from_reseller_id is required")))
+			return
+		}
+		fromResellerID = *req.FromResellerID
+		requireApproval = false
+	}
+
+	if fromResellerID == req.ToResellerID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "cannot transfer stock to the same reseller")))
+		return
+	}
+
+	transfer, err := s.repo.ResellerRepository.TransferStock(ctx, &repository.StockTransfer{
+		FromResellerID: fromResellerID,
+		ToResellerID:   req.ToResellerID,
+		ProductID:      req.ProductID,
+		BatchID:        req.BatchID,
+		Quantity:       int32(req.Quantity),
+		Note:           strings.TrimSpace(req.Note),
+		RequestedBy:    payload.UserID,
+	}, requireApproval)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": transfer})
+}
+
+func (s *Server) approveStockTransferHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	transfer, err := s.repo.ResellerRepository.ApproveStockTransfer(ctx, id, payload.UserID)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": transfer})
+}
+
+type rejectStockTransferRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (s *Server) rejectStockTransferHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req rejectStockTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reason is required")))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	transfer, err := s.repo.ResellerRepository.RejectStockTransfer(ctx, id, payload.UserID, reason)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": transfer})
+}
+
+func (s *Server) acceptStockTransferHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	if s.config.STOCK_TRANSFER_REQUIRES_APPROVAL {
+		ctx.JSON(http.StatusForbidden, errorResponse(pkg.Errorf(pkg.FORBIDDEN_ERROR, "stock transfers need an admin's approval")))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	transfer, err := s.repo.ResellerRepository.AcceptStockTransfer(ctx, id, payload.UserID)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": transfer})
+}
+
+func (s *Server) declineStockTransferHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req rejectStockTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reason is required")))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	transfer, err := s.repo.ResellerRepository.DeclineStockTransfer(ctx, id, payload.UserID, reason)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": transfer})
+}
+
+func (s *Server) listStockTransfersHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.StockTransferFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		ResellerID: nil,
+		ProductID:  nil,
+		Status:     nil,
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	// resellers see the transfers they sent or received
+	if strings.ToLower(payload.Role) != repository.ADMIN_ROLE {
+		filter.ResellerID = &payload.UserID
+	} else {
+		if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+			resellerID, err := pkg.StringToUint32(resellerIDStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id format")))
+				return
+			}
+			filter.ResellerID = &resellerID
+		}
+	}
+
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := pkg.StringToUint32(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid product_id format")))
+			return
+		}
+		filter.ProductID = &productID
+	}
+
+	if status := strings.ToUpper(ctx.Query("status")); status != "" {
+		if status != repository.STOCK_TRANSFER_PENDING && status != repository.STOCK_TRANSFER_COMPLETED && status != repository.STOCK_TRANSFER_REJECTED {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid status")))
+			return
+		}
+		filter.Status = &status
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "stock-transfers", filter.Pagination, stockTransferExportColumns, func() ([]*repository.StockTransfer, *pkg.Pagination, error) {
+			return s.repo.ResellerRepository.ListStockTransfers(ctx, filter)
+		})
+		return
+	}
+
+	transfers, pagination, err := s.repo.ResellerRepository.ListStockTransfers(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       transfers,
+		"pagination": pagination,
+	})
+}
+
+var stockTransferExportColumns = []exportColumn[*repository.StockTransfer]{
+	{Header: "ID", Value: func(t *repository.StockTransfer) any { return t.ID }},
+	{Header: "From Reseller", Value: func(t *repository.StockTransfer) any { return exportUserName(t.FromReseller) }},
+	{Header: "To Reseller", Value: func(t *repository.StockTransfer) any { return exportUserName(t.ToReseller) }},
+	{Header: "Product", Value: func(t *repository.StockTransfer) any { return exportProductName(t.Product) }},
+	{Header: "Batch ID", Value: func(t *repository.StockTransfer) any { return exportOptionalID(t.BatchID) }},
+	{Header: "Quantity", Value: func(t *repository.StockTransfer) any { return t.Quantity }},
+	{Header: "Total Value", Value: func(t *repository.StockTransfer) any { return t.TotalValue }},
+	{Header: "Status", Value: func(t *repository.StockTransfer) any { return t.Status }},
+	{Header: "Note", Value: func(t *repository.StockTransfer) any { return t.Note }},
+	{Header: "Date Transferred", Value: func(t *repository.StockTransfer) any { return t.DateTransferred }},
+	{Header: "Created At", Value: func(t *repository.StockTransfer) any { return t.CreatedAt }},
+}
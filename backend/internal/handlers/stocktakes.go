@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type startStocktakeRequest struct {
+	OwnerType  string  `json:"owner_type" binding:"required"`
+	ResellerID *uint32 `json:"reseller_id"`
+	Note       string  `json:"note"`
+	// company stock is counted one location at a time, the default location when left out
+	LocationID *uint32 `json:"location_id"`
+}
+
+func (s *Server) createStocktakeHandler(ctx *gin.Context) {
+	var req startStocktakeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	ownerType, ok := parseStockOwnerType(req.OwnerType)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "owner_type must be COMPANY or RESELLER")))
+		return
+	}
+
+	if ownerType == repository.STOCK_OWNER_RESELLER && req.ResellerID == nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "reseller_id is required for reseller stock")))
+		return
+	}
+	if ownerType == repository.STOCK_OWNER_COMPANY {
+		req.ResellerID = nil
+	} else {
+		req.LocationID = nil
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	stocktake, err := s.repo.StocktakeRepository.StartStocktake(ctx, &repository.Stocktake{
+		OwnerType:  ownerType,
+		ResellerID: req.ResellerID,
+		Note:       strings.TrimSpace(req.Note),
+		CreatedBy:  payload.UserID,
+		LocationID: req.LocationID,
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": stocktake})
+}
+
+func (s *Server) getStocktakeHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	stocktake, err := s.repo.StocktakeRepository.GetStocktake(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": stocktake})
+}
+
+func (s *Server) listStocktakesHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.StocktakeFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		OwnerType:  nil,
+		ResellerID: nil,
+		LocationID: nil,
+		Status:     nil,
+	}
+
+	if ownerTypeStr := ctx.Query("owner_type"); ownerTypeStr != "" {
+		ownerType, ok := parseStockOwnerType(ownerTypeStr)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid owner_type")))
+			return
+		}
+		filter.OwnerType = &ownerType
+	}
+
+	if resellerIDStr := ctx.Query("reseller_id"); resellerIDStr != "" {
+		resellerID, err := pkg.StringToUint32(resellerIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid reseller_id format")))
+			return
+		}
+		filter.ResellerID = &resellerID
+	}
+
+	if locationIDStr := ctx.Query("location_id"); locationIDStr != "" {
+		locationID, err := pkg.StringToUint32(locationIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid location_id format")))
+			return
+		}
+		filter.LocationID = &locationID
+	}
+
+	if status := strings.ToUpper(ctx.Query("status")); status != "" {
+		if status != repository.STOCKTAKE_OPEN && status != repository.STOCKTAKE_POSTED && status != repository.STOCKTAKE_CANCELLED {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "invalid status")))
+			return
+		}
+		filter.Status = &status
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "stocktakes", filter.Pagination, stocktakeExportColumns, func() ([]*repository.Stocktake, *pkg.Pagination, error) {
+			return s.repo.StocktakeRepository.ListStocktakes(ctx, filter)
+		})
+		return
+	}
+
+	stocktakes, pagination, err := s.repo.StocktakeRepository.ListStocktakes(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       stocktakes,
+		"pagination": pagination,
+	})
+}
+
+type stocktakeCountRequest struct {
+	ProductID       uint32 `json:"product_id" binding:"required"`
+	CountedQuantity *int64 `json:"counted_quantity" binding:"required,gte=0"`
+}
+
+type recordStocktakeCountsRequest struct {
+	Counts []stocktakeCountRequest `json:"counts" binding:"required,min=1,dive"`
+}
+
+func (s *Server) recordStocktakeCountsHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req recordStocktakeCountsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	counts := make([]*repository.StocktakeCount, len(req.Counts))
+	for i, count := range req.Counts {
+		counts[i] = &repository.StocktakeCount{
+			ProductID:       count.ProductID,
+			CountedQuantity: *count.CountedQuantity,
+		}
+	}
+
+	stocktake, err := s.repo.StocktakeRepository.RecordStocktakeCounts(ctx, id, counts)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": stocktake})
+}
+
+func (s *Server) postStocktakeHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	stocktake, err := s.repo.StocktakeRepository.PostStocktake(ctx, id, payload.UserID)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": stocktake})
+}
+
+func (s *Server) cancelStocktakeHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	authPayload, ok := ctx.Get(authorizationPayloadKey)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get auth payload")))
+		return
+	}
+	payload := authPayload.(*pkg.Payload)
+
+	stocktake, err := s.repo.StocktakeRepository.CancelStocktake(ctx, id, payload.UserID)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": stocktake})
+}
+
+var stocktakeExportColumns = []exportColumn[*repository.Stocktake]{
+	{Header: "ID", Value: func(t *repository.Stocktake) any { return t.ID }},
+	{Header: "Owner", Value: func(t *repository.Stocktake) any { return t.OwnerType }},
+	{Header: "Reseller", Value: func(t *repository.Stocktake) any { return exportUserName(t.User) }},
+	{Header: "Location", Value: func(t *repository.Stocktake) any { return exportLocationName(t.Location) }},
+	{Header: "Status", Value: func(t *repository.Stocktake) any { return t.Status }},
+	{Header: "Products", Value: func(t *repository.Stocktake) any { return t.TotalLines }},
+	{Header: "Counted", Value: func(t *repository.Stocktake) any { return t.CountedLines }},
+	{Header: "Variance Quantity", Value: func(t *repository.Stocktake) any { return t.VarianceQuantity }},
+	{Header: "Variance Value", Value: func(t *repository.Stocktake) any { return t.VarianceValue }},
+	{Header: "Note", Value: func(t *repository.Stocktake) any { return t.Note }},
+	{Header: "Closed At", Value: func(t *repository.Stocktake) any { return t.ClosedAt }},
+	{Header: "Created At", Value: func(t *repository.Stocktake) any { return t.CreatedAt }},
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type createSupplierRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	ContactName string `json:"contact_name" binding:"max=100"`
+	PhoneNumber string `json:"phone_number" binding:"max=20"`
+	Email       string `json:"email" binding:"omitempty,email,max=100"`
+	Address     string `json:"address"`
+}
+
+func (s *Server) createSupplierHandler(ctx *gin.Context) {
+	var req createSupplierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "name is required")))
+		return
+	}
+
+	supplier, err := s.repo.PurchaseOrderRepository.CreateSupplier(ctx, &repository.Supplier{
+		Name:        name,
+		ContactName: strings.TrimSpace(req.ContactName),
+		PhoneNumber: strings.TrimSpace(req.PhoneNumber),
+		Email:       strings.TrimSpace(req.Email),
+		Address:     strings.TrimSpace(req.Address),
+	})
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": supplier})
+}
+
+func (s *Server) getSupplierHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	supplier, err := s.repo.PurchaseOrderRepository.GetSupplier(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": supplier})
+}
+
+func (s *Server) updateSupplierHandler(ctx *gin.Context) {
+	id, err := pkg.StringToUint32(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	var req repository.SupplierUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "name cannot be empty")))
+			return
+		}
+		req.Name = &name
+	}
+
+	supplier, err := s.repo.PurchaseOrderRepository.UpdateSupplier(ctx, id, &req)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": supplier})
+}
+
+func (s *Server) listSuppliersHandler(ctx *gin.Context) {
+	pageNoStr := ctx.DefaultQuery("page", "1")
+	pageNo, err := pkg.StringToInt64(pageNoStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	pageSizeStr := ctx.DefaultQuery("limit", "10")
+	pageSize, err := pkg.StringToInt64(pageSizeStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s", err.Error())))
+		return
+	}
+
+	filter := &repository.SupplierFilter{
+		Pagination: &pkg.Pagination{
+			Page:     uint32(pageNo),
+			PageSize: uint32(pageSize),
+		},
+		Search: nil,
+		Active: nil,
+	}
+
+	if search := ctx.Query("search"); search != "" {
+		filter.Search = &search
+	}
+
+	if activeStr := ctx.Query("active"); activeStr != "" {
+		active := pkg.StringToBool(activeStr)
+		filter.Active = &active
+	}
+
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "suppliers", filter.Pagination, supplierExportColumns, func() ([]*repository.Supplier, *pkg.Pagination, error) {
+			return s.repo.PurchaseOrderRepository.ListSuppliers(ctx, filter)
+		})
+		return
+	}
+
+	suppliers, pagination, err := s.repo.PurchaseOrderRepository.ListSuppliers(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":       suppliers,
+		"pagination": pagination,
+	})
+}
+
+var supplierExportColumns = []exportColumn[*repository.Supplier]{
+	{Header: "ID", Value: func(s *repository.Supplier) any { return s.ID }},
+	{Header: "Name", Value: func(s *repository.Supplier) any { return s.Name }},
+	{Header: "Contact Name", Value: func(s *repository.Supplier) any { return s.ContactName }},
+	{Header: "Phone Number", Value: func(s *repository.Supplier) any { return s.PhoneNumber }},
+	{Header: "Email", Value: func(s *repository.Supplier) any { return s.Email }},
+	{Header: "Address", Value: func(s *repository.Supplier) any { return s.Address }},
+	{Header: "Active", Value: func(s *repository.Supplier) any { return s.Active }},
+	{Header: "Created At", Value: func(s *repository.Supplier) any { return s.CreatedAt }},
+}
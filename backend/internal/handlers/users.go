@@ -257,6 +257,13 @@ func (s *Server) listUsersHandler(ctx *gin.Context) {
 		filter.Role = &role
 	}
 
+	if format, ok := exportFormat(ctx); ok {
+		exportList(ctx, format, "users", filter.Pagination, userExportColumns, func() ([]*repository.User, *pkg.Pagination, error) {
+			return s.repo.UserRepository.List(ctx, filter)
+		})
+		return
+	}
+
 	users, pagination, err := s.repo.UserRepository.List(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.ErrorToStatusCode(err), errorResponse(err))
@@ -365,3 +372,12 @@ func (s *Server) refreshTokenHandler(ctx *gin.Context) {
 		},
 	})
 }
+
+var userExportColumns = []exportColumn[*repository.User]{
+	{Header: "ID", Value: func(u *repository.User) any { return u.ID }},
+	{Header: "Name", Value: func(u *repository.User) any { return u.Name }},
+	{Header: "Email", Value: func(u *repository.User) any { return u.Email }},
+	{Header: "Phone Number", Value: func(u *repository.User) any { return u.PhoneNumber }},
+	{Header: "Role", Value: func(u *repository.User) any { return u.Role }},
+	{Header: "Created At", Value: func(u *repository.User) any { return u.CreatedAt }},
+}
@@ -0,0 +1,131 @@
+package mpesa
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+type stkCallbackBody struct {
+	Body struct {
+		StkCallback struct {
+			MerchantRequestID string `json:"MerchantRequestID"`
+			CheckoutRequestID string `json:"CheckoutRequestID"`
+			ResultCode        int32  `json:"ResultCode"`
+			ResultDesc        string `json:"ResultDesc"`
+			CallbackMetadata  struct {
+				Item []struct {
+					Name  string          `json:"Name"`
+					Value json.RawMessage `json:"Value"`
+				} `json:"Item"`
+			} `json:"CallbackMetadata"`
+		} `json:"stkCallback"`
+	} `json:"Body"`
+}
+
+func (d *darajaClient) ParseStkCallback(body []byte) (*repository.MpesaStkResult, error) {
+	var callback stkCallbackBody
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid stk callback: %s", err.Error())
+	}
+
+	stk := callback.Body.StkCallback
+	if stk.CheckoutRequestID == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "stk callback is missing CheckoutRequestID")
+	}
+
+	result := &repository.MpesaStkResult{
+		MerchantRequestID: stk.MerchantRequestID,
+		CheckoutRequestID: stk.CheckoutRequestID,
+		ResultCode:        stk.ResultCode,
+		ResultDesc:        stk.ResultDesc,
+	}
+
+	// metadata is only sent for successful payments
+	if stk.ResultCode != 0 {
+		return result, nil
+	}
+
+	for _, item := range stk.CallbackMetadata.Item {
+		var err error
+		switch item.Name {
+		case "Amount":
+			err = json.Unmarshal(item.Value, &result.Amount)
+		case "MpesaReceiptNumber":
+			err = json.Unmarshal(item.Value, &result.MpesaReceipt)
+		case "PhoneNumber":
+			// sent as a number
+			var phone json.Number
+			if err = json.Unmarshal(item.Value, &phone); err == nil {
+				result.PhoneNumber = phone.String()
+			}
+		case "TransactionDate":
+			// sent as a number in the darajaTimestampFormat layout
+			var date json.Number
+			if err = json.Unmarshal(item.Value, &date); err == nil {
+				result.TransactionDate, err = time.ParseInLocation(darajaTimestampFormat, date.String(), eat)
+			}
+		}
+		if err != nil {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid stk callback %s: %s", item.Name, err.Error())
+		}
+	}
+
+	if result.Amount <= 0 || result.MpesaReceipt == "" || result.PhoneNumber == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "stk callback is missing the amount, receipt or phone number")
+	}
+
+	if result.TransactionDate.IsZero() {
+		result.TransactionDate = time.Now()
+	}
+
+	return result, nil
+}
+
+type c2bTransactionBody struct {
+	TransactionType   string `json:"TransactionType"`
+	TransID           string `json:"TransID"`
+	TransTime         string `json:"TransTime"`
+	TransAmount       string `json:"TransAmount"`
+	BusinessShortCode string `json:"BusinessShortCode"`
+	BillRefNumber     string `json:"BillRefNumber"`
+	MSISDN            string `json:"MSISDN"`
+	FirstName         string `json:"FirstName"`
+	MiddleName        string `json:"MiddleName"`
+	LastName          string `json:"LastName"`
+}
+
+func (d *darajaClient) ParseC2BTransaction(body []byte) (*repository.MpesaC2BTransaction, error) {
+	var c2b c2bTransactionBody
+	if err := json.Unmarshal(body, &c2b); err != nil {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid c2b transaction: %s", err.Error())
+	}
+
+	if c2b.TransID == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "c2b transaction is missing TransID")
+	}
+
+	amount, err := strconv.ParseFloat(c2b.TransAmount, 64)
+	if err != nil || amount <= 0 {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid c2b transaction amount: %s", c2b.TransAmount)
+	}
+
+	transTime, err := time.ParseInLocation(darajaTimestampFormat, c2b.TransTime, eat)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid c2b transaction time: %s", c2b.TransTime)
+	}
+
+	return &repository.MpesaC2BTransaction{
+		TransID:       c2b.TransID,
+		TransType:     c2b.TransactionType,
+		TransTime:     transTime,
+		Amount:        amount,
+		BillRefNumber: strings.TrimSpace(c2b.BillRefNumber),
+		Msisdn:        c2b.MSISDN,
+		PayerName:     strings.Join(strings.Fields(strings.Join([]string{c2b.FirstName, c2b.MiddleName, c2b.LastName}, " ")), " "),
+	}, nil
+}
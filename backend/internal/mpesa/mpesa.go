@@ -0,0 +1,210 @@
+package mpesa
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/services"
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+var _ services.MpesaService = (*darajaClient)(nil)
+
+const (
+	darajaTimestampFormat = "20060102150405"
+
+	// renew the access token this long before daraja expires it
+	tokenExpiryMargin = time.Minute
+)
+
+// daraja timestamps are in East Africa Time
+var eat = time.FixedZone("EAT", 3*60*60)
+
+type darajaClient struct {
+	baseURL        string
+	consumerKey    string
+	consumerSecret string
+	shortCode      string
+	passKey        string
+	callbackURL    string
+	httpClient     *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewMpesaClient returns a daraja api client. MPESA_BASE_URL can point at a local stub,
+// MPESA_CALLBACK_TOKEN is added to the callback url so callbacks can be verified.
+func NewMpesaClient(config pkg.Config) (services.MpesaService, error) {
+	callbackURL, err := url.Parse(config.MPESA_CALLBACK_URL)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "invalid mpesa callback url: %s", err.Error())
+	}
+
+	if config.MPESA_CALLBACK_TOKEN != "" {
+		query := callbackURL.Query()
+		query.Set("token", config.MPESA_CALLBACK_TOKEN)
+		callbackURL.RawQuery = query.Encode()
+	}
+
+	return &darajaClient{
+		baseURL:        strings.TrimRight(config.MPESA_BASE_URL, "/"),
+		consumerKey:    config.MPESA_CONSUMER_KEY,
+		consumerSecret: config.MPESA_CONSUMER_SECRET,
+		shortCode:      config.MPESA_SHORTCODE,
+		passKey:        config.MPESA_PASSKEY,
+		callbackURL:    callbackURL.String(),
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type stkPushBody struct {
+	BusinessShortCode string `json:"BusinessShortCode"`
+	Password          string `json:"Password"`
+	Timestamp         string `json:"Timestamp"`
+	TransactionType   string `json:"TransactionType"`
+	Amount            int64  `json:"Amount"`
+	PartyA            string `json:"PartyA"`
+	PartyB            string `json:"PartyB"`
+	PhoneNumber       string `json:"PhoneNumber"`
+	CallBackURL       string `json:"CallBackURL"`
+	AccountReference  string `json:"AccountReference"`
+	TransactionDesc   string `json:"TransactionDesc"`
+}
+
+type stkPushResponseBody struct {
+	MerchantRequestID   string `json:"MerchantRequestID"`
+	CheckoutRequestID   string `json:"CheckoutRequestID"`
+	ResponseCode        string `json:"ResponseCode"`
+	ResponseDescription string `json:"ResponseDescription"`
+	CustomerMessage     string `json:"CustomerMessage"`
+}
+
+type errorResponseBody struct {
+	RequestID    string `json:"requestId"`
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+func (d *darajaClient) StkPush(ctx context.Context, req *services.StkPushRequest) (*services.StkPushResponse, error) {
+	token, err := d.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().In(eat).Format(darajaTimestampFormat)
+	body := stkPushBody{
+		BusinessShortCode: d.shortCode,
+		Password:          base64.StdEncoding.EncodeToString([]byte(d.shortCode + d.passKey + timestamp)),
+		Timestamp:         timestamp,
+		TransactionType:   "CustomerPayBillOnline",
+		Amount:            req.Amount,
+		PartyA:            req.PhoneNumber,
+		PartyB:            d.shortCode,
+		PhoneNumber:       req.PhoneNumber,
+		CallBackURL:       d.callbackURL,
+		AccountReference:  req.AccountReference,
+		TransactionDesc:   req.Description,
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to encode stk push request: %s", err.Error())
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, d.baseURL+"/mpesa/stkpush/v1/processrequest", bytes.NewReader(payload))
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stk push request: %s", err.Error())
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	var resp stkPushResponseBody
+	if err := d.do(httpReq, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.ResponseCode != "0" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "stk push rejected: %s", resp.ResponseDescription)
+	}
+
+	return &services.StkPushResponse{
+		MerchantRequestID:   resp.MerchantRequestID,
+		CheckoutRequestID:   resp.CheckoutRequestID,
+		ResponseDescription: resp.ResponseDescription,
+		CustomerMessage:     resp.CustomerMessage,
+	}, nil
+}
+
+// token returns a cached access token, requesting a new one when it is about to expire.
+func (d *darajaClient) token(ctx context.Context) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.accessToken != "" && time.Now().Before(d.expiresAt) {
+		return d.accessToken, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/oauth/v1/generate?grant_type=client_credentials", nil)
+	if err != nil {
+		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create token request: %s", err.Error())
+	}
+	httpReq.SetBasicAuth(d.consumerKey, d.consumerSecret)
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   string `json:"expires_in"`
+	}
+	if err := d.do(httpReq, &resp); err != nil {
+		return "", err
+	}
+
+	expiresIn, err := strconv.ParseInt(resp.ExpiresIn, 10, 64)
+	if err != nil {
+		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "invalid token expiry from daraja: %s", resp.ExpiresIn)
+	}
+
+	d.accessToken = resp.AccessToken
+	d.expiresAt = time.Now().Add(time.Duration(expiresIn)*time.Second - tokenExpiryMargin)
+
+	return d.accessToken, nil
+}
+
+func (d *darajaClient) do(httpReq *http.Request, target any) error {
+	httpResp, err := d.httpClient.Do(httpReq)
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "daraja request failed: %s", err.Error())
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		var errResp errorResponseBody
+		message := httpResp.Status
+		if err := json.NewDecoder(httpResp.Body).Decode(&errResp); err == nil && errResp.ErrorMessage != "" {
+			message = fmt.Sprintf("%s (%s)", errResp.ErrorMessage, errResp.ErrorCode)
+		}
+
+		// client errors are caused by the request, e.g. an invalid phone number
+		if httpResp.StatusCode < http.StatusInternalServerError && httpResp.StatusCode != http.StatusUnauthorized {
+			return pkg.Errorf(pkg.INVALID_ERROR, "daraja rejected request: %s", message)
+		}
+
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "daraja request failed: %s", message)
+	}
+
+	if err := json.NewDecoder(httpResp.Body).Decode(target); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to decode daraja response: %s", err.Error())
+	}
+
+	return nil
+}
@@ -2,8 +2,11 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/EmilioCliff/boffo/internal/postgres/generated"
 	"github.com/EmilioCliff/boffo/internal/repository"
@@ -27,101 +30,132 @@ func NewCompanyRepository(db *Store) *CompanyRepository {
 
 func (cr *CompanyRepository) AddProductBatch(ctx context.Context, batch *repository.ProductBatch) (*repository.ProductBatch, error) {
 	err := cr.db.ExecTx(ctx, func(q *generated.Queries) error {
-		// create product batch record
-		pgProductBatch, err := q.CreateProductBatchRecord(ctx, generated.CreateProductBatchRecordParams{
-			ProductID:     int64(batch.ProductID),
-			BatchNumber:   batch.BatchNumber,
-			Quantity:      batch.Quantity,
-			PurchasePrice: pkg.Float64ToPgTypeNumeric(batch.PurchasePrice),
-			DateReceived:  batch.DateReceived,
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create product batch record: %s", err.Error())
-		}
+		return addProductBatch(ctx, q, batch)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		batch.ID = uint32(pgProductBatch.ID)
-		batch.CreatedAt = pgProductBatch.CreatedAt
-
-		_, err = q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
-			ProductID:    int64(batch.ProductID),
-			OwnerType:    "COMPANY",
-			OwnerID:      pgtype.Int8{Valid: false},
-			MovementType: "IN",
-			Quantity:     batch.Quantity,
-			UnitPrice:    pkg.Float64ToPgTypeNumeric(batch.PurchasePrice),
-			Source:       "PURCHASE",
-			Note:         batch.BatchNumber,
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
-		}
+	return batch, nil
+}
 
-		// add batch to batch_inventory record
-		_, err = q.CreateBatchInventoryRecord(ctx, generated.CreateBatchInventoryRecordParams{
-			BatchID:           int64(batch.ID),
-			ProductID:         int64(batch.ProductID),
-			RemainingQuantity: batch.Quantity,
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create batch inventory record: %s", err.Error())
-		}
+// addProductBatch records a received batch: the batch row, the PURCHASE movement, the
+// batch inventory layer and the company stock at the batch location.
+func addProductBatch(ctx context.Context, q *generated.Queries, batch *repository.ProductBatch) error {
+	location, err := resolveLocation(ctx, q, batch.LocationID)
+	if err != nil {
+		return err
+	}
+	batch.LocationID = uint32(location.ID)
 
-		// add product stock to company stock
-		_, err = q.AddCompanyStock(ctx, generated.AddCompanyStockParams{
-			ProductID: int64(batch.ProductID),
-			Quantity:  batch.Quantity,
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add company stock: %s", err.Error())
-		}
+	purchaseOrderLineID := pgtype.Int8{Valid: false}
+	if batch.PurchaseOrderLineID != nil {
+		purchaseOrderLineID = pgtype.Int8{Int64: int64(*batch.PurchaseOrderLineID), Valid: true}
+	}
 
-		// add admin_stats.total_company_stock
-		adminstats, err := q.GetAdminStats(ctx, 1)
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats: %s", err.Error())
-		}
+	expiryDate := pgtype.Date{Valid: false}
+	if batch.ExpiryDate != nil {
+		expiryDate = pgtype.Date{Time: *batch.ExpiryDate, Valid: true}
+	}
 
-		_, err = q.UpdateAdminStats(ctx, generated.UpdateAdminStatsParams{
-			ID:                    adminstats.ID,
-			TotalCompanyStock:     pgtype.Int8{Int64: adminstats.TotalCompanyStock + int64(batch.Quantity), Valid: true},
-			TotalStockDistributed: pgtype.Int8{Valid: false},
-			TotalValueDistributed: pgtype.Numeric{Valid: false},
-			TotalPaymentsReceived: pgtype.Numeric{Valid: false},
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update admin stats: %s", err.Error())
-		}
+	// create product batch record
+	pgProductBatch, err := q.CreateProductBatchRecord(ctx, generated.CreateProductBatchRecordParams{
+		ProductID:           int64(batch.ProductID),
+		BatchNumber:         batch.BatchNumber,
+		Quantity:            batch.Quantity,
+		PurchasePrice:       pkg.Float64ToPgTypeNumeric(batch.PurchasePrice),
+		DateReceived:        batch.DateReceived,
+		ExpiryDate:          expiryDate,
+		LocationID:          location.ID,
+		PurchaseOrderLineID: purchaseOrderLineID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create product batch record: %s", err.Error())
+	}
 
-		// create alert
-		if err = q.CreateAlert(ctx, generated.CreateAlertParams{
-			Type:        "STOCK_RECEIVED",
-			Title:       "Stock received",
-			Description: fmt.Sprintf("Batch #%s - %d units", pgProductBatch.BatchNumber, pgProductBatch.Quantity),
-		}); err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
-		}
+	batch.ID = uint32(pgProductBatch.ID)
+	batch.UnitCost = pkg.PgTypeNumericToFloat64(pgProductBatch.UnitCost)
+	batch.CreatedAt = pgProductBatch.CreatedAt
+
+	_, err = q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+		ProductID:    int64(batch.ProductID),
+		OwnerType:    "COMPANY",
+		OwnerID:      pgtype.Int8{Valid: false},
+		MovementType: "IN",
+		Quantity:     batch.Quantity,
+		UnitPrice:    pkg.Float64ToPgTypeNumeric(batch.PurchasePrice),
+		Source:       "PURCHASE",
+		Note:         batch.BatchNumber,
+		LocationID:   pgtype.Int8{Int64: location.ID, Valid: true},
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+	}
 
-		return nil
+	// add batch to batch_inventory record
+	_, err = q.CreateBatchInventoryRecord(ctx, generated.CreateBatchInventoryRecordParams{
+		BatchID:           int64(batch.ID),
+		LocationID:        location.ID,
+		ProductID:         int64(batch.ProductID),
+		RemainingQuantity: batch.Quantity,
 	})
 	if err != nil {
-		return nil, err
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create batch inventory record: %s", err.Error())
 	}
 
-	return batch, nil
+	// add product stock to company stock
+	_, err = q.AddCompanyStock(ctx, generated.AddCompanyStockParams{
+		ProductID:  int64(batch.ProductID),
+		LocationID: location.ID,
+		Quantity:   batch.Quantity,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add company stock: %s", err.Error())
+	}
+
+	// add admin_stats.total_company_stock
+	adminstats, err := q.GetAdminStats(ctx, 1)
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats: %s", err.Error())
+	}
+
+	_, err = q.UpdateAdminStats(ctx, generated.UpdateAdminStatsParams{
+		ID:                    adminstats.ID,
+		TotalCompanyStock:     pgtype.Int8{Int64: adminstats.TotalCompanyStock + int64(batch.Quantity), Valid: true},
+		TotalStockDistributed: pgtype.Int8{Valid: false},
+		TotalValueDistributed: pgtype.Numeric{Valid: false},
+		TotalPaymentsReceived: pgtype.Numeric{Valid: false},
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update admin stats: %s", err.Error())
+	}
+
+	// create alert
+	if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+		Type:        "STOCK_RECEIVED",
+		Title:       "Stock received",
+		Description: fmt.Sprintf("Batch #%s - %d units into %s", pgProductBatch.BatchNumber, pgProductBatch.Quantity, location.Name),
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+	}
+
+	return nil
 }
 
 func (cr *CompanyRepository) ListProductBatches(ctx context.Context, filter *repository.ProductBatchFilter) ([]*repository.ProductBatch, *pkg.Pagination, error) {
 	listParams := generated.ListBatchInventoryParams{
-		Limit:     int32(filter.Pagination.PageSize),
-		Offset:    pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
-		Search:    pgtype.Text{Valid: false},
-		ProductID: pgtype.Int8{Valid: false},
-		InStock:   pgtype.Bool{Valid: false},
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		Search:     pgtype.Text{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		InStock:    pgtype.Bool{Valid: false},
 	}
 	countParams := generated.ListBatchInventoryCountParams{
-		Search:    pgtype.Text{Valid: false},
-		ProductID: pgtype.Int8{Valid: false},
-		InStock:   pgtype.Bool{Valid: false},
+		Search:     pgtype.Text{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		InStock:    pgtype.Bool{Valid: false},
 	}
 
 	if filter.Search != nil {
@@ -140,6 +174,11 @@ func (cr *CompanyRepository) ListProductBatches(ctx context.Context, filter *rep
 		countParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
 	}
 
+	if filter.LocationID != nil {
+		listParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+		countParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+	}
+
 	pgBatches, err := cr.queries.ListBatchInventory(ctx, listParams)
 	if err != nil {
 		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list product batches: %s", err.Error())
@@ -152,13 +191,16 @@ func (cr *CompanyRepository) ListProductBatches(ctx context.Context, filter *rep
 
 	batches := make([]*repository.ProductBatch, len(pgBatches))
 	for i, pgBatch := range pgBatches {
-		batches[i] = &repository.ProductBatch{
+		batch := &repository.ProductBatch{
 			ID:                uint32(pgBatch.ID),
 			ProductID:         uint32(pgBatch.ProductID),
 			BatchNumber:       pgBatch.BatchNumber,
 			Quantity:          pgBatch.Quantity,
 			PurchasePrice:     pkg.PgTypeNumericToFloat64(pgBatch.PurchasePrice),
+			LandedUnitCost:    pkg.PgTypeNumericToFloat64(pgBatch.LandedUnitCost),
+			UnitCost:          pkg.PgTypeNumericToFloat64(pgBatch.UnitCost),
 			DateReceived:      pgBatch.DateReceived,
+			LocationID:        uint32(pgBatch.LocationID),
 			CreatedAt:         pgBatch.CreatedAt,
 			ProductCategory:   pgBatch.ProductCategory,
 			RemainingQuantity: pgBatch.RemainingQuantity,
@@ -169,220 +211,442 @@ func (cr *CompanyRepository) ListProductBatches(ctx context.Context, filter *rep
 				Unit:              pgBatch.ProductUnit,
 				LowStockThreshold: int32(pgBatch.ProductLowStockThreshold),
 			},
+			Location: &repository.LocationShort{
+				ID:   uint32(pgBatch.LocationID),
+				Name: pgBatch.LocationName,
+			},
+		}
+		if pgBatch.ExpiryDate.Valid {
+			batch.ExpiryDate = &pgBatch.ExpiryDate.Time
 		}
+		if pgBatch.PurchaseOrderLineID.Valid {
+			lineID := uint32(pgBatch.PurchaseOrderLineID.Int64)
+			batch.PurchaseOrderLineID = &lineID
+		}
+		batches[i] = batch
 	}
 
 	return batches, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
 }
 
 func (cr *CompanyRepository) DistributeStockToReseller(ctx context.Context, distribution *repository.StockDistribution) (*repository.StockDistribution, error) {
+	distribution.AllocationStrategy = cr.db.allocationStrategy(distribution.AllocationStrategy)
+
 	err := cr.db.ExecTx(ctx, func(q *generated.Queries) error {
-		totalAvailable, err := q.GetBatchInventoryProductSum(ctx, int64(distribution.ProductID))
+		location, err := resolveLocation(ctx, q, distribution.LocationID)
 		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get batch inventory product sum: %s", err.Error())
+			return err
 		}
+		distribution.LocationID = uint32(location.ID)
 
-		if totalAvailable < int64(distribution.Quantity) {
-			return pkg.Errorf(pkg.INVALID_ERROR, "insufficient stock available for distribution")
+		// locking the account keeps concurrent distributions from both fitting under the limit
+		account, err := q.GetResellerAccountForUpdate(ctx, int64(distribution.ResellerID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller account not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
 		}
 
-		batches, err := q.ListBatchInventoryForUpdate(ctx, int64(distribution.ProductID))
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list batch inventory for update: %s", err.Error())
+		balance := pkg.PgTypeNumericToFloat64(account.Balance)
+		total := roundCents(float64(distribution.Quantity) * distribution.UnitPrice)
+
+		if !account.CreditLimit.Valid || roundCents(balance+total) <= pkg.PgTypeNumericToFloat64(account.CreditLimit) {
+			return distributeStock(ctx, q, cr.db.config.INVOICE_DUE_DAYS, distribution)
 		}
 
-		resellerName, err := q.GetResellerNameByID(ctx, int64(distribution.ResellerID))
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+		creditLimit := pkg.PgTypeNumericToFloat64(account.CreditLimit)
+		holdParams := generated.CreateCreditHoldParams{
+			ResellerID:      int64(distribution.ResellerID),
+			ProductID:       int64(distribution.ProductID),
+			Quantity:        distribution.Quantity,
+			UnitPrice:       pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
+			DateDistributed: distribution.DateDistributed,
+			DeferInvoice:    distribution.DeferInvoice,
+			DueDate:         pgtype.Date{Valid: false},
+			Balance:         account.Balance,
+			CreditLimit:     account.CreditLimit,
+			Status:          repository.CREDIT_HOLD_HELD,
+			Justification:   pgtype.Text{Valid: false},
+			DistributionID:  pgtype.Int8{Valid: false},
+			DecidedAt:       pgtype.Timestamptz{Valid: false},
+			LocationID:      location.ID,
 		}
 
-		// create stock movement records for company (OUT)
-		stockMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
-			ProductID:    int64(distribution.ProductID),
-			OwnerType:    "COMPANY",
-			OwnerID:      pgtype.Int8{Valid: false},
-			MovementType: "OUT",
-			Quantity:     int64(distribution.Quantity),
-			UnitPrice:    pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
-			Source:       "DISTRIBUTION",
-			Note:         fmt.Sprintf("Distributed to: %s", resellerName),
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+		if distribution.DueDate != nil {
+			holdParams.DueDate = pgtype.Date{Time: *distribution.DueDate, Valid: true}
 		}
 
-		remainingToIssue := int64(distribution.Quantity)
+		switch {
+		case distribution.OverrideReason != "":
+			if err := distributeStock(ctx, q, cr.db.config.INVOICE_DUE_DAYS, distribution); err != nil {
+				return err
+			}
 
-		for _, batch := range batches {
-			if remainingToIssue <= 0 {
-				break
+			holdParams.Status = repository.CREDIT_HOLD_OVERRIDDEN
+			holdParams.Justification = pgtype.Text{String: distribution.OverrideReason, Valid: true}
+			holdParams.DistributionID = pgtype.Int8{Int64: int64(distribution.ID), Valid: true}
+			holdParams.DecidedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		case distribution.HoldOverLimit:
+			// held distributions are allocated when they are overridden, the batches asked for
+			// may be gone by then and the default strategy is used
+			if len(distribution.Batches) > 0 {
+				return pkg.Errorf(pkg.INVALID_ERROR, "distribution of %.2f is past the credit limit of %.2f and cannot be held with named batches", total, creditLimit)
 			}
+			if distribution.AllocationStrategy != cr.db.allocationStrategy("") {
+				return pkg.Errorf(pkg.INVALID_ERROR, "distribution of %.2f is past the credit limit of %.2f and cannot be held with the %s allocation strategy", total, creditLimit, distribution.AllocationStrategy)
+			}
+		default:
+			return pkg.Errorf(pkg.INVALID_ERROR, "distribution of %.2f would take the balance to %.2f, past the credit limit of %.2f", total, roundCents(balance+total), creditLimit)
+		}
 
-			takeQty := min(batch.RemainingQuantity, remainingToIssue)
+		pgHold, err := q.CreateCreditHold(ctx, holdParams)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create credit hold: %s", err.Error())
+		}
 
-			// update batch inventory records
-			_, err = q.RemoveBatchInventoryQuantity(ctx, generated.RemoveBatchInventoryQuantityParams{
-				Quantity:  takeQty,
-				BatchID:   batch.BatchID,
-				ProductID: int64(batch.ProductID),
-			})
-			if err != nil {
-				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove batch inventory quantity: %s", err.Error())
-			}
+		distribution.CreditHold = newCreditHold(pgHold)
 
-			// add stock_movement_batch record
-			_, err = q.CreateStockMovementBatchRecord(ctx, generated.CreateStockMovementBatchRecordParams{
-				Owner:           "COMPANY",
-				StockMovementID: stockMovement.ID,
-				BatchID:         batch.BatchID,
-				BatchNumber:     batch.BatchNumber,
-				Quantity:        takeQty,
-				UnitCost:        pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
-			})
-			if err != nil {
-				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
-			}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			_, err = q.CreateResellerBatchInventoryRecord(ctx, generated.CreateResellerBatchInventoryRecordParams{
-				ResellerID:        int64(distribution.ResellerID),
-				ProductID:         int64(distribution.ProductID),
-				SourceBatchID:     batch.BatchID,
-				BatchNumber:       batch.BatchNumber,
-				RemainingQuantity: takeQty,
-				UnitCost:          pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
-			})
-			if err != nil {
-				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create reseller batch inventory record: %s", err.Error())
-			}
+	return distribution, nil
+}
+
+// distributeStock issues the stock from company batches to the reseller, charges their account
+// and invoices it using the caller's transaction.
+func distributeStock(ctx context.Context, q *generated.Queries, dueDays int, distribution *repository.StockDistribution) error {
+	available, err := q.GetBatchInventoryProductSum(ctx, generated.GetBatchInventoryProductSumParams{
+		ProductID:  int64(distribution.ProductID),
+		LocationID: int64(distribution.LocationID),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get batch inventory product sum: %s", err.Error())
+	}
 
-			remainingToIssue -= takeQty
+	if available.TotalRemaining < int64(distribution.Quantity) {
+		if available.TotalExpired > 0 {
+			return pkg.Errorf(pkg.INVALID_ERROR, "insufficient stock available for distribution, %d units are in expired batches", available.TotalExpired)
 		}
+		return pkg.Errorf(pkg.INVALID_ERROR, "insufficient stock available for distribution")
+	}
+
+	batches, err := q.ListBatchInventoryForUpdate(ctx, generated.ListBatchInventoryForUpdateParams{
+		ProductID:  int64(distribution.ProductID),
+		LocationID: int64(distribution.LocationID),
+		Strategy:   distribution.AllocationStrategy,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list batch inventory for update: %s", err.Error())
+	}
+
+	batchIDs := make([]int64, len(batches))
+	remaining := make([]int64, len(batches))
+	for i, batch := range batches {
+		batchIDs[i] = batch.BatchID
+		remaining[i] = batch.RemainingQuantity
+	}
 
-		if remainingToIssue > 0 {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "insufficient stock")
+	takes, err := allocateBatches(batchIDs, remaining, int64(distribution.Quantity), distribution.Batches)
+	if err != nil {
+		return err
+	}
+
+	resellerName, err := q.GetResellerNameByID(ctx, int64(distribution.ResellerID))
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+	}
+
+	// create stock movement records for company (OUT)
+	stockMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+		ProductID:    int64(distribution.ProductID),
+		OwnerType:    "COMPANY",
+		OwnerID:      pgtype.Int8{Valid: false},
+		MovementType: "OUT",
+		Quantity:     int64(distribution.Quantity),
+		UnitPrice:    pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
+		Source:       "DISTRIBUTION",
+		Note:         fmt.Sprintf("Distributed to: %s", resellerName),
+		LocationID:   pgtype.Int8{Int64: int64(distribution.LocationID), Valid: true},
+		MovementDate: pgtype.Timestamptz{Time: distribution.DateDistributed, Valid: true},
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+	}
+
+	issuedBatches := []generated.CreateStockMovementBatchRecordParams{}
+
+	for i, batch := range batches {
+		takeQty := takes[i]
+		if takeQty == 0 {
+			continue
 		}
 
-		// create stock distribution record
-		pgStockDistribution, err := q.CreateStockDistributionRecord(ctx, generated.CreateStockDistributionRecordParams{
-			ResellerID:      int64(distribution.ResellerID),
-			ProductID:       int64(distribution.ProductID),
-			Quantity:        distribution.Quantity,
-			UnitPrice:       pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
-			DateDistributed: distribution.DateDistributed,
+		// update batch inventory records
+		_, err = q.RemoveBatchInventoryQuantity(ctx, generated.RemoveBatchInventoryQuantityParams{
+			Quantity:   takeQty,
+			BatchID:    batch.BatchID,
+			LocationID: batch.LocationID,
 		})
 		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock distribution record: %s", err.Error())
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove batch inventory quantity: %s", err.Error())
 		}
 
-		distribution.ID = uint32(pgStockDistribution.ID)
-		distribution.TotalPrice = pkg.PgTypeNumericToFloat64(pgStockDistribution.TotalPrice)
-		distribution.CreatedAt = pgStockDistribution.CreatedAt
-
-		// create stock movement record for reseller (IN)
-		_, err = q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
-			ProductID:    int64(distribution.ProductID),
-			OwnerType:    "RESELLER",
-			OwnerID:      pgtype.Int8{Int64: int64(distribution.ResellerID), Valid: true},
-			MovementType: "IN",
-			Quantity:     int64(distribution.Quantity),
-			UnitPrice:    pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
-			Source:       "PURCHASE",
-			Note:         fmt.Sprintf("%s received products worth: %.2f", resellerName, distribution.TotalPrice),
+		// add stock_movement_batch record
+		issued, err := q.CreateStockMovementBatchRecord(ctx, generated.CreateStockMovementBatchRecordParams{
+			Owner:           "COMPANY",
+			StockMovementID: stockMovement.ID,
+			BatchID:         batch.BatchID,
+			BatchNumber:     batch.BatchNumber,
+			Quantity:        takeQty,
+			UnitCost:        pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
 		})
 		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
 		}
 
-		// update company stock (reduce quantity)
-		_, err = q.RemoveCompanyStock(ctx, generated.RemoveCompanyStockParams{
-			ProductID: int64(distribution.ProductID),
-			Quantity:  int64(distribution.Quantity),
+		// the reseller's layer keeps the cost the units left company stock at
+		issuedBatches = append(issuedBatches, generated.CreateStockMovementBatchRecordParams{
+			Owner:         "RESELLER",
+			BatchID:       batch.BatchID,
+			BatchNumber:   batch.BatchNumber,
+			Quantity:      takeQty,
+			UnitCost:      pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
+			BatchUnitCost: issued.BatchUnitCost,
+		})
+
+		_, err = q.CreateResellerBatchInventoryRecord(ctx, generated.CreateResellerBatchInventoryRecordParams{
+			ResellerID:        int64(distribution.ResellerID),
+			ProductID:         int64(distribution.ProductID),
+			SourceBatchID:     batch.BatchID,
+			BatchNumber:       batch.BatchNumber,
+			RemainingQuantity: takeQty,
+			UnitCost:          pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
+			BatchUnitCost:     issued.BatchUnitCost,
 		})
 		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove company stock: %s", err.Error())
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create reseller batch inventory record: %s", err.Error())
 		}
+	}
 
-		// update reseller stock (check if exists, else create)
-		resellerStockExists, err := q.CheckResellerStockExists(ctx, generated.CheckResellerStockExistsParams{
+	// create stock distribution record
+	pgStockDistribution, err := q.CreateStockDistributionRecord(ctx, generated.CreateStockDistributionRecordParams{
+		ResellerID:      int64(distribution.ResellerID),
+		ProductID:       int64(distribution.ProductID),
+		Quantity:        distribution.Quantity,
+		UnitPrice:       pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
+		DateDistributed: distribution.DateDistributed,
+		LocationID:      int64(distribution.LocationID),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock distribution record: %s", err.Error())
+	}
+
+	distribution.ID = uint32(pgStockDistribution.ID)
+	distribution.TotalPrice = pkg.PgTypeNumericToFloat64(pgStockDistribution.TotalPrice)
+	distribution.CreatedAt = pgStockDistribution.CreatedAt
+
+	// create stock movement record for reseller (IN)
+	resellerStockMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+		ProductID:    int64(distribution.ProductID),
+		OwnerType:    "RESELLER",
+		OwnerID:      pgtype.Int8{Int64: int64(distribution.ResellerID), Valid: true},
+		MovementType: "IN",
+		Quantity:     int64(distribution.Quantity),
+		UnitPrice:    pkg.Float64ToPgTypeNumeric(distribution.UnitPrice),
+		Source:       "PURCHASE",
+		Note:         fmt.Sprintf("%s received products worth: %.2f", resellerName, distribution.TotalPrice),
+		LocationID:   pgtype.Int8{Valid: false},
+		MovementDate: pgtype.Timestamptz{Time: distribution.DateDistributed, Valid: true},
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+	}
+
+	// record the batches the reseller received so their stock can be replayed per batch
+	for _, issuedBatch := range issuedBatches {
+		issuedBatch.StockMovementID = resellerStockMovement.ID
+		_, err = q.CreateStockMovementBatchRecord(ctx, issuedBatch)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
+		}
+	}
+
+	// update company stock (reduce quantity)
+	_, err = q.RemoveCompanyStock(ctx, generated.RemoveCompanyStockParams{
+		ProductID:  int64(distribution.ProductID),
+		LocationID: int64(distribution.LocationID),
+		Quantity:   int64(distribution.Quantity),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove company stock: %s", err.Error())
+	}
+
+	// update reseller stock (check if exists, else create)
+	resellerStockExists, err := q.CheckResellerStockExists(ctx, generated.CheckResellerStockExistsParams{
+		ProductID:  int64(distribution.ProductID),
+		ResellerID: int64(distribution.ResellerID),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to check reseller stock exists: %s", err.Error())
+	}
+
+	if !resellerStockExists {
+		_, err = q.CreateResellerStock(ctx, generated.CreateResellerStockParams{
+			ResellerID: int64(distribution.ResellerID),
 			ProductID:  int64(distribution.ProductID),
+			Quantity:   int64(distribution.Quantity),
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create reseller stock: %s", err.Error())
+		}
+	} else {
+		_, err = q.AddResellerStockQuantity(ctx, generated.AddResellerStockQuantityParams{
 			ResellerID: int64(distribution.ResellerID),
+			ProductID:  int64(distribution.ProductID),
+			Quantity:   int64(distribution.Quantity),
 		})
 		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to check reseller stock exists: %s", err.Error())
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add reseller stock quantity: %s", err.Error())
+		}
+	}
+
+	// update admin stats (company stock, stock_distributed, value_distributed)
+	adminstats, err := q.GetAdminStats(ctx, 1)
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats: %s", err.Error())
+	}
+
+	_, err = q.UpdateAdminStats(ctx, generated.UpdateAdminStatsParams{
+		ID:                    1,
+		TotalCompanyStock:     pgtype.Int8{Int64: adminstats.TotalCompanyStock - int64(distribution.Quantity), Valid: true},
+		TotalStockDistributed: pgtype.Int8{Int64: adminstats.TotalStockDistributed + int64(distribution.Quantity), Valid: true},
+		TotalValueDistributed: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(adminstats.TotalValueDistributed) + distribution.TotalPrice),
+		TotalPaymentsReceived: pgtype.Numeric{Valid: false},
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update admin stats: %s", err.Error())
+	}
+
+	// update reseller account (stock_received, value_received, balance)
+	resellerAccount, err := q.GetResellerAccount(ctx, int64(distribution.ResellerID))
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
+	}
+
+	_, err = q.UpdateResellerAccount(ctx, generated.UpdateResellerAccountParams{
+		ResellerID:         int64(distribution.ResellerID),
+		TotalStockReceived: pgtype.Int8{Int64: resellerAccount.TotalStockReceived + int64(distribution.Quantity), Valid: true},
+		TotalValueReceived: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(resellerAccount.TotalValueReceived) + distribution.TotalPrice),
+		TotalSalesValue:    pgtype.Numeric{Valid: false},
+		TotalPaid:          pgtype.Numeric{Valid: false},
+		TotalCogs:          pgtype.Numeric{Valid: false},
+		Balance:            pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(resellerAccount.Balance) + distribution.TotalPrice),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update reseller account: %s", err.Error())
+	}
+
+	if !distribution.DeferInvoice {
+		invoiceID, err := createInvoice(ctx, q, dueDays, int64(distribution.ResellerID), []int64{pgStockDistribution.ID}, distribution.TotalPrice, distribution.DateDistributed, distribution.DueDate)
+		if err != nil {
+			return err
 		}
 
-		if !resellerStockExists {
-			_, err = q.CreateResellerStock(ctx, generated.CreateResellerStockParams{
-				ResellerID: int64(distribution.ResellerID),
-				ProductID:  int64(distribution.ProductID),
-				Quantity:   int64(distribution.Quantity),
-			})
-			if err != nil {
-				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create reseller stock: %s", err.Error())
+		id := uint32(invoiceID)
+		distribution.InvoiceID = &id
+	}
+
+	// create alert
+	if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+		Type:        "STOCK_DISTRIBUTED",
+		Title:       "Stock distributed",
+		Description: fmt.Sprintf("To %s - %d units", resellerName, distribution.Quantity),
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+	}
+
+	return nil
+}
+
+// allocationStrategy falls back to STOCK_ALLOCATION_STRATEGY, checked when the config is
+// loaded, when no strategy is given.
+func (s *Store) allocationStrategy(strategy string) string {
+	if strategy == "" {
+		return s.config.STOCK_ALLOCATION_STRATEGY
+	}
+
+	return strategy
+}
+
+// allocateBatches works out how much to take from each locked inventory row. The rows come in
+// allocation order with batchIDs and remaining holding each row's batch and quantity left.
+// Without requested batches the rows are taken from in order until the quantity is covered,
+// with them only the requested batches are taken from, each up to the quantity asked for.
+func allocateBatches(batchIDs, remaining []int64, quantity int64, requested []repository.BatchAllocation) ([]int64, error) {
+	var wanted map[int64]int64
+
+	if len(requested) > 0 {
+		wanted = make(map[int64]int64, len(requested))
+		var total int64
+		for _, allocation := range requested {
+			batchID := int64(allocation.BatchID)
+			if _, ok := wanted[batchID]; ok {
+				return nil, pkg.Errorf(pkg.INVALID_ERROR, "batch %d is listed more than once", batchID)
 			}
-		} else {
-			_, err = q.AddResellerStockQuantity(ctx, generated.AddResellerStockQuantityParams{
-				ResellerID: int64(distribution.ResellerID),
-				ProductID:  int64(distribution.ProductID),
-				Quantity:   int64(distribution.Quantity),
-			})
-			if err != nil {
-				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add reseller stock quantity: %s", err.Error())
+			if allocation.Quantity <= 0 {
+				return nil, pkg.Errorf(pkg.INVALID_ERROR, "quantity for batch %d must be greater than 0", batchID)
 			}
+			wanted[batchID] = allocation.Quantity
+			total += allocation.Quantity
 		}
 
-		// update admin stats (company stock, stock_distributed, value_distributed)
-		adminstats, err := q.GetAdminStats(ctx, 1)
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats: %s", err.Error())
+		if total != quantity {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "batch quantities add up to %d, not %d", total, quantity)
 		}
 
-		_, err = q.UpdateAdminStats(ctx, generated.UpdateAdminStatsParams{
-			ID:                    1,
-			TotalCompanyStock:     pgtype.Int8{Int64: adminstats.TotalCompanyStock - int64(distribution.Quantity), Valid: true},
-			TotalStockDistributed: pgtype.Int8{Int64: adminstats.TotalStockDistributed + int64(distribution.Quantity), Valid: true},
-			TotalValueDistributed: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(adminstats.TotalValueDistributed) + distribution.TotalPrice),
-			TotalPaymentsReceived: pgtype.Numeric{Valid: false},
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update admin stats: %s", err.Error())
+		available := make(map[int64]int64, len(batchIDs))
+		for i, batchID := range batchIDs {
+			available[batchID] += remaining[i]
 		}
 
-		// update reseller account (stock_received, value_received, balance)
-		resellerAccount, err := q.GetResellerAccount(ctx, int64(distribution.ResellerID))
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
+		for _, allocation := range requested {
+			left := available[int64(allocation.BatchID)]
+			if left == 0 {
+				return nil, pkg.Errorf(pkg.INVALID_ERROR, "batch %d has no unexpired stock of the product left", allocation.BatchID)
+			}
+			if left < allocation.Quantity {
+				return nil, pkg.Errorf(pkg.INVALID_ERROR, "batch %d has %d units left, %d were asked for", allocation.BatchID, left, allocation.Quantity)
+			}
 		}
+	}
 
-		_, err = q.UpdateResellerAccount(ctx, generated.UpdateResellerAccountParams{
-			ResellerID:         int64(distribution.ResellerID),
-			TotalStockReceived: pgtype.Int8{Int64: resellerAccount.TotalStockReceived + int64(distribution.Quantity), Valid: true},
-			TotalValueReceived: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(resellerAccount.TotalValueReceived) + distribution.TotalPrice),
-			TotalSalesValue:    pgtype.Numeric{Valid: false},
-			TotalPaid:          pgtype.Numeric{Valid: false},
-			TotalCogs:          pgtype.Numeric{Valid: false},
-			Balance:            pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(resellerAccount.Balance) + distribution.TotalPrice),
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update reseller account: %s", err.Error())
+	takes := make([]int64, len(batchIDs))
+	toTake := quantity
+
+	for i, batchID := range batchIDs {
+		if toTake <= 0 {
+			break
 		}
 
-		// create alert
-		if err = q.CreateAlert(ctx, generated.CreateAlertParams{
-			Type:        "STOCK_DISTRIBUTED",
-			Title:       "Stock distributed",
-			Description: fmt.Sprintf("To %s - %d units", resellerName, distribution.Quantity),
-		}); err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+		take := min(remaining[i], toTake)
+		if wanted != nil {
+			take = min(remaining[i], wanted[batchID])
+			wanted[batchID] -= take
 		}
 
-		return nil
-	})
-	if err != nil {
-		return nil, err
+		takes[i] = take
+		toTake -= take
 	}
 
-	return distribution, nil
+	if toTake > 0 {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "insufficient stock")
+	}
+
+	return takes, nil
 }
 
 func (cr *CompanyRepository) ListStockDistributions(ctx context.Context, filter *repository.StockDistributionFilter) ([]*repository.StockDistribution, *pkg.Pagination, error) {
@@ -392,12 +656,14 @@ func (cr *CompanyRepository) ListStockDistributions(ctx context.Context, filter
 		Search:     pgtype.Text{Valid: false},
 		ProductID:  pgtype.Int8{Valid: false},
 		ResellerID: pgtype.Int8{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
 	}
 
 	countParams := generated.ListStockDistributionsCountParams{
 		Search:     pgtype.Text{Valid: false},
 		ProductID:  pgtype.Int8{Valid: false},
 		ResellerID: pgtype.Int8{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
 	}
 
 	if filter.Search != nil {
@@ -416,6 +682,11 @@ func (cr *CompanyRepository) ListStockDistributions(ctx context.Context, filter
 		countParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
 	}
 
+	if filter.LocationID != nil {
+		listParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+		countParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+	}
+
 	pgDistributions, err := cr.queries.ListStockDistributions(ctx, listParams)
 	if err != nil {
 		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stock distributions: %s", err.Error())
@@ -436,6 +707,7 @@ func (cr *CompanyRepository) ListStockDistributions(ctx context.Context, filter
 			UnitPrice:       pkg.PgTypeNumericToFloat64(pgDistribution.UnitPrice),
 			TotalPrice:      pkg.PgTypeNumericToFloat64(pgDistribution.TotalPrice),
 			DateDistributed: pgDistribution.DateDistributed,
+			LocationID:      uint32(pgDistribution.LocationID),
 			CreatedAt:       pgDistribution.CreatedAt,
 			Product: &repository.ProductShort{
 				ID:                uint32(pgDistribution.ProductID),
@@ -449,6 +721,15 @@ func (cr *CompanyRepository) ListStockDistributions(ctx context.Context, filter
 				Name:        pgDistribution.ResellerName.String,
 				PhoneNumber: pgDistribution.ResellerPhoneNumber.String,
 			},
+			Location: &repository.LocationShort{
+				ID:   uint32(pgDistribution.LocationID),
+				Name: pgDistribution.LocationName.String,
+			},
+		}
+
+		if pgDistribution.InvoiceID.Valid {
+			invoiceID := uint32(pgDistribution.InvoiceID.Int64)
+			distributions[i].InvoiceID = &invoiceID
 		}
 	}
 
@@ -457,14 +738,16 @@ func (cr *CompanyRepository) ListStockDistributions(ctx context.Context, filter
 
 func (cr *CompanyRepository) ListCompanyStock(ctx context.Context, filter *repository.CompanyStockFilter) ([]*repository.CompanyStock, *pkg.Pagination, error) {
 	listParams := generated.ListCompanyStockParams{
-		Limit:   int32(filter.Pagination.PageSize),
-		Offset:  pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
-		Search:  pgtype.Text{Valid: false},
-		InStock: pgtype.Bool{Valid: false},
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		Search:     pgtype.Text{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
+		InStock:    pgtype.Bool{Valid: false},
 	}
 	countParams := generated.ListCompanyStockCountParams{
-		Search:  pgtype.Text{Valid: false},
-		InStock: pgtype.Bool{Valid: false},
+		Search:     pgtype.Text{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
+		InStock:    pgtype.Bool{Valid: false},
 	}
 
 	if filter.Search != nil {
@@ -478,6 +761,11 @@ func (cr *CompanyRepository) ListCompanyStock(ctx context.Context, filter *repos
 		countParams.InStock = pgtype.Bool{Bool: *filter.InStock, Valid: true}
 	}
 
+	if filter.LocationID != nil {
+		listParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+		countParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+	}
+
 	pgStocks, err := cr.queries.ListCompanyStock(ctx, listParams)
 	if err != nil {
 		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list company stock: %s", err.Error())
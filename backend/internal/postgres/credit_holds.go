@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (cr *CompanyRepository) ListCreditHolds(ctx context.Context, filter *repository.CreditHoldFilter) ([]*repository.CreditHold, *pkg.Pagination, error) {
+	listParams := generated.ListCreditHoldsParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		ResellerID: pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+	}
+
+	countParams := generated.ListCreditHoldsCountParams{
+		ResellerID: pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+	}
+
+	if filter.ResellerID != nil {
+		listParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+		countParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	if filter.Status != nil {
+		listParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+		countParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+	}
+
+	pgHolds, err := cr.queries.ListCreditHolds(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list credit holds: %s", err.Error())
+	}
+
+	totalCount, err := cr.queries.ListCreditHoldsCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count credit holds: %s", err.Error())
+	}
+
+	holds := make([]*repository.CreditHold, len(pgHolds))
+	for i, pgHold := range pgHolds {
+		holds[i] = newCreditHold(generated.CreditHold{
+			ID:              pgHold.ID,
+			ResellerID:      pgHold.ResellerID,
+			ProductID:       pgHold.ProductID,
+			Quantity:        pgHold.Quantity,
+			UnitPrice:       pgHold.UnitPrice,
+			TotalPrice:      pgHold.TotalPrice,
+			DateDistributed: pgHold.DateDistributed,
+			DeferInvoice:    pgHold.DeferInvoice,
+			DueDate:         pgHold.DueDate,
+			Balance:         pgHold.Balance,
+			CreditLimit:     pgHold.CreditLimit,
+			Status:          pgHold.Status,
+			Justification:   pgHold.Justification,
+			DistributionID:  pgHold.DistributionID,
+			DecidedAt:       pgHold.DecidedAt,
+			CreatedAt:       pgHold.CreatedAt,
+			LocationID:      pgHold.LocationID,
+		})
+		holds[i].Product = &repository.ProductShort{
+			ID:   uint32(pgHold.ProductID),
+			Name: pgHold.ProductName,
+			Unit: pgHold.ProductUnit,
+		}
+		holds[i].User = &repository.UserShort{
+			ID:          uint32(pgHold.ResellerID),
+			Name:        pgHold.ResellerName,
+			PhoneNumber: pgHold.ResellerPhoneNumber,
+		}
+	}
+
+	return holds, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+func (cr *CompanyRepository) OverrideCreditHold(ctx context.Context, id uint32, justification string) (*repository.CreditHold, error) {
+	var hold *repository.CreditHold
+
+	err := cr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgHold, err := getHeldCreditHold(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		distribution := &repository.StockDistribution{
+			ResellerID:      uint32(pgHold.ResellerID),
+			ProductID:       uint32(pgHold.ProductID),
+			Quantity:        pgHold.Quantity,
+			UnitPrice:       pkg.PgTypeNumericToFloat64(pgHold.UnitPrice),
+			DateDistributed: pgHold.DateDistributed,
+			DeferInvoice:    pgHold.DeferInvoice,
+			LocationID:      uint32(pgHold.LocationID),
+			// held distributions are allocated with the default strategy
+			AllocationStrategy: cr.db.allocationStrategy(""),
+		}
+
+		if pgHold.DueDate.Valid {
+			distribution.DueDate = &pgHold.DueDate.Time
+		}
+
+		if _, err := q.GetResellerAccountForUpdate(ctx, pgHold.ResellerID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
+		}
+
+		if err := distributeStock(ctx, q, cr.db.config.INVOICE_DUE_DAYS, distribution); err != nil {
+			return err
+		}
+
+		resolved, err := q.ResolveCreditHold(ctx, generated.ResolveCreditHoldParams{
+			ID:             pgHold.ID,
+			Status:         repository.CREDIT_HOLD_OVERRIDDEN,
+			Justification:  pgtype.Text{String: justification, Valid: true},
+			DistributionID: pgtype.Int8{Int64: int64(distribution.ID), Valid: true},
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update credit hold: %s", err.Error())
+		}
+
+		hold = newCreditHold(resolved)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (cr *CompanyRepository) RejectCreditHold(ctx context.Context, id uint32, reason string) (*repository.CreditHold, error) {
+	var hold *repository.CreditHold
+
+	err := cr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgHold, err := getHeldCreditHold(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		resolved, err := q.ResolveCreditHold(ctx, generated.ResolveCreditHoldParams{
+			ID:             pgHold.ID,
+			Status:         repository.CREDIT_HOLD_REJECTED,
+			Justification:  pgtype.Text{String: reason, Valid: true},
+			DistributionID: pgtype.Int8{Valid: false},
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update credit hold: %s", err.Error())
+		}
+
+		hold = newCreditHold(resolved)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// getHeldCreditHold locks a credit hold that is still waiting on an admin.
+func getHeldCreditHold(ctx context.Context, q *generated.Queries, id uint32) (generated.CreditHold, error) {
+	pgHold, err := q.GetCreditHoldForUpdate(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pgHold, pkg.Errorf(pkg.NOT_FOUND_ERROR, "credit hold not found")
+		}
+		return pgHold, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get credit hold: %s", err.Error())
+	}
+
+	if pgHold.Status != repository.CREDIT_HOLD_HELD {
+		return pgHold, pkg.Errorf(pkg.INVALID_ERROR, "credit hold is already %s", pgHold.Status)
+	}
+
+	return pgHold, nil
+}
+
+func newCreditHold(pgHold generated.CreditHold) *repository.CreditHold {
+	hold := &repository.CreditHold{
+		ID:              uint32(pgHold.ID),
+		ResellerID:      uint32(pgHold.ResellerID),
+		ProductID:       uint32(pgHold.ProductID),
+		Quantity:        pgHold.Quantity,
+		UnitPrice:       pkg.PgTypeNumericToFloat64(pgHold.UnitPrice),
+		TotalPrice:      pkg.PgTypeNumericToFloat64(pgHold.TotalPrice),
+		DateDistributed: pgHold.DateDistributed,
+		DeferInvoice:    pgHold.DeferInvoice,
+		Balance:         pkg.PgTypeNumericToFloat64(pgHold.Balance),
+		CreditLimit:     pkg.PgTypeNumericToFloat64(pgHold.CreditLimit),
+		Status:          pgHold.Status,
+		Justification:   pgHold.Justification.String,
+		LocationID:      uint32(pgHold.LocationID),
+		CreatedAt:       pgHold.CreatedAt,
+	}
+
+	if pgHold.DueDate.Valid {
+		hold.DueDate = &pgHold.DueDate.Time
+	}
+
+	if pgHold.DistributionID.Valid {
+		distributionID := uint32(pgHold.DistributionID.Int64)
+		hold.DistributionID = &distributionID
+	}
+
+	if pgHold.DecidedAt.Valid {
+		hold.DecidedAt = &pgHold.DecidedAt.Time
+	}
+
+	return hold
+}
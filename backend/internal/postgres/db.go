@@ -20,6 +20,16 @@ type PostgresRepo struct {
 	ResellerRepository      *ResellerRepository
 	PaymentRepository       *PaymentRepository
 	StockMovementRepository *StockMovementRepository
+	ReportRepository        *ReportRepository
+	ReportRunRepository     *ReportRunRepository
+	StockAuditRepository    *StockAuditRepository
+	MpesaRepository         *MpesaRepository
+	InvoiceRepository       *InvoiceRepository
+	PaymentImportRepository *PaymentImportRepository
+	StocktakeRepository     *StocktakeRepository
+	LocationRepository      *LocationRepository
+	PurchaseOrderRepository *PurchaseOrderRepository
+	LandedCostRepository    *LandedCostRepository
 }
 
 func NewPostgresRepo(store *Store) *PostgresRepo {
@@ -30,6 +40,16 @@ func NewPostgresRepo(store *Store) *PostgresRepo {
 		ResellerRepository:      NewResellerRepository(store),
 		PaymentRepository:       NewPaymentRepository(store),
 		StockMovementRepository: NewStockMovementRepository(store),
+		ReportRepository:        NewReportRepository(store),
+		ReportRunRepository:     NewReportRunRepository(store),
+		StockAuditRepository:    NewStockAuditRepository(store),
+		MpesaRepository:         NewMpesaRepository(store),
+		InvoiceRepository:       NewInvoiceRepository(store),
+		PaymentImportRepository: NewPaymentImportRepository(store),
+		StocktakeRepository:     NewStocktakeRepository(store),
+		LocationRepository:      NewLocationRepository(store),
+		PurchaseOrderRepository: NewPurchaseOrderRepository(store),
+		LandedCostRepository:    NewLandedCostRepository(store),
 	}
 }
 
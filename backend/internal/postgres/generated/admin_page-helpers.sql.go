@@ -7,28 +7,34 @@ package generated
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const getAdminBatchesPageStats = `-- name: GetAdminBatchesPageStats :one
 WITH total_batches AS (
   SELECT COUNT(*)::bigint AS batch_count
-  FROM product_batches
+  FROM product_batches pb
+  WHERE ($1::bigint IS NULL OR pb.location_id = $1)
 ),
 active_batches AS (
   SELECT COUNT(DISTINCT pb.id)::bigint AS active_count
   FROM product_batches pb
   JOIN batch_inventory bi ON bi.batch_id = pb.id
   WHERE bi.remaining_quantity > 0
+    AND ($1::bigint IS NULL OR bi.location_id = $1)
 ),
 total_value AS (
-  SELECT COALESCE(SUM(pb.quantity * pb.purchase_price), 0)::numeric AS batch_value
+  SELECT COALESCE(SUM(pb.quantity * pb.unit_cost), 0)::numeric AS batch_value
   FROM product_batches pb
+  WHERE ($1::bigint IS NULL OR pb.location_id = $1)
 ),
 remaining_value AS (
-  SELECT COALESCE(SUM(bi.remaining_quantity * pb.purchase_price), 0)::numeric AS remaining_stock_value
+  SELECT COALESCE(SUM(bi.remaining_quantity * pb.unit_cost), 0)::numeric AS remaining_stock_value
   FROM batch_inventory bi
   JOIN product_batches pb ON pb.id = bi.batch_id
   WHERE bi.remaining_quantity > 0
+    AND ($1::bigint IS NULL OR bi.location_id = $1)
 )
 SELECT 
   json_build_object(
@@ -39,8 +45,8 @@ SELECT
   ) AS batches_stats
 `
 
-func (q *Queries) GetAdminBatchesPageStats(ctx context.Context) ([]byte, error) {
-	row := q.db.QueryRow(ctx, getAdminBatchesPageStats)
+func (q *Queries) GetAdminBatchesPageStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getAdminBatchesPageStats, locationID)
 	var batches_stats []byte
 	err := row.Scan(&batches_stats)
 	return batches_stats, err
@@ -48,16 +54,27 @@ func (q *Queries) GetAdminBatchesPageStats(ctx context.Context) ([]byte, error)
 
 const getAdminDashboardStats = `-- name: GetAdminDashboardStats :one
 WITH company_stock_total AS (
-  SELECT COALESCE(SUM(quantity), 0)::bigint AS total_stock
-  FROM company_stock
+  SELECT COALESCE(SUM(cs.quantity), 0)::bigint AS total_stock
+  FROM company_stock cs
+  WHERE ($1::bigint IS NULL OR cs.location_id = $1)
+),
+product_stock AS (
+  SELECT p.id, p.name, p.low_stock_threshold, COALESCE(SUM(cs.quantity), 0)::bigint AS quantity
+  FROM products p
+  LEFT JOIN company_stock cs ON cs.product_id = p.id
+    AND ($1::bigint IS NULL OR cs.location_id = $1)
+  WHERE p.deleted = false
+  GROUP BY p.id, p.name, p.low_stock_threshold
 ),
 distribution_units AS (
-  SELECT COALESCE(SUM(quantity), 0)::bigint AS units
-  FROM stock_distributions
+  SELECT COALESCE(SUM(sd.quantity), 0)::bigint AS units
+  FROM stock_distributions sd
+  WHERE ($1::bigint IS NULL OR sd.location_id = $1)
 ),
 distribution_value AS (
-  SELECT COALESCE(SUM(total_price), 0)::numeric AS value
-  FROM stock_distributions
+  SELECT COALESCE(SUM(sd.total_price), 0)::numeric AS value
+  FROM stock_distributions sd
+  WHERE ($1::bigint IS NULL OR sd.location_id = $1)
 ),
 payments_received AS (
   SELECT COALESCE(SUM(amount), 0)::numeric AS total
@@ -65,9 +82,8 @@ payments_received AS (
 ),
 low_stock_products AS (
   SELECT COUNT(*)::bigint AS low_stock_count
-  FROM products p
-  JOIN company_stock cs ON cs.product_id = p.id
-  WHERE p.deleted = false AND cs.quantity <= p.low_stock_threshold
+  FROM product_stock ps
+  WHERE ps.quantity <= ps.low_stock_threshold
 ),
 pending_requests AS (
   SELECT COUNT(*)::bigint AS pending_count
@@ -81,26 +97,24 @@ active_resellers_count AS (
     AND EXISTS (SELECT 1 FROM reseller_stock rs WHERE rs.reseller_id = u.id)
 ),
 recent_activities AS (
-  SELECT id, title, description, type, created_at
+  SELECT *
   FROM activities
   ORDER BY created_at DESC
   LIMIT 5
 ),
 stock_alerts AS (
   SELECT 
-    p.id,
-    p.name AS product_name,
-    cs.quantity,
-    p.low_stock_threshold,
+    ps.id,
+    ps.name AS product_name,
+    ps.quantity,
+    ps.low_stock_threshold,
     CASE 
-      WHEN cs.quantity = 0 THEN 'OUT_OF_STOCK'
+      WHEN ps.quantity = 0 THEN 'OUT_OF_STOCK'
       ELSE 'LOW_STOCK'
     END AS alert_type
-  FROM products p
-  JOIN company_stock cs ON cs.product_id = p.id
-  WHERE p.deleted = false 
-    AND cs.quantity <= p.low_stock_threshold
-  ORDER BY cs.quantity ASC, p.name
+  FROM product_stock ps
+  WHERE ps.quantity <= ps.low_stock_threshold
+  ORDER BY ps.quantity ASC, ps.name
 ),
 top_resellers AS (
   SELECT 
@@ -142,29 +156,34 @@ SELECT
   ) AS dashboard_stats
 `
 
-func (q *Queries) GetAdminDashboardStats(ctx context.Context) ([]byte, error) {
-	row := q.db.QueryRow(ctx, getAdminDashboardStats)
+func (q *Queries) GetAdminDashboardStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getAdminDashboardStats, locationID)
 	var dashboard_stats []byte
 	err := row.Scan(&dashboard_stats)
 	return dashboard_stats, err
 }
 
 const getAdminDistributionPageStats = `-- name: GetAdminDistributionPageStats :one
-WITH total_distributions AS (
+WITH distributions AS (
+  SELECT sd.reseller_id, sd.quantity, sd.total_price
+  FROM stock_distributions sd
+  WHERE ($1::bigint IS NULL OR sd.location_id = $1)
+),
+total_distributions AS (
   SELECT COUNT(*)::bigint AS distribution_count
-  FROM stock_distributions
+  FROM distributions
 ),
 units_distributed AS (
   SELECT COALESCE(SUM(quantity), 0)::bigint AS units
-  FROM stock_distributions
+  FROM distributions
 ),
 total_value AS (
   SELECT COALESCE(SUM(total_price), 0)::numeric AS value
-  FROM stock_distributions
+  FROM distributions
 ),
 active_resellers_count AS (
   SELECT COUNT(DISTINCT reseller_id)::bigint AS reseller_count
-  FROM stock_distributions
+  FROM distributions
 )
 SELECT 
   json_build_object(
@@ -175,8 +194,8 @@ SELECT
   ) AS distribution_stats
 `
 
-func (q *Queries) GetAdminDistributionPageStats(ctx context.Context) ([]byte, error) {
-	row := q.db.QueryRow(ctx, getAdminDistributionPageStats)
+func (q *Queries) GetAdminDistributionPageStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getAdminDistributionPageStats, locationID)
 	var distribution_stats []byte
 	err := row.Scan(&distribution_stats)
 	return distribution_stats, err
@@ -211,18 +230,29 @@ func (q *Queries) GetAdminGoodsRequestsPageStats(ctx context.Context) ([]byte, e
 const getAdminPaymentsPageStats = `-- name: GetAdminPaymentsPageStats :one
 WITH payment_stats AS (
   SELECT 
-    COUNT(*)::bigint AS total_payments,
+    COUNT(*) FILTER (WHERE reversal_of IS NULL)::bigint AS total_payments,
     COALESCE(SUM(amount), 0)::numeric AS total_amount_received,
     COALESCE(SUM(amount) FILTER (WHERE method = 'MPESA'), 0)::numeric AS mpesa_total,
     COALESCE(SUM(amount) FILTER (WHERE method = 'CASH'), 0)::numeric AS cash_total
   FROM payments
+),
+method_totals AS (
+  SELECT pm.id, pm.code, pm.name, COALESCE(SUM(p.amount), 0)::numeric AS total
+  FROM payment_methods pm
+  LEFT JOIN payments p ON p.method = pm.code
+  GROUP BY pm.id, pm.code, pm.name
 )
 SELECT 
   json_build_object(
     'total_payments', (SELECT total_payments FROM payment_stats),
     'total_received', (SELECT total_amount_received FROM payment_stats),
+    -- kept for clients reading the fixed keys, method_totals covers every method
     'mpesa_total', (SELECT mpesa_total FROM payment_stats),
-    'cash_total', (SELECT cash_total FROM payment_stats)
+    'cash_total', (SELECT cash_total FROM payment_stats),
+    'method_totals', (
+      SELECT COALESCE(json_agg(json_build_object('method', code, 'name', name, 'total', total) ORDER BY id), '[]'::json)
+      FROM method_totals
+    )
   ) AS payments_stats
 `
 
@@ -237,18 +267,25 @@ const getAdminProductsPageStats = `-- name: GetAdminProductsPageStats :one
 WITH total_stock AS (
   SELECT COALESCE(SUM(cs.quantity), 0)::bigint AS total_units
   FROM company_stock cs
+  WHERE ($1::bigint IS NULL OR cs.location_id = $1)
+),
+product_stock AS (
+  SELECT p.id, p.low_stock_threshold, COALESCE(SUM(cs.quantity), 0)::bigint AS quantity
+  FROM products p
+  LEFT JOIN company_stock cs ON cs.product_id = p.id
+    AND ($1::bigint IS NULL OR cs.location_id = $1)
+  WHERE p.deleted = false
+  GROUP BY p.id, p.low_stock_threshold
 ),
 low_stock_items AS (
   SELECT COUNT(*)::bigint AS low_stock_count
-  FROM products p
-  JOIN company_stock cs ON cs.product_id = p.id
-  WHERE p.deleted = false AND cs.quantity <= p.low_stock_threshold
+  FROM product_stock
+  WHERE quantity <= low_stock_threshold
 ),
 out_of_stock AS (
   SELECT COUNT(*)::bigint AS out_of_stock_count
-  FROM products p
-  JOIN company_stock cs ON cs.product_id = p.id
-  WHERE p.deleted = false AND cs.quantity = 0
+  FROM product_stock
+  WHERE quantity = 0
 )
 SELECT 
   json_build_object(
@@ -258,8 +295,8 @@ SELECT
   ) AS products_stats
 `
 
-func (q *Queries) GetAdminProductsPageStats(ctx context.Context) ([]byte, error) {
-	row := q.db.QueryRow(ctx, getAdminProductsPageStats)
+func (q *Queries) GetAdminProductsPageStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getAdminProductsPageStats, locationID)
 	var products_stats []byte
 	err := row.Scan(&products_stats)
 	return products_stats, err
@@ -307,7 +344,8 @@ WITH movement_stats AS (
     COUNT(*)::bigint AS total_movements,
     COALESCE(SUM(quantity) FILTER (WHERE movement_type = 'IN'), 0)::bigint AS stock_in,
     COALESCE(SUM(quantity) FILTER (WHERE movement_type = 'OUT'), 0)::bigint AS stock_out
-  FROM stock_movements
+  FROM stock_movements sm
+  WHERE ($1::bigint IS NULL OR sm.location_id = $1)
 )
 SELECT 
   json_build_object(
@@ -321,8 +359,8 @@ SELECT
   ) AS stock_movements_stats
 `
 
-func (q *Queries) GetAdminStockMovementsPageStats(ctx context.Context) ([]byte, error) {
-	row := q.db.QueryRow(ctx, getAdminStockMovementsPageStats)
+func (q *Queries) GetAdminStockMovementsPageStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getAdminStockMovementsPageStats, locationID)
 	var stock_movements_stats []byte
 	err := row.Scan(&stock_movements_stats)
 	return stock_movements_stats, err
@@ -341,13 +379,15 @@ distributed AS (
   SELECT
     DATE(date_distributed)::date AS day,
     COALESCE(SUM(quantity), 0)::bigint AS units_distributed
-  FROM stock_distributions
+  FROM stock_distributions sd
   WHERE date_distributed >= CURRENT_DATE - INTERVAL '6 days'
+    AND ($1::bigint IS NULL OR sd.location_id = $1)
   GROUP BY DATE(date_distributed)
 ),
 current_in_stock AS (
-  SELECT COALESCE(SUM(quantity), 0)::bigint AS current_units
-  FROM company_stock
+  SELECT COALESCE(SUM(cs.quantity), 0)::bigint AS current_units
+  FROM company_stock cs
+  WHERE ($1::bigint IS NULL OR cs.location_id = $1)
 ),
 future_distributions AS (
   -- cumulative distributions after a given day (to reconstruct past stock)
@@ -356,6 +396,7 @@ future_distributions AS (
            SELECT SUM(quantity)
            FROM stock_distributions sd
            WHERE DATE(sd.date_distributed) > ds.day
+             AND ($1::bigint IS NULL OR sd.location_id = $1)
          ), 0)::bigint AS future_units
   FROM date_series ds
 )
@@ -376,8 +417,8 @@ type GetAdminWeeklyStockChartRow struct {
 	Distributed int64  `json:"distributed"`
 }
 
-func (q *Queries) GetAdminWeeklyStockChart(ctx context.Context) ([]GetAdminWeeklyStockChartRow, error) {
-	rows, err := q.db.Query(ctx, getAdminWeeklyStockChart)
+func (q *Queries) GetAdminWeeklyStockChart(ctx context.Context, locationID pgtype.Int8) ([]GetAdminWeeklyStockChartRow, error) {
+	rows, err := q.db.Query(ctx, getAdminWeeklyStockChart, locationID)
 	if err != nil {
 		return nil, err
 	}
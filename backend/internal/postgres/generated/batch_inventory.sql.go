@@ -13,99 +13,135 @@ import (
 )
 
 const addBatchInventoryQuantity = `-- name: AddBatchInventoryQuantity :one
-UPDATE batch_inventory
-SET remaining_quantity = remaining_quantity + $1
-WHERE batch_id = $2 AND product_id = $3
-RETURNING batch_id, product_id, remaining_quantity, created_at
+INSERT INTO batch_inventory (batch_id, location_id, product_id, remaining_quantity)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (batch_id, location_id) DO UPDATE
+SET remaining_quantity = batch_inventory.remaining_quantity + EXCLUDED.remaining_quantity
+RETURNING batch_id, product_id, remaining_quantity, created_at, location_id
 `
 
 type AddBatchInventoryQuantityParams struct {
-	Quantity  int64 `json:"quantity"`
-	BatchID   int64 `json:"batch_id"`
-	ProductID int64 `json:"product_id"`
+	BatchID    int64 `json:"batch_id"`
+	LocationID int64 `json:"location_id"`
+	ProductID  int64 `json:"product_id"`
+	Quantity   int64 `json:"quantity"`
 }
 
 func (q *Queries) AddBatchInventoryQuantity(ctx context.Context, arg AddBatchInventoryQuantityParams) (BatchInventory, error) {
-	row := q.db.QueryRow(ctx, addBatchInventoryQuantity, arg.Quantity, arg.BatchID, arg.ProductID)
+	row := q.db.QueryRow(ctx, addBatchInventoryQuantity,
+		arg.BatchID,
+		arg.LocationID,
+		arg.ProductID,
+		arg.Quantity,
+	)
 	var i BatchInventory
 	err := row.Scan(
 		&i.BatchID,
 		&i.ProductID,
 		&i.RemainingQuantity,
 		&i.CreatedAt,
+		&i.LocationID,
 	)
 	return i, err
 }
 
 const createBatchInventoryRecord = `-- name: CreateBatchInventoryRecord :one
-INSERT INTO batch_inventory (batch_id, product_id, remaining_quantity)
-VALUES ($1, $2, $3)
-RETURNING batch_id, product_id, remaining_quantity, created_at
+INSERT INTO batch_inventory (batch_id, location_id, product_id, remaining_quantity)
+VALUES ($1, $2, $3, $4)
+RETURNING batch_id, product_id, remaining_quantity, created_at, location_id
 `
 
 type CreateBatchInventoryRecordParams struct {
 	BatchID           int64 `json:"batch_id"`
+	LocationID        int64 `json:"location_id"`
 	ProductID         int64 `json:"product_id"`
 	RemainingQuantity int64 `json:"remaining_quantity"`
 }
 
 func (q *Queries) CreateBatchInventoryRecord(ctx context.Context, arg CreateBatchInventoryRecordParams) (BatchInventory, error) {
-	row := q.db.QueryRow(ctx, createBatchInventoryRecord, arg.BatchID, arg.ProductID, arg.RemainingQuantity)
+	row := q.db.QueryRow(ctx, createBatchInventoryRecord,
+		arg.BatchID,
+		arg.LocationID,
+		arg.ProductID,
+		arg.RemainingQuantity,
+	)
 	var i BatchInventory
 	err := row.Scan(
 		&i.BatchID,
 		&i.ProductID,
 		&i.RemainingQuantity,
 		&i.CreatedAt,
+		&i.LocationID,
 	)
 	return i, err
 }
 
 const getBatchInventoryProductSum = `-- name: GetBatchInventoryProductSum :one
-SELECT COALESCE(SUM(remaining_quantity), 0)::bigint AS total_remaining
-FROM batch_inventory
-WHERE product_id = $1
-      AND remaining_quantity > 0
+SELECT 
+    COALESCE(SUM(bi.remaining_quantity) FILTER (WHERE pb.expiry_date IS NULL OR pb.expiry_date >= CURRENT_DATE), 0)::bigint AS total_remaining,
+    COALESCE(SUM(bi.remaining_quantity) FILTER (WHERE pb.expiry_date < CURRENT_DATE), 0)::bigint AS total_expired
+FROM batch_inventory bi
+JOIN product_batches pb ON pb.id = bi.batch_id
+WHERE bi.product_id = $1
+      AND bi.location_id = $2
+      AND bi.remaining_quantity > 0
 `
 
-func (q *Queries) GetBatchInventoryProductSum(ctx context.Context, productID int64) (int64, error) {
-	row := q.db.QueryRow(ctx, getBatchInventoryProductSum, productID)
-	var total_remaining int64
-	err := row.Scan(&total_remaining)
-	return total_remaining, err
+type GetBatchInventoryProductSumParams struct {
+	ProductID  int64 `json:"product_id"`
+	LocationID int64 `json:"location_id"`
+}
+
+type GetBatchInventoryProductSumRow struct {
+	TotalRemaining int64 `json:"total_remaining"`
+	TotalExpired   int64 `json:"total_expired"`
+}
+
+func (q *Queries) GetBatchInventoryProductSum(ctx context.Context, arg GetBatchInventoryProductSumParams) (GetBatchInventoryProductSumRow, error) {
+	row := q.db.QueryRow(ctx, getBatchInventoryProductSum, arg.ProductID, arg.LocationID)
+	var i GetBatchInventoryProductSumRow
+	err := row.Scan(&i.TotalRemaining, &i.TotalExpired)
+	return i, err
 }
 
 const listBatchInventory = `-- name: ListBatchInventory :many
-SELECT pb.id, pb.product_id, pb.batch_number, pb.quantity, pb.purchase_price, pb.date_received, pb.created_at, p.name AS product_name, bi.remaining_quantity, p.price AS product_price, p.unit AS product_unit, p.low_stock_threshold AS product_low_stock_threshold, p.category AS product_category
+SELECT pb.id, pb.product_id, pb.batch_number, pb.quantity, pb.purchase_price, pb.date_received, pb.created_at, pb.expiry_date, pb.location_id, pb.purchase_order_line_id, pb.landed_unit_cost, pb.unit_cost, p.name AS product_name, bi.remaining_quantity, p.price AS product_price, p.unit AS product_unit, p.low_stock_threshold AS product_low_stock_threshold, p.category AS product_category, l.name AS location_name
 FROM product_batches pb
 JOIN products p ON p.id = pb.product_id
-JOIN batch_inventory bi ON bi.batch_id = pb.id
+JOIN locations l ON l.id = pb.location_id
+JOIN LATERAL (
+    SELECT COUNT(*) AS locations, COALESCE(SUM(remaining_quantity), 0)::bigint AS remaining_quantity
+    FROM batch_inventory
+    WHERE batch_id = pb.id
+      AND ($1::bigint IS NULL OR location_id = $1)
+) bi ON bi.locations > 0
 WHERE 
     (
-        $1::bigint IS NULL
-        OR pb.product_id = $1
+        $2::bigint IS NULL
+        OR pb.product_id = $2
     )
     AND (
-        COALESCE($2, '') = '' 
-        OR LOWER(p.name) LIKE $2
-        OR LOWER(p.category) LIKE $2
-        OR LOWER(pb.batch_number) LIKE $2
+        COALESCE($3, '') = '' 
+        OR LOWER(p.name) LIKE $3
+        OR LOWER(p.category) LIKE $3
+        OR LOWER(pb.batch_number) LIKE $3
     )
     AND (
-        $3::boolean IS NULL
-        OR ($3 = true AND bi.remaining_quantity > 0)
-        OR ($3 = false AND bi.remaining_quantity = 0)
+        $4::boolean IS NULL
+        OR ($4 = true AND bi.remaining_quantity > 0)
+        OR ($4 = false AND bi.remaining_quantity = 0)
     )
 ORDER BY pb.date_received DESC
-LIMIT $5 OFFSET $4
+LIMIT $5 OFFSET $6
 `
 
 type ListBatchInventoryParams struct {
-	ProductID pgtype.Int8 `json:"product_id"`
-	Search    interface{} `json:"search"`
-	InStock   pgtype.Bool `json:"in_stock"`
-	Offset    int32       `json:"offset"`
-	Limit     int32       `json:"limit"`
+	LocationID pgtype.Int8 `json:"location_id"`
+	ProductID  pgtype.Int8 `json:"product_id"`
+	Search     interface{} `json:"search"`
+	InStock    pgtype.Bool `json:"in_stock"`
+	Limit      int32       `json:"limit"`
+	Offset     int32       `json:"offset"`
 }
 
 type ListBatchInventoryRow struct {
@@ -116,21 +152,28 @@ type ListBatchInventoryRow struct {
 	PurchasePrice            pgtype.Numeric `json:"purchase_price"`
 	DateReceived             time.Time      `json:"date_received"`
 	CreatedAt                time.Time      `json:"created_at"`
+	ExpiryDate               pgtype.Date    `json:"expiry_date"`
+	LocationID               int64          `json:"location_id"`
+	PurchaseOrderLineID      pgtype.Int8    `json:"purchase_order_line_id"`
+	LandedUnitCost           pgtype.Numeric `json:"landed_unit_cost"`
+	UnitCost                 pgtype.Numeric `json:"unit_cost"`
 	ProductName              string         `json:"product_name"`
 	RemainingQuantity        int64          `json:"remaining_quantity"`
 	ProductPrice             pgtype.Numeric `json:"product_price"`
 	ProductUnit              string         `json:"product_unit"`
 	ProductLowStockThreshold int32          `json:"product_low_stock_threshold"`
 	ProductCategory          string         `json:"product_category"`
+	LocationName             string         `json:"location_name"`
 }
 
 func (q *Queries) ListBatchInventory(ctx context.Context, arg ListBatchInventoryParams) ([]ListBatchInventoryRow, error) {
 	rows, err := q.db.Query(ctx, listBatchInventory,
+		arg.LocationID,
 		arg.ProductID,
 		arg.Search,
 		arg.InStock,
-		arg.Offset,
 		arg.Limit,
+		arg.Offset,
 	)
 	if err != nil {
 		return nil, err
@@ -147,12 +190,51 @@ func (q *Queries) ListBatchInventory(ctx context.Context, arg ListBatchInventory
 			&i.PurchasePrice,
 			&i.DateReceived,
 			&i.CreatedAt,
+			&i.ExpiryDate,
+			&i.LocationID,
+			&i.PurchaseOrderLineID,
+			&i.LandedUnitCost,
+			&i.UnitCost,
 			&i.ProductName,
 			&i.RemainingQuantity,
 			&i.ProductPrice,
 			&i.ProductUnit,
 			&i.ProductLowStockThreshold,
 			&i.ProductCategory,
+			&i.LocationName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBatchInventoryByBatchIDsForUpdate = `-- name: ListBatchInventoryByBatchIDsForUpdate :many
+SELECT batch_id, product_id, remaining_quantity, created_at, location_id FROM batch_inventory
+WHERE batch_id = ANY($1::bigint[])
+ORDER BY batch_id ASC, location_id ASC
+FOR UPDATE
+`
+
+func (q *Queries) ListBatchInventoryByBatchIDsForUpdate(ctx context.Context, batchIds []int64) ([]BatchInventory, error) {
+	rows, err := q.db.Query(ctx, listBatchInventoryByBatchIDsForUpdate, batchIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []BatchInventory{}
+	for rows.Next() {
+		var i BatchInventory
+		if err := rows.Scan(
+			&i.BatchID,
+			&i.ProductID,
+			&i.RemainingQuantity,
+			&i.CreatedAt,
+			&i.LocationID,
 		); err != nil {
 			return nil, err
 		}
@@ -168,61 +250,149 @@ const listBatchInventoryCount = `-- name: ListBatchInventoryCount :one
 SELECT COUNT(*) AS total_batches
 FROM product_batches pb
 LEFT JOIN products p ON p.id = pb.product_id
-JOIN batch_inventory bi ON bi.batch_id = pb.id
+JOIN LATERAL (
+    SELECT COUNT(*) AS locations, COALESCE(SUM(remaining_quantity), 0)::bigint AS remaining_quantity
+    FROM batch_inventory
+    WHERE batch_id = pb.id
+      AND ($1::bigint IS NULL OR location_id = $1)
+) bi ON bi.locations > 0
 WHERE 
     (
-        $1::bigint IS NULL
-        OR pb.product_id = $1
+        $2::bigint IS NULL
+        OR pb.product_id = $2
     )
     AND (
-        COALESCE($2, '') = '' 
-        OR LOWER(p.name) LIKE $2
-        OR LOWER(p.category) LIKE $2
-        OR LOWER(pb.batch_number) LIKE $2
+        COALESCE($3, '') = '' 
+        OR LOWER(p.name) LIKE $3
+        OR LOWER(p.category) LIKE $3
+        OR LOWER(pb.batch_number) LIKE $3
     )
     AND (
-        $3::boolean IS NULL
-        OR ($3 = true AND bi.remaining_quantity > 0)
-        OR ($3 = false AND bi.remaining_quantity = 0)
+        $4::boolean IS NULL
+        OR ($4 = true AND bi.remaining_quantity > 0)
+        OR ($4 = false AND bi.remaining_quantity = 0)
     )
 `
 
 type ListBatchInventoryCountParams struct {
-	ProductID pgtype.Int8 `json:"product_id"`
-	Search    interface{} `json:"search"`
-	InStock   pgtype.Bool `json:"in_stock"`
+	LocationID pgtype.Int8 `json:"location_id"`
+	ProductID  pgtype.Int8 `json:"product_id"`
+	Search     interface{} `json:"search"`
+	InStock    pgtype.Bool `json:"in_stock"`
 }
 
 func (q *Queries) ListBatchInventoryCount(ctx context.Context, arg ListBatchInventoryCountParams) (int64, error) {
-	row := q.db.QueryRow(ctx, listBatchInventoryCount, arg.ProductID, arg.Search, arg.InStock)
+	row := q.db.QueryRow(ctx, listBatchInventoryCount,
+		arg.LocationID,
+		arg.ProductID,
+		arg.Search,
+		arg.InStock,
+	)
 	var total_batches int64
 	err := row.Scan(&total_batches)
 	return total_batches, err
 }
 
+const listBatchInventoryForAdjustment = `-- name: ListBatchInventoryForAdjustment :many
+SELECT 
+    bi.batch_id, bi.product_id, bi.remaining_quantity, bi.created_at, bi.location_id,
+    pb.batch_number,
+    pb.unit_cost
+FROM batch_inventory bi
+JOIN product_batches pb ON pb.id = bi.batch_id
+WHERE 
+    bi.product_id = $1
+    AND bi.location_id = $2
+    AND (
+        $3::bigint IS NULL
+        OR bi.batch_id = $3
+    )
+    AND (bi.remaining_quantity > 0 OR $3::bigint IS NOT NULL)
+ORDER BY pb.date_received ASC
+FOR UPDATE
+`
+
+type ListBatchInventoryForAdjustmentParams struct {
+	ProductID  int64       `json:"product_id"`
+	LocationID int64       `json:"location_id"`
+	BatchID    pgtype.Int8 `json:"batch_id"`
+}
+
+type ListBatchInventoryForAdjustmentRow struct {
+	BatchID           int64          `json:"batch_id"`
+	ProductID         int64          `json:"product_id"`
+	RemainingQuantity int64          `json:"remaining_quantity"`
+	CreatedAt         time.Time      `json:"created_at"`
+	LocationID        int64          `json:"location_id"`
+	BatchNumber       string         `json:"batch_number"`
+	UnitCost          pgtype.Numeric `json:"unit_cost"`
+}
+
+func (q *Queries) ListBatchInventoryForAdjustment(ctx context.Context, arg ListBatchInventoryForAdjustmentParams) ([]ListBatchInventoryForAdjustmentRow, error) {
+	rows, err := q.db.Query(ctx, listBatchInventoryForAdjustment, arg.ProductID, arg.LocationID, arg.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListBatchInventoryForAdjustmentRow{}
+	for rows.Next() {
+		var i ListBatchInventoryForAdjustmentRow
+		if err := rows.Scan(
+			&i.BatchID,
+			&i.ProductID,
+			&i.RemainingQuantity,
+			&i.CreatedAt,
+			&i.LocationID,
+			&i.BatchNumber,
+			&i.UnitCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listBatchInventoryForUpdate = `-- name: ListBatchInventoryForUpdate :many
 SELECT 
-    bi.batch_id, bi.product_id, bi.remaining_quantity, bi.created_at,
+    bi.batch_id, bi.product_id, bi.remaining_quantity, bi.created_at, bi.location_id,
     pb.batch_number
 FROM batch_inventory bi
 JOIN product_batches pb ON pb.id = bi.batch_id
 WHERE 
     bi.product_id = $1
+    AND bi.location_id = $2
     AND bi.remaining_quantity > 0
-ORDER BY pb.date_received ASC
+    AND (pb.expiry_date IS NULL OR pb.expiry_date >= CURRENT_DATE)
+ORDER BY
+    CASE WHEN $3::text = 'LIFO' THEN pb.date_received END DESC,
+    CASE WHEN $3::text = 'LIFO' THEN pb.id END DESC,
+    CASE WHEN $3::text = 'FEFO' THEN pb.expiry_date END ASC NULLS LAST,
+    pb.date_received ASC,
+    pb.id ASC
 FOR UPDATE
 `
 
+type ListBatchInventoryForUpdateParams struct {
+	ProductID  int64  `json:"product_id"`
+	LocationID int64  `json:"location_id"`
+	Strategy   string `json:"strategy"`
+}
+
 type ListBatchInventoryForUpdateRow struct {
 	BatchID           int64     `json:"batch_id"`
 	ProductID         int64     `json:"product_id"`
 	RemainingQuantity int64     `json:"remaining_quantity"`
 	CreatedAt         time.Time `json:"created_at"`
+	LocationID        int64     `json:"location_id"`
 	BatchNumber       string    `json:"batch_number"`
 }
 
-func (q *Queries) ListBatchInventoryForUpdate(ctx context.Context, productID int64) ([]ListBatchInventoryForUpdateRow, error) {
-	rows, err := q.db.Query(ctx, listBatchInventoryForUpdate, productID)
+func (q *Queries) ListBatchInventoryForUpdate(ctx context.Context, arg ListBatchInventoryForUpdateParams) ([]ListBatchInventoryForUpdateRow, error) {
+	rows, err := q.db.Query(ctx, listBatchInventoryForUpdate, arg.ProductID, arg.LocationID, arg.Strategy)
 	if err != nil {
 		return nil, err
 	}
@@ -235,6 +405,7 @@ func (q *Queries) ListBatchInventoryForUpdate(ctx context.Context, productID int
 			&i.ProductID,
 			&i.RemainingQuantity,
 			&i.CreatedAt,
+			&i.LocationID,
 			&i.BatchNumber,
 		); err != nil {
 			return nil, err
@@ -250,24 +421,25 @@ func (q *Queries) ListBatchInventoryForUpdate(ctx context.Context, productID int
 const removeBatchInventoryQuantity = `-- name: RemoveBatchInventoryQuantity :one
 UPDATE batch_inventory
 SET remaining_quantity = remaining_quantity - $1
-WHERE batch_id = $2 AND product_id = $3 AND remaining_quantity >= $1
-RETURNING batch_id, product_id, remaining_quantity, created_at
+WHERE batch_id = $2 AND location_id = $3 AND remaining_quantity >= $1
+RETURNING batch_id, product_id, remaining_quantity, created_at, location_id
 `
 
 type RemoveBatchInventoryQuantityParams struct {
-	Quantity  int64 `json:"quantity"`
-	BatchID   int64 `json:"batch_id"`
-	ProductID int64 `json:"product_id"`
+	Quantity   int64 `json:"quantity"`
+	BatchID    int64 `json:"batch_id"`
+	LocationID int64 `json:"location_id"`
 }
 
 func (q *Queries) RemoveBatchInventoryQuantity(ctx context.Context, arg RemoveBatchInventoryQuantityParams) (BatchInventory, error) {
-	row := q.db.QueryRow(ctx, removeBatchInventoryQuantity, arg.Quantity, arg.BatchID, arg.ProductID)
+	row := q.db.QueryRow(ctx, removeBatchInventoryQuantity, arg.Quantity, arg.BatchID, arg.LocationID)
 	var i BatchInventory
 	err := row.Scan(
 		&i.BatchID,
 		&i.ProductID,
 		&i.RemainingQuantity,
 		&i.CreatedAt,
+		&i.LocationID,
 	)
 	return i, err
 }
@@ -12,34 +12,36 @@ import (
 )
 
 const addCompanyStock = `-- name: AddCompanyStock :one
-UPDATE company_stock
-SET quantity = quantity + $1
-WHERE product_id = $2
-RETURNING product_id, quantity
+INSERT INTO company_stock (product_id, location_id, quantity)
+VALUES ($1, $2, $3)
+ON CONFLICT (product_id, location_id) DO UPDATE
+SET quantity = company_stock.quantity + EXCLUDED.quantity
+RETURNING product_id, quantity, location_id
 `
 
 type AddCompanyStockParams struct {
-	Quantity  int64 `json:"quantity"`
-	ProductID int64 `json:"product_id"`
+	ProductID  int64 `json:"product_id"`
+	LocationID int64 `json:"location_id"`
+	Quantity   int64 `json:"quantity"`
 }
 
 func (q *Queries) AddCompanyStock(ctx context.Context, arg AddCompanyStockParams) (CompanyStock, error) {
-	row := q.db.QueryRow(ctx, addCompanyStock, arg.Quantity, arg.ProductID)
+	row := q.db.QueryRow(ctx, addCompanyStock, arg.ProductID, arg.LocationID, arg.Quantity)
 	var i CompanyStock
-	err := row.Scan(&i.ProductID, &i.Quantity)
+	err := row.Scan(&i.ProductID, &i.Quantity, &i.LocationID)
 	return i, err
 }
 
 const createCompanyStock = `-- name: CreateCompanyStock :one
-INSERT INTO company_stock (product_id)
-VALUES ($1)
-RETURNING product_id, quantity
+INSERT INTO company_stock (product_id, location_id)
+SELECT $1, id FROM locations WHERE is_default
+RETURNING product_id, quantity, location_id
 `
 
 func (q *Queries) CreateCompanyStock(ctx context.Context, productID int64) (CompanyStock, error) {
 	row := q.db.QueryRow(ctx, createCompanyStock, productID)
 	var i CompanyStock
-	err := row.Scan(&i.ProductID, &i.Quantity)
+	err := row.Scan(&i.ProductID, &i.Quantity, &i.LocationID)
 	return i, err
 }
 
@@ -53,29 +55,35 @@ SELECT
     p.low_stock_threshold,
     p.description,
     cs.quantity AS company_quantity
-FROM company_stock cs
-JOIN products p ON p.id = cs.product_id
+FROM products p
+JOIN LATERAL (
+    SELECT COALESCE(SUM(quantity), 0)::bigint AS quantity
+    FROM company_stock
+    WHERE product_id = p.id
+      AND ($1::bigint IS NULL OR location_id = $1)
+) cs ON true
 WHERE 
     (
-        COALESCE($1, '') = '' 
-        OR LOWER(p.name) LIKE $1
-        OR LOWER(p.category) LIKE $1
+        COALESCE($2, '') = '' 
+        OR LOWER(p.name) LIKE $2
+        OR LOWER(p.category) LIKE $2
     )
     AND (
-        $2::boolean IS NULL
-        OR ($2 = true AND cs.quantity > 0)
-        OR ($2 = false AND cs.quantity = 0)
+        $3::boolean IS NULL
+        OR ($3 = true AND cs.quantity > 0)
+        OR ($3 = false AND cs.quantity = 0)
     )
     AND p.deleted = false
 ORDER BY p.name
-LIMIT $4 OFFSET $3
+LIMIT $4 OFFSET $5
 `
 
 type ListCompanyStockParams struct {
-	Search  interface{} `json:"search"`
-	InStock pgtype.Bool `json:"in_stock"`
-	Offset  int32       `json:"offset"`
-	Limit   int32       `json:"limit"`
+	LocationID pgtype.Int8 `json:"location_id"`
+	Search     interface{} `json:"search"`
+	InStock    pgtype.Bool `json:"in_stock"`
+	Limit      int32       `json:"limit"`
+	Offset     int32       `json:"offset"`
 }
 
 type ListCompanyStockRow struct {
@@ -91,10 +99,11 @@ type ListCompanyStockRow struct {
 
 func (q *Queries) ListCompanyStock(ctx context.Context, arg ListCompanyStockParams) ([]ListCompanyStockRow, error) {
 	rows, err := q.db.Query(ctx, listCompanyStock,
+		arg.LocationID,
 		arg.Search,
 		arg.InStock,
-		arg.Offset,
 		arg.Limit,
+		arg.Offset,
 	)
 	if err != nil {
 		return nil, err
@@ -125,29 +134,35 @@ func (q *Queries) ListCompanyStock(ctx context.Context, arg ListCompanyStockPara
 
 const listCompanyStockCount = `-- name: ListCompanyStockCount :one
 SELECT COUNT(*) AS total_items
-FROM company_stock cs
-JOIN products p ON p.id = cs.product_id
+FROM products p
+JOIN LATERAL (
+    SELECT COALESCE(SUM(quantity), 0)::bigint AS quantity
+    FROM company_stock
+    WHERE product_id = p.id
+      AND ($1::bigint IS NULL OR location_id = $1)
+) cs ON true
 WHERE 
     (
-        COALESCE($1, '') = '' 
-        OR LOWER(p.name) LIKE $1
-        OR LOWER(p.category) LIKE $1
+        COALESCE($2, '') = '' 
+        OR LOWER(p.name) LIKE $2
+        OR LOWER(p.category) LIKE $2
     )
     AND (
-        $2::boolean IS NULL
-        OR ($2 = true AND cs.quantity > 0)
-        OR ($2 = false AND cs.quantity = 0)
+        $3::boolean IS NULL
+        OR ($3 = true AND cs.quantity > 0)
+        OR ($3 = false AND cs.quantity = 0)
     )
     AND p.deleted = false
 `
 
 type ListCompanyStockCountParams struct {
-	Search  interface{} `json:"search"`
-	InStock pgtype.Bool `json:"in_stock"`
+	LocationID pgtype.Int8 `json:"location_id"`
+	Search     interface{} `json:"search"`
+	InStock    pgtype.Bool `json:"in_stock"`
 }
 
 func (q *Queries) ListCompanyStockCount(ctx context.Context, arg ListCompanyStockCountParams) (int64, error) {
-	row := q.db.QueryRow(ctx, listCompanyStockCount, arg.Search, arg.InStock)
+	row := q.db.QueryRow(ctx, listCompanyStockCount, arg.LocationID, arg.Search, arg.InStock)
 	var total_items int64
 	err := row.Scan(&total_items)
 	return total_items, err
@@ -156,18 +171,19 @@ func (q *Queries) ListCompanyStockCount(ctx context.Context, arg ListCompanyStoc
 const removeCompanyStock = `-- name: RemoveCompanyStock :one
 UPDATE company_stock
 SET quantity = quantity - $1
-WHERE product_id = $2 AND quantity >= $1
-RETURNING product_id, quantity
+WHERE product_id = $2 AND location_id = $3 AND quantity >= $1
+RETURNING product_id, quantity, location_id
 `
 
 type RemoveCompanyStockParams struct {
-	Quantity  int64 `json:"quantity"`
-	ProductID int64 `json:"product_id"`
+	Quantity   int64 `json:"quantity"`
+	ProductID  int64 `json:"product_id"`
+	LocationID int64 `json:"location_id"`
 }
 
 func (q *Queries) RemoveCompanyStock(ctx context.Context, arg RemoveCompanyStockParams) (CompanyStock, error) {
-	row := q.db.QueryRow(ctx, removeCompanyStock, arg.Quantity, arg.ProductID)
+	row := q.db.QueryRow(ctx, removeCompanyStock, arg.Quantity, arg.ProductID, arg.LocationID)
 	var i CompanyStock
-	err := row.Scan(&i.ProductID, &i.Quantity)
+	err := row.Scan(&i.ProductID, &i.Quantity, &i.LocationID)
 	return i, err
 }
@@ -148,7 +148,11 @@ func (q *Queries) GetTotalActiveResellers(ctx context.Context) (int64, error) {
 const getTotalLowStockProducts = `-- name: GetTotalLowStockProducts :one
 SELECT COUNT(p.*) AS total_low_stock_products
 FROM products p
-JOIN company_stock cs ON cs.product_id = p.id
+JOIN (
+    SELECT product_id, SUM(quantity) AS quantity
+    FROM company_stock
+    GROUP BY product_id
+) cs ON cs.product_id = p.id
 WHERE p.deleted = false AND cs.quantity <= p.low_stock_threshold
 `
 
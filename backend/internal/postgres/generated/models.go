@@ -31,11 +31,33 @@ type BatchInventory struct {
 	ProductID         int64     `json:"product_id"`
 	RemainingQuantity int64     `json:"remaining_quantity"`
 	CreatedAt         time.Time `json:"created_at"`
+	LocationID        int64     `json:"location_id"`
 }
 
 type CompanyStock struct {
-	ProductID int64 `json:"product_id"`
-	Quantity  int64 `json:"quantity"`
+	ProductID  int64 `json:"product_id"`
+	Quantity   int64 `json:"quantity"`
+	LocationID int64 `json:"location_id"`
+}
+
+type CreditHold struct {
+	ID              int64              `json:"id"`
+	ResellerID      int64              `json:"reseller_id"`
+	ProductID       int64              `json:"product_id"`
+	Quantity        int32              `json:"quantity"`
+	UnitPrice       pgtype.Numeric     `json:"unit_price"`
+	TotalPrice      pgtype.Numeric     `json:"total_price"`
+	DateDistributed time.Time          `json:"date_distributed"`
+	DeferInvoice    bool               `json:"defer_invoice"`
+	DueDate         pgtype.Date        `json:"due_date"`
+	Balance         pgtype.Numeric     `json:"balance"`
+	CreditLimit     pgtype.Numeric     `json:"credit_limit"`
+	Status          string             `json:"status"`
+	Justification   pgtype.Text        `json:"justification"`
+	DistributionID  pgtype.Int8        `json:"distribution_id"`
+	DecidedAt       pgtype.Timestamptz `json:"decided_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+	LocationID      int64              `json:"location_id"`
 }
 
 type GoodsRequest struct {
@@ -50,15 +72,164 @@ type GoodsRequest struct {
 	CreatedAt   time.Time          `json:"created_at"`
 }
 
+type Invoice struct {
+	ID            int64          `json:"id"`
+	InvoiceNumber string         `json:"invoice_number"`
+	ResellerID    int64          `json:"reseller_id"`
+	TotalAmount   pgtype.Numeric `json:"total_amount"`
+	AmountPaid    pgtype.Numeric `json:"amount_paid"`
+	Status        string         `json:"status"`
+	IssueDate     time.Time      `json:"issue_date"`
+	DueDate       pgtype.Date    `json:"due_date"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type InvoiceCounter struct {
+	ID         int32 `json:"id"`
+	LastNumber int64 `json:"last_number"`
+}
+
+type LandedCost struct {
+	ID               int64          `json:"id"`
+	CostType         string         `json:"cost_type"`
+	Amount           pgtype.Numeric `json:"amount"`
+	AllocationMethod string         `json:"allocation_method"`
+	Reference        pgtype.Text    `json:"reference"`
+	Note             pgtype.Text    `json:"note"`
+	CreatedBy        int64          `json:"created_by"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+type LandedCostAllocation struct {
+	ID                 int64          `json:"id"`
+	LandedCostID       int64          `json:"landed_cost_id"`
+	BatchID            int64          `json:"batch_id"`
+	Amount             pgtype.Numeric `json:"amount"`
+	UnitCostAdded      pgtype.Numeric `json:"unit_cost_added"`
+	CreatedAt          time.Time      `json:"created_at"`
+	Quantity           int64          `json:"quantity"`
+	RoundingDifference pgtype.Numeric `json:"rounding_difference"`
+}
+
+type Location struct {
+	ID        int64       `json:"id"`
+	Name      string      `json:"name"`
+	Address   pgtype.Text `json:"address"`
+	IsDefault bool        `json:"is_default"`
+	Active    bool        `json:"active"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type LocationTransfer struct {
+	ID             int64          `json:"id"`
+	ProductID      int64          `json:"product_id"`
+	FromLocationID int64          `json:"from_location_id"`
+	ToLocationID   int64          `json:"to_location_id"`
+	BatchID        pgtype.Int8    `json:"batch_id"`
+	Quantity       int32          `json:"quantity"`
+	TotalValue     pgtype.Numeric `json:"total_value"`
+	Note           pgtype.Text    `json:"note"`
+	FromMovementID int64          `json:"from_movement_id"`
+	ToMovementID   int64          `json:"to_movement_id"`
+	CreatedBy      int64          `json:"created_by"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type MpesaC2bTransaction struct {
+	ID            int64              `json:"id"`
+	TransID       string             `json:"trans_id"`
+	TransType     string             `json:"trans_type"`
+	TransTime     time.Time          `json:"trans_time"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	BillRefNumber string             `json:"bill_ref_number"`
+	Msisdn        string             `json:"msisdn"`
+	PayerName     string             `json:"payer_name"`
+	Status        string             `json:"status"`
+	ResellerID    pgtype.Int8        `json:"reseller_id"`
+	PaymentID     pgtype.Int8        `json:"payment_id"`
+	AllocatedBy   pgtype.Text        `json:"allocated_by"`
+	AllocatedAt   pgtype.Timestamptz `json:"allocated_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type MpesaStkRequest struct {
+	ID                int64          `json:"id"`
+	ResellerID        int64          `json:"reseller_id"`
+	PhoneNumber       string         `json:"phone_number"`
+	Amount            pgtype.Numeric `json:"amount"`
+	MerchantRequestID string         `json:"merchant_request_id"`
+	CheckoutRequestID string         `json:"checkout_request_id"`
+	Status            string         `json:"status"`
+	ResultCode        pgtype.Int4    `json:"result_code"`
+	ResultDesc        pgtype.Text    `json:"result_desc"`
+	MpesaReceipt      pgtype.Text    `json:"mpesa_receipt"`
+	PaymentID         pgtype.Int8    `json:"payment_id"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
 type Payment struct {
-	ID         int64          `json:"id"`
-	ResellerID int64          `json:"reseller_id"`
-	Amount     pgtype.Numeric `json:"amount"`
-	Method     string         `json:"method"`
-	Reference  pgtype.Text    `json:"reference"`
-	RecordedBy string         `json:"recorded_by"`
-	DatePaid   time.Time      `json:"date_paid"`
-	CreatedAt  time.Time      `json:"created_at"`
+	ID             int64          `json:"id"`
+	ResellerID     int64          `json:"reseller_id"`
+	Amount         pgtype.Numeric `json:"amount"`
+	Method         string         `json:"method"`
+	Reference      pgtype.Text    `json:"reference"`
+	RecordedBy     string         `json:"recorded_by"`
+	DatePaid       time.Time      `json:"date_paid"`
+	CreatedAt      time.Time      `json:"created_at"`
+	ReversalOf     pgtype.Int8    `json:"reversal_of"`
+	ReversalReason pgtype.Text    `json:"reversal_reason"`
+}
+
+type PaymentAllocation struct {
+	ID          int64          `json:"id"`
+	PaymentID   int64          `json:"payment_id"`
+	InvoiceID   int64          `json:"invoice_id"`
+	Amount      pgtype.Numeric `json:"amount"`
+	AllocatedBy string         `json:"allocated_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type PaymentImport struct {
+	ID          int64              `json:"id"`
+	Source      string             `json:"source"`
+	Method      string             `json:"method"`
+	FileName    string             `json:"file_name"`
+	Status      string             `json:"status"`
+	UploadedBy  int64              `json:"uploaded_by"`
+	CommittedAt pgtype.Timestamptz `json:"committed_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+type PaymentImportRow struct {
+	ID                 int64          `json:"id"`
+	ImportID           int64          `json:"import_id"`
+	RowNumber          int32          `json:"row_number"`
+	Reference          string         `json:"reference"`
+	Amount             pgtype.Numeric `json:"amount"`
+	DatePaid           time.Time      `json:"date_paid"`
+	PayerName          string         `json:"payer_name"`
+	PayerPhone         string         `json:"payer_phone"`
+	AccountNumber      string         `json:"account_number"`
+	Details            string         `json:"details"`
+	ResellerID         pgtype.Int8    `json:"reseller_id"`
+	Status             string         `json:"status"`
+	Note               string         `json:"note"`
+	DuplicatePaymentID pgtype.Int8    `json:"duplicate_payment_id"`
+	PaymentID          pgtype.Int8    `json:"payment_id"`
+	CreatedAt          time.Time      `json:"created_at"`
+}
+
+type PaymentMethod struct {
+	ID                int64       `json:"id"`
+	Code              string      `json:"code"`
+	Name              string      `json:"name"`
+	ReferenceRequired bool        `json:"reference_required"`
+	ReferencePattern  pgtype.Text `json:"reference_pattern"`
+	Active            bool        `json:"active"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	CreatedAt         time.Time   `json:"created_at"`
 }
 
 type Product struct {
@@ -74,13 +245,59 @@ type Product struct {
 }
 
 type ProductBatch struct {
-	ID            int64          `json:"id"`
-	ProductID     int64          `json:"product_id"`
-	BatchNumber   string         `json:"batch_number"`
-	Quantity      int64          `json:"quantity"`
-	PurchasePrice pgtype.Numeric `json:"purchase_price"`
-	DateReceived  time.Time      `json:"date_received"`
-	CreatedAt     time.Time      `json:"created_at"`
+	ID                  int64          `json:"id"`
+	ProductID           int64          `json:"product_id"`
+	BatchNumber         string         `json:"batch_number"`
+	Quantity            int64          `json:"quantity"`
+	PurchasePrice       pgtype.Numeric `json:"purchase_price"`
+	DateReceived        time.Time      `json:"date_received"`
+	CreatedAt           time.Time      `json:"created_at"`
+	ExpiryDate          pgtype.Date    `json:"expiry_date"`
+	LocationID          int64          `json:"location_id"`
+	PurchaseOrderLineID pgtype.Int8    `json:"purchase_order_line_id"`
+	LandedUnitCost      pgtype.Numeric `json:"landed_unit_cost"`
+	UnitCost            pgtype.Numeric `json:"unit_cost"`
+}
+
+type PurchaseOrder struct {
+	ID           int64              `json:"id"`
+	SupplierID   int64              `json:"supplier_id"`
+	Status       string             `json:"status"`
+	ExpectedDate pgtype.Date        `json:"expected_date"`
+	Note         pgtype.Text        `json:"note"`
+	CreatedBy    int64              `json:"created_by"`
+	SentAt       pgtype.Timestamptz `json:"sent_at"`
+	ReceivedAt   pgtype.Timestamptz `json:"received_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+	ClosedAt     pgtype.Timestamptz `json:"closed_at"`
+	CloseReason  pgtype.Text        `json:"close_reason"`
+}
+
+type PurchaseOrderLine struct {
+	ID               int64          `json:"id"`
+	PurchaseOrderID  int64          `json:"purchase_order_id"`
+	ProductID        int64          `json:"product_id"`
+	QuantityOrdered  int64          `json:"quantity_ordered"`
+	QuantityReceived int64          `json:"quantity_received"`
+	ExpectedPrice    pgtype.Numeric `json:"expected_price"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+type ReportRun struct {
+	ID          int64              `json:"id"`
+	ReportType  string             `json:"report_type"`
+	Status      string             `json:"status"`
+	Parameters  []byte             `json:"parameters"`
+	TriggeredBy string             `json:"triggered_by"`
+	Attempts    int32              `json:"attempts"`
+	FileName    pgtype.Text        `json:"file_name"`
+	FilePath    pgtype.Text        `json:"file_path"`
+	ContentType pgtype.Text        `json:"content_type"`
+	Error       pgtype.Text        `json:"error"`
+	StartedAt   pgtype.Timestamptz `json:"started_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	CreatedAt   time.Time          `json:"created_at"`
 }
 
 type ResellerAccount struct {
@@ -91,6 +308,7 @@ type ResellerAccount struct {
 	TotalPaid          pgtype.Numeric `json:"total_paid"`
 	TotalCogs          pgtype.Numeric `json:"total_cogs"`
 	Balance            pgtype.Numeric `json:"balance"`
+	CreditLimit        pgtype.Numeric `json:"credit_limit"`
 }
 
 type ResellerBatchInventory struct {
@@ -102,6 +320,7 @@ type ResellerBatchInventory struct {
 	UnitCost          pgtype.Numeric `json:"unit_cost"`
 	RemainingQuantity int64          `json:"remaining_quantity"`
 	CreatedAt         time.Time      `json:"created_at"`
+	BatchUnitCost     pgtype.Numeric `json:"batch_unit_cost"`
 }
 
 type ResellerSale struct {
@@ -122,6 +341,22 @@ type ResellerStock struct {
 	LowStockThreshold int32 `json:"low_stock_threshold"`
 }
 
+type StockAdjustment struct {
+	ID              int64          `json:"id"`
+	OwnerType       string         `json:"owner_type"`
+	ResellerID      pgtype.Int8    `json:"reseller_id"`
+	ProductID       int64          `json:"product_id"`
+	BatchID         pgtype.Int8    `json:"batch_id"`
+	Quantity        int32          `json:"quantity"`
+	TotalValue      pgtype.Numeric `json:"total_value"`
+	Reason          string         `json:"reason"`
+	Note            pgtype.Text    `json:"note"`
+	StockMovementID int64          `json:"stock_movement_id"`
+	CreatedBy       int64          `json:"created_by"`
+	CreatedAt       time.Time      `json:"created_at"`
+	LocationID      pgtype.Int8    `json:"location_id"`
+}
+
 type StockDistribution struct {
 	ID              int64          `json:"id"`
 	ResellerID      int64          `json:"reseller_id"`
@@ -131,6 +366,8 @@ type StockDistribution struct {
 	TotalPrice      pgtype.Numeric `json:"total_price"`
 	DateDistributed time.Time      `json:"date_distributed"`
 	CreatedAt       time.Time      `json:"created_at"`
+	InvoiceID       pgtype.Int8    `json:"invoice_id"`
+	LocationID      int64          `json:"location_id"`
 }
 
 type StockMovement struct {
@@ -144,6 +381,8 @@ type StockMovement struct {
 	Source       string         `json:"source"`
 	Note         string         `json:"note"`
 	CreatedAt    time.Time      `json:"created_at"`
+	LocationID   pgtype.Int8    `json:"location_id"`
+	MovementDate time.Time      `json:"movement_date"`
 }
 
 type StockMovementBatch struct {
@@ -155,6 +394,97 @@ type StockMovementBatch struct {
 	Quantity        int64          `json:"quantity"`
 	UnitCost        pgtype.Numeric `json:"unit_cost"`
 	CreatedAt       time.Time      `json:"created_at"`
+	BatchUnitCost   pgtype.Numeric `json:"batch_unit_cost"`
+}
+
+type StockReturn struct {
+	ID                 int64          `json:"id"`
+	ResellerID         int64          `json:"reseller_id"`
+	ProductID          int64          `json:"product_id"`
+	BatchID            pgtype.Int8    `json:"batch_id"`
+	Quantity           int32          `json:"quantity"`
+	TotalValue         pgtype.Numeric `json:"total_value"`
+	Reason             string         `json:"reason"`
+	ResellerMovementID int64          `json:"reseller_movement_id"`
+	CompanyMovementID  int64          `json:"company_movement_id"`
+	DateReturned       time.Time      `json:"date_returned"`
+	CreatedBy          int64          `json:"created_by"`
+	CreatedAt          time.Time      `json:"created_at"`
+	LocationID         int64          `json:"location_id"`
+}
+
+type StockReturnAllocation struct {
+	ID            int64          `json:"id"`
+	StockReturnID int64          `json:"stock_return_id"`
+	InvoiceID     int64          `json:"invoice_id"`
+	Amount        pgtype.Numeric `json:"amount"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type StockTransfer struct {
+	ID              int64              `json:"id"`
+	FromResellerID  int64              `json:"from_reseller_id"`
+	ToResellerID    int64              `json:"to_reseller_id"`
+	ProductID       int64              `json:"product_id"`
+	BatchID         pgtype.Int8        `json:"batch_id"`
+	Quantity        int32              `json:"quantity"`
+	TotalValue      pgtype.Numeric     `json:"total_value"`
+	Status          string             `json:"status"`
+	Note            pgtype.Text        `json:"note"`
+	RejectionReason pgtype.Text        `json:"rejection_reason"`
+	FromMovementID  pgtype.Int8        `json:"from_movement_id"`
+	ToMovementID    pgtype.Int8        `json:"to_movement_id"`
+	InvoiceID       pgtype.Int8        `json:"invoice_id"`
+	RequestedBy     int64              `json:"requested_by"`
+	DecidedBy       pgtype.Int8        `json:"decided_by"`
+	DecidedAt       pgtype.Timestamptz `json:"decided_at"`
+	DateTransferred pgtype.Timestamptz `json:"date_transferred"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+type StockTransferAllocation struct {
+	ID              int64          `json:"id"`
+	StockTransferID int64          `json:"stock_transfer_id"`
+	InvoiceID       int64          `json:"invoice_id"`
+	Amount          pgtype.Numeric `json:"amount"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+type Stocktake struct {
+	ID                 int64              `json:"id"`
+	OwnerType          string             `json:"owner_type"`
+	ResellerID         pgtype.Int8        `json:"reseller_id"`
+	Status             string             `json:"status"`
+	Note               pgtype.Text        `json:"note"`
+	CreatedBy          int64              `json:"created_by"`
+	ClosedBy           pgtype.Int8        `json:"closed_by"`
+	ClosedAt           pgtype.Timestamptz `json:"closed_at"`
+	CreatedAt          time.Time          `json:"created_at"`
+	LocationID         pgtype.Int8        `json:"location_id"`
+	SnapshotMovementID int64              `json:"snapshot_movement_id"`
+}
+
+type StocktakeLine struct {
+	ID               int64              `json:"id"`
+	StocktakeID      int64              `json:"stocktake_id"`
+	ProductID        int64              `json:"product_id"`
+	ExpectedQuantity int64              `json:"expected_quantity"`
+	CountedQuantity  pgtype.Int8        `json:"counted_quantity"`
+	UnitCost         pgtype.Numeric     `json:"unit_cost"`
+	CountedAt        pgtype.Timestamptz `json:"counted_at"`
+	AdjustmentID     pgtype.Int8        `json:"adjustment_id"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+type Supplier struct {
+	ID          int64       `json:"id"`
+	Name        string      `json:"name"`
+	ContactName pgtype.Text `json:"contact_name"`
+	PhoneNumber pgtype.Text `json:"phone_number"`
+	Email       pgtype.Text `json:"email"`
+	Address     pgtype.Text `json:"address"`
+	Active      bool        `json:"active"`
+	CreatedAt   time.Time   `json:"created_at"`
 }
 
 type User struct {
@@ -15,7 +15,7 @@ import (
 const createPayment = `-- name: CreatePayment :one
 INSERT INTO payments (reseller_id, amount, method, reference, recorded_by, date_paid)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, reseller_id, amount, method, reference, recorded_by, date_paid, created_at
+RETURNING id, reseller_id, amount, method, reference, recorded_by, date_paid, created_at, reversal_of, reversal_reason
 `
 
 type CreatePaymentParams struct {
@@ -46,14 +46,85 @@ func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (P
 		&i.RecordedBy,
 		&i.DatePaid,
 		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ReversalReason,
+	)
+	return i, err
+}
+
+const createPaymentReversal = `-- name: CreatePaymentReversal :one
+INSERT INTO payments (reseller_id, amount, method, reference, recorded_by, date_paid, reversal_of, reversal_reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, reseller_id, amount, method, reference, recorded_by, date_paid, created_at, reversal_of, reversal_reason
+`
+
+type CreatePaymentReversalParams struct {
+	ResellerID     int64          `json:"reseller_id"`
+	Amount         pgtype.Numeric `json:"amount"`
+	Method         string         `json:"method"`
+	Reference      pgtype.Text    `json:"reference"`
+	RecordedBy     string         `json:"recorded_by"`
+	DatePaid       time.Time      `json:"date_paid"`
+	ReversalOf     pgtype.Int8    `json:"reversal_of"`
+	ReversalReason pgtype.Text    `json:"reversal_reason"`
+}
+
+func (q *Queries) CreatePaymentReversal(ctx context.Context, arg CreatePaymentReversalParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, createPaymentReversal,
+		arg.ResellerID,
+		arg.Amount,
+		arg.Method,
+		arg.Reference,
+		arg.RecordedBy,
+		arg.DatePaid,
+		arg.ReversalOf,
+		arg.ReversalReason,
+	)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.ResellerID,
+		&i.Amount,
+		&i.Method,
+		&i.Reference,
+		&i.RecordedBy,
+		&i.DatePaid,
+		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ReversalReason,
+	)
+	return i, err
+}
+
+const getPaymentForUpdate = `-- name: GetPaymentForUpdate :one
+SELECT id, reseller_id, amount, method, reference, recorded_by, date_paid, created_at, reversal_of, reversal_reason FROM payments
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetPaymentForUpdate(ctx context.Context, id int64) (Payment, error) {
+	row := q.db.QueryRow(ctx, getPaymentForUpdate, id)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.ResellerID,
+		&i.Amount,
+		&i.Method,
+		&i.Reference,
+		&i.RecordedBy,
+		&i.DatePaid,
+		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ReversalReason,
 	)
 	return i, err
 }
 
 const listPayments = `-- name: ListPayments :many
-SELECT p.id, p.reseller_id, p.amount, p.method, p.reference, p.recorded_by, p.date_paid, p.created_at, u.name, u.phone_number 
+SELECT p.id, p.reseller_id, p.amount, p.method, p.reference, p.recorded_by, p.date_paid, p.created_at, p.reversal_of, p.reversal_reason, u.name, u.phone_number, r.id AS reversed_by
 FROM payments p
 JOIN users u ON u.id = p.reseller_id
+LEFT JOIN payments r ON r.reversal_of = p.id
 WHERE 
     (
         $1::bigint IS NULL
@@ -96,16 +167,19 @@ type ListPaymentsParams struct {
 }
 
 type ListPaymentsRow struct {
-	ID          int64          `json:"id"`
-	ResellerID  int64          `json:"reseller_id"`
-	Amount      pgtype.Numeric `json:"amount"`
-	Method      string         `json:"method"`
-	Reference   pgtype.Text    `json:"reference"`
-	RecordedBy  string         `json:"recorded_by"`
-	DatePaid    time.Time      `json:"date_paid"`
-	CreatedAt   time.Time      `json:"created_at"`
-	Name        string         `json:"name"`
-	PhoneNumber string         `json:"phone_number"`
+	ID             int64          `json:"id"`
+	ResellerID     int64          `json:"reseller_id"`
+	Amount         pgtype.Numeric `json:"amount"`
+	Method         string         `json:"method"`
+	Reference      pgtype.Text    `json:"reference"`
+	RecordedBy     string         `json:"recorded_by"`
+	DatePaid       time.Time      `json:"date_paid"`
+	CreatedAt      time.Time      `json:"created_at"`
+	ReversalOf     pgtype.Int8    `json:"reversal_of"`
+	ReversalReason pgtype.Text    `json:"reversal_reason"`
+	Name           string         `json:"name"`
+	PhoneNumber    string         `json:"phone_number"`
+	ReversedBy     pgtype.Int8    `json:"reversed_by"`
 }
 
 func (q *Queries) ListPayments(ctx context.Context, arg ListPaymentsParams) ([]ListPaymentsRow, error) {
@@ -135,8 +209,11 @@ func (q *Queries) ListPayments(ctx context.Context, arg ListPaymentsParams) ([]L
 			&i.RecordedBy,
 			&i.DatePaid,
 			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.ReversalReason,
 			&i.Name,
 			&i.PhoneNumber,
+			&i.ReversedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -148,6 +225,39 @@ func (q *Queries) ListPayments(ctx context.Context, arg ListPaymentsParams) ([]L
 	return items, nil
 }
 
+const listPaymentsByReferences = `-- name: ListPaymentsByReferences :many
+SELECT p.id, UPPER(p.reference)::text AS reference
+FROM payments p
+WHERE p.reversal_of IS NULL
+    AND UPPER(p.reference) = ANY($1::text[])
+    AND NOT EXISTS (SELECT 1 FROM payments r WHERE r.reversal_of = p.id)
+`
+
+type ListPaymentsByReferencesRow struct {
+	ID        int64  `json:"id"`
+	Reference string `json:"reference"`
+}
+
+func (q *Queries) ListPaymentsByReferences(ctx context.Context, references []string) ([]ListPaymentsByReferencesRow, error) {
+	rows, err := q.db.Query(ctx, listPaymentsByReferences, references)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPaymentsByReferencesRow{}
+	for rows.Next() {
+		var i ListPaymentsByReferencesRow
+		if err := rows.Scan(&i.ID, &i.Reference); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPaymentsCount = `-- name: ListPaymentsCount :one
 SELECT COUNT(*) AS total_payments
 FROM payments p
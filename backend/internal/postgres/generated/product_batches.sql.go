@@ -12,18 +12,53 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addProductBatchLandedCost = `-- name: AddProductBatchLandedCost :one
+UPDATE product_batches
+SET landed_unit_cost = landed_unit_cost + $1
+WHERE id = $2
+RETURNING id, product_id, batch_number, quantity, purchase_price, date_received, created_at, expiry_date, location_id, purchase_order_line_id, landed_unit_cost, unit_cost
+`
+
+type AddProductBatchLandedCostParams struct {
+	LandedUnitCost pgtype.Numeric `json:"landed_unit_cost"`
+	ID             int64          `json:"id"`
+}
+
+func (q *Queries) AddProductBatchLandedCost(ctx context.Context, arg AddProductBatchLandedCostParams) (ProductBatch, error) {
+	row := q.db.QueryRow(ctx, addProductBatchLandedCost, arg.LandedUnitCost, arg.ID)
+	var i ProductBatch
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.BatchNumber,
+		&i.Quantity,
+		&i.PurchasePrice,
+		&i.DateReceived,
+		&i.CreatedAt,
+		&i.ExpiryDate,
+		&i.LocationID,
+		&i.PurchaseOrderLineID,
+		&i.LandedUnitCost,
+		&i.UnitCost,
+	)
+	return i, err
+}
+
 const createProductBatchRecord = `-- name: CreateProductBatchRecord :one
-INSERT INTO product_batches (product_id, batch_number, quantity, purchase_price, date_received)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, product_id, batch_number, quantity, purchase_price, date_received, created_at
+INSERT INTO product_batches (product_id, batch_number, quantity, purchase_price, date_received, expiry_date, location_id, purchase_order_line_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, product_id, batch_number, quantity, purchase_price, date_received, created_at, expiry_date, location_id, purchase_order_line_id, landed_unit_cost, unit_cost
 `
 
 type CreateProductBatchRecordParams struct {
-	ProductID     int64          `json:"product_id"`
-	BatchNumber   string         `json:"batch_number"`
-	Quantity      int64          `json:"quantity"`
-	PurchasePrice pgtype.Numeric `json:"purchase_price"`
-	DateReceived  time.Time      `json:"date_received"`
+	ProductID           int64          `json:"product_id"`
+	BatchNumber         string         `json:"batch_number"`
+	Quantity            int64          `json:"quantity"`
+	PurchasePrice       pgtype.Numeric `json:"purchase_price"`
+	DateReceived        time.Time      `json:"date_received"`
+	ExpiryDate          pgtype.Date    `json:"expiry_date"`
+	LocationID          int64          `json:"location_id"`
+	PurchaseOrderLineID pgtype.Int8    `json:"purchase_order_line_id"`
 }
 
 func (q *Queries) CreateProductBatchRecord(ctx context.Context, arg CreateProductBatchRecordParams) (ProductBatch, error) {
@@ -33,6 +68,9 @@ func (q *Queries) CreateProductBatchRecord(ctx context.Context, arg CreateProduc
 		arg.Quantity,
 		arg.PurchasePrice,
 		arg.DateReceived,
+		arg.ExpiryDate,
+		arg.LocationID,
+		arg.PurchaseOrderLineID,
 	)
 	var i ProductBatch
 	err := row.Scan(
@@ -43,12 +81,17 @@ func (q *Queries) CreateProductBatchRecord(ctx context.Context, arg CreateProduc
 		&i.PurchasePrice,
 		&i.DateReceived,
 		&i.CreatedAt,
+		&i.ExpiryDate,
+		&i.LocationID,
+		&i.PurchaseOrderLineID,
+		&i.LandedUnitCost,
+		&i.UnitCost,
 	)
 	return i, err
 }
 
 const listProductBatches = `-- name: ListProductBatches :many
-SELECT pb.id, pb.product_id, pb.batch_number, pb.quantity, pb.purchase_price, pb.date_received, pb.created_at, p.name AS product_name, p.price AS product_price, p.unit AS product_unit, p.low_stock_threshold AS product_low_stock_threshold
+SELECT pb.id, pb.product_id, pb.batch_number, pb.quantity, pb.purchase_price, pb.date_received, pb.created_at, pb.expiry_date, pb.location_id, pb.purchase_order_line_id, pb.landed_unit_cost, pb.unit_cost, p.name AS product_name, p.price AS product_price, p.unit AS product_unit, p.low_stock_threshold AS product_low_stock_threshold
 FROM product_batches pb
 JOIN products p ON p.id = pb.product_id
 WHERE 
@@ -63,14 +106,14 @@ WHERE
         OR LOWER(pb.batch_number) LIKE $2
     )
 ORDER BY pb.date_received DESC
-LIMIT $4 OFFSET $3
+LIMIT $3 OFFSET $4
 `
 
 type ListProductBatchesParams struct {
 	ProductID pgtype.Int8 `json:"product_id"`
 	Search    interface{} `json:"search"`
-	Offset    int32       `json:"offset"`
 	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
 }
 
 type ListProductBatchesRow struct {
@@ -81,6 +124,11 @@ type ListProductBatchesRow struct {
 	PurchasePrice            pgtype.Numeric `json:"purchase_price"`
 	DateReceived             time.Time      `json:"date_received"`
 	CreatedAt                time.Time      `json:"created_at"`
+	ExpiryDate               pgtype.Date    `json:"expiry_date"`
+	LocationID               int64          `json:"location_id"`
+	PurchaseOrderLineID      pgtype.Int8    `json:"purchase_order_line_id"`
+	LandedUnitCost           pgtype.Numeric `json:"landed_unit_cost"`
+	UnitCost                 pgtype.Numeric `json:"unit_cost"`
 	ProductName              string         `json:"product_name"`
 	ProductPrice             pgtype.Numeric `json:"product_price"`
 	ProductUnit              string         `json:"product_unit"`
@@ -91,8 +139,8 @@ func (q *Queries) ListProductBatches(ctx context.Context, arg ListProductBatches
 	rows, err := q.db.Query(ctx, listProductBatches,
 		arg.ProductID,
 		arg.Search,
-		arg.Offset,
 		arg.Limit,
+		arg.Offset,
 	)
 	if err != nil {
 		return nil, err
@@ -109,6 +157,11 @@ func (q *Queries) ListProductBatches(ctx context.Context, arg ListProductBatches
 			&i.PurchasePrice,
 			&i.DateReceived,
 			&i.CreatedAt,
+			&i.ExpiryDate,
+			&i.LocationID,
+			&i.PurchaseOrderLineID,
+			&i.LandedUnitCost,
+			&i.UnitCost,
 			&i.ProductName,
 			&i.ProductPrice,
 			&i.ProductUnit,
@@ -152,3 +205,43 @@ func (q *Queries) ListProductBatchesCount(ctx context.Context, arg ListProductBa
 	err := row.Scan(&total_batches)
 	return total_batches, err
 }
+
+const listProductBatchesForUpdate = `-- name: ListProductBatchesForUpdate :many
+SELECT id, product_id, batch_number, quantity, purchase_price, date_received, created_at, expiry_date, location_id, purchase_order_line_id, landed_unit_cost, unit_cost FROM product_batches
+WHERE id = ANY($1::bigint[])
+ORDER BY id ASC
+FOR UPDATE
+`
+
+func (q *Queries) ListProductBatchesForUpdate(ctx context.Context, ids []int64) ([]ProductBatch, error) {
+	rows, err := q.db.Query(ctx, listProductBatchesForUpdate, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ProductBatch{}
+	for rows.Next() {
+		var i ProductBatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.BatchNumber,
+			&i.Quantity,
+			&i.PurchasePrice,
+			&i.DateReceived,
+			&i.CreatedAt,
+			&i.ExpiryDate,
+			&i.LocationID,
+			&i.PurchaseOrderLineID,
+			&i.LandedUnitCost,
+			&i.UnitCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
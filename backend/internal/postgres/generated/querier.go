@@ -13,40 +13,106 @@ import (
 type Querier interface {
 	AddBatchInventoryQuantity(ctx context.Context, arg AddBatchInventoryQuantityParams) (BatchInventory, error)
 	AddCompanyStock(ctx context.Context, arg AddCompanyStockParams) (CompanyStock, error)
+	AddInvoicePayment(ctx context.Context, arg AddInvoicePaymentParams) (Invoice, error)
+	AddProductBatchLandedCost(ctx context.Context, arg AddProductBatchLandedCostParams) (ProductBatch, error)
+	AddPurchaseOrderLineReceived(ctx context.Context, arg AddPurchaseOrderLineReceivedParams) (PurchaseOrderLine, error)
+	AddResellerBatchInventoryQuantity(ctx context.Context, arg AddResellerBatchInventoryQuantityParams) (ResellerBatchInventory, error)
 	AddResellerStockQuantity(ctx context.Context, arg AddResellerStockQuantityParams) (ResellerStock, error)
+	AllocateMpesaC2bTransaction(ctx context.Context, arg AllocateMpesaC2bTransactionParams) (MpesaC2bTransaction, error)
 	CancelGoodsRequest(ctx context.Context, id int64) (GoodsRequest, error)
 	CheckResellerStockExists(ctx context.Context, arg CheckResellerStockExistsParams) (bool, error)
+	ClaimReportRun(ctx context.Context, id int64) (ReportRun, error)
+	ClearDefaultLocation(ctx context.Context, id int64) error
+	CloseStocktake(ctx context.Context, arg CloseStocktakeParams) error
+	CommitPaymentImport(ctx context.Context, id int64) error
+	CompleteMpesaStkRequest(ctx context.Context, arg CompleteMpesaStkRequestParams) (MpesaStkRequest, error)
+	CompleteReportRun(ctx context.Context, arg CompleteReportRunParams) error
+	CompleteStockTransfer(ctx context.Context, arg CompleteStockTransferParams) (StockTransfer, error)
+	CountOutstandingPurchaseOrderLines(ctx context.Context, purchaseOrderID int64) (int64, error)
 	CreateAlert(ctx context.Context, arg CreateAlertParams) error
 	CreateBatchInventoryRecord(ctx context.Context, arg CreateBatchInventoryRecordParams) (BatchInventory, error)
 	CreateCompanyStock(ctx context.Context, productID int64) (CompanyStock, error)
+	CreateCompanyStocktakeLines(ctx context.Context, stocktakeID int64) error
+	CreateCreditHold(ctx context.Context, arg CreateCreditHoldParams) (CreditHold, error)
 	CreateGoodsRequest(ctx context.Context, arg CreateGoodsRequestParams) (GoodsRequest, error)
+	CreateInvoice(ctx context.Context, arg CreateInvoiceParams) (Invoice, error)
+	CreateLandedCost(ctx context.Context, arg CreateLandedCostParams) (LandedCost, error)
+	CreateLandedCostAllocation(ctx context.Context, arg CreateLandedCostAllocationParams) (LandedCostAllocation, error)
+	CreateLocation(ctx context.Context, arg CreateLocationParams) (Location, error)
+	CreateLocationTransfer(ctx context.Context, arg CreateLocationTransferParams) (LocationTransfer, error)
+	CreateMissingResellerBatchInventory(ctx context.Context) (int64, error)
+	CreateMpesaC2bTransaction(ctx context.Context, arg CreateMpesaC2bTransactionParams) (MpesaC2bTransaction, error)
+	CreateMpesaStkRequest(ctx context.Context, arg CreateMpesaStkRequestParams) (MpesaStkRequest, error)
 	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
+	CreatePaymentAllocation(ctx context.Context, arg CreatePaymentAllocationParams) (PaymentAllocation, error)
+	CreatePaymentImport(ctx context.Context, arg CreatePaymentImportParams) (PaymentImport, error)
+	CreatePaymentImportRow(ctx context.Context, arg CreatePaymentImportRowParams) (PaymentImportRow, error)
+	CreatePaymentMethod(ctx context.Context, arg CreatePaymentMethodParams) (PaymentMethod, error)
+	CreatePaymentReversal(ctx context.Context, arg CreatePaymentReversalParams) (Payment, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
 	CreateProductBatchRecord(ctx context.Context, arg CreateProductBatchRecordParams) (ProductBatch, error)
+	CreatePurchaseOrder(ctx context.Context, arg CreatePurchaseOrderParams) (PurchaseOrder, error)
+	CreatePurchaseOrderLine(ctx context.Context, arg CreatePurchaseOrderLineParams) (PurchaseOrderLine, error)
+	CreateReportRun(ctx context.Context, arg CreateReportRunParams) (ReportRun, error)
 	CreateResellerAccount(ctx context.Context, resellerID int64) (ResellerAccount, error)
 	CreateResellerBatchInventoryRecord(ctx context.Context, arg CreateResellerBatchInventoryRecordParams) (ResellerBatchInventory, error)
 	CreateResellerSalesRecord(ctx context.Context, arg CreateResellerSalesRecordParams) (ResellerSale, error)
 	CreateResellerStock(ctx context.Context, arg CreateResellerStockParams) (ResellerStock, error)
+	CreateResellerStocktakeLines(ctx context.Context, arg CreateResellerStocktakeLinesParams) error
+	CreateStockAdjustment(ctx context.Context, arg CreateStockAdjustmentParams) (StockAdjustment, error)
 	CreateStockDistributionRecord(ctx context.Context, arg CreateStockDistributionRecordParams) (StockDistribution, error)
 	CreateStockMovementBatchRecord(ctx context.Context, arg CreateStockMovementBatchRecordParams) (StockMovementBatch, error)
 	CreateStockMovementRecord(ctx context.Context, arg CreateStockMovementRecordParams) (StockMovement, error)
+	CreateStockReturn(ctx context.Context, arg CreateStockReturnParams) (StockReturn, error)
+	CreateStockReturnAllocation(ctx context.Context, arg CreateStockReturnAllocationParams) (StockReturnAllocation, error)
+	CreateStockTransfer(ctx context.Context, arg CreateStockTransferParams) (StockTransfer, error)
+	CreateStockTransferAllocation(ctx context.Context, arg CreateStockTransferAllocationParams) (StockTransferAllocation, error)
+	CreateStocktake(ctx context.Context, arg CreateStocktakeParams) (Stocktake, error)
+	CreateSupplier(ctx context.Context, arg CreateSupplierParams) (Supplier, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeletePaymentAllocations(ctx context.Context, paymentID int64) ([]PaymentAllocation, error)
 	DeleteProduct(ctx context.Context, id int64) error
+	DeletePurchaseOrderLines(ctx context.Context, purchaseOrderID int64) error
 	DeleteUser(ctx context.Context, id int64) error
-	GetAdminBatchesPageStats(ctx context.Context) ([]byte, error)
-	GetAdminDashboardStats(ctx context.Context) ([]byte, error)
-	GetAdminDistributionPageStats(ctx context.Context) ([]byte, error)
+	FailInterruptedReportRuns(ctx context.Context) (int64, error)
+	FailReportRun(ctx context.Context, arg FailReportRunParams) error
+	GetActiveResellerID(ctx context.Context, id int64) (int64, error)
+	GetAdminBatchesPageStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error)
+	GetAdminDashboardStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error)
+	GetAdminDistributionPageStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error)
 	GetAdminGoodsRequestsPageStats(ctx context.Context) ([]byte, error)
 	GetAdminPaymentsPageStats(ctx context.Context) ([]byte, error)
-	GetAdminProductsPageStats(ctx context.Context) ([]byte, error)
+	GetAdminProductsPageStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error)
 	GetAdminResellersPageStats(ctx context.Context) ([]byte, error)
 	GetAdminStats(ctx context.Context, id int32) (AdminStat, error)
-	GetAdminStockMovementsPageStats(ctx context.Context) ([]byte, error)
-	GetAdminWeeklyStockChart(ctx context.Context) ([]GetAdminWeeklyStockChartRow, error)
-	GetBatchInventoryProductSum(ctx context.Context, productID int64) (int64, error)
+	GetAdminStockMovementsPageStats(ctx context.Context, locationID pgtype.Int8) ([]byte, error)
+	GetAdminWeeklyStockChart(ctx context.Context, locationID pgtype.Int8) ([]GetAdminWeeklyStockChartRow, error)
+	GetBatchInventoryProductSum(ctx context.Context, arg GetBatchInventoryProductSumParams) (GetBatchInventoryProductSumRow, error)
+	GetCreditHoldForUpdate(ctx context.Context, id int64) (CreditHold, error)
+	GetDefaultLocation(ctx context.Context) (Location, error)
+	GetInvoice(ctx context.Context, id int64) (GetInvoiceRow, error)
+	GetInvoiceForUpdate(ctx context.Context, id int64) (Invoice, error)
+	GetLandedCost(ctx context.Context, id int64) (LandedCost, error)
+	GetLatestCompanyBatchID(ctx context.Context, arg GetLatestCompanyBatchIDParams) (int64, error)
+	GetLatestResellerBatchID(ctx context.Context, arg GetLatestResellerBatchIDParams) (int64, error)
+	GetLocation(ctx context.Context, id int64) (Location, error)
+	GetLocationStockQuantity(ctx context.Context, locationID int64) (int64, error)
+	GetMpesaC2bTransactionByTransID(ctx context.Context, transID string) (MpesaC2bTransaction, error)
+	GetMpesaC2bTransactionForUpdate(ctx context.Context, id int64) (MpesaC2bTransaction, error)
+	GetMpesaStkRequest(ctx context.Context, id int64) (MpesaStkRequest, error)
+	GetMpesaStkRequestByCheckoutIDForUpdate(ctx context.Context, checkoutRequestID string) (MpesaStkRequest, error)
+	GetPaymentForUpdate(ctx context.Context, id int64) (Payment, error)
+	GetPaymentImport(ctx context.Context, id int64) (PaymentImport, error)
+	GetPaymentImportForUpdate(ctx context.Context, id int64) (PaymentImport, error)
+	GetPaymentMethodByCode(ctx context.Context, code string) (PaymentMethod, error)
 	GetProductByID(ctx context.Context, id int64) (Product, error)
+	GetPurchaseOrder(ctx context.Context, id int64) (GetPurchaseOrderRow, error)
+	GetPurchaseOrderForUpdate(ctx context.Context, id int64) (PurchaseOrder, error)
+	GetPurchaseOrderLineForUpdate(ctx context.Context, arg GetPurchaseOrderLineForUpdateParams) (PurchaseOrderLine, error)
+	GetReportRun(ctx context.Context, id int64) (ReportRun, error)
 	GetResellerAccount(ctx context.Context, resellerID int64) (ResellerAccount, error)
-	GetResellerBatchInventoryProductSum(ctx context.Context, arg GetResellerBatchInventoryProductSumParams) (int64, error)
+	GetResellerAccountForUpdate(ctx context.Context, resellerID int64) (ResellerAccount, error)
+	GetResellerBatchInventoryProductSum(ctx context.Context, arg GetResellerBatchInventoryProductSumParams) (GetResellerBatchInventoryProductSumRow, error)
 	GetResellerDashboardData(ctx context.Context, resellerID int64) ([]byte, error)
 	GetResellerGoodsRequestsPageStats(ctx context.Context, resellerID int64) ([]byte, error)
 	// -- name: GetDashboardData :one
@@ -108,58 +174,152 @@ type Querier interface {
 	// LEFT JOIN sales_data sd ON ds.day = sd.day
 	// ORDER BY ds.day;
 	GetResellerNameByID(ctx context.Context, resellerID int64) (string, error)
+	GetResellerOpeningBalance(ctx context.Context, arg GetResellerOpeningBalanceParams) (pgtype.Numeric, error)
 	GetResellerPaymentsPageStats(ctx context.Context, resellerID int64) ([]byte, error)
 	GetResellerSalesPageStats(ctx context.Context, resellerID int64) ([]byte, error)
 	GetResellerStockPageStats(ctx context.Context, resellerID int64) ([]byte, error)
 	GetResellerWithAccountByID(ctx context.Context, resellerID int64) (GetResellerWithAccountByIDRow, error)
+	GetStockTransferForUpdate(ctx context.Context, id int64) (StockTransfer, error)
+	GetStocktake(ctx context.Context, id int64) (GetStocktakeRow, error)
+	GetStocktakeForUpdate(ctx context.Context, id int64) (Stocktake, error)
+	GetSupplier(ctx context.Context, id int64) (Supplier, error)
 	GetTotalActiveResellers(ctx context.Context) (int64, error)
 	GetTotalLowStockProducts(ctx context.Context) (int64, error)
 	GetTotalOutstandingPayments(ctx context.Context) (pgtype.Numeric, error)
 	GetTotalPendingGoodsRequests(ctx context.Context) (int64, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id int64) (User, error)
+	ImportPaymentImportRow(ctx context.Context, arg ImportPaymentImportRowParams) error
+	ListAnalyticsSeries(ctx context.Context, arg ListAnalyticsSeriesParams) ([]ListAnalyticsSeriesRow, error)
 	ListBatchInventory(ctx context.Context, arg ListBatchInventoryParams) ([]ListBatchInventoryRow, error)
 	ListBatchInventoryCount(ctx context.Context, arg ListBatchInventoryCountParams) (int64, error)
-	ListBatchInventoryForUpdate(ctx context.Context, productID int64) ([]ListBatchInventoryForUpdateRow, error)
+	ListBatchInventoryForAdjustment(ctx context.Context, arg ListBatchInventoryForAdjustmentParams) ([]ListBatchInventoryForAdjustmentRow, error)
+	ListBatchInventoryForUpdate(ctx context.Context, arg ListBatchInventoryForUpdateParams) ([]ListBatchInventoryForUpdateRow, error)
+	ListBatchResellerHoldings(ctx context.Context, batchNumber string) ([]ListBatchResellerHoldingsRow, error)
+	ListBatchTraceMovements(ctx context.Context, batchNumber string) ([]ListBatchTraceMovementsRow, error)
+	ListBatchesByBatchNumber(ctx context.Context, batchNumber string) ([]ListBatchesByBatchNumberRow, error)
+	ListCompanyInventoryValuation(ctx context.Context, arg ListCompanyInventoryValuationParams) ([]ListCompanyInventoryValuationRow, error)
 	ListCompanyStock(ctx context.Context, arg ListCompanyStockParams) ([]ListCompanyStockRow, error)
 	ListCompanyStockCount(ctx context.Context, arg ListCompanyStockCountParams) (int64, error)
+	ListCreditHolds(ctx context.Context, arg ListCreditHoldsParams) ([]ListCreditHoldsRow, error)
+	ListCreditHoldsCount(ctx context.Context, arg ListCreditHoldsCountParams) (int64, error)
 	ListGoodsRequestsByAdmin(ctx context.Context, arg ListGoodsRequestsByAdminParams) ([]ListGoodsRequestsByAdminRow, error)
 	ListGoodsRequestsByAdminCount(ctx context.Context, arg ListGoodsRequestsByAdminCountParams) (int64, error)
 	ListGoodsRequestsByReseller(ctx context.Context, arg ListGoodsRequestsByResellerParams) ([]GoodsRequest, error)
 	ListGoodsRequestsByResellerCount(ctx context.Context, arg ListGoodsRequestsByResellerCountParams) (int64, error)
+	ListInvoiceAllocations(ctx context.Context, invoiceID int64) ([]ListInvoiceAllocationsRow, error)
+	ListInvoiceDistributions(ctx context.Context, invoiceID pgtype.Int8) ([]ListInvoiceDistributionsRow, error)
+	ListInvoiceStockReturnAllocations(ctx context.Context, invoiceID int64) ([]ListInvoiceStockReturnAllocationsRow, error)
+	ListInvoiceStockTransferAllocations(ctx context.Context, invoiceID int64) ([]ListInvoiceStockTransferAllocationsRow, error)
+	ListInvoiceStockTransfers(ctx context.Context, invoiceID pgtype.Int8) ([]ListInvoiceStockTransfersRow, error)
+	ListInvoices(ctx context.Context, arg ListInvoicesParams) ([]ListInvoicesRow, error)
+	ListInvoicesCount(ctx context.Context, arg ListInvoicesCountParams) (int64, error)
+	ListLandedCostAllocations(ctx context.Context, landedCostID int64) ([]ListLandedCostAllocationsRow, error)
+	ListLandedCosts(ctx context.Context, arg ListLandedCostsParams) ([]ListLandedCostsRow, error)
+	ListLandedCostsCount(ctx context.Context, arg ListLandedCostsCountParams) (int64, error)
+	ListLocationTransfers(ctx context.Context, arg ListLocationTransfersParams) ([]ListLocationTransfersRow, error)
+	ListLocationTransfersCount(ctx context.Context, arg ListLocationTransfersCountParams) (int64, error)
+	ListLocations(ctx context.Context, arg ListLocationsParams) ([]ListLocationsRow, error)
+	ListLocationsCount(ctx context.Context, arg ListLocationsCountParams) (int64, error)
+	ListMpesaC2bTransactions(ctx context.Context, arg ListMpesaC2bTransactionsParams) ([]MpesaC2bTransaction, error)
+	ListMpesaC2bTransactionsCount(ctx context.Context, arg ListMpesaC2bTransactionsCountParams) (int64, error)
+	ListNearExpiryStock(ctx context.Context, arg ListNearExpiryStockParams) ([]ListNearExpiryStockRow, error)
+	ListNearExpiryStockCount(ctx context.Context, arg ListNearExpiryStockCountParams) (int64, error)
+	ListOpenInvoicesForUpdate(ctx context.Context, resellerID int64) ([]Invoice, error)
+	ListPaymentImportRows(ctx context.Context, importID int64) ([]ListPaymentImportRowsRow, error)
+	ListPaymentImports(ctx context.Context, arg ListPaymentImportsParams) ([]PaymentImport, error)
+	ListPaymentImportsCount(ctx context.Context, status pgtype.Text) (int64, error)
+	ListPaymentMethods(ctx context.Context, active pgtype.Bool) ([]PaymentMethod, error)
 	ListPayments(ctx context.Context, arg ListPaymentsParams) ([]ListPaymentsRow, error)
+	ListPaymentsByReferences(ctx context.Context, references []string) ([]ListPaymentsByReferencesRow, error)
 	ListPaymentsCount(ctx context.Context, arg ListPaymentsCountParams) (int64, error)
+	ListPaymentsSummary(ctx context.Context, arg ListPaymentsSummaryParams) ([]ListPaymentsSummaryRow, error)
 	ListProductBatches(ctx context.Context, arg ListProductBatchesParams) ([]ListProductBatchesRow, error)
 	ListProductBatchesCount(ctx context.Context, arg ListProductBatchesCountParams) (int64, error)
+	ListProductBatchesForUpdate(ctx context.Context, ids []int64) ([]ProductBatch, error)
 	ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error)
 	ListProductsCount(ctx context.Context, search interface{}) (int64, error)
+	ListProfitAndLossAdjustments(ctx context.Context, arg ListProfitAndLossAdjustmentsParams) ([]ListProfitAndLossAdjustmentsRow, error)
+	ListProfitAndLossLines(ctx context.Context, arg ListProfitAndLossLinesParams) ([]ListProfitAndLossLinesRow, error)
+	ListPurchaseOrderBatches(ctx context.Context, purchaseOrderID int64) ([]ListPurchaseOrderBatchesRow, error)
+	ListPurchaseOrderFillRates(ctx context.Context, arg ListPurchaseOrderFillRatesParams) ([]ListPurchaseOrderFillRatesRow, error)
+	ListPurchaseOrderLines(ctx context.Context, purchaseOrderID int64) ([]ListPurchaseOrderLinesRow, error)
+	ListPurchaseOrders(ctx context.Context, arg ListPurchaseOrdersParams) ([]ListPurchaseOrdersRow, error)
+	ListPurchaseOrdersCount(ctx context.Context, arg ListPurchaseOrdersCountParams) (int64, error)
+	ListPurchasePriceVariances(ctx context.Context, arg ListPurchasePriceVariancesParams) ([]ListPurchasePriceVariancesRow, error)
+	ListReceivablesAging(ctx context.Context, resellerID pgtype.Int8) ([]ListReceivablesAgingRow, error)
+	ListReportRuns(ctx context.Context, arg ListReportRunsParams) ([]ReportRun, error)
+	ListReportRunsCount(ctx context.Context, arg ListReportRunsCountParams) (int64, error)
+	ListResellerBalances(ctx context.Context) ([]ListResellerBalancesRow, error)
+	ListResellerBatchInventoryForAdjustment(ctx context.Context, arg ListResellerBatchInventoryForAdjustmentParams) ([]ResellerBatchInventory, error)
+	ListResellerBatchInventoryForReturn(ctx context.Context, arg ListResellerBatchInventoryForReturnParams) ([]ResellerBatchInventory, error)
 	ListResellerBatchInventoryForUpdate(ctx context.Context, arg ListResellerBatchInventoryForUpdateParams) ([]ListResellerBatchInventoryForUpdateRow, error)
+	ListResellerIDsByPhoneSuffix(ctx context.Context, phoneSuffix string) ([]int64, error)
+	ListResellerInventoryValuation(ctx context.Context, arg ListResellerInventoryValuationParams) ([]ListResellerInventoryValuationRow, error)
 	ListResellerSales(ctx context.Context, arg ListResellerSalesParams) ([]ListResellerSalesRow, error)
 	ListResellerSalesCount(ctx context.Context, arg ListResellerSalesCountParams) (int64, error)
+	ListResellerStatementEntries(ctx context.Context, arg ListResellerStatementEntriesParams) ([]ListResellerStatementEntriesRow, error)
 	ListResellerStock(ctx context.Context, arg ListResellerStockParams) ([]ListResellerStockRow, error)
 	ListResellerStockCount(ctx context.Context, arg ListResellerStockCountParams) (int64, error)
 	ListResellersWithAccount(ctx context.Context, arg ListResellersWithAccountParams) ([]ListResellersWithAccountRow, error)
 	ListResellersWithAccountCount(ctx context.Context, search interface{}) (int64, error)
+	ListRetryableReportRuns(ctx context.Context, maxAttempts int32) ([]ReportRun, error)
+	ListStockAdjustments(ctx context.Context, arg ListStockAdjustmentsParams) ([]ListStockAdjustmentsRow, error)
+	ListStockAdjustmentsCount(ctx context.Context, arg ListStockAdjustmentsCountParams) (int64, error)
 	ListStockDistributions(ctx context.Context, arg ListStockDistributionsParams) ([]ListStockDistributionsRow, error)
 	ListStockDistributionsCount(ctx context.Context, arg ListStockDistributionsCountParams) (int64, error)
+	ListStockDistributionsForInvoice(ctx context.Context, ids []int64) ([]StockDistribution, error)
+	ListStockDrifts(ctx context.Context) ([]ListStockDriftsRow, error)
 	ListStockMovementBatchesByBatchID(ctx context.Context, batchID int64) ([]StockMovementBatch, error)
 	ListStockMovementBatchesByStockMovementID(ctx context.Context, stockMovementID int64) ([]StockMovementBatch, error)
 	ListStockMovements(ctx context.Context, arg ListStockMovementsParams) ([]ListStockMovementsRow, error)
 	ListStockMovementsCount(ctx context.Context, arg ListStockMovementsCountParams) (int64, error)
+	ListStockReturns(ctx context.Context, arg ListStockReturnsParams) ([]ListStockReturnsRow, error)
+	ListStockReturnsCount(ctx context.Context, arg ListStockReturnsCountParams) (int64, error)
+	ListStockTransfers(ctx context.Context, arg ListStockTransfersParams) ([]ListStockTransfersRow, error)
+	ListStockTransfersCount(ctx context.Context, arg ListStockTransfersCountParams) (int64, error)
+	ListStocktakeLines(ctx context.Context, stocktakeID int64) ([]ListStocktakeLinesRow, error)
+	ListStocktakes(ctx context.Context, arg ListStocktakesParams) ([]ListStocktakesRow, error)
+	ListStocktakesCount(ctx context.Context, arg ListStocktakesCountParams) (int64, error)
+	ListSuppliers(ctx context.Context, arg ListSuppliersParams) ([]Supplier, error)
+	ListSuppliersCount(ctx context.Context, arg ListSuppliersCountParams) (int64, error)
+	ListUnallocatedPayments(ctx context.Context, resellerID int64) ([]ListUnallocatedPaymentsRow, error)
+	ListUnallocatedStockReturns(ctx context.Context, resellerID int64) ([]ListUnallocatedStockReturnsRow, error)
+	ListUnallocatedStockTransfers(ctx context.Context, fromResellerID int64) ([]ListUnallocatedStockTransfersRow, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
 	ListUsersCount(ctx context.Context, arg ListUsersCountParams) (int64, error)
+	LockResellerAccount(ctx context.Context, resellerID int64) error
+	LockStockTables(ctx context.Context) error
+	NextInvoiceNumber(ctx context.Context) (int64, error)
 	ProductHelpers(ctx context.Context) ([]ProductHelpersRow, error)
+	RebuildAdminStatsCompanyStock(ctx context.Context) (int64, error)
+	RebuildBatchInventory(ctx context.Context) (int64, error)
+	RebuildCompanyStock(ctx context.Context) (int64, error)
+	RebuildResellerBatchInventory(ctx context.Context) (int64, error)
+	RebuildResellerStock(ctx context.Context) (int64, error)
+	RecordStocktakeCount(ctx context.Context, arg RecordStocktakeCountParams) (int64, error)
+	RejectStockTransfer(ctx context.Context, arg RejectStockTransferParams) (StockTransfer, error)
 	RemoveBatchInventoryQuantity(ctx context.Context, arg RemoveBatchInventoryQuantityParams) (BatchInventory, error)
 	RemoveCompanyStock(ctx context.Context, arg RemoveCompanyStockParams) (CompanyStock, error)
 	RemoveResellerBatchInventoryQuantity(ctx context.Context, arg RemoveResellerBatchInventoryQuantityParams) (ResellerBatchInventory, error)
 	ResellerStockFormHelpers(ctx context.Context, resellerID int64) ([]ResellerStockFormHelpersRow, error)
+	ResolveCreditHold(ctx context.Context, arg ResolveCreditHoldParams) (CreditHold, error)
+	SetStockDistributionInvoice(ctx context.Context, arg SetStockDistributionInvoiceParams) error
+	SetStocktakeLineAdjustment(ctx context.Context, arg SetStocktakeLineAdjustmentParams) error
+	SkipPaymentImportRows(ctx context.Context, importID int64) error
 	SubtractResellerStockQuantity(ctx context.Context, arg SubtractResellerStockQuantityParams) (ResellerStock, error)
 	UpdateAdminStats(ctx context.Context, arg UpdateAdminStatsParams) (AdminStat, error)
 	UpdateGoodsRequestAdmin(ctx context.Context, arg UpdateGoodsRequestAdminParams) (GoodsRequest, error)
 	UpdateGoodsRequestPayload(ctx context.Context, arg UpdateGoodsRequestPayloadParams) (GoodsRequest, error)
+	UpdateLocation(ctx context.Context, arg UpdateLocationParams) (Location, error)
+	UpdatePaymentMethod(ctx context.Context, arg UpdatePaymentMethodParams) (PaymentMethod, error)
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
+	UpdatePurchaseOrder(ctx context.Context, arg UpdatePurchaseOrderParams) (PurchaseOrder, error)
+	UpdatePurchaseOrderStatus(ctx context.Context, arg UpdatePurchaseOrderStatusParams) (PurchaseOrder, error)
 	UpdateResellerAccount(ctx context.Context, arg UpdateResellerAccountParams) (ResellerAccount, error)
+	UpdateResellerCreditLimit(ctx context.Context, arg UpdateResellerCreditLimitParams) (ResellerAccount, error)
 	UpdateResellerStockThreshold(ctx context.Context, arg UpdateResellerStockThresholdParams) (ResellerStock, error)
+	UpdateSupplier(ctx context.Context, arg UpdateSupplierParams) (Supplier, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UserHelpers(ctx context.Context) ([]UserHelpersRow, error)
 }
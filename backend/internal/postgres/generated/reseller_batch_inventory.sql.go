@@ -12,6 +12,35 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addResellerBatchInventoryQuantity = `-- name: AddResellerBatchInventoryQuantity :one
+UPDATE reseller_batch_inventory
+SET remaining_quantity = remaining_quantity + $1
+WHERE id = $2
+RETURNING id, reseller_id, product_id, source_batch_id, batch_number, unit_cost, remaining_quantity, created_at, batch_unit_cost
+`
+
+type AddResellerBatchInventoryQuantityParams struct {
+	Quantity    int64 `json:"quantity"`
+	InventoryID int64 `json:"inventory_id"`
+}
+
+func (q *Queries) AddResellerBatchInventoryQuantity(ctx context.Context, arg AddResellerBatchInventoryQuantityParams) (ResellerBatchInventory, error) {
+	row := q.db.QueryRow(ctx, addResellerBatchInventoryQuantity, arg.Quantity, arg.InventoryID)
+	var i ResellerBatchInventory
+	err := row.Scan(
+		&i.ID,
+		&i.ResellerID,
+		&i.ProductID,
+		&i.SourceBatchID,
+		&i.BatchNumber,
+		&i.UnitCost,
+		&i.RemainingQuantity,
+		&i.CreatedAt,
+		&i.BatchUnitCost,
+	)
+	return i, err
+}
+
 const createResellerBatchInventoryRecord = `-- name: CreateResellerBatchInventoryRecord :one
 INSERT INTO reseller_batch_inventory (
           reseller_id,
@@ -19,10 +48,11 @@ INSERT INTO reseller_batch_inventory (
           source_batch_id,
           batch_number,
           remaining_quantity,
-          unit_cost
+          unit_cost,
+          batch_unit_cost
       )
-VALUES  ($1, $2, $3, $4, $5, $6)
-RETURNING id, reseller_id, product_id, source_batch_id, batch_number, unit_cost, remaining_quantity, created_at
+VALUES  ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, reseller_id, product_id, source_batch_id, batch_number, unit_cost, remaining_quantity, created_at, batch_unit_cost
 `
 
 type CreateResellerBatchInventoryRecordParams struct {
@@ -32,6 +62,7 @@ type CreateResellerBatchInventoryRecordParams struct {
 	BatchNumber       string         `json:"batch_number"`
 	RemainingQuantity int64          `json:"remaining_quantity"`
 	UnitCost          pgtype.Numeric `json:"unit_cost"`
+	BatchUnitCost     pgtype.Numeric `json:"batch_unit_cost"`
 }
 
 func (q *Queries) CreateResellerBatchInventoryRecord(ctx context.Context, arg CreateResellerBatchInventoryRecordParams) (ResellerBatchInventory, error) {
@@ -42,6 +73,7 @@ func (q *Queries) CreateResellerBatchInventoryRecord(ctx context.Context, arg Cr
 		arg.BatchNumber,
 		arg.RemainingQuantity,
 		arg.UnitCost,
+		arg.BatchUnitCost,
 	)
 	var i ResellerBatchInventory
 	err := row.Scan(
@@ -53,16 +85,20 @@ func (q *Queries) CreateResellerBatchInventoryRecord(ctx context.Context, arg Cr
 		&i.UnitCost,
 		&i.RemainingQuantity,
 		&i.CreatedAt,
+		&i.BatchUnitCost,
 	)
 	return i, err
 }
 
 const getResellerBatchInventoryProductSum = `-- name: GetResellerBatchInventoryProductSum :one
-SELECT COALESCE(SUM(remaining_quantity), 0)::bigint AS total_remaining
-FROM reseller_batch_inventory
-WHERE reseller_id = $1
-      AND product_id = $2
-      AND remaining_quantity > 0
+SELECT 
+    COALESCE(SUM(rbi.remaining_quantity) FILTER (WHERE pb.expiry_date IS NULL OR pb.expiry_date >= CURRENT_DATE), 0)::bigint AS total_remaining,
+    COALESCE(SUM(rbi.remaining_quantity) FILTER (WHERE pb.expiry_date < CURRENT_DATE), 0)::bigint AS total_expired
+FROM reseller_batch_inventory rbi
+JOIN product_batches pb ON pb.id = rbi.source_batch_id
+WHERE rbi.reseller_id = $1
+      AND rbi.product_id = $2
+      AND rbi.remaining_quantity > 0
 `
 
 type GetResellerBatchInventoryProductSumParams struct {
@@ -70,28 +106,142 @@ type GetResellerBatchInventoryProductSumParams struct {
 	ProductID  int64 `json:"product_id"`
 }
 
-func (q *Queries) GetResellerBatchInventoryProductSum(ctx context.Context, arg GetResellerBatchInventoryProductSumParams) (int64, error) {
+type GetResellerBatchInventoryProductSumRow struct {
+	TotalRemaining int64 `json:"total_remaining"`
+	TotalExpired   int64 `json:"total_expired"`
+}
+
+func (q *Queries) GetResellerBatchInventoryProductSum(ctx context.Context, arg GetResellerBatchInventoryProductSumParams) (GetResellerBatchInventoryProductSumRow, error) {
 	row := q.db.QueryRow(ctx, getResellerBatchInventoryProductSum, arg.ResellerID, arg.ProductID)
-	var total_remaining int64
-	err := row.Scan(&total_remaining)
-	return total_remaining, err
+	var i GetResellerBatchInventoryProductSumRow
+	err := row.Scan(&i.TotalRemaining, &i.TotalExpired)
+	return i, err
+}
+
+const listResellerBatchInventoryForAdjustment = `-- name: ListResellerBatchInventoryForAdjustment :many
+SELECT id, reseller_id, product_id, source_batch_id, batch_number, unit_cost, remaining_quantity, created_at, batch_unit_cost FROM reseller_batch_inventory
+WHERE 
+    reseller_id = $1
+    AND product_id = $2
+    AND (
+        $3::bigint IS NULL
+        OR source_batch_id = $3
+    )
+    AND (remaining_quantity > 0 OR $3::bigint IS NOT NULL)
+ORDER BY created_at ASC, id ASC
+FOR UPDATE
+`
+
+type ListResellerBatchInventoryForAdjustmentParams struct {
+	ResellerID int64       `json:"reseller_id"`
+	ProductID  int64       `json:"product_id"`
+	BatchID    pgtype.Int8 `json:"batch_id"`
+}
+
+func (q *Queries) ListResellerBatchInventoryForAdjustment(ctx context.Context, arg ListResellerBatchInventoryForAdjustmentParams) ([]ResellerBatchInventory, error) {
+	rows, err := q.db.Query(ctx, listResellerBatchInventoryForAdjustment, arg.ResellerID, arg.ProductID, arg.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ResellerBatchInventory{}
+	for rows.Next() {
+		var i ResellerBatchInventory
+		if err := rows.Scan(
+			&i.ID,
+			&i.ResellerID,
+			&i.ProductID,
+			&i.SourceBatchID,
+			&i.BatchNumber,
+			&i.UnitCost,
+			&i.RemainingQuantity,
+			&i.CreatedAt,
+			&i.BatchUnitCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listResellerBatchInventoryForReturn = `-- name: ListResellerBatchInventoryForReturn :many
+SELECT id, reseller_id, product_id, source_batch_id, batch_number, unit_cost, remaining_quantity, created_at, batch_unit_cost FROM reseller_batch_inventory
+WHERE 
+    reseller_id = $1
+    AND product_id = $2
+    AND remaining_quantity > 0
+    AND (
+        $3::bigint IS NULL
+        OR source_batch_id = $3
+    )
+ORDER BY created_at DESC, id DESC
+FOR UPDATE
+`
+
+type ListResellerBatchInventoryForReturnParams struct {
+	ResellerID int64       `json:"reseller_id"`
+	ProductID  int64       `json:"product_id"`
+	BatchID    pgtype.Int8 `json:"batch_id"`
+}
+
+func (q *Queries) ListResellerBatchInventoryForReturn(ctx context.Context, arg ListResellerBatchInventoryForReturnParams) ([]ResellerBatchInventory, error) {
+	rows, err := q.db.Query(ctx, listResellerBatchInventoryForReturn, arg.ResellerID, arg.ProductID, arg.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ResellerBatchInventory{}
+	for rows.Next() {
+		var i ResellerBatchInventory
+		if err := rows.Scan(
+			&i.ID,
+			&i.ResellerID,
+			&i.ProductID,
+			&i.SourceBatchID,
+			&i.BatchNumber,
+			&i.UnitCost,
+			&i.RemainingQuantity,
+			&i.CreatedAt,
+			&i.BatchUnitCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const listResellerBatchInventoryForUpdate = `-- name: ListResellerBatchInventoryForUpdate :many
-SELECT rbi.id, rbi.reseller_id, rbi.product_id, rbi.source_batch_id, rbi.batch_number, rbi.unit_cost, rbi.remaining_quantity, rbi.created_at, pb.batch_number
+SELECT rbi.id, rbi.reseller_id, rbi.product_id, rbi.source_batch_id, rbi.batch_number, rbi.unit_cost, rbi.remaining_quantity, rbi.created_at, rbi.batch_unit_cost, pb.batch_number
 FROM reseller_batch_inventory rbi
 JOIN product_batches pb ON pb.id = rbi.source_batch_id
 WHERE 
     rbi.reseller_id = $1
     AND rbi.product_id = $2
     AND rbi.remaining_quantity > 0
-ORDER BY pb.date_received ASC
+    AND (pb.expiry_date IS NULL OR pb.expiry_date >= CURRENT_DATE)
+ORDER BY
+    CASE WHEN $3::text = 'LIFO' THEN pb.date_received END DESC,
+    CASE WHEN $3::text = 'LIFO' THEN pb.id END DESC,
+    CASE WHEN $3::text = 'FEFO' THEN pb.expiry_date END ASC NULLS LAST,
+    pb.date_received ASC,
+    pb.id ASC,
+    CASE WHEN $3::text = 'LIFO' THEN rbi.id END DESC,
+    rbi.id ASC
 FOR UPDATE
 `
 
 type ListResellerBatchInventoryForUpdateParams struct {
-	ResellerID int64 `json:"reseller_id"`
-	ProductID  int64 `json:"product_id"`
+	ResellerID int64  `json:"reseller_id"`
+	ProductID  int64  `json:"product_id"`
+	Strategy   string `json:"strategy"`
 }
 
 type ListResellerBatchInventoryForUpdateRow struct {
@@ -103,11 +253,12 @@ type ListResellerBatchInventoryForUpdateRow struct {
 	UnitCost          pgtype.Numeric `json:"unit_cost"`
 	RemainingQuantity int64          `json:"remaining_quantity"`
 	CreatedAt         time.Time      `json:"created_at"`
+	BatchUnitCost     pgtype.Numeric `json:"batch_unit_cost"`
 	BatchNumber_2     string         `json:"batch_number_2"`
 }
 
 func (q *Queries) ListResellerBatchInventoryForUpdate(ctx context.Context, arg ListResellerBatchInventoryForUpdateParams) ([]ListResellerBatchInventoryForUpdateRow, error) {
-	rows, err := q.db.Query(ctx, listResellerBatchInventoryForUpdate, arg.ResellerID, arg.ProductID)
+	rows, err := q.db.Query(ctx, listResellerBatchInventoryForUpdate, arg.ResellerID, arg.ProductID, arg.Strategy)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +275,7 @@ func (q *Queries) ListResellerBatchInventoryForUpdate(ctx context.Context, arg L
 			&i.UnitCost,
 			&i.RemainingQuantity,
 			&i.CreatedAt,
+			&i.BatchUnitCost,
 			&i.BatchNumber_2,
 		); err != nil {
 			return nil, err
@@ -141,7 +293,7 @@ UPDATE reseller_batch_inventory
 SET remaining_quantity = remaining_quantity - $1
 WHERE id = $2
   AND remaining_quantity >= $1
-RETURNING id, reseller_id, product_id, source_batch_id, batch_number, unit_cost, remaining_quantity, created_at
+RETURNING id, reseller_id, product_id, source_batch_id, batch_number, unit_cost, remaining_quantity, created_at, batch_unit_cost
 `
 
 type RemoveResellerBatchInventoryQuantityParams struct {
@@ -161,6 +313,7 @@ func (q *Queries) RemoveResellerBatchInventoryQuantity(ctx context.Context, arg
 		&i.UnitCost,
 		&i.RemainingQuantity,
 		&i.CreatedAt,
+		&i.BatchUnitCost,
 	)
 	return i, err
 }
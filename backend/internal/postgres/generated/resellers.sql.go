@@ -59,7 +59,7 @@ func (q *Queries) CheckResellerStockExists(ctx context.Context, arg CheckReselle
 const createResellerAccount = `-- name: CreateResellerAccount :one
 INSERT INTO reseller_accounts (reseller_id)
 VALUES ($1)
-RETURNING reseller_id, total_stock_received, total_value_received, total_sales_value, total_paid, total_cogs, balance
+RETURNING reseller_id, total_stock_received, total_value_received, total_sales_value, total_paid, total_cogs, balance, credit_limit
 `
 
 func (q *Queries) CreateResellerAccount(ctx context.Context, resellerID int64) (ResellerAccount, error) {
@@ -73,6 +73,7 @@ func (q *Queries) CreateResellerAccount(ctx context.Context, resellerID int64) (
 		&i.TotalPaid,
 		&i.TotalCogs,
 		&i.Balance,
+		&i.CreditLimit,
 	)
 	return i, err
 }
@@ -102,7 +103,7 @@ func (q *Queries) CreateResellerStock(ctx context.Context, arg CreateResellerSto
 }
 
 const getResellerAccount = `-- name: GetResellerAccount :one
-SELECT reseller_id, total_stock_received, total_value_received, total_sales_value, total_paid, total_cogs, balance FROM reseller_accounts
+SELECT reseller_id, total_stock_received, total_value_received, total_sales_value, total_paid, total_cogs, balance, credit_limit FROM reseller_accounts
 WHERE reseller_id = $1
 `
 
@@ -117,12 +118,35 @@ func (q *Queries) GetResellerAccount(ctx context.Context, resellerID int64) (Res
 		&i.TotalPaid,
 		&i.TotalCogs,
 		&i.Balance,
+		&i.CreditLimit,
+	)
+	return i, err
+}
+
+const getResellerAccountForUpdate = `-- name: GetResellerAccountForUpdate :one
+SELECT reseller_id, total_stock_received, total_value_received, total_sales_value, total_paid, total_cogs, balance, credit_limit FROM reseller_accounts
+WHERE reseller_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetResellerAccountForUpdate(ctx context.Context, resellerID int64) (ResellerAccount, error) {
+	row := q.db.QueryRow(ctx, getResellerAccountForUpdate, resellerID)
+	var i ResellerAccount
+	err := row.Scan(
+		&i.ResellerID,
+		&i.TotalStockReceived,
+		&i.TotalValueReceived,
+		&i.TotalSalesValue,
+		&i.TotalPaid,
+		&i.TotalCogs,
+		&i.Balance,
+		&i.CreditLimit,
 	)
 	return i, err
 }
 
 const getResellerWithAccountByID = `-- name: GetResellerWithAccountByID :one
-SELECT u.id, u.name, u.email, u.phone_number, u.role, u.password, u.refresh_token, u.deleted, u.created_at, ra.reseller_id, ra.total_stock_received, ra.total_value_received, ra.total_sales_value, ra.total_paid, ra.total_cogs, ra.balance
+SELECT u.id, u.name, u.email, u.phone_number, u.role, u.password, u.refresh_token, u.deleted, u.created_at, ra.reseller_id, ra.total_stock_received, ra.total_value_received, ra.total_sales_value, ra.total_paid, ra.total_cogs, ra.balance, ra.credit_limit
 FROM users u
 JOIN reseller_accounts ra ON ra.reseller_id = u.id
 WHERE 
@@ -147,6 +171,7 @@ type GetResellerWithAccountByIDRow struct {
 	TotalPaid          pgtype.Numeric `json:"total_paid"`
 	TotalCogs          pgtype.Numeric `json:"total_cogs"`
 	Balance            pgtype.Numeric `json:"balance"`
+	CreditLimit        pgtype.Numeric `json:"credit_limit"`
 }
 
 func (q *Queries) GetResellerWithAccountByID(ctx context.Context, resellerID int64) (GetResellerWithAccountByIDRow, error) {
@@ -169,6 +194,7 @@ func (q *Queries) GetResellerWithAccountByID(ctx context.Context, resellerID int
 		&i.TotalPaid,
 		&i.TotalCogs,
 		&i.Balance,
+		&i.CreditLimit,
 	)
 	return i, err
 }
@@ -297,10 +323,17 @@ func (q *Queries) ListResellerStockCount(ctx context.Context, arg ListResellerSt
 }
 
 const listResellersWithAccount = `-- name: ListResellersWithAccount :many
-SELECT u.id as user_id, u.name, u.phone_number, u.email, ra.reseller_id, ra.total_stock_received, ra.total_value_received, ra.total_sales_value, ra.total_paid, ra.total_cogs, ra.balance,
-       COALESCE((SELECT SUM(quantity) FROM reseller_stock WHERE reseller_id = u.id), 0)::bigint AS current_stock_units
+SELECT u.id as user_id, u.name, u.phone_number, u.email, ra.reseller_id, ra.total_stock_received, ra.total_value_received, ra.total_sales_value, ra.total_paid, ra.total_cogs, ra.balance, ra.credit_limit,
+       COALESCE((SELECT SUM(quantity) FROM reseller_stock WHERE reseller_id = u.id), 0)::bigint AS current_stock_units,
+       COALESCE(ag.days_0_30, 0)::numeric AS days_0_30,
+       COALESCE(ag.days_31_60, 0)::numeric AS days_31_60,
+       COALESCE(ag.days_61_90, 0)::numeric AS days_61_90,
+       COALESCE(ag.days_over_90, 0)::numeric AS days_over_90,
+       COALESCE(ag.total_outstanding, 0)::numeric AS total_outstanding,
+       COALESCE(ag.days_overdue, 0)::bigint AS days_overdue
 FROM users u
 JOIN reseller_accounts ra ON ra.reseller_id = u.id
+LEFT JOIN reseller_receivables_aging ag ON ag.reseller_id = u.id
 WHERE 
     role = 'staff' AND deleted = false
     AND (
@@ -309,12 +342,17 @@ WHERE
         OR LOWER(u.phone_number) LIKE $1
          OR LOWER(u.email) LIKE $1
     )
-ORDER BY u.created_at DESC
-LIMIT $3 OFFSET $2
+ORDER BY
+    CASE WHEN $2::text = 'most_overdue' THEN COALESCE(ag.days_overdue, 0) END DESC,
+    CASE WHEN $2::text = 'most_overdue' THEN COALESCE(ag.total_outstanding, 0) END DESC,
+    CASE WHEN $2::text = 'credit_utilisation' THEN ra.balance / NULLIF(ra.credit_limit, 0) END DESC NULLS LAST,
+    u.created_at DESC
+LIMIT $4 OFFSET $3
 `
 
 type ListResellersWithAccountParams struct {
 	Search interface{} `json:"search"`
+	Sort   pgtype.Text `json:"sort"`
 	Offset int32       `json:"offset"`
 	Limit  int32       `json:"limit"`
 }
@@ -331,11 +369,23 @@ type ListResellersWithAccountRow struct {
 	TotalPaid          pgtype.Numeric `json:"total_paid"`
 	TotalCogs          pgtype.Numeric `json:"total_cogs"`
 	Balance            pgtype.Numeric `json:"balance"`
+	CreditLimit        pgtype.Numeric `json:"credit_limit"`
 	CurrentStockUnits  int64          `json:"current_stock_units"`
+	Days030            pgtype.Numeric `json:"days_0_30"`
+	Days3160           pgtype.Numeric `json:"days_31_60"`
+	Days6190           pgtype.Numeric `json:"days_61_90"`
+	DaysOver90         pgtype.Numeric `json:"days_over_90"`
+	TotalOutstanding   pgtype.Numeric `json:"total_outstanding"`
+	DaysOverdue        int64          `json:"days_overdue"`
 }
 
 func (q *Queries) ListResellersWithAccount(ctx context.Context, arg ListResellersWithAccountParams) ([]ListResellersWithAccountRow, error) {
-	rows, err := q.db.Query(ctx, listResellersWithAccount, arg.Search, arg.Offset, arg.Limit)
+	rows, err := q.db.Query(ctx, listResellersWithAccount,
+		arg.Search,
+		arg.Sort,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -355,7 +405,14 @@ func (q *Queries) ListResellersWithAccount(ctx context.Context, arg ListReseller
 			&i.TotalPaid,
 			&i.TotalCogs,
 			&i.Balance,
+			&i.CreditLimit,
 			&i.CurrentStockUnits,
+			&i.Days030,
+			&i.Days3160,
+			&i.Days6190,
+			&i.DaysOver90,
+			&i.TotalOutstanding,
+			&i.DaysOverdue,
 		); err != nil {
 			return nil, err
 		}
@@ -387,6 +444,17 @@ func (q *Queries) ListResellersWithAccountCount(ctx context.Context, search inte
 	return total_resellers, err
 }
 
+const lockResellerAccount = `-- name: LockResellerAccount :exec
+SELECT reseller_id FROM reseller_accounts
+WHERE reseller_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockResellerAccount(ctx context.Context, resellerID int64) error {
+	_, err := q.db.Exec(ctx, lockResellerAccount, resellerID)
+	return err
+}
+
 const subtractResellerStockQuantity = `-- name: SubtractResellerStockQuantity :one
 UPDATE reseller_stock
 SET quantity = GREATEST(quantity - $3, 0)
@@ -421,7 +489,7 @@ SET total_stock_received = coalesce($1, total_stock_received),
     total_cogs = coalesce($5, total_cogs),
     balance = coalesce($6, balance)
 WHERE reseller_id = $7
-RETURNING reseller_id, total_stock_received, total_value_received, total_sales_value, total_paid, total_cogs, balance
+RETURNING reseller_id, total_stock_received, total_value_received, total_sales_value, total_paid, total_cogs, balance, credit_limit
 `
 
 type UpdateResellerAccountParams struct {
@@ -453,6 +521,35 @@ func (q *Queries) UpdateResellerAccount(ctx context.Context, arg UpdateResellerA
 		&i.TotalPaid,
 		&i.TotalCogs,
 		&i.Balance,
+		&i.CreditLimit,
+	)
+	return i, err
+}
+
+const updateResellerCreditLimit = `-- name: UpdateResellerCreditLimit :one
+UPDATE reseller_accounts
+SET credit_limit = $1
+WHERE reseller_id = $2
+RETURNING reseller_id, total_stock_received, total_value_received, total_sales_value, total_paid, total_cogs, balance, credit_limit
+`
+
+type UpdateResellerCreditLimitParams struct {
+	CreditLimit pgtype.Numeric `json:"credit_limit"`
+	ResellerID  int64          `json:"reseller_id"`
+}
+
+func (q *Queries) UpdateResellerCreditLimit(ctx context.Context, arg UpdateResellerCreditLimitParams) (ResellerAccount, error) {
+	row := q.db.QueryRow(ctx, updateResellerCreditLimit, arg.CreditLimit, arg.ResellerID)
+	var i ResellerAccount
+	err := row.Scan(
+		&i.ResellerID,
+		&i.TotalStockReceived,
+		&i.TotalValueReceived,
+		&i.TotalSalesValue,
+		&i.TotalPaid,
+		&i.TotalCogs,
+		&i.Balance,
+		&i.CreditLimit,
 	)
 	return i, err
 }
@@ -13,9 +13,9 @@ import (
 )
 
 const createStockDistributionRecord = `-- name: CreateStockDistributionRecord :one
-INSERT INTO stock_distributions (reseller_id, product_id, quantity, unit_price, date_distributed)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, reseller_id, product_id, quantity, unit_price, total_price, date_distributed, created_at
+INSERT INTO stock_distributions (reseller_id, product_id, quantity, unit_price, date_distributed, location_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, reseller_id, product_id, quantity, unit_price, total_price, date_distributed, created_at, invoice_id, location_id
 `
 
 type CreateStockDistributionRecordParams struct {
@@ -24,6 +24,7 @@ type CreateStockDistributionRecordParams struct {
 	Quantity        int32          `json:"quantity"`
 	UnitPrice       pgtype.Numeric `json:"unit_price"`
 	DateDistributed time.Time      `json:"date_distributed"`
+	LocationID      int64          `json:"location_id"`
 }
 
 func (q *Queries) CreateStockDistributionRecord(ctx context.Context, arg CreateStockDistributionRecordParams) (StockDistribution, error) {
@@ -33,6 +34,7 @@ func (q *Queries) CreateStockDistributionRecord(ctx context.Context, arg CreateS
 		arg.Quantity,
 		arg.UnitPrice,
 		arg.DateDistributed,
+		arg.LocationID,
 	)
 	var i StockDistribution
 	err := row.Scan(
@@ -44,21 +46,25 @@ func (q *Queries) CreateStockDistributionRecord(ctx context.Context, arg CreateS
 		&i.TotalPrice,
 		&i.DateDistributed,
 		&i.CreatedAt,
+		&i.InvoiceID,
+		&i.LocationID,
 	)
 	return i, err
 }
 
 const listStockDistributions = `-- name: ListStockDistributions :many
-SELECT sd.id, sd.reseller_id, sd.product_id, sd.quantity, sd.unit_price, sd.total_price, sd.date_distributed, sd.created_at, 
+SELECT sd.id, sd.reseller_id, sd.product_id, sd.quantity, sd.unit_price, sd.total_price, sd.date_distributed, sd.created_at, sd.invoice_id, sd.location_id, 
     p.name AS product_name,
     p.price AS product_price,
     p.unit AS product_unit,
     p.low_stock_threshold AS product_low_stock_threshold,
     u.name AS reseller_name,
-    u.phone_number AS reseller_phone_number
+    u.phone_number AS reseller_phone_number,
+    l.name AS location_name
 FROM stock_distributions sd
 LEFT JOIN products p ON p.id = sd.product_id
 LEFT JOIN users u ON u.id = sd.reseller_id
+LEFT JOIN locations l ON l.id = sd.location_id
 WHERE 
     (
         $1::bigint IS NULL
@@ -69,20 +75,25 @@ WHERE
         OR sd.product_id = $2
     )
     AND (
-        COALESCE($3, '') = '' 
-        OR LOWER(p.name) LIKE $3
-        OR LOWER(p.category) LIKE $3
+        $3::bigint IS NULL
+        OR sd.location_id = $3
+    )
+    AND (
+        COALESCE($4, '') = '' 
+        OR LOWER(p.name) LIKE $4
+        OR LOWER(p.category) LIKE $4
     )
 ORDER BY sd.date_distributed DESC
-LIMIT $5 OFFSET $4
+LIMIT $5 OFFSET $6
 `
 
 type ListStockDistributionsParams struct {
 	ResellerID pgtype.Int8 `json:"reseller_id"`
 	ProductID  pgtype.Int8 `json:"product_id"`
+	LocationID pgtype.Int8 `json:"location_id"`
 	Search     interface{} `json:"search"`
-	Offset     int32       `json:"offset"`
 	Limit      int32       `json:"limit"`
+	Offset     int32       `json:"offset"`
 }
 
 type ListStockDistributionsRow struct {
@@ -94,21 +105,25 @@ type ListStockDistributionsRow struct {
 	TotalPrice               pgtype.Numeric `json:"total_price"`
 	DateDistributed          time.Time      `json:"date_distributed"`
 	CreatedAt                time.Time      `json:"created_at"`
+	InvoiceID                pgtype.Int8    `json:"invoice_id"`
+	LocationID               int64          `json:"location_id"`
 	ProductName              pgtype.Text    `json:"product_name"`
 	ProductPrice             pgtype.Numeric `json:"product_price"`
 	ProductUnit              pgtype.Text    `json:"product_unit"`
 	ProductLowStockThreshold pgtype.Int4    `json:"product_low_stock_threshold"`
 	ResellerName             pgtype.Text    `json:"reseller_name"`
 	ResellerPhoneNumber      pgtype.Text    `json:"reseller_phone_number"`
+	LocationName             pgtype.Text    `json:"location_name"`
 }
 
 func (q *Queries) ListStockDistributions(ctx context.Context, arg ListStockDistributionsParams) ([]ListStockDistributionsRow, error) {
 	rows, err := q.db.Query(ctx, listStockDistributions,
 		arg.ResellerID,
 		arg.ProductID,
+		arg.LocationID,
 		arg.Search,
-		arg.Offset,
 		arg.Limit,
+		arg.Offset,
 	)
 	if err != nil {
 		return nil, err
@@ -126,12 +141,15 @@ func (q *Queries) ListStockDistributions(ctx context.Context, arg ListStockDistr
 			&i.TotalPrice,
 			&i.DateDistributed,
 			&i.CreatedAt,
+			&i.InvoiceID,
+			&i.LocationID,
 			&i.ProductName,
 			&i.ProductPrice,
 			&i.ProductUnit,
 			&i.ProductLowStockThreshold,
 			&i.ResellerName,
 			&i.ResellerPhoneNumber,
+			&i.LocationName,
 		); err != nil {
 			return nil, err
 		}
@@ -157,20 +175,30 @@ WHERE
         OR sd.product_id = $2
     )
     AND (
-        COALESCE($3, '') = '' 
-        OR LOWER(p.name) LIKE $3
-        OR LOWER(p.category) LIKE $3
+        $3::bigint IS NULL
+        OR sd.location_id = $3
+    )
+    AND (
+        COALESCE($4, '') = '' 
+        OR LOWER(p.name) LIKE $4
+        OR LOWER(p.category) LIKE $4
     )
 `
 
 type ListStockDistributionsCountParams struct {
 	ResellerID pgtype.Int8 `json:"reseller_id"`
 	ProductID  pgtype.Int8 `json:"product_id"`
+	LocationID pgtype.Int8 `json:"location_id"`
 	Search     interface{} `json:"search"`
 }
 
 func (q *Queries) ListStockDistributionsCount(ctx context.Context, arg ListStockDistributionsCountParams) (int64, error) {
-	row := q.db.QueryRow(ctx, listStockDistributionsCount, arg.ResellerID, arg.ProductID, arg.Search)
+	row := q.db.QueryRow(ctx, listStockDistributionsCount,
+		arg.ResellerID,
+		arg.ProductID,
+		arg.LocationID,
+		arg.Search,
+	)
 	var total_distributions int64
 	err := row.Scan(&total_distributions)
 	return total_distributions, err
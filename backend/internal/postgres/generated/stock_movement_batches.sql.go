@@ -12,9 +12,19 @@ import (
 )
 
 const createStockMovementBatchRecord = `-- name: CreateStockMovementBatchRecord :one
-INSERT INTO stock_movement_batches (owner, batch_number, stock_movement_id, batch_id, quantity, unit_cost)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, owner, stock_movement_id, batch_id, batch_number, quantity, unit_cost, created_at
+INSERT INTO stock_movement_batches (owner, batch_number, stock_movement_id, batch_id, quantity, unit_cost, batch_unit_cost)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    -- reseller stock carries the cost it left the company at, anything else is costed at the
+    -- batch's cost now
+    COALESCE($7::numeric, (SELECT pb.unit_cost FROM product_batches pb WHERE pb.id = $4))
+)
+RETURNING id, owner, stock_movement_id, batch_id, batch_number, quantity, unit_cost, created_at, batch_unit_cost
 `
 
 type CreateStockMovementBatchRecordParams struct {
@@ -24,6 +34,7 @@ type CreateStockMovementBatchRecordParams struct {
 	BatchID         int64          `json:"batch_id"`
 	Quantity        int64          `json:"quantity"`
 	UnitCost        pgtype.Numeric `json:"unit_cost"`
+	BatchUnitCost   pgtype.Numeric `json:"batch_unit_cost"`
 }
 
 func (q *Queries) CreateStockMovementBatchRecord(ctx context.Context, arg CreateStockMovementBatchRecordParams) (StockMovementBatch, error) {
@@ -34,6 +45,7 @@ func (q *Queries) CreateStockMovementBatchRecord(ctx context.Context, arg Create
 		arg.BatchID,
 		arg.Quantity,
 		arg.UnitCost,
+		arg.BatchUnitCost,
 	)
 	var i StockMovementBatch
 	err := row.Scan(
@@ -45,12 +57,13 @@ func (q *Queries) CreateStockMovementBatchRecord(ctx context.Context, arg Create
 		&i.Quantity,
 		&i.UnitCost,
 		&i.CreatedAt,
+		&i.BatchUnitCost,
 	)
 	return i, err
 }
 
 const listStockMovementBatchesByBatchID = `-- name: ListStockMovementBatchesByBatchID :many
-SELECT smb.id, smb.owner, smb.stock_movement_id, smb.batch_id, smb.batch_number, smb.quantity, smb.unit_cost, smb.created_at
+SELECT smb.id, smb.owner, smb.stock_movement_id, smb.batch_id, smb.batch_number, smb.quantity, smb.unit_cost, smb.created_at, smb.batch_unit_cost
 FROM stock_movement_batches smb
 WHERE smb.batch_id = $1
 ORDER BY smb.created_at DESC
@@ -74,6 +87,7 @@ func (q *Queries) ListStockMovementBatchesByBatchID(ctx context.Context, batchID
 			&i.Quantity,
 			&i.UnitCost,
 			&i.CreatedAt,
+			&i.BatchUnitCost,
 		); err != nil {
 			return nil, err
 		}
@@ -86,7 +100,7 @@ func (q *Queries) ListStockMovementBatchesByBatchID(ctx context.Context, batchID
 }
 
 const listStockMovementBatchesByStockMovementID = `-- name: ListStockMovementBatchesByStockMovementID :many
-SELECT smb.id, smb.owner, smb.stock_movement_id, smb.batch_id, smb.batch_number, smb.quantity, smb.unit_cost, smb.created_at
+SELECT smb.id, smb.owner, smb.stock_movement_id, smb.batch_id, smb.batch_number, smb.quantity, smb.unit_cost, smb.created_at, smb.batch_unit_cost
 FROM stock_movement_batches smb
 WHERE smb.stock_movement_id = $1
 ORDER BY smb.created_at DESC
@@ -110,6 +124,7 @@ func (q *Queries) ListStockMovementBatchesByStockMovementID(ctx context.Context,
 			&i.Quantity,
 			&i.UnitCost,
 			&i.CreatedAt,
+			&i.BatchUnitCost,
 		); err != nil {
 			return nil, err
 		}
@@ -13,20 +13,26 @@ import (
 )
 
 const createStockMovementRecord = `-- name: CreateStockMovementRecord :one
-INSERT INTO stock_movements (product_id, owner_type, owner_id, movement_type, quantity, unit_price, source, note)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, product_id, owner_type, owner_id, movement_type, quantity, unit_price, source, note, created_at
+INSERT INTO stock_movements (product_id, owner_type, owner_id, movement_type, quantity, unit_price, source, note, location_id, movement_date)
+VALUES (
+    $1, $2, $3, $4, $5,
+    $6, $7, $8, $9,
+    COALESCE($10::timestamptz, now())
+)
+RETURNING id, product_id, owner_type, owner_id, movement_type, quantity, unit_price, source, note, created_at, location_id, movement_date
 `
 
 type CreateStockMovementRecordParams struct {
-	ProductID    int64          `json:"product_id"`
-	OwnerType    string         `json:"owner_type"`
-	OwnerID      pgtype.Int8    `json:"owner_id"`
-	MovementType string         `json:"movement_type"`
-	Quantity     int64          `json:"quantity"`
-	UnitPrice    pgtype.Numeric `json:"unit_price"`
-	Source       string         `json:"source"`
-	Note         string         `json:"note"`
+	ProductID    int64              `json:"product_id"`
+	OwnerType    string             `json:"owner_type"`
+	OwnerID      pgtype.Int8        `json:"owner_id"`
+	MovementType string             `json:"movement_type"`
+	Quantity     int64              `json:"quantity"`
+	UnitPrice    pgtype.Numeric     `json:"unit_price"`
+	Source       string             `json:"source"`
+	Note         string             `json:"note"`
+	LocationID   pgtype.Int8        `json:"location_id"`
+	MovementDate pgtype.Timestamptz `json:"movement_date"`
 }
 
 func (q *Queries) CreateStockMovementRecord(ctx context.Context, arg CreateStockMovementRecordParams) (StockMovement, error) {
@@ -39,6 +45,8 @@ func (q *Queries) CreateStockMovementRecord(ctx context.Context, arg CreateStock
 		arg.UnitPrice,
 		arg.Source,
 		arg.Note,
+		arg.LocationID,
+		arg.MovementDate,
 	)
 	var i StockMovement
 	err := row.Scan(
@@ -52,16 +60,19 @@ func (q *Queries) CreateStockMovementRecord(ctx context.Context, arg CreateStock
 		&i.Source,
 		&i.Note,
 		&i.CreatedAt,
+		&i.LocationID,
+		&i.MovementDate,
 	)
 	return i, err
 }
 
 const listStockMovements = `-- name: ListStockMovements :many
-SELECT sm.id, sm.product_id, sm.owner_type, sm.owner_id, sm.movement_type, sm.quantity, sm.unit_price, sm.source, sm.note, sm.created_at, p.name AS product_name, p.unit AS product_unit, p.category AS product_category,
-    u.name AS owner_name, u.phone_number AS owner_phone_number
+SELECT sm.id, sm.product_id, sm.owner_type, sm.owner_id, sm.movement_type, sm.quantity, sm.unit_price, sm.source, sm.note, sm.created_at, sm.location_id, sm.movement_date, p.name AS product_name, p.unit AS product_unit, p.category AS product_category,
+    u.name AS owner_name, u.phone_number AS owner_phone_number, l.name AS location_name
 FROM stock_movements sm
 LEFT JOIN products p ON p.id = sm.product_id
 LEFT JOIN users u ON u.id = sm.owner_id AND sm.owner_type = 'RESELLER'
+LEFT JOIN locations l ON l.id = sm.location_id
 WHERE 
     (
         $1::text IS NULL
@@ -88,8 +99,12 @@ WHERE
         $6::text IS NULL
         OR sm.source = $6
     )
+    AND (
+        $7::bigint IS NULL
+        OR sm.location_id = $7
+    )
 ORDER BY sm.created_at DESC
-LIMIT $8 OFFSET $7
+LIMIT $8 OFFSET $9
 `
 
 type ListStockMovementsParams struct {
@@ -99,8 +114,9 @@ type ListStockMovementsParams struct {
 	ProductID    pgtype.Int8 `json:"product_id"`
 	MovementType pgtype.Text `json:"movement_type"`
 	Source       pgtype.Text `json:"source"`
-	Offset       int32       `json:"offset"`
+	LocationID   pgtype.Int8 `json:"location_id"`
 	Limit        int32       `json:"limit"`
+	Offset       int32       `json:"offset"`
 }
 
 type ListStockMovementsRow struct {
@@ -114,11 +130,14 @@ type ListStockMovementsRow struct {
 	Source           string         `json:"source"`
 	Note             string         `json:"note"`
 	CreatedAt        time.Time      `json:"created_at"`
+	LocationID       pgtype.Int8    `json:"location_id"`
+	MovementDate     time.Time      `json:"movement_date"`
 	ProductName      pgtype.Text    `json:"product_name"`
 	ProductUnit      pgtype.Text    `json:"product_unit"`
 	ProductCategory  pgtype.Text    `json:"product_category"`
 	OwnerName        pgtype.Text    `json:"owner_name"`
 	OwnerPhoneNumber pgtype.Text    `json:"owner_phone_number"`
+	LocationName     pgtype.Text    `json:"location_name"`
 }
 
 func (q *Queries) ListStockMovements(ctx context.Context, arg ListStockMovementsParams) ([]ListStockMovementsRow, error) {
@@ -129,8 +148,9 @@ func (q *Queries) ListStockMovements(ctx context.Context, arg ListStockMovements
 		arg.ProductID,
 		arg.MovementType,
 		arg.Source,
-		arg.Offset,
+		arg.LocationID,
 		arg.Limit,
+		arg.Offset,
 	)
 	if err != nil {
 		return nil, err
@@ -150,11 +170,14 @@ func (q *Queries) ListStockMovements(ctx context.Context, arg ListStockMovements
 			&i.Source,
 			&i.Note,
 			&i.CreatedAt,
+			&i.LocationID,
+			&i.MovementDate,
 			&i.ProductName,
 			&i.ProductUnit,
 			&i.ProductCategory,
 			&i.OwnerName,
 			&i.OwnerPhoneNumber,
+			&i.LocationName,
 		); err != nil {
 			return nil, err
 		}
@@ -197,6 +220,10 @@ WHERE
         $6::text IS NULL
         OR sm.source = $6
     )
+    AND (
+        $7::bigint IS NULL
+        OR sm.location_id = $7
+    )
 `
 
 type ListStockMovementsCountParams struct {
@@ -206,6 +233,7 @@ type ListStockMovementsCountParams struct {
 	ProductID    pgtype.Int8 `json:"product_id"`
 	MovementType pgtype.Text `json:"movement_type"`
 	Source       pgtype.Text `json:"source"`
+	LocationID   pgtype.Int8 `json:"location_id"`
 }
 
 func (q *Queries) ListStockMovementsCount(ctx context.Context, arg ListStockMovementsCountParams) (int64, error) {
@@ -216,6 +244,7 @@ func (q *Queries) ListStockMovementsCount(ctx context.Context, arg ListStockMove
 		arg.ProductID,
 		arg.MovementType,
 		arg.Source,
+		arg.LocationID,
 	)
 	var total_movements int64
 	err := row.Scan(&total_movements)
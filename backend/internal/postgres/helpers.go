@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func (rr *ResellerRepository) GetResellerPageData(ctx context.Context, resellerID uint32, page string) (any, error) {
@@ -82,10 +83,15 @@ func (rr *ResellerRepository) GetResellerPageData(ctx context.Context, resellerI
 	}
 }
 
-func (cr *CompanyRepository) GetAdminPageData(ctx context.Context, page string) (any, error) {
+func (cr *CompanyRepository) GetAdminPageData(ctx context.Context, page string, locationID *uint32) (any, error) {
+	location := pgtype.Int8{Valid: false}
+	if locationID != nil {
+		location = pgtype.Int8{Int64: int64(*locationID), Valid: true}
+	}
+
 	switch page {
 	case "dashboard":
-		data, err := cr.queries.GetAdminDashboardStats(ctx)
+		data, err := cr.queries.GetAdminDashboardStats(ctx, location)
 		if err != nil {
 			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats page data: %s", err.Error())
 		}
@@ -96,7 +102,7 @@ func (cr *CompanyRepository) GetAdminPageData(ctx context.Context, page string)
 		}
 
 		// get weekly stock chat
-		chartData, err := cr.queries.GetAdminWeeklyStockChart(ctx)
+		chartData, err := cr.queries.GetAdminWeeklyStockChart(ctx, location)
 		if err != nil {
 			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin weekly stock chart data: %s", err.Error())
 		}
@@ -106,7 +112,7 @@ func (cr *CompanyRepository) GetAdminPageData(ctx context.Context, page string)
 		return stats, nil
 
 	case "products":
-		data, err := cr.queries.GetAdminProductsPageStats(ctx)
+		data, err := cr.queries.GetAdminProductsPageStats(ctx, location)
 		if err != nil {
 			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get products stats page data: %s", err.Error())
 		}
@@ -119,7 +125,7 @@ func (cr *CompanyRepository) GetAdminPageData(ctx context.Context, page string)
 		return stats, nil
 
 	case "batches":
-		data, err := cr.queries.GetAdminBatchesPageStats(ctx)
+		data, err := cr.queries.GetAdminBatchesPageStats(ctx, location)
 		if err != nil {
 			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get batches stats page data: %s", err.Error())
 		}
@@ -132,7 +138,7 @@ func (cr *CompanyRepository) GetAdminPageData(ctx context.Context, page string)
 		return stats, nil
 
 	case "distributions":
-		data, err := cr.queries.GetAdminDistributionPageStats(ctx)
+		data, err := cr.queries.GetAdminDistributionPageStats(ctx, location)
 		if err != nil {
 			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get distributions stats page data: %s", err.Error())
 		}
@@ -184,7 +190,7 @@ func (cr *CompanyRepository) GetAdminPageData(ctx context.Context, page string)
 		return stats, nil
 
 	case "stock_movements":
-		data, err := cr.queries.GetAdminStockMovementsPageStats(ctx)
+		data, err := cr.queries.GetAdminStockMovementsPageStats(ctx, location)
 		if err != nil {
 			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get stock_movements stats page data: %s", err.Error())
 		}
@@ -0,0 +1,636 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.InvoiceRepository = (*InvoiceRepository)(nil)
+
+type InvoiceRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewInvoiceRepository(db *Store) *InvoiceRepository {
+	return &InvoiceRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (ir *InvoiceRepository) CreateInvoice(ctx context.Context, distributionIDs []uint32, dueDate *time.Time) (*repository.Invoice, error) {
+	var invoiceID int64
+
+	err := ir.db.ExecTx(ctx, func(q *generated.Queries) error {
+		ids := make([]int64, len(distributionIDs))
+		for i, id := range distributionIDs {
+			ids[i] = int64(id)
+		}
+
+		distributions, err := q.ListStockDistributionsForInvoice(ctx, ids)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get stock distributions: %s", err.Error())
+		}
+
+		if len(distributions) != len(ids) {
+			return pkg.Errorf(pkg.NOT_FOUND_ERROR, "stock distribution not found")
+		}
+
+		resellerID := distributions[0].ResellerID
+		var total float64
+		for _, distribution := range distributions {
+			if distribution.ResellerID != resellerID {
+				return pkg.Errorf(pkg.INVALID_ERROR, "distributions on an invoice must belong to the same reseller")
+			}
+
+			if distribution.InvoiceID.Valid {
+				return pkg.Errorf(pkg.INVALID_ERROR, "distribution %d is already invoiced", distribution.ID)
+			}
+
+			total += pkg.PgTypeNumericToFloat64(distribution.TotalPrice)
+		}
+
+		invoiceID, err = createInvoice(ctx, q, ir.db.config.INVOICE_DUE_DAYS, resellerID, ids, total, time.Now(), dueDate)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ir.GetInvoice(ctx, uint32(invoiceID))
+}
+
+func (ir *InvoiceRepository) GetInvoice(ctx context.Context, id uint32) (*repository.Invoice, error) {
+	pgInvoice, err := ir.queries.GetInvoice(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "invoice not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get invoice: %s", err.Error())
+	}
+
+	pgDistributions, err := ir.queries.ListInvoiceDistributions(ctx, pgtype.Int8{Int64: pgInvoice.ID, Valid: true})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list invoice distributions: %s", err.Error())
+	}
+
+	pgTransfers, err := ir.queries.ListInvoiceStockTransfers(ctx, pgtype.Int8{Int64: pgInvoice.ID, Valid: true})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list invoice transfers: %s", err.Error())
+	}
+
+	pgAllocations, err := ir.queries.ListInvoiceAllocations(ctx, pgInvoice.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list invoice allocations: %s", err.Error())
+	}
+
+	pgReturnCredits, err := ir.queries.ListInvoiceStockReturnAllocations(ctx, pgInvoice.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list invoice return credits: %s", err.Error())
+	}
+
+	pgTransferCredits, err := ir.queries.ListInvoiceStockTransferAllocations(ctx, pgInvoice.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list invoice transfer credits: %s", err.Error())
+	}
+
+	totalAmount := pkg.PgTypeNumericToFloat64(pgInvoice.TotalAmount)
+	amountPaid := pkg.PgTypeNumericToFloat64(pgInvoice.AmountPaid)
+
+	invoice := &repository.Invoice{
+		ID:            uint32(pgInvoice.ID),
+		InvoiceNumber: pgInvoice.InvoiceNumber,
+		ResellerID:    uint32(pgInvoice.ResellerID),
+		TotalAmount:   totalAmount,
+		AmountPaid:    amountPaid,
+		Balance:       roundCents(totalAmount - amountPaid),
+		Status:        pgInvoice.Status,
+		IssueDate:     pgInvoice.IssueDate,
+		DueDate:       pgInvoice.DueDate.Time,
+		Overdue:       invoiceOverdue(pgInvoice.Status, pgInvoice.DueDate.Time),
+		UpdatedAt:     pgInvoice.UpdatedAt,
+		CreatedAt:     pgInvoice.CreatedAt,
+		User: &repository.UserShort{
+			ID:          uint32(pgInvoice.ResellerID),
+			Name:        pgInvoice.ResellerName,
+			PhoneNumber: pgInvoice.ResellerPhoneNumber,
+		},
+		Distributions:   make([]*repository.StockDistribution, len(pgDistributions)),
+		Transfers:       make([]*repository.StockTransfer, len(pgTransfers)),
+		Allocations:     make([]*repository.PaymentAllocation, len(pgAllocations)),
+		ReturnCredits:   make([]*repository.StockReturnAllocation, len(pgReturnCredits)),
+		TransferCredits: make([]*repository.StockTransferAllocation, len(pgTransferCredits)),
+	}
+
+	for i, pgDistribution := range pgDistributions {
+		invoice.Distributions[i] = &repository.StockDistribution{
+			ID:              uint32(pgDistribution.ID),
+			ResellerID:      uint32(pgDistribution.ResellerID),
+			ProductID:       uint32(pgDistribution.ProductID),
+			Quantity:        pgDistribution.Quantity,
+			UnitPrice:       pkg.PgTypeNumericToFloat64(pgDistribution.UnitPrice),
+			TotalPrice:      pkg.PgTypeNumericToFloat64(pgDistribution.TotalPrice),
+			DateDistributed: pgDistribution.DateDistributed,
+			InvoiceID:       &invoice.ID,
+			CreatedAt:       pgDistribution.CreatedAt,
+			Product: &repository.ProductShort{
+				ID:   uint32(pgDistribution.ProductID),
+				Name: pgDistribution.ProductName,
+				Unit: pgDistribution.ProductUnit,
+			},
+		}
+	}
+
+	for i, pgTransfer := range pgTransfers {
+		invoice.Transfers[i] = &repository.StockTransfer{
+			ID:             uint32(pgTransfer.ID),
+			FromResellerID: uint32(pgTransfer.FromResellerID),
+			ToResellerID:   uint32(pgInvoice.ResellerID),
+			ProductID:      uint32(pgTransfer.ProductID),
+			Quantity:       pgTransfer.Quantity,
+			TotalValue:     pkg.PgTypeNumericToFloat64(pgTransfer.TotalValue),
+			Status:         repository.STOCK_TRANSFER_COMPLETED,
+			InvoiceID:      &invoice.ID,
+			Product: &repository.ProductShort{
+				ID:   uint32(pgTransfer.ProductID),
+				Name: pgTransfer.ProductName,
+				Unit: pgTransfer.ProductUnit,
+			},
+		}
+		if pgTransfer.DateTransferred.Valid {
+			invoice.Transfers[i].DateTransferred = &pgTransfer.DateTransferred.Time
+		}
+	}
+
+	for i, pgAllocation := range pgAllocations {
+		invoice.Allocations[i] = &repository.PaymentAllocation{
+			ID:            uint32(pgAllocation.ID),
+			PaymentID:     uint32(pgAllocation.PaymentID),
+			InvoiceID:     uint32(pgAllocation.InvoiceID),
+			InvoiceNumber: pgInvoice.InvoiceNumber,
+			Amount:        pkg.PgTypeNumericToFloat64(pgAllocation.Amount),
+			AllocatedBy:   pgAllocation.AllocatedBy,
+			CreatedAt:     pgAllocation.CreatedAt,
+			Payment: &repository.Payment{
+				ID:         uint32(pgAllocation.PaymentID),
+				ResellerID: uint32(pgInvoice.ResellerID),
+				Method:     pgAllocation.Method,
+				Reference:  pgAllocation.Reference.String,
+				DatePaid:   pgAllocation.DatePaid,
+			},
+		}
+	}
+
+	for i, pgCredit := range pgReturnCredits {
+		invoice.ReturnCredits[i] = &repository.StockReturnAllocation{
+			ID:            uint32(pgCredit.ID),
+			StockReturnID: uint32(pgCredit.StockReturnID),
+			InvoiceID:     uint32(pgCredit.InvoiceID),
+			Amount:        pkg.PgTypeNumericToFloat64(pgCredit.Amount),
+			CreatedAt:     pgCredit.CreatedAt,
+			StockReturn: &repository.StockReturn{
+				ID:           uint32(pgCredit.StockReturnID),
+				ResellerID:   uint32(pgInvoice.ResellerID),
+				ProductID:    uint32(pgCredit.ProductID),
+				Quantity:     pgCredit.Quantity,
+				DateReturned: pgCredit.DateReturned,
+				Product: &repository.ProductShort{
+					ID:   uint32(pgCredit.ProductID),
+					Name: pgCredit.ProductName,
+				},
+			},
+		}
+	}
+
+	for i, pgCredit := range pgTransferCredits {
+		invoice.TransferCredits[i] = &repository.StockTransferAllocation{
+			ID:              uint32(pgCredit.ID),
+			StockTransferID: uint32(pgCredit.StockTransferID),
+			InvoiceID:       uint32(pgCredit.InvoiceID),
+			Amount:          pkg.PgTypeNumericToFloat64(pgCredit.Amount),
+			CreatedAt:       pgCredit.CreatedAt,
+			StockTransfer: &repository.StockTransfer{
+				ID:             uint32(pgCredit.StockTransferID),
+				FromResellerID: uint32(pgInvoice.ResellerID),
+				ProductID:      uint32(pgCredit.ProductID),
+				Quantity:       pgCredit.Quantity,
+				Status:         repository.STOCK_TRANSFER_COMPLETED,
+				Product: &repository.ProductShort{
+					ID:   uint32(pgCredit.ProductID),
+					Name: pgCredit.ProductName,
+				},
+			},
+		}
+		if pgCredit.DateTransferred.Valid {
+			invoice.TransferCredits[i].StockTransfer.DateTransferred = &pgCredit.DateTransferred.Time
+		}
+	}
+
+	return invoice, nil
+}
+
+func (ir *InvoiceRepository) ListInvoices(ctx context.Context, filter *repository.InvoiceFilter) ([]*repository.Invoice, *pkg.Pagination, error) {
+	listParams := generated.ListInvoicesParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		ResellerID: pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+		Open:       pgtype.Bool{Valid: false},
+		Search:     pgtype.Text{Valid: false},
+	}
+
+	countParams := generated.ListInvoicesCountParams{
+		ResellerID: pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+		Open:       pgtype.Bool{Valid: false},
+		Search:     pgtype.Text{Valid: false},
+	}
+
+	if filter.ResellerID != nil {
+		listParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+		countParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	if filter.Status != nil {
+		listParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+		countParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+	}
+
+	if filter.Open != nil {
+		listParams.Open = pgtype.Bool{Bool: *filter.Open, Valid: true}
+		countParams.Open = pgtype.Bool{Bool: *filter.Open, Valid: true}
+	}
+
+	if filter.Search != nil {
+		s := strings.ToLower(*filter.Search)
+		listParams.Search = pgtype.Text{String: "%" + s + "%", Valid: true}
+		countParams.Search = pgtype.Text{String: "%" + s + "%", Valid: true}
+	}
+
+	pgInvoices, err := ir.queries.ListInvoices(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list invoices: %s", err.Error())
+	}
+
+	totalCount, err := ir.queries.ListInvoicesCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count invoices: %s", err.Error())
+	}
+
+	invoices := make([]*repository.Invoice, len(pgInvoices))
+	for i, pgInvoice := range pgInvoices {
+		totalAmount := pkg.PgTypeNumericToFloat64(pgInvoice.TotalAmount)
+		amountPaid := pkg.PgTypeNumericToFloat64(pgInvoice.AmountPaid)
+
+		invoices[i] = &repository.Invoice{
+			ID:            uint32(pgInvoice.ID),
+			InvoiceNumber: pgInvoice.InvoiceNumber,
+			ResellerID:    uint32(pgInvoice.ResellerID),
+			TotalAmount:   totalAmount,
+			AmountPaid:    amountPaid,
+			Balance:       roundCents(totalAmount - amountPaid),
+			Status:        pgInvoice.Status,
+			IssueDate:     pgInvoice.IssueDate,
+			DueDate:       pgInvoice.DueDate.Time,
+			Overdue:       invoiceOverdue(pgInvoice.Status, pgInvoice.DueDate.Time),
+			UpdatedAt:     pgInvoice.UpdatedAt,
+			CreatedAt:     pgInvoice.CreatedAt,
+			User: &repository.UserShort{
+				ID:          uint32(pgInvoice.ResellerID),
+				Name:        pgInvoice.ResellerName,
+				PhoneNumber: pgInvoice.ResellerPhoneNumber,
+			},
+		}
+	}
+
+	return invoices, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+// createInvoice raises an invoice for the distributions and settles it with any payments the
+// reseller has not allocated yet, using the caller's transaction.
+func createInvoice(ctx context.Context, q *generated.Queries, dueDays int, resellerID int64, distributionIDs []int64, total float64, issueDate time.Time, dueDate *time.Time) (int64, error) {
+	due := issueDate.AddDate(0, 0, dueDays)
+	if dueDate != nil {
+		if dateOnly(*dueDate).Before(dateOnly(issueDate)) {
+			return 0, pkg.Errorf(pkg.INVALID_ERROR, "due_date cannot be before the invoice date")
+		}
+		due = *dueDate
+	}
+
+	number, err := q.NextInvoiceNumber(ctx)
+	if err != nil {
+		return 0, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get invoice number: %s", err.Error())
+	}
+
+	pgInvoice, err := q.CreateInvoice(ctx, generated.CreateInvoiceParams{
+		InvoiceNumber: fmt.Sprintf("%s%06d", repository.INVOICE_NUMBER_PREFIX, number),
+		ResellerID:    resellerID,
+		TotalAmount:   pkg.Float64ToPgTypeNumeric(total),
+		IssueDate:     issueDate,
+		DueDate:       pgtype.Date{Time: due, Valid: true},
+	})
+	if err != nil {
+		return 0, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create invoice: %s", err.Error())
+	}
+
+	if err := q.SetStockDistributionInvoice(ctx, generated.SetStockDistributionInvoiceParams{
+		InvoiceID: pgtype.Int8{Int64: pgInvoice.ID, Valid: true},
+		Ids:       distributionIDs,
+	}); err != nil {
+		return 0, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to link distributions to invoice: %s", err.Error())
+	}
+
+	if _, err := allocateCredit(ctx, q, resellerID); err != nil {
+		return 0, err
+	}
+
+	return pgInvoice.ID, nil
+}
+
+// allocatePayment settles the invoices chosen for a new payment, the rest of the payment
+// settles the reseller's oldest open invoices. payment.Allocations is replaced with what
+// was recorded.
+func allocatePayment(ctx context.Context, q *generated.Queries, payment *repository.Payment) error {
+	chosen := payment.Allocations
+	payment.Allocations = []*repository.PaymentAllocation{}
+
+	var allocated float64
+	for _, allocation := range chosen {
+		invoice, err := q.GetInvoiceForUpdate(ctx, int64(allocation.InvoiceID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "invoice %d not found", allocation.InvoiceID)
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get invoice: %s", err.Error())
+		}
+
+		if invoice.ResellerID != int64(payment.ResellerID) {
+			return pkg.Errorf(pkg.INVALID_ERROR, "invoice %s does not belong to the reseller", invoice.InvoiceNumber)
+		}
+
+		outstanding := roundCents(pkg.PgTypeNumericToFloat64(invoice.TotalAmount) - pkg.PgTypeNumericToFloat64(invoice.AmountPaid))
+		if allocation.Amount > outstanding {
+			return pkg.Errorf(pkg.INVALID_ERROR, "allocation of %.2f is more than the %.2f outstanding on invoice %s", allocation.Amount, outstanding, invoice.InvoiceNumber)
+		}
+
+		allocated = roundCents(allocated + allocation.Amount)
+		if allocated > payment.Amount {
+			return pkg.Errorf(pkg.INVALID_ERROR, "allocations are more than the payment amount")
+		}
+
+		recorded, err := recordAllocation(ctx, q, int64(payment.ID), invoice.ID, allocation.Amount, "ADMIN")
+		if err != nil {
+			return err
+		}
+		payment.Allocations = append(payment.Allocations, recorded)
+	}
+
+	allocations, err := allocateCredit(ctx, q, int64(payment.ResellerID))
+	if err != nil {
+		return err
+	}
+
+	for _, allocation := range allocations {
+		if allocation.PaymentID == payment.ID {
+			payment.Allocations = append(payment.Allocations, allocation)
+		}
+	}
+
+	return nil
+}
+
+// unallocatePayment takes a payment off the invoices it settled.
+func unallocatePayment(ctx context.Context, q *generated.Queries, paymentID int64) error {
+	allocations, err := q.DeletePaymentAllocations(ctx, paymentID)
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete payment allocations: %s", err.Error())
+	}
+
+	for _, allocation := range allocations {
+		if _, err := q.AddInvoicePayment(ctx, generated.AddInvoicePaymentParams{
+			Amount: pkg.Float64ToPgTypeNumeric(-pkg.PgTypeNumericToFloat64(allocation.Amount)),
+			ID:     allocation.InvoiceID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update invoice: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// allocateCredit settles the reseller's open invoices, earliest due first, with what is left
+// of their stock return and transfer credits and then their payments, oldest first. Only the
+// payment allocations are returned.
+func allocateCredit(ctx context.Context, q *generated.Queries, resellerID int64) ([]*repository.PaymentAllocation, error) {
+	// serialises allocations per reseller so an invoice and a payment recorded at the same
+	// time still settle each other
+	if err := q.LockResellerAccount(ctx, resellerID); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to lock reseller account: %s", err.Error())
+	}
+
+	invoices, err := q.ListOpenInvoicesForUpdate(ctx, resellerID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list open invoices: %s", err.Error())
+	}
+
+	if len(invoices) == 0 {
+		return nil, nil
+	}
+
+	returns, err := q.ListUnallocatedStockReturns(ctx, resellerID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list unallocated stock returns: %s", err.Error())
+	}
+
+	transfers, err := q.ListUnallocatedStockTransfers(ctx, resellerID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list unallocated stock transfers: %s", err.Error())
+	}
+
+	payments, err := q.ListUnallocatedPayments(ctx, resellerID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list unallocated payments: %s", err.Error())
+	}
+
+	next := 0
+	outstanding := roundCents(pkg.PgTypeNumericToFloat64(invoices[0].TotalAmount) - pkg.PgTypeNumericToFloat64(invoices[0].AmountPaid))
+
+	// settle spreads one credit over the open invoices from where the last one stopped
+	settle := func(credit float64, record func(invoiceID int64, amount float64) error) error {
+		remaining := roundCents(credit)
+
+		for remaining > 0 && next < len(invoices) {
+			amount := min(remaining, outstanding)
+
+			if err := record(invoices[next].ID, amount); err != nil {
+				return err
+			}
+
+			remaining = roundCents(remaining - amount)
+			outstanding = roundCents(outstanding - amount)
+
+			if outstanding <= 0 {
+				next++
+				if next < len(invoices) {
+					outstanding = roundCents(pkg.PgTypeNumericToFloat64(invoices[next].TotalAmount) - pkg.PgTypeNumericToFloat64(invoices[next].AmountPaid))
+				}
+			}
+		}
+
+		return nil
+	}
+
+	for _, stockReturn := range returns {
+		if next == len(invoices) {
+			return nil, nil
+		}
+
+		if err := settle(pkg.PgTypeNumericToFloat64(stockReturn.Unallocated), func(invoiceID int64, amount float64) error {
+			_, err := recordReturnAllocation(ctx, q, stockReturn.ID, invoiceID, amount)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, transfer := range transfers {
+		if next == len(invoices) {
+			return nil, nil
+		}
+
+		if err := settle(pkg.PgTypeNumericToFloat64(transfer.Unallocated), func(invoiceID int64, amount float64) error {
+			_, err := recordTransferAllocation(ctx, q, transfer.ID, invoiceID, amount)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	allocations := []*repository.PaymentAllocation{}
+
+	for _, payment := range payments {
+		if next == len(invoices) {
+			break
+		}
+
+		if err := settle(pkg.PgTypeNumericToFloat64(payment.Unallocated), func(invoiceID int64, amount float64) error {
+			allocation, err := recordAllocation(ctx, q, payment.ID, invoiceID, amount, "SYSTEM")
+			if err != nil {
+				return err
+			}
+			allocations = append(allocations, allocation)
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return allocations, nil
+}
+
+func recordAllocation(ctx context.Context, q *generated.Queries, paymentID, invoiceID int64, amount float64, allocatedBy string) (*repository.PaymentAllocation, error) {
+	pgAllocation, err := q.CreatePaymentAllocation(ctx, generated.CreatePaymentAllocationParams{
+		PaymentID:   paymentID,
+		InvoiceID:   invoiceID,
+		Amount:      pkg.Float64ToPgTypeNumeric(amount),
+		AllocatedBy: allocatedBy,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create payment allocation: %s", err.Error())
+	}
+
+	invoice, err := q.AddInvoicePayment(ctx, generated.AddInvoicePaymentParams{
+		Amount: pkg.Float64ToPgTypeNumeric(amount),
+		ID:     invoiceID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update invoice: %s", err.Error())
+	}
+
+	return &repository.PaymentAllocation{
+		ID:            uint32(pgAllocation.ID),
+		PaymentID:     uint32(pgAllocation.PaymentID),
+		InvoiceID:     uint32(pgAllocation.InvoiceID),
+		InvoiceNumber: invoice.InvoiceNumber,
+		Amount:        amount,
+		AllocatedBy:   pgAllocation.AllocatedBy,
+		CreatedAt:     pgAllocation.CreatedAt,
+	}, nil
+}
+
+func recordReturnAllocation(ctx context.Context, q *generated.Queries, stockReturnID, invoiceID int64, amount float64) (*repository.StockReturnAllocation, error) {
+	pgAllocation, err := q.CreateStockReturnAllocation(ctx, generated.CreateStockReturnAllocationParams{
+		StockReturnID: stockReturnID,
+		InvoiceID:     invoiceID,
+		Amount:        pkg.Float64ToPgTypeNumeric(amount),
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock return allocation: %s", err.Error())
+	}
+
+	if _, err := q.AddInvoicePayment(ctx, generated.AddInvoicePaymentParams{
+		Amount: pkg.Float64ToPgTypeNumeric(amount),
+		ID:     invoiceID,
+	}); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update invoice: %s", err.Error())
+	}
+
+	return &repository.StockReturnAllocation{
+		ID:            uint32(pgAllocation.ID),
+		StockReturnID: uint32(pgAllocation.StockReturnID),
+		InvoiceID:     uint32(pgAllocation.InvoiceID),
+		Amount:        amount,
+		CreatedAt:     pgAllocation.CreatedAt,
+	}, nil
+}
+
+func recordTransferAllocation(ctx context.Context, q *generated.Queries, stockTransferID, invoiceID int64, amount float64) (*repository.StockTransferAllocation, error) {
+	pgAllocation, err := q.CreateStockTransferAllocation(ctx, generated.CreateStockTransferAllocationParams{
+		StockTransferID: stockTransferID,
+		InvoiceID:       invoiceID,
+		Amount:          pkg.Float64ToPgTypeNumeric(amount),
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock transfer allocation: %s", err.Error())
+	}
+
+	if _, err := q.AddInvoicePayment(ctx, generated.AddInvoicePaymentParams{
+		Amount: pkg.Float64ToPgTypeNumeric(amount),
+		ID:     invoiceID,
+	}); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update invoice: %s", err.Error())
+	}
+
+	return &repository.StockTransferAllocation{
+		ID:              uint32(pgAllocation.ID),
+		StockTransferID: uint32(pgAllocation.StockTransferID),
+		InvoiceID:       uint32(pgAllocation.InvoiceID),
+		Amount:          amount,
+		CreatedAt:       pgAllocation.CreatedAt,
+	}, nil
+}
+
+func invoiceOverdue(status string, dueDate time.Time) bool {
+	return status != repository.INVOICE_PAID && dateOnly(dueDate).Before(dateOnly(time.Now()))
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.LandedCostRepository = (*LandedCostRepository)(nil)
+
+type LandedCostRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewLandedCostRepository(db *Store) *LandedCostRepository {
+	return &LandedCostRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (lr *LandedCostRepository) CreateLandedCost(ctx context.Context, cost *repository.LandedCost) (*repository.LandedCost, error) {
+	if len(cost.BatchIDs) == 0 {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "at least one batch is required")
+	}
+
+	batchIDs := make([]int64, 0, len(cost.BatchIDs))
+	seen := make(map[uint32]bool, len(cost.BatchIDs))
+	for _, batchID := range cost.BatchIDs {
+		if seen[batchID] {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "batch %d is listed more than once", batchID)
+		}
+		seen[batchID] = true
+		batchIDs = append(batchIDs, int64(batchID))
+	}
+
+	var costID int64
+
+	err := lr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		batches, err := q.ListProductBatchesForUpdate(ctx, batchIDs)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list product batches: %s", err.Error())
+		}
+
+		if len(batches) != len(batchIDs) {
+			found := make(map[int64]bool, len(batches))
+			for _, batch := range batches {
+				found[batch.ID] = true
+			}
+			for _, batchID := range batchIDs {
+				if !found[batchID] {
+					return pkg.Errorf(pkg.NOT_FOUND_ERROR, "batch %d not found", batchID)
+				}
+			}
+		}
+
+		// the cost is carried by the units still in company stock, what was already issued
+		// keeps the cost it went out at
+		inventory, err := q.ListBatchInventoryByBatchIDsForUpdate(ctx, batchIDs)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list batch inventory for update: %s", err.Error())
+		}
+
+		remaining := make(map[int64]int64, len(batches))
+		for _, layer := range inventory {
+			remaining[layer.BatchID] += layer.RemainingQuantity
+		}
+
+		for _, batch := range batches {
+			if remaining[batch.ID] == 0 {
+				return pkg.Errorf(pkg.INVALID_ERROR, "batch %s has no stock left to carry the landed cost", batch.BatchNumber)
+			}
+		}
+
+		pgCost, err := q.CreateLandedCost(ctx, generated.CreateLandedCostParams{
+			CostType:         cost.CostType,
+			Amount:           pkg.Float64ToPgTypeNumeric(cost.Amount),
+			AllocationMethod: cost.AllocationMethod,
+			Reference:        pgtype.Text{String: cost.Reference, Valid: cost.Reference != ""},
+			Note:             pgtype.Text{String: cost.Note, Valid: cost.Note != ""},
+			CreatedBy:        int64(cost.CreatedBy),
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create landed cost: %s", err.Error())
+		}
+		costID = pgCost.ID
+
+		shares := splitLandedCost(cost.Amount, cost.AllocationMethod, batches, remaining)
+		for i, batch := range batches {
+			quantity := remaining[batch.ID]
+			unitCostAdded, roundingDifference := landedUnitCost(shares[i], quantity)
+
+			if _, err := q.AddProductBatchLandedCost(ctx, generated.AddProductBatchLandedCostParams{
+				LandedUnitCost: pkg.Float64ToPgTypeNumeric(unitCostAdded),
+				ID:             batch.ID,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add landed cost to batch: %s", err.Error())
+			}
+
+			if _, err := q.CreateLandedCostAllocation(ctx, generated.CreateLandedCostAllocationParams{
+				LandedCostID:       pgCost.ID,
+				BatchID:            batch.ID,
+				Amount:             pkg.Float64ToPgTypeNumeric(shares[i]),
+				UnitCostAdded:      pkg.Float64ToPgTypeNumeric(unitCostAdded),
+				Quantity:           quantity,
+				RoundingDifference: pkg.Float64ToPgTypeNumeric(roundingDifference),
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create landed cost allocation: %s", err.Error())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lr.GetLandedCost(ctx, uint32(costID))
+}
+
+func (lr *LandedCostRepository) GetLandedCost(ctx context.Context, id uint32) (*repository.LandedCost, error) {
+	pgCost, err := lr.queries.GetLandedCost(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "landed cost not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get landed cost: %s", err.Error())
+	}
+
+	pgAllocations, err := lr.queries.ListLandedCostAllocations(ctx, pgCost.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list landed cost allocations: %s", err.Error())
+	}
+
+	cost := newLandedCost(pgCost)
+	cost.BatchCount = int64(len(pgAllocations))
+	cost.Allocations = make([]*repository.LandedCostAllocation, len(pgAllocations))
+	for i, pgAllocation := range pgAllocations {
+		cost.Allocations[i] = &repository.LandedCostAllocation{
+			ID:                 uint32(pgAllocation.ID),
+			LandedCostID:       uint32(pgAllocation.LandedCostID),
+			BatchID:            uint32(pgAllocation.BatchID),
+			Amount:             pkg.PgTypeNumericToFloat64(pgAllocation.Amount),
+			UnitCostAdded:      pkg.PgTypeNumericToFloat64(pgAllocation.UnitCostAdded),
+			Quantity:           pgAllocation.Quantity,
+			RoundingDifference: pkg.PgTypeNumericToFloat64(pgAllocation.RoundingDifference),
+			CreatedAt:          pgAllocation.CreatedAt,
+			BatchNumber:        pgAllocation.BatchNumber,
+			BatchQuantity:      pgAllocation.BatchQuantity,
+			PurchasePrice:      pkg.PgTypeNumericToFloat64(pgAllocation.PurchasePrice),
+			UnitCost:           pkg.PgTypeNumericToFloat64(pgAllocation.UnitCost),
+			Product: &repository.ProductShort{
+				ID:   uint32(pgAllocation.ProductID),
+				Name: pgAllocation.ProductName,
+				Unit: pgAllocation.ProductUnit,
+			},
+		}
+	}
+
+	return cost, nil
+}
+
+func (lr *LandedCostRepository) ListLandedCosts(ctx context.Context, filter *repository.LandedCostFilter) ([]*repository.LandedCost, *pkg.Pagination, error) {
+	listParams := generated.ListLandedCostsParams{
+		Limit:     int32(filter.Pagination.PageSize),
+		Offset:    pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		CostType:  pgtype.Text{Valid: false},
+		BatchID:   pgtype.Int8{Valid: false},
+		ProductID: pgtype.Int8{Valid: false},
+	}
+
+	countParams := generated.ListLandedCostsCountParams{
+		CostType:  pgtype.Text{Valid: false},
+		BatchID:   pgtype.Int8{Valid: false},
+		ProductID: pgtype.Int8{Valid: false},
+	}
+
+	if filter.CostType != nil {
+		listParams.CostType = pgtype.Text{String: *filter.CostType, Valid: true}
+		countParams.CostType = pgtype.Text{String: *filter.CostType, Valid: true}
+	}
+
+	if filter.BatchID != nil {
+		listParams.BatchID = pgtype.Int8{Int64: int64(*filter.BatchID), Valid: true}
+		countParams.BatchID = pgtype.Int8{Int64: int64(*filter.BatchID), Valid: true}
+	}
+
+	if filter.ProductID != nil {
+		listParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+		countParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	pgCosts, err := lr.queries.ListLandedCosts(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list landed costs: %s", err.Error())
+	}
+
+	totalCount, err := lr.queries.ListLandedCostsCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count landed costs: %s", err.Error())
+	}
+
+	costs := make([]*repository.LandedCost, len(pgCosts))
+	for i, pgCost := range pgCosts {
+		costs[i] = newLandedCost(generated.LandedCost{
+			ID:               pgCost.ID,
+			CostType:         pgCost.CostType,
+			Amount:           pgCost.Amount,
+			AllocationMethod: pgCost.AllocationMethod,
+			Reference:        pgCost.Reference,
+			Note:             pgCost.Note,
+			CreatedBy:        pgCost.CreatedBy,
+			CreatedAt:        pgCost.CreatedAt,
+		})
+		costs[i].BatchCount = pgCost.BatchCount
+	}
+
+	return costs, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+// splitLandedCost shares the amount over the batches in proportion to their remaining quantity
+// or its purchase value. Shares are rounded to cents and the last batch takes the rounding difference
+// so the shares add up to the amount.
+func splitLandedCost(amount float64, method string, batches []generated.ProductBatch, remaining map[int64]int64) []float64 {
+	weights := make([]float64, len(batches))
+	var totalWeight float64
+	for i, batch := range batches {
+		weights[i] = float64(remaining[batch.ID])
+		if method == repository.LANDED_COST_BY_VALUE {
+			weights[i] *= pkg.PgTypeNumericToFloat64(batch.PurchasePrice)
+		}
+		totalWeight += weights[i]
+	}
+
+	shares := make([]float64, len(batches))
+	var allocated float64
+	for i := range batches {
+		if i == len(batches)-1 {
+			shares[i] = roundCents(amount - allocated)
+			break
+		}
+
+		shares[i] = roundCents(amount * weights[i] / totalWeight)
+		allocated += shares[i]
+	}
+
+	return shares
+}
+
+// landedUnitCost spreads a batch's share over its quantity at the 4 decimals unit costs are kept
+// to. The rounding difference is what that leaves off the share, it is kept with the allocation
+// so share = unitCostAdded * quantity + roundingDifference.
+func landedUnitCost(share float64, quantity int64) (unitCostAdded, roundingDifference float64) {
+	unitCostAdded = math.Round(share/float64(quantity)*10000) / 10000
+	roundingDifference = math.Round((share-unitCostAdded*float64(quantity))*10000) / 10000
+
+	return unitCostAdded, roundingDifference
+}
+
+func newLandedCost(pgCost generated.LandedCost) *repository.LandedCost {
+	return &repository.LandedCost{
+		ID:               uint32(pgCost.ID),
+		CostType:         pgCost.CostType,
+		Amount:           pkg.PgTypeNumericToFloat64(pgCost.Amount),
+		AllocationMethod: pgCost.AllocationMethod,
+		Reference:        pgCost.Reference.String,
+		Note:             pgCost.Note.String,
+		CreatedBy:        uint32(pgCost.CreatedBy),
+		CreatedAt:        pgCost.CreatedAt,
+	}
+}
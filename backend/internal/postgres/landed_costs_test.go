@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+func TestSplitLandedCost(t *testing.T) {
+	batch := func(id int64, quantity int64, purchasePrice float64) generated.ProductBatch {
+		return generated.ProductBatch{
+			ID:            id,
+			Quantity:      quantity,
+			PurchasePrice: pkg.Float64ToPgTypeNumeric(purchasePrice),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		amount    float64
+		method    string
+		batches   []generated.ProductBatch
+		remaining map[int64]int64
+		want      []float64
+	}{
+		{
+			name:      "by quantity, last batch takes the rounding",
+			amount:    100,
+			method:    repository.LANDED_COST_BY_QUANTITY,
+			batches:   []generated.ProductBatch{batch(1, 10, 50), batch(2, 10, 20), batch(3, 10, 5)},
+			remaining: map[int64]int64{1: 10, 2: 10, 3: 10},
+			want:      []float64{33.33, 33.33, 33.34},
+		},
+		{
+			name:   "by quantity over the remaining stock only",
+			amount: 1000,
+			method: repository.LANDED_COST_BY_QUANTITY,
+			// received quantities are ignored, only the units still held carry the cost
+			batches:   []generated.ProductBatch{batch(1, 100, 50), batch(2, 50, 20), batch(3, 20, 5)},
+			remaining: map[int64]int64{1: 30, 2: 50, 3: 20},
+			want:      []float64{300, 500, 200},
+		},
+		{
+			name:      "by value",
+			amount:    900,
+			method:    repository.LANDED_COST_BY_VALUE,
+			batches:   []generated.ProductBatch{batch(1, 10, 50), batch(2, 20, 20), batch(3, 5, 20)},
+			remaining: map[int64]int64{1: 10, 2: 20, 3: 5},
+			want:      []float64{450, 360, 90},
+		},
+		{
+			name:      "by value with rounding",
+			amount:    250.55,
+			method:    repository.LANDED_COST_BY_VALUE,
+			batches:   []generated.ProductBatch{batch(1, 7, 13.25), batch(2, 3, 40), batch(3, 11, 9.99)},
+			remaining: map[int64]int64{1: 7, 2: 3, 3: 11},
+			want:      []float64{72.03, 93.19, 85.33},
+		},
+		{
+			name:      "single batch",
+			amount:    75.5,
+			method:    repository.LANDED_COST_BY_VALUE,
+			batches:   []generated.ProductBatch{batch(1, 40, 12.5)},
+			remaining: map[int64]int64{1: 9},
+			want:      []float64{75.5},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			shares := splitLandedCost(tc.amount, tc.method, tc.batches, tc.remaining)
+			if len(shares) != len(tc.want) {
+				t.Fatalf("got %d shares, want %d", len(shares), len(tc.want))
+			}
+
+			var total float64
+			for i, share := range shares {
+				if share != tc.want[i] {
+					t.Errorf("share %d: got %.2f, want %.2f", i, share, tc.want[i])
+				}
+				total += share
+
+				quantity := tc.remaining[tc.batches[i].ID]
+				unitCostAdded, roundingDifference := landedUnitCost(share, quantity)
+				if got := unitCostAdded*float64(quantity) + roundingDifference; math.Abs(got-share) > 1e-9 {
+					t.Errorf("share %d: unit cost %.4f * %d + rounding %.4f = %.6f, want %.2f", i, unitCostAdded, quantity, roundingDifference, got, share)
+				}
+				if math.Abs(roundingDifference) > 0.00005*float64(quantity) {
+					t.Errorf("share %d: rounding difference %.4f is more than the unit cost rounding over %d units", i, roundingDifference, quantity)
+				}
+			}
+
+			if roundCents(total) != tc.amount {
+				t.Errorf("shares add up to %.2f, want %.2f", total, tc.amount)
+			}
+		})
+	}
+}
+
+func TestLandedUnitCost(t *testing.T) {
+	tests := []struct {
+		share              float64
+		quantity           int64
+		unitCostAdded      float64
+		roundingDifference float64
+	}{
+		{share: 300, quantity: 30, unitCostAdded: 10, roundingDifference: 0},
+		{share: 33.33, quantity: 10, unitCostAdded: 3.333, roundingDifference: 0},
+		{share: 100, quantity: 3, unitCostAdded: 33.3333, roundingDifference: 0.0001},
+		{share: 85.33, quantity: 11, unitCostAdded: 7.7573, roundingDifference: -0.0003},
+		{share: 0.01, quantity: 7, unitCostAdded: 0.0014, roundingDifference: 0.0002},
+	}
+
+	for _, tc := range tests {
+		unitCostAdded, roundingDifference := landedUnitCost(tc.share, tc.quantity)
+		if unitCostAdded != tc.unitCostAdded || roundingDifference != tc.roundingDifference {
+			t.Errorf("landedUnitCost(%.2f, %d) = %.4f, %.4f, want %.4f, %.4f",
+				tc.share, tc.quantity, unitCostAdded, roundingDifference, tc.unitCostAdded, tc.roundingDifference)
+		}
+	}
+}
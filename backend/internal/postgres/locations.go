@@ -0,0 +1,505 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.LocationRepository = (*LocationRepository)(nil)
+
+type LocationRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewLocationRepository(db *Store) *LocationRepository {
+	return &LocationRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (lr *LocationRepository) CreateLocation(ctx context.Context, location *repository.Location) (*repository.Location, error) {
+	pgLocation, err := lr.queries.CreateLocation(ctx, generated.CreateLocationParams{
+		Name:    location.Name,
+		Address: pgtype.Text{String: location.Address, Valid: location.Address != ""},
+	})
+	if err != nil {
+		if pkg.PgxErrorCode(err) == pkg.UNIQUE_VIOLATION {
+			return nil, pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "location %s already exists", location.Name)
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create location: %s", err.Error())
+	}
+
+	return newLocation(pgLocation), nil
+}
+
+func (lr *LocationRepository) GetLocation(ctx context.Context, id uint32) (*repository.Location, error) {
+	pgLocation, err := lr.queries.GetLocation(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "location not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get location: %s", err.Error())
+	}
+
+	return newLocation(pgLocation), nil
+}
+
+func (lr *LocationRepository) UpdateLocation(ctx context.Context, id uint32, update *repository.LocationUpdate) (*repository.Location, error) {
+	var result *repository.Location
+
+	err := lr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		current, err := q.GetLocation(ctx, int64(id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "location not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get location: %s", err.Error())
+		}
+
+		params := generated.UpdateLocationParams{
+			Name:      pgtype.Text{Valid: false},
+			Address:   pgtype.Text{Valid: false},
+			Active:    pgtype.Bool{Valid: false},
+			IsDefault: pgtype.Bool{Valid: false},
+			ID:        current.ID,
+		}
+
+		if update.Name != nil {
+			params.Name = pgtype.Text{String: *update.Name, Valid: true}
+		}
+
+		if update.Address != nil {
+			params.Address = pgtype.Text{String: *update.Address, Valid: true}
+		}
+
+		active := current.Active
+		if update.Active != nil {
+			active = *update.Active
+			params.Active = pgtype.Bool{Bool: active, Valid: true}
+		}
+
+		isDefault := current.IsDefault
+		if update.IsDefault != nil {
+			// there is always a default, it moves by making another location the default
+			if current.IsDefault && !*update.IsDefault {
+				return pkg.Errorf(pkg.INVALID_ERROR, "make another location the default instead")
+			}
+			isDefault = *update.IsDefault
+			params.IsDefault = pgtype.Bool{Bool: isDefault, Valid: true}
+		}
+
+		if isDefault && !active {
+			return pkg.Errorf(pkg.INVALID_ERROR, "the default location must be active")
+		}
+
+		if current.Active && !active {
+			quantity, err := q.GetLocationStockQuantity(ctx, current.ID)
+			if err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get location stock quantity: %s", err.Error())
+			}
+
+			if quantity > 0 {
+				return pkg.Errorf(pkg.INVALID_ERROR, "location still holds %d units, transfer them out first", quantity)
+			}
+		}
+
+		if isDefault && !current.IsDefault {
+			if err := q.ClearDefaultLocation(ctx, current.ID); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to clear default location: %s", err.Error())
+			}
+		}
+
+		pgLocation, err := q.UpdateLocation(ctx, params)
+		if err != nil {
+			if pkg.PgxErrorCode(err) == pkg.UNIQUE_VIOLATION {
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "location %s already exists", params.Name.String)
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update location: %s", err.Error())
+		}
+
+		result = newLocation(pgLocation)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (lr *LocationRepository) ListLocations(ctx context.Context, filter *repository.LocationFilter) ([]*repository.Location, *pkg.Pagination, error) {
+	listParams := generated.ListLocationsParams{
+		Limit:  int32(filter.Pagination.PageSize),
+		Offset: pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		Active: pgtype.Bool{Valid: false},
+		Search: nil,
+	}
+
+	countParams := generated.ListLocationsCountParams{
+		Active: pgtype.Bool{Valid: false},
+		Search: nil,
+	}
+
+	if filter.Search != nil {
+		search := strings.ToLower(*filter.Search)
+		listParams.Search = "%" + search + "%"
+		countParams.Search = "%" + search + "%"
+	}
+
+	if filter.Active != nil {
+		listParams.Active = pgtype.Bool{Bool: *filter.Active, Valid: true}
+		countParams.Active = pgtype.Bool{Bool: *filter.Active, Valid: true}
+	}
+
+	pgLocations, err := lr.queries.ListLocations(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list locations: %s", err.Error())
+	}
+
+	totalCount, err := lr.queries.ListLocationsCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count locations: %s", err.Error())
+	}
+
+	locations := make([]*repository.Location, len(pgLocations))
+	for i, pgLocation := range pgLocations {
+		locations[i] = newLocation(generated.Location{
+			ID:        pgLocation.ID,
+			Name:      pgLocation.Name,
+			Address:   pgLocation.Address,
+			IsDefault: pgLocation.IsDefault,
+			Active:    pgLocation.Active,
+			CreatedAt: pgLocation.CreatedAt,
+		})
+		locations[i].TotalQuantity = pgLocation.TotalQuantity
+		locations[i].TotalValue = pkg.PgTypeNumericToFloat64(pgLocation.TotalValue)
+	}
+
+	return locations, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+func (lr *LocationRepository) TransferStock(ctx context.Context, transfer *repository.LocationTransfer) (*repository.LocationTransfer, error) {
+	var result *repository.LocationTransfer
+
+	err := lr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		fromLocation, err := resolveLocation(ctx, q, transfer.FromLocationID)
+		if err != nil {
+			return err
+		}
+
+		toLocation, err := resolveLocation(ctx, q, transfer.ToLocationID)
+		if err != nil {
+			return err
+		}
+
+		// compared once resolved since a missing id is the default location
+		if fromLocation.ID == toLocation.ID {
+			return pkg.Errorf(pkg.INVALID_ERROR, "cannot transfer stock to the same location")
+		}
+
+		batchID := pgtype.Int8{Valid: false}
+		if transfer.BatchID != nil {
+			batchID = pgtype.Int8{Int64: int64(*transfer.BatchID), Valid: true}
+		}
+
+		// the batches expiring first leave first unless a batch is chosen
+		layers, err := q.ListBatchInventoryForAdjustment(ctx, generated.ListBatchInventoryForAdjustmentParams{
+			ProductID:  int64(transfer.ProductID),
+			LocationID: fromLocation.ID,
+			BatchID:    batchID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list batch inventory for update: %s", err.Error())
+		}
+
+		var available int64
+		for _, layer := range layers {
+			available += layer.RemainingQuantity
+		}
+
+		if available < int64(transfer.Quantity) {
+			return pkg.Errorf(pkg.INVALID_ERROR, "only %d units of the product are in stock at %s", available, fromLocation.Name)
+		}
+
+		remainingToTransfer := int64(transfer.Quantity)
+		transferredBatches := []generated.CreateStockMovementBatchRecordParams{}
+		batches := []*repository.LocationTransferBatch{}
+		var totalValue float64
+
+		for _, layer := range layers {
+			if remainingToTransfer <= 0 {
+				break
+			}
+
+			takeQty := min(layer.RemainingQuantity, remainingToTransfer)
+			if takeQty == 0 {
+				continue
+			}
+
+			if _, err := q.RemoveBatchInventoryQuantity(ctx, generated.RemoveBatchInventoryQuantityParams{
+				Quantity:   takeQty,
+				BatchID:    layer.BatchID,
+				LocationID: fromLocation.ID,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove batch inventory quantity: %s", err.Error())
+			}
+
+			if _, err := q.AddBatchInventoryQuantity(ctx, generated.AddBatchInventoryQuantityParams{
+				BatchID:    layer.BatchID,
+				LocationID: toLocation.ID,
+				ProductID:  layer.ProductID,
+				Quantity:   takeQty,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add batch inventory quantity: %s", err.Error())
+			}
+
+			unitCost := pkg.PgTypeNumericToFloat64(layer.UnitCost)
+			totalValue = roundCents(totalValue + float64(takeQty)*unitCost)
+
+			transferredBatches = append(transferredBatches, generated.CreateStockMovementBatchRecordParams{
+				Owner:       "COMPANY",
+				BatchID:     layer.BatchID,
+				BatchNumber: layer.BatchNumber,
+				Quantity:    takeQty,
+				UnitCost:    layer.UnitCost,
+			})
+			batches = append(batches, &repository.LocationTransferBatch{
+				BatchID:     uint32(layer.BatchID),
+				BatchNumber: layer.BatchNumber,
+				Quantity:    takeQty,
+				UnitCost:    unitCost,
+			})
+
+			remainingToTransfer -= takeQty
+		}
+
+		unitPrice := pkg.Float64ToPgTypeNumeric(roundCents(totalValue / float64(transfer.Quantity)))
+
+		// paired movements out of one location and into the other, the company total is unchanged
+		fromMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+			ProductID:    int64(transfer.ProductID),
+			OwnerType:    "COMPANY",
+			OwnerID:      pgtype.Int8{Valid: false},
+			MovementType: "OUT",
+			Quantity:     int64(transfer.Quantity),
+			UnitPrice:    unitPrice,
+			Source:       "LOCATION_TRANSFER",
+			Note:         fmt.Sprintf("Moved to: %s", toLocation.Name),
+			LocationID:   pgtype.Int8{Int64: fromLocation.ID, Valid: true},
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+		}
+
+		toMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+			ProductID:    int64(transfer.ProductID),
+			OwnerType:    "COMPANY",
+			OwnerID:      pgtype.Int8{Valid: false},
+			MovementType: "IN",
+			Quantity:     int64(transfer.Quantity),
+			UnitPrice:    unitPrice,
+			Source:       "LOCATION_TRANSFER",
+			Note:         fmt.Sprintf("Moved from: %s", fromLocation.Name),
+			LocationID:   pgtype.Int8{Int64: toLocation.ID, Valid: true},
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+		}
+
+		for _, transferredBatch := range transferredBatches {
+			for _, movementID := range []int64{fromMovement.ID, toMovement.ID} {
+				transferredBatch.StockMovementID = movementID
+				if _, err := q.CreateStockMovementBatchRecord(ctx, transferredBatch); err != nil {
+					return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
+				}
+			}
+		}
+
+		if _, err := q.RemoveCompanyStock(ctx, generated.RemoveCompanyStockParams{
+			Quantity:   int64(transfer.Quantity),
+			ProductID:  int64(transfer.ProductID),
+			LocationID: fromLocation.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove company stock: %s", err.Error())
+		}
+
+		if _, err := q.AddCompanyStock(ctx, generated.AddCompanyStockParams{
+			ProductID:  int64(transfer.ProductID),
+			LocationID: toLocation.ID,
+			Quantity:   int64(transfer.Quantity),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add company stock: %s", err.Error())
+		}
+
+		pgTransfer, err := q.CreateLocationTransfer(ctx, generated.CreateLocationTransferParams{
+			ProductID:      int64(transfer.ProductID),
+			FromLocationID: fromLocation.ID,
+			ToLocationID:   toLocation.ID,
+			BatchID:        batchID,
+			Quantity:       transfer.Quantity,
+			TotalValue:     pkg.Float64ToPgTypeNumeric(totalValue),
+			Note:           pgtype.Text{String: transfer.Note, Valid: transfer.Note != ""},
+			FromMovementID: fromMovement.ID,
+			ToMovementID:   toMovement.ID,
+			CreatedBy:      int64(transfer.CreatedBy),
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create location transfer: %s", err.Error())
+		}
+
+		// create alert
+		if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+			Type:        "STOCK_MOVED",
+			Title:       "Stock moved between locations",
+			Description: fmt.Sprintf("From %s to %s - %d units", fromLocation.Name, toLocation.Name, transfer.Quantity),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+		}
+
+		result = newLocationTransfer(pgTransfer)
+		result.Batches = batches
+		result.FromLocation = &repository.LocationShort{ID: uint32(fromLocation.ID), Name: fromLocation.Name}
+		result.ToLocation = &repository.LocationShort{ID: uint32(toLocation.ID), Name: toLocation.Name}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (lr *LocationRepository) ListLocationTransfers(ctx context.Context, filter *repository.LocationTransferFilter) ([]*repository.LocationTransfer, *pkg.Pagination, error) {
+	listParams := generated.ListLocationTransfersParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		LocationID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+	}
+
+	countParams := generated.ListLocationTransfersCountParams{
+		LocationID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+	}
+
+	if filter.LocationID != nil {
+		listParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+		countParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+	}
+
+	if filter.ProductID != nil {
+		listParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+		countParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	pgTransfers, err := lr.queries.ListLocationTransfers(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list location transfers: %s", err.Error())
+	}
+
+	totalCount, err := lr.queries.ListLocationTransfersCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count location transfers: %s", err.Error())
+	}
+
+	transfers := make([]*repository.LocationTransfer, len(pgTransfers))
+	for i, pgTransfer := range pgTransfers {
+		transfers[i] = newLocationTransfer(generated.LocationTransfer{
+			ID:             pgTransfer.ID,
+			ProductID:      pgTransfer.ProductID,
+			FromLocationID: pgTransfer.FromLocationID,
+			ToLocationID:   pgTransfer.ToLocationID,
+			BatchID:        pgTransfer.BatchID,
+			Quantity:       pgTransfer.Quantity,
+			TotalValue:     pgTransfer.TotalValue,
+			Note:           pgTransfer.Note,
+			FromMovementID: pgTransfer.FromMovementID,
+			ToMovementID:   pgTransfer.ToMovementID,
+			CreatedBy:      pgTransfer.CreatedBy,
+			CreatedAt:      pgTransfer.CreatedAt,
+		})
+		transfers[i].Product = &repository.ProductShort{
+			ID:   uint32(pgTransfer.ProductID),
+			Name: pgTransfer.ProductName,
+			Unit: pgTransfer.ProductUnit,
+		}
+		transfers[i].FromLocation = &repository.LocationShort{
+			ID:   uint32(pgTransfer.FromLocationID),
+			Name: pgTransfer.FromLocationName,
+		}
+		transfers[i].ToLocation = &repository.LocationShort{
+			ID:   uint32(pgTransfer.ToLocationID),
+			Name: pgTransfer.ToLocationName,
+		}
+	}
+
+	return transfers, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+// resolveLocation returns the location company stock is moved into or out of, the default
+// location when id is zero. Inactive locations cannot take or give stock.
+func resolveLocation(ctx context.Context, q *generated.Queries, id uint32) (generated.Location, error) {
+	var location generated.Location
+	var err error
+	if id == 0 {
+		location, err = q.GetDefaultLocation(ctx)
+	} else {
+		location, err = q.GetLocation(ctx, int64(id))
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.Location{}, pkg.Errorf(pkg.NOT_FOUND_ERROR, "location not found")
+		}
+		return generated.Location{}, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get location: %s", err.Error())
+	}
+
+	if !location.Active {
+		return generated.Location{}, pkg.Errorf(pkg.INVALID_ERROR, "location %s is not active", location.Name)
+	}
+
+	return location, nil
+}
+
+func newLocation(pgLocation generated.Location) *repository.Location {
+	return &repository.Location{
+		ID:        uint32(pgLocation.ID),
+		Name:      pgLocation.Name,
+		Address:   pgLocation.Address.String,
+		IsDefault: pgLocation.IsDefault,
+		Active:    pgLocation.Active,
+		CreatedAt: pgLocation.CreatedAt,
+	}
+}
+
+func newLocationTransfer(pgTransfer generated.LocationTransfer) *repository.LocationTransfer {
+	transfer := &repository.LocationTransfer{
+		ID:             uint32(pgTransfer.ID),
+		ProductID:      uint32(pgTransfer.ProductID),
+		FromLocationID: uint32(pgTransfer.FromLocationID),
+		ToLocationID:   uint32(pgTransfer.ToLocationID),
+		Quantity:       pgTransfer.Quantity,
+		TotalValue:     pkg.PgTypeNumericToFloat64(pgTransfer.TotalValue),
+		Note:           pgTransfer.Note.String,
+		CreatedBy:      uint32(pgTransfer.CreatedBy),
+		CreatedAt:      pgTransfer.CreatedAt,
+	}
+
+	if pgTransfer.BatchID.Valid {
+		id := uint32(pgTransfer.BatchID.Int64)
+		transfer.BatchID = &id
+	}
+
+	return transfer
+}
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_stock_movements_created_at;
+
+DELETE FROM stock_movement_batches smb
+USING stock_movements sm
+WHERE sm.id = smb.stock_movement_id
+    AND sm.owner_type = 'RESELLER'
+    AND sm.movement_type = 'IN'
+    AND sm.source = 'PURCHASE';
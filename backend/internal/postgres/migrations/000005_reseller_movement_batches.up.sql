@@ -0,0 +1,20 @@
+-- backfill the batches behind each reseller IN movement from the matching company
+-- DISTRIBUTION movement, both are written in the same transaction
+INSERT INTO stock_movement_batches (owner, stock_movement_id, batch_id, batch_number, quantity, unit_cost, created_at)
+SELECT 'RESELLER', rsm.id, smb.batch_id, smb.batch_number, smb.quantity, smb.unit_cost, smb.created_at
+FROM stock_movements rsm
+JOIN stock_movements csm ON csm.owner_type = 'COMPANY'
+    AND csm.movement_type = 'OUT'
+    AND csm.source = 'DISTRIBUTION'
+    AND csm.product_id = rsm.product_id
+    AND csm.quantity = rsm.quantity
+    AND csm.created_at = rsm.created_at
+JOIN stock_movement_batches smb ON smb.stock_movement_id = csm.id
+WHERE rsm.owner_type = 'RESELLER'
+    AND rsm.movement_type = 'IN'
+    AND rsm.source = 'PURCHASE'
+    AND NOT EXISTS (
+        SELECT 1 FROM stock_movement_batches x WHERE x.stock_movement_id = rsm.id
+    );
+
+CREATE INDEX idx_stock_movements_created_at ON stock_movements (created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stock_distributions_date;
+DROP VIEW IF EXISTS reseller_receivables_aging;
@@ -0,0 +1,37 @@
+-- outstanding distribution value per reseller bucketed by age, payments settle the oldest distributions first
+CREATE VIEW reseller_receivables_aging AS
+WITH distributions AS (
+    SELECT
+        sd.reseller_id,
+        sd.date_distributed,
+        sd.total_price,
+        SUM(sd.total_price) OVER (PARTITION BY sd.reseller_id ORDER BY sd.date_distributed, sd.id) AS running_total
+    FROM stock_distributions sd
+),
+paid AS (
+    SELECT reseller_id, SUM(amount) AS total_paid
+    FROM payments
+    GROUP BY reseller_id
+),
+outstanding AS (
+    SELECT
+        d.reseller_id,
+        d.date_distributed,
+        GREATEST(0, LEAST(d.total_price, d.running_total - COALESCE(p.total_paid, 0))) AS amount,
+        (CURRENT_DATE - d.date_distributed::date) AS age_days
+    FROM distributions d
+    LEFT JOIN paid p ON p.reseller_id = d.reseller_id
+)
+SELECT
+    reseller_id,
+    COALESCE(SUM(amount) FILTER (WHERE age_days <= 30), 0)::numeric(14,2) AS days_0_30,
+    COALESCE(SUM(amount) FILTER (WHERE age_days BETWEEN 31 AND 60), 0)::numeric(14,2) AS days_31_60,
+    COALESCE(SUM(amount) FILTER (WHERE age_days BETWEEN 61 AND 90), 0)::numeric(14,2) AS days_61_90,
+    COALESCE(SUM(amount) FILTER (WHERE age_days > 90), 0)::numeric(14,2) AS days_over_90,
+    COALESCE(SUM(amount), 0)::numeric(14,2) AS total_outstanding,
+    MIN(date_distributed) FILTER (WHERE amount > 0) AS oldest_unpaid_date,
+    COALESCE(MAX(age_days) FILTER (WHERE amount > 0), 0)::bigint AS days_overdue
+FROM outstanding
+GROUP BY reseller_id;
+
+CREATE INDEX IF NOT EXISTS idx_stock_distributions_date ON stock_distributions (date_distributed);
@@ -0,0 +1,19 @@
+CREATE TABLE report_runs (
+    id BIGSERIAL PRIMARY KEY,
+    report_type VARCHAR(50) NOT NULL CHECK (report_type IN ('RESELLER_BALANCES', 'PAYMENTS_SUMMARY', 'STOCK_LISTING')),
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'RUNNING', 'SUCCESS', 'FAILED')),
+    parameters JSONB NOT NULL DEFAULT '{}',
+    triggered_by VARCHAR(20) NOT NULL CHECK (triggered_by IN ('SCHEDULE', 'ADMIN')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    file_name VARCHAR(255),
+    file_path TEXT,
+    content_type VARCHAR(100),
+    error TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_report_runs_report_type ON report_runs (report_type);
+CREATE INDEX idx_report_runs_status ON report_runs (status);
@@ -0,0 +1,5 @@
+DELETE FROM report_runs WHERE report_type = 'STOCK_AUDIT';
+
+ALTER TABLE report_runs DROP CONSTRAINT report_runs_report_type_check;
+ALTER TABLE report_runs ADD CONSTRAINT report_runs_report_type_check
+    CHECK (report_type IN ('RESELLER_BALANCES', 'PAYMENTS_SUMMARY', 'STOCK_LISTING'));
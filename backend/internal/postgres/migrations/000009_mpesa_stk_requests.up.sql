@@ -0,0 +1,17 @@
+CREATE TABLE mpesa_stk_requests (
+    id BIGSERIAL PRIMARY KEY,
+    reseller_id BIGINT NOT NULL REFERENCES users(id),
+    phone_number VARCHAR(20) NOT NULL,
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    merchant_request_id VARCHAR(100) NOT NULL,
+    checkout_request_id VARCHAR(100) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'SUCCESS', 'FAILED')),
+    result_code INTEGER,
+    result_desc TEXT,
+    mpesa_receipt VARCHAR(50),
+    payment_id BIGINT REFERENCES payments(id),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_mpesa_stk_requests_reseller_id ON mpesa_stk_requests (reseller_id);
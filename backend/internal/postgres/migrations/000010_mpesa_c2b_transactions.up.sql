@@ -0,0 +1,18 @@
+CREATE TABLE mpesa_c2b_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    trans_id VARCHAR(50) NOT NULL UNIQUE,
+    trans_type VARCHAR(50) NOT NULL,
+    trans_time TIMESTAMPTZ NOT NULL,
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    bill_ref_number VARCHAR(100) NOT NULL DEFAULT '',
+    msisdn VARCHAR(100) NOT NULL DEFAULT '',
+    payer_name VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'SUSPENSE' CHECK (status IN ('SUSPENSE', 'ALLOCATED')),
+    reseller_id BIGINT REFERENCES users(id),
+    payment_id BIGINT REFERENCES payments(id),
+    allocated_by VARCHAR(20) CHECK (allocated_by IN ('SYSTEM', 'ADMIN')),
+    allocated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_mpesa_c2b_transactions_status ON mpesa_c2b_transactions (status);
@@ -0,0 +1,32 @@
+DELETE FROM activities WHERE type = 'PAYMENT_REVERSED';
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE'));
+
+-- reversals are negative payments that took the original back off total_paid and added it
+-- to the balance, undo that before the reversal rows go. Invoice allocations were added
+-- after this migration and are already gone by the time it is rolled back.
+UPDATE reseller_accounts ra
+SET total_paid = ra.total_paid - r.amount,
+    balance = ra.balance + r.amount
+FROM (
+    SELECT reseller_id, SUM(amount) AS amount
+    FROM payments
+    WHERE reversal_of IS NOT NULL
+    GROUP BY reseller_id
+) r
+WHERE ra.reseller_id = r.reseller_id;
+
+UPDATE admin_stats
+SET total_payments_received = total_payments_received - COALESCE((
+    SELECT SUM(amount) FROM payments WHERE reversal_of IS NOT NULL
+), 0)
+WHERE id = 1;
+
+DELETE FROM payments WHERE reversal_of IS NOT NULL;
+
+ALTER TABLE payments DROP CONSTRAINT payments_reversal_check;
+ALTER TABLE payments
+    DROP COLUMN reversal_reason,
+    DROP COLUMN reversal_of;
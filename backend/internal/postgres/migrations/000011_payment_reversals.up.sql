@@ -0,0 +1,14 @@
+-- a reversal is a negative payment linked to the payment it cancels, the original row is kept
+ALTER TABLE payments
+    ADD COLUMN reversal_of BIGINT UNIQUE REFERENCES payments(id),
+    ADD COLUMN reversal_reason TEXT;
+
+ALTER TABLE payments ADD CONSTRAINT payments_reversal_check
+    CHECK (
+        (reversal_of IS NULL AND reversal_reason IS NULL)
+        OR (reversal_of IS NOT NULL AND reversal_reason IS NOT NULL AND amount < 0)
+    );
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED'));
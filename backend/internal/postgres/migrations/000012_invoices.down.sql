@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS payment_allocations;
+
+ALTER TABLE stock_distributions DROP COLUMN IF EXISTS invoice_id;
+
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_counter;
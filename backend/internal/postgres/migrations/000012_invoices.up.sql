@@ -0,0 +1,105 @@
+-- a single counter row keeps invoice numbers gapless, a sequence would skip numbers when the
+-- distribution creating the invoice is rolled back
+CREATE TABLE invoice_counter (
+    id INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    last_number BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE invoices (
+    id BIGSERIAL PRIMARY KEY,
+    invoice_number VARCHAR(20) NOT NULL UNIQUE,
+    reseller_id BIGINT NOT NULL REFERENCES users(id),
+    total_amount NUMERIC(12,2) NOT NULL CHECK (total_amount > 0),
+    amount_paid NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (amount_paid >= 0 AND amount_paid <= total_amount),
+    status VARCHAR(20) NOT NULL DEFAULT 'UNPAID' CHECK (status IN ('UNPAID', 'PARTIAL', 'PAID')),
+    issue_date TIMESTAMPTZ NOT NULL,
+    due_date DATE NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_invoices_reseller_id ON invoices (reseller_id);
+CREATE INDEX idx_invoices_status ON invoices (status);
+
+ALTER TABLE stock_distributions ADD COLUMN invoice_id BIGINT REFERENCES invoices(id);
+
+CREATE INDEX idx_stock_distributions_invoice_id ON stock_distributions (invoice_id);
+
+-- the part of a payment that settles an invoice, payments with no open invoices stay
+-- unallocated and settle the next invoice raised for the reseller
+CREATE TABLE payment_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL REFERENCES payments(id),
+    invoice_id BIGINT NOT NULL REFERENCES invoices(id),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    allocated_by VARCHAR(20) NOT NULL CHECK (allocated_by IN ('SYSTEM', 'ADMIN')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_payment_allocations_payment_id ON payment_allocations (payment_id);
+CREATE INDEX idx_payment_allocations_invoice_id ON payment_allocations (invoice_id);
+
+-- existing distributions get an invoice each, due 30 days after they were distributed
+INSERT INTO invoices (invoice_number, reseller_id, total_amount, issue_date, due_date, created_at)
+SELECT
+    'INV-' || LPAD((ROW_NUMBER() OVER (ORDER BY date_distributed, id))::text, 6, '0'),
+    reseller_id,
+    total_price,
+    date_distributed,
+    date_distributed::date + 30,
+    created_at
+FROM stock_distributions
+WHERE total_price > 0;
+
+UPDATE stock_distributions sd
+SET invoice_id = i.id
+FROM (
+    SELECT id, 'INV-' || LPAD((ROW_NUMBER() OVER (ORDER BY date_distributed, id))::text, 6, '0') AS invoice_number
+    FROM stock_distributions
+    WHERE total_price > 0
+) n
+JOIN invoices i ON i.invoice_number = n.invoice_number
+WHERE sd.id = n.id;
+
+INSERT INTO invoice_counter (id, last_number)
+SELECT 1, COUNT(*) FROM invoices;
+
+-- existing payments settle the oldest invoices first, reversed payments settle nothing
+WITH inv AS (
+    SELECT
+        id,
+        reseller_id,
+        total_amount,
+        SUM(total_amount) OVER (PARTITION BY reseller_id ORDER BY due_date, issue_date, id) AS running_total
+    FROM invoices
+),
+pay AS (
+    SELECT
+        p.id,
+        p.reseller_id,
+        p.amount,
+        SUM(p.amount) OVER (PARTITION BY p.reseller_id ORDER BY p.date_paid, p.id) AS running_total
+    FROM payments p
+    WHERE p.reversal_of IS NULL
+        AND NOT EXISTS (SELECT 1 FROM payments r WHERE r.reversal_of = p.id)
+)
+INSERT INTO payment_allocations (payment_id, invoice_id, amount, allocated_by, created_at)
+SELECT
+    pay.id,
+    inv.id,
+    LEAST(pay.running_total, inv.running_total) - GREATEST(pay.running_total - pay.amount, inv.running_total - inv.total_amount),
+    'SYSTEM',
+    now()
+FROM pay
+JOIN inv ON inv.reseller_id = pay.reseller_id
+WHERE LEAST(pay.running_total, inv.running_total) > GREATEST(pay.running_total - pay.amount, inv.running_total - inv.total_amount);
+
+UPDATE invoices i
+SET amount_paid = a.amount_paid,
+    status = CASE WHEN a.amount_paid >= i.total_amount THEN 'PAID' ELSE 'PARTIAL' END
+FROM (
+    SELECT invoice_id, SUM(amount) AS amount_paid
+    FROM payment_allocations
+    GROUP BY invoice_id
+) a
+WHERE a.invoice_id = i.id;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS credit_holds;
+
+ALTER TABLE reseller_accounts DROP COLUMN IF EXISTS credit_limit;
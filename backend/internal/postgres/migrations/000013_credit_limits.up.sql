@@ -0,0 +1,31 @@
+-- a NULL credit limit means the reseller can owe any amount
+ALTER TABLE reseller_accounts ADD COLUMN credit_limit NUMERIC(14,2) CHECK (credit_limit >= 0);
+
+-- distributions that would take a reseller's balance past their credit limit, held until an
+-- admin overrides or rejects them, or recorded as overridden when distributed straight away
+CREATE TABLE credit_holds (
+    id BIGSERIAL PRIMARY KEY,
+    reseller_id BIGINT NOT NULL REFERENCES users(id),
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(10,2) NOT NULL,
+    total_price NUMERIC(12,2) GENERATED ALWAYS AS (quantity * unit_price) STORED,
+    date_distributed TIMESTAMPTZ NOT NULL,
+    defer_invoice BOOLEAN NOT NULL DEFAULT false,
+    due_date DATE,
+    balance NUMERIC(14,2) NOT NULL,
+    credit_limit NUMERIC(14,2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'HELD' CHECK (status IN ('HELD', 'OVERRIDDEN', 'REJECTED')),
+    justification TEXT,
+    distribution_id BIGINT REFERENCES stock_distributions(id),
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT credit_holds_decision_check CHECK (
+        (status = 'HELD' AND justification IS NULL AND decided_at IS NULL)
+        OR (status <> 'HELD' AND justification IS NOT NULL AND decided_at IS NOT NULL)
+    ),
+    CONSTRAINT credit_holds_distribution_check CHECK ((status = 'OVERRIDDEN') = (distribution_id IS NOT NULL))
+);
+
+CREATE INDEX idx_credit_holds_status ON credit_holds (status);
+CREATE INDEX idx_credit_holds_reseller_id ON credit_holds (reseller_id);
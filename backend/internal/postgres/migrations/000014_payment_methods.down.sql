@@ -0,0 +1,7 @@
+ALTER TABLE payments DROP CONSTRAINT payments_method_fkey;
+
+-- payments made with the added methods fail the old check, so it only applies to new rows
+ALTER TABLE payments ADD CONSTRAINT payments_method_check
+    CHECK (method IN ('MPESA', 'CASH')) NOT VALID;
+
+DROP TABLE IF EXISTS payment_methods;
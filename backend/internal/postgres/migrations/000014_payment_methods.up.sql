@@ -0,0 +1,23 @@
+-- payment methods are managed by admins instead of being fixed in a check constraint.
+-- reference_pattern is a regular expression the payment reference must match when set.
+CREATE TABLE payment_methods (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE CHECK (code ~ '^[A-Z][A-Z0-9_]*$'),
+    name VARCHAR(100) NOT NULL,
+    reference_required BOOLEAN NOT NULL DEFAULT false,
+    reference_pattern TEXT,
+    active BOOLEAN NOT NULL DEFAULT true,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO payment_methods (code, name, reference_required, reference_pattern) VALUES
+    ('MPESA', 'M-Pesa', false, NULL),
+    ('CASH', 'Cash', false, NULL),
+    ('BANK_TRANSFER', 'Bank Transfer', true, NULL),
+    ('CHEQUE', 'Cheque', true, '^[0-9]{6}$'),
+    ('AIRTEL_MONEY', 'Airtel Money', true, NULL);
+
+ALTER TABLE payments DROP CONSTRAINT payments_method_check;
+ALTER TABLE payments ADD CONSTRAINT payments_method_fkey
+    FOREIGN KEY (method) REFERENCES payment_methods(code);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payment_import_rows;
+DROP TABLE IF EXISTS payment_imports;
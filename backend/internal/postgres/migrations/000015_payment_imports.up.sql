@@ -0,0 +1,38 @@
+-- statement files uploaded by admins, rows are matched and previewed before they are
+-- committed as payments
+CREATE TABLE payment_imports (
+    id BIGSERIAL PRIMARY KEY,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('MPESA', 'BANK')),
+    method VARCHAR(20) NOT NULL REFERENCES payment_methods(code),
+    file_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PREVIEW' CHECK (status IN ('PREVIEW', 'COMMITTED')),
+    uploaded_by BIGINT NOT NULL REFERENCES users(id),
+    committed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT payment_imports_committed_check CHECK ((status = 'COMMITTED') = (committed_at IS NOT NULL))
+);
+
+CREATE TABLE payment_import_rows (
+    id BIGSERIAL PRIMARY KEY,
+    import_id BIGINT NOT NULL REFERENCES payment_imports(id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    date_paid TIMESTAMPTZ NOT NULL,
+    payer_name VARCHAR(255) NOT NULL DEFAULT '',
+    payer_phone VARCHAR(100) NOT NULL DEFAULT '',
+    account_number VARCHAR(100) NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '',
+    reseller_id BIGINT REFERENCES users(id),
+    -- IMPORTED and SKIPPED are set when the import is committed
+    status VARCHAR(20) NOT NULL CHECK (status IN ('MATCHED', 'UNMATCHED', 'DUPLICATE', 'INVALID', 'IMPORTED', 'SKIPPED')),
+    note TEXT NOT NULL DEFAULT '',
+    duplicate_payment_id BIGINT REFERENCES payments(id),
+    payment_id BIGINT REFERENCES payments(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT payment_import_rows_payment_check CHECK ((status = 'IMPORTED') = (payment_id IS NOT NULL)),
+    UNIQUE (import_id, row_number)
+);
+
+CREATE INDEX idx_payment_imports_status ON payment_imports (status);
+CREATE INDEX idx_payment_import_rows_import_id ON payment_import_rows (import_id);
@@ -0,0 +1,83 @@
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_source_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_source_check
+    CHECK (source IN ('PURCHASE', 'DISTRIBUTION', 'SALE', 'RETURN'));
+
+-- stock a reseller hands back, the units go back into the batches they were issued from and
+-- the reseller is credited what they were charged for them
+CREATE TABLE stock_returns (
+    id BIGSERIAL PRIMARY KEY,
+    reseller_id BIGINT NOT NULL REFERENCES users(id),
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    batch_id BIGINT REFERENCES product_batches(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    total_value NUMERIC(12,2) NOT NULL CHECK (total_value >= 0),
+    reason TEXT NOT NULL,
+    reseller_movement_id BIGINT NOT NULL REFERENCES stock_movements(id),
+    company_movement_id BIGINT NOT NULL REFERENCES stock_movements(id),
+    date_returned TIMESTAMPTZ NOT NULL,
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_stock_returns_reseller_id ON stock_returns (reseller_id);
+CREATE INDEX idx_stock_returns_product_id ON stock_returns (product_id);
+CREATE INDEX idx_stock_returns_date_returned ON stock_returns (date_returned);
+
+-- the part of a return's credit that settles an invoice, like payment_allocations
+CREATE TABLE stock_return_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    stock_return_id BIGINT NOT NULL REFERENCES stock_returns(id),
+    invoice_id BIGINT NOT NULL REFERENCES invoices(id),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_stock_return_allocations_stock_return_id ON stock_return_allocations (stock_return_id);
+CREATE INDEX idx_stock_return_allocations_invoice_id ON stock_return_allocations (invoice_id);
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED'));
+
+-- returns credit the reseller like payments do
+CREATE OR REPLACE VIEW reseller_receivables_aging AS
+WITH distributions AS (
+    SELECT
+        sd.reseller_id,
+        sd.date_distributed,
+        sd.total_price,
+        SUM(sd.total_price) OVER (PARTITION BY sd.reseller_id ORDER BY sd.date_distributed, sd.id) AS running_total
+    FROM stock_distributions sd
+),
+credits AS (
+    SELECT reseller_id, amount
+    FROM payments
+    UNION ALL
+    SELECT reseller_id, total_value
+    FROM stock_returns
+),
+paid AS (
+    SELECT reseller_id, SUM(amount) AS total_paid
+    FROM credits
+    GROUP BY reseller_id
+),
+outstanding AS (
+    SELECT
+        d.reseller_id,
+        d.date_distributed,
+        GREATEST(0, LEAST(d.total_price, d.running_total - COALESCE(p.total_paid, 0))) AS amount,
+        (CURRENT_DATE - d.date_distributed::date) AS age_days
+    FROM distributions d
+    LEFT JOIN paid p ON p.reseller_id = d.reseller_id
+)
+SELECT
+    reseller_id,
+    COALESCE(SUM(amount) FILTER (WHERE age_days <= 30), 0)::numeric(14,2) AS days_0_30,
+    COALESCE(SUM(amount) FILTER (WHERE age_days BETWEEN 31 AND 60), 0)::numeric(14,2) AS days_31_60,
+    COALESCE(SUM(amount) FILTER (WHERE age_days BETWEEN 61 AND 90), 0)::numeric(14,2) AS days_61_90,
+    COALESCE(SUM(amount) FILTER (WHERE age_days > 90), 0)::numeric(14,2) AS days_over_90,
+    COALESCE(SUM(amount), 0)::numeric(14,2) AS total_outstanding,
+    MIN(date_distributed) FILTER (WHERE amount > 0) AS oldest_unpaid_date,
+    COALESCE(MAX(age_days) FILTER (WHERE amount > 0), 0)::bigint AS days_overdue
+FROM outstanding
+GROUP BY reseller_id;
@@ -0,0 +1,13 @@
+DELETE FROM activities WHERE type = 'STOCK_ADJUSTED';
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED'));
+
+DROP TABLE IF EXISTS stock_adjustments;
+
+DELETE FROM stock_movements WHERE source = 'ADJUSTMENT';
+
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_source_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_source_check
+    CHECK (source IN ('PURCHASE', 'DISTRIBUTION', 'SALE', 'RETURN'));
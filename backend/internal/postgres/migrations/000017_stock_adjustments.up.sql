@@ -0,0 +1,31 @@
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_source_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_source_check
+    CHECK (source IN ('PURCHASE', 'DISTRIBUTION', 'SALE', 'RETURN', 'ADJUSTMENT'));
+
+-- stock written off or corrected outside of a sale, a negative quantity takes units out of the
+-- batch layers and only count corrections can add units back. total_value is the stock's cost
+-- to the owner, signed like quantity
+CREATE TABLE stock_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    owner_type VARCHAR(20) NOT NULL CHECK (owner_type IN ('COMPANY', 'RESELLER')),
+    reseller_id BIGINT REFERENCES users(id),
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    batch_id BIGINT REFERENCES product_batches(id),
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    total_value NUMERIC(12,2) NOT NULL,
+    reason VARCHAR(30) NOT NULL CHECK (reason IN ('DAMAGED', 'EXPIRED', 'THEFT', 'COUNT_CORRECTION')),
+    note TEXT,
+    stock_movement_id BIGINT NOT NULL REFERENCES stock_movements(id),
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT stock_adjustments_owner_check CHECK ((owner_type = 'COMPANY') = (reseller_id IS NULL)),
+    CONSTRAINT stock_adjustments_increase_check CHECK (quantity < 0 OR (reason = 'COUNT_CORRECTION' AND batch_id IS NOT NULL))
+);
+
+CREATE INDEX idx_stock_adjustments_owner ON stock_adjustments (owner_type, reseller_id);
+CREATE INDEX idx_stock_adjustments_product_id ON stock_adjustments (product_id);
+CREATE INDEX idx_stock_adjustments_reason ON stock_adjustments (reason);
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED', 'STOCK_ADJUSTED'));
@@ -0,0 +1,56 @@
+CREATE OR REPLACE VIEW reseller_receivables_aging AS
+WITH distributions AS (
+    SELECT
+        sd.reseller_id,
+        sd.date_distributed,
+        sd.total_price,
+        SUM(sd.total_price) OVER (PARTITION BY sd.reseller_id ORDER BY sd.date_distributed, sd.id) AS running_total
+    FROM stock_distributions sd
+),
+credits AS (
+    SELECT reseller_id, amount
+    FROM payments
+    UNION ALL
+    SELECT reseller_id, total_value
+    FROM stock_returns
+),
+paid AS (
+    SELECT reseller_id, SUM(amount) AS total_paid
+    FROM credits
+    GROUP BY reseller_id
+),
+outstanding AS (
+    SELECT
+        d.reseller_id,
+        d.date_distributed,
+        GREATEST(0, LEAST(d.total_price, d.running_total - COALESCE(p.total_paid, 0))) AS amount,
+        (CURRENT_DATE - d.date_distributed::date) AS age_days
+    FROM distributions d
+    LEFT JOIN paid p ON p.reseller_id = d.reseller_id
+)
+SELECT
+    reseller_id,
+    COALESCE(SUM(amount) FILTER (WHERE age_days <= 30), 0)::numeric(14,2) AS days_0_30,
+    COALESCE(SUM(amount) FILTER (WHERE age_days BETWEEN 31 AND 60), 0)::numeric(14,2) AS days_31_60,
+    COALESCE(SUM(amount) FILTER (WHERE age_days BETWEEN 61 AND 90), 0)::numeric(14,2) AS days_61_90,
+    COALESCE(SUM(amount) FILTER (WHERE age_days > 90), 0)::numeric(14,2) AS days_over_90,
+    COALESCE(SUM(amount), 0)::numeric(14,2) AS total_outstanding,
+    MIN(date_distributed) FILTER (WHERE amount > 0) AS oldest_unpaid_date,
+    COALESCE(MAX(age_days) FILTER (WHERE amount > 0), 0)::bigint AS days_overdue
+FROM outstanding
+GROUP BY reseller_id;
+
+DELETE FROM activities WHERE type IN ('STOCK_TRANSFER_REQUESTED', 'STOCK_TRANSFERRED');
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED', 'STOCK_ADJUSTED'));
+
+DROP TABLE IF EXISTS stock_transfer_allocations;
+DROP TABLE IF EXISTS stock_transfers;
+
+DELETE FROM stock_movements WHERE source = 'TRANSFER';
+
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_source_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_source_check
+    CHECK (source IN ('PURCHASE', 'DISTRIBUTION', 'SALE', 'RETURN', 'ADJUSTMENT'));
@@ -0,0 +1,107 @@
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_source_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_source_check
+    CHECK (source IN ('PURCHASE', 'DISTRIBUTION', 'SALE', 'RETURN', 'ADJUSTMENT', 'TRANSFER'));
+
+-- stock one reseller hands to another. The batch layers move across at the unit cost the
+-- sender was charged, the sender is credited that value and the receiver is invoiced for it.
+-- Transfers requested by a reseller may wait as PENDING for an admin, the layers are only
+-- moved once the transfer is COMPLETED
+CREATE TABLE stock_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    from_reseller_id BIGINT NOT NULL REFERENCES users(id),
+    to_reseller_id BIGINT NOT NULL REFERENCES users(id),
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    batch_id BIGINT REFERENCES product_batches(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    total_value NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (total_value >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'COMPLETED', 'REJECTED')),
+    note TEXT,
+    rejection_reason TEXT,
+    from_movement_id BIGINT REFERENCES stock_movements(id),
+    to_movement_id BIGINT REFERENCES stock_movements(id),
+    invoice_id BIGINT REFERENCES invoices(id),
+    requested_by BIGINT NOT NULL REFERENCES users(id),
+    decided_by BIGINT REFERENCES users(id),
+    decided_at TIMESTAMPTZ,
+    date_transferred TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT stock_transfers_resellers_check CHECK (from_reseller_id <> to_reseller_id),
+    CONSTRAINT stock_transfers_completed_check CHECK ((status = 'COMPLETED') = (date_transferred IS NOT NULL))
+);
+
+CREATE INDEX idx_stock_transfers_from_reseller_id ON stock_transfers (from_reseller_id);
+CREATE INDEX idx_stock_transfers_to_reseller_id ON stock_transfers (to_reseller_id);
+CREATE INDEX idx_stock_transfers_status ON stock_transfers (status);
+
+-- the part of a transfer's credit that settles one of the sender's invoices, like
+-- stock_return_allocations
+CREATE TABLE stock_transfer_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    stock_transfer_id BIGINT NOT NULL REFERENCES stock_transfers(id),
+    invoice_id BIGINT NOT NULL REFERENCES invoices(id),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_stock_transfer_allocations_stock_transfer_id ON stock_transfer_allocations (stock_transfer_id);
+CREATE INDEX idx_stock_transfer_allocations_invoice_id ON stock_transfer_allocations (invoice_id);
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED', 'STOCK_ADJUSTED', 'STOCK_TRANSFER_REQUESTED', 'STOCK_TRANSFERRED'));
+
+-- stock received by transfer is owed like a distribution, stock sent credits the sender like
+-- a return
+CREATE OR REPLACE VIEW reseller_receivables_aging AS
+WITH debits AS (
+    SELECT sd.id, sd.reseller_id, sd.date_distributed, sd.total_price
+    FROM stock_distributions sd
+    UNION ALL
+    SELECT st.id, st.to_reseller_id, st.date_transferred, st.total_value
+    FROM stock_transfers st
+    WHERE st.status = 'COMPLETED'
+),
+distributions AS (
+    SELECT
+        d.reseller_id,
+        d.date_distributed,
+        d.total_price,
+        SUM(d.total_price) OVER (PARTITION BY d.reseller_id ORDER BY d.date_distributed, d.id) AS running_total
+    FROM debits d
+),
+credits AS (
+    SELECT reseller_id, amount
+    FROM payments
+    UNION ALL
+    SELECT reseller_id, total_value
+    FROM stock_returns
+    UNION ALL
+    SELECT from_reseller_id, total_value
+    FROM stock_transfers
+    WHERE status = 'COMPLETED'
+),
+paid AS (
+    SELECT reseller_id, SUM(amount) AS total_paid
+    FROM credits
+    GROUP BY reseller_id
+),
+outstanding AS (
+    SELECT
+        d.reseller_id,
+        d.date_distributed,
+        GREATEST(0, LEAST(d.total_price, d.running_total - COALESCE(p.total_paid, 0))) AS amount,
+        (CURRENT_DATE - d.date_distributed::date) AS age_days
+    FROM distributions d
+    LEFT JOIN paid p ON p.reseller_id = d.reseller_id
+)
+SELECT
+    reseller_id,
+    COALESCE(SUM(amount) FILTER (WHERE age_days <= 30), 0)::numeric(14,2) AS days_0_30,
+    COALESCE(SUM(amount) FILTER (WHERE age_days BETWEEN 31 AND 60), 0)::numeric(14,2) AS days_31_60,
+    COALESCE(SUM(amount) FILTER (WHERE age_days BETWEEN 61 AND 90), 0)::numeric(14,2) AS days_61_90,
+    COALESCE(SUM(amount) FILTER (WHERE age_days > 90), 0)::numeric(14,2) AS days_over_90,
+    COALESCE(SUM(amount), 0)::numeric(14,2) AS total_outstanding,
+    MIN(date_distributed) FILTER (WHERE amount > 0) AS oldest_unpaid_date,
+    COALESCE(MAX(age_days) FILTER (WHERE amount > 0), 0)::bigint AS days_overdue
+FROM outstanding
+GROUP BY reseller_id;
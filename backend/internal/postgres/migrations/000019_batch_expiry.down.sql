@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_product_batches_expiry_date;
+
+ALTER TABLE product_batches DROP COLUMN IF EXISTS expiry_date;
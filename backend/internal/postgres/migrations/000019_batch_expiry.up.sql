@@ -0,0 +1,4 @@
+-- batches without an expiry date never expire and are allocated after every dated batch
+ALTER TABLE product_batches ADD COLUMN expiry_date DATE;
+
+CREATE INDEX idx_product_batches_expiry_date ON product_batches(expiry_date) WHERE expiry_date IS NOT NULL;
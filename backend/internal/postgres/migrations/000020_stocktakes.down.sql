@@ -0,0 +1,8 @@
+DELETE FROM activities WHERE type = 'STOCKTAKE_POSTED';
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED', 'STOCK_ADJUSTED', 'STOCK_TRANSFER_REQUESTED', 'STOCK_TRANSFERRED'));
+
+DROP TABLE IF EXISTS stocktake_lines;
+DROP TABLE IF EXISTS stocktakes;
@@ -0,0 +1,42 @@
+-- a physical count of company stock or of one reseller's stock. Opening a session snapshots
+-- the owner's stock into lines, counts are entered per product and posting turns the
+-- variances into stock adjustments
+CREATE TABLE stocktakes (
+    id BIGSERIAL PRIMARY KEY,
+    owner_type VARCHAR(20) NOT NULL CHECK (owner_type IN ('COMPANY', 'RESELLER')),
+    reseller_id BIGINT REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'POSTED', 'CANCELLED')),
+    note TEXT,
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    closed_by BIGINT REFERENCES users(id),
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT stocktakes_owner_check CHECK ((owner_type = 'RESELLER') = (reseller_id IS NOT NULL)),
+    CONSTRAINT stocktakes_closed_check CHECK ((status = 'OPEN') = (closed_at IS NULL))
+);
+
+-- one open count per owner at a time
+CREATE UNIQUE INDEX idx_stocktakes_open_owner ON stocktakes (owner_type, COALESCE(reseller_id, 0)) WHERE status = 'OPEN';
+CREATE INDEX idx_stocktakes_status ON stocktakes (status);
+
+-- unit_cost is the average batch cost of the stock when the session was opened, used to
+-- value the variance before it is posted
+CREATE TABLE stocktake_lines (
+    id BIGSERIAL PRIMARY KEY,
+    stocktake_id BIGINT NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    expected_quantity BIGINT NOT NULL,
+    counted_quantity BIGINT CHECK (counted_quantity >= 0),
+    unit_cost NUMERIC(10,2) NOT NULL DEFAULT 0,
+    counted_at TIMESTAMPTZ,
+    adjustment_id BIGINT REFERENCES stock_adjustments(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT stocktake_lines_counted_check CHECK ((counted_quantity IS NULL) = (counted_at IS NULL)),
+    UNIQUE (stocktake_id, product_id)
+);
+
+CREATE INDEX idx_stocktake_lines_stocktake_id ON stocktake_lines (stocktake_id);
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED', 'STOCK_ADJUSTED', 'STOCK_TRANSFER_REQUESTED', 'STOCK_TRANSFERRED', 'STOCKTAKE_POSTED'));
@@ -0,0 +1,65 @@
+DELETE FROM activities WHERE type = 'STOCK_MOVED';
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED', 'STOCK_ADJUSTED', 'STOCK_TRANSFER_REQUESTED', 'STOCK_TRANSFERRED', 'STOCKTAKE_POSTED'));
+
+DROP TABLE IF EXISTS location_transfers;
+
+DELETE FROM stock_movement_batches
+WHERE stock_movement_id IN (SELECT id FROM stock_movements WHERE source = 'LOCATION_TRANSFER');
+DELETE FROM stock_movements WHERE source = 'LOCATION_TRANSFER';
+
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_source_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_source_check
+    CHECK (source IN ('PURCHASE', 'DISTRIBUTION', 'SALE', 'RETURN', 'ADJUSTMENT', 'TRANSFER'));
+
+-- the locations are folded back into one company-wide stock
+DROP INDEX idx_stocktakes_open_owner;
+DELETE FROM stocktakes s
+WHERE s.status = 'OPEN'
+    AND s.owner_type = 'COMPANY'
+    AND s.id <> (SELECT MIN(id) FROM stocktakes WHERE status = 'OPEN' AND owner_type = 'COMPANY');
+CREATE UNIQUE INDEX idx_stocktakes_open_owner ON stocktakes (owner_type, COALESCE(reseller_id, 0)) WHERE status = 'OPEN';
+
+ALTER TABLE stocktakes DROP CONSTRAINT stocktakes_location_check;
+ALTER TABLE stocktakes DROP COLUMN location_id;
+
+ALTER TABLE stock_adjustments DROP CONSTRAINT stock_adjustments_location_check;
+ALTER TABLE stock_adjustments DROP COLUMN location_id;
+
+ALTER TABLE stock_returns DROP COLUMN location_id;
+ALTER TABLE credit_holds DROP COLUMN location_id;
+ALTER TABLE stock_distributions DROP COLUMN location_id;
+
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_location_check;
+ALTER TABLE stock_movements DROP COLUMN location_id;
+
+CREATE TABLE company_stock_merged AS
+SELECT product_id, SUM(quantity)::bigint AS quantity
+FROM company_stock
+GROUP BY product_id;
+
+DELETE FROM company_stock;
+ALTER TABLE company_stock DROP CONSTRAINT company_stock_pkey;
+ALTER TABLE company_stock DROP COLUMN location_id;
+ALTER TABLE company_stock ADD PRIMARY KEY (product_id);
+INSERT INTO company_stock (product_id, quantity) SELECT product_id, quantity FROM company_stock_merged;
+DROP TABLE company_stock_merged;
+
+CREATE TABLE batch_inventory_merged AS
+SELECT batch_id, product_id, SUM(remaining_quantity)::bigint AS remaining_quantity, MIN(created_at) AS created_at
+FROM batch_inventory
+GROUP BY batch_id, product_id;
+
+DELETE FROM batch_inventory;
+ALTER TABLE batch_inventory DROP CONSTRAINT batch_inventory_pkey;
+ALTER TABLE batch_inventory DROP COLUMN location_id;
+ALTER TABLE batch_inventory ADD PRIMARY KEY (batch_id);
+INSERT INTO batch_inventory (batch_id, product_id, remaining_quantity, created_at)
+SELECT batch_id, product_id, remaining_quantity, created_at FROM batch_inventory_merged;
+DROP TABLE batch_inventory_merged;
+
+ALTER TABLE product_batches DROP COLUMN location_id;
+
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,103 @@
+-- places company stock is held. Batches are received into a location, company_stock and
+-- batch_inventory are tracked per location and the default location is used whenever a
+-- location is not given
+CREATE TABLE locations (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    address TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT locations_default_check CHECK (NOT is_default OR active)
+);
+
+CREATE UNIQUE INDEX idx_locations_default ON locations (is_default) WHERE is_default;
+
+INSERT INTO locations (name, is_default) VALUES ('Main Store', true);
+
+ALTER TABLE product_batches ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE product_batches SET location_id = (SELECT id FROM locations WHERE is_default);
+ALTER TABLE product_batches ALTER COLUMN location_id SET NOT NULL;
+
+ALTER TABLE batch_inventory ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE batch_inventory SET location_id = (SELECT id FROM locations WHERE is_default);
+ALTER TABLE batch_inventory ALTER COLUMN location_id SET NOT NULL;
+ALTER TABLE batch_inventory DROP CONSTRAINT batch_inventory_pkey;
+ALTER TABLE batch_inventory ADD PRIMARY KEY (batch_id, location_id);
+
+CREATE INDEX idx_batch_inventory_location_id ON batch_inventory (location_id);
+
+ALTER TABLE company_stock ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE company_stock SET location_id = (SELECT id FROM locations WHERE is_default);
+ALTER TABLE company_stock ALTER COLUMN location_id SET NOT NULL;
+ALTER TABLE company_stock DROP CONSTRAINT company_stock_pkey;
+ALTER TABLE company_stock ADD PRIMARY KEY (product_id, location_id);
+
+CREATE INDEX idx_company_stock_location_id ON company_stock (location_id);
+
+-- company movements record the location the units went into or out of
+ALTER TABLE stock_movements ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE stock_movements SET location_id = (SELECT id FROM locations WHERE is_default) WHERE owner_type = 'COMPANY';
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_location_check CHECK ((owner_type = 'COMPANY') = (location_id IS NOT NULL));
+
+CREATE INDEX idx_stock_movements_location_id ON stock_movements (location_id);
+
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_source_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_source_check
+    CHECK (source IN ('PURCHASE', 'DISTRIBUTION', 'SALE', 'RETURN', 'ADJUSTMENT', 'TRANSFER', 'LOCATION_TRANSFER'));
+
+-- the location a distribution ships from, a held distribution keeps it for when it is
+-- overridden
+ALTER TABLE stock_distributions ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE stock_distributions SET location_id = (SELECT id FROM locations WHERE is_default);
+ALTER TABLE stock_distributions ALTER COLUMN location_id SET NOT NULL;
+
+CREATE INDEX idx_stock_distributions_location_id ON stock_distributions (location_id);
+
+ALTER TABLE credit_holds ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE credit_holds SET location_id = (SELECT id FROM locations WHERE is_default);
+ALTER TABLE credit_holds ALTER COLUMN location_id SET NOT NULL;
+
+-- the location returned stock goes back into
+ALTER TABLE stock_returns ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE stock_returns SET location_id = (SELECT id FROM locations WHERE is_default);
+ALTER TABLE stock_returns ALTER COLUMN location_id SET NOT NULL;
+
+ALTER TABLE stock_adjustments ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE stock_adjustments SET location_id = (SELECT id FROM locations WHERE is_default) WHERE owner_type = 'COMPANY';
+ALTER TABLE stock_adjustments ADD CONSTRAINT stock_adjustments_location_check CHECK ((owner_type = 'COMPANY') = (location_id IS NOT NULL));
+
+-- company stocktakes count one location
+ALTER TABLE stocktakes ADD COLUMN location_id BIGINT REFERENCES locations(id);
+UPDATE stocktakes SET location_id = (SELECT id FROM locations WHERE is_default) WHERE owner_type = 'COMPANY';
+ALTER TABLE stocktakes ADD CONSTRAINT stocktakes_location_check CHECK ((owner_type = 'COMPANY') = (location_id IS NOT NULL));
+
+DROP INDEX idx_stocktakes_open_owner;
+CREATE UNIQUE INDEX idx_stocktakes_open_owner ON stocktakes (owner_type, COALESCE(reseller_id, 0), COALESCE(location_id, 0)) WHERE status = 'OPEN';
+
+-- company stock moved from one location to another. The batch layers move across unchanged,
+-- from_movement_id takes them out of the source and to_movement_id puts them in the
+-- destination
+CREATE TABLE location_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    from_location_id BIGINT NOT NULL REFERENCES locations(id),
+    to_location_id BIGINT NOT NULL REFERENCES locations(id),
+    batch_id BIGINT REFERENCES product_batches(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    total_value NUMERIC(12,2) NOT NULL CHECK (total_value >= 0),
+    note TEXT,
+    from_movement_id BIGINT NOT NULL REFERENCES stock_movements(id),
+    to_movement_id BIGINT NOT NULL REFERENCES stock_movements(id),
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT location_transfers_locations_check CHECK (from_location_id <> to_location_id)
+);
+
+CREATE INDEX idx_location_transfers_product_id ON location_transfers (product_id);
+CREATE INDEX idx_location_transfers_from_location_id ON location_transfers (from_location_id);
+CREATE INDEX idx_location_transfers_to_location_id ON location_transfers (to_location_id);
+
+ALTER TABLE activities DROP CONSTRAINT activities_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_type_check
+    CHECK (type IN ('PAYMENT_RECEIVED', 'STOCK_DISTRIBUTED', 'STOCK_RECEIVED', 'RESELLER_SALE', 'PAYMENT_REVERSED', 'STOCK_RETURNED', 'STOCK_ADJUSTED', 'STOCK_TRANSFER_REQUESTED', 'STOCK_TRANSFERRED', 'STOCKTAKE_POSTED', 'STOCK_MOVED'));
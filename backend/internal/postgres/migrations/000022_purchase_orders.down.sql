@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_product_batches_purchase_order_line_id;
+ALTER TABLE product_batches DROP COLUMN IF EXISTS purchase_order_line_id;
+
+DROP TABLE IF EXISTS purchase_order_lines;
+DROP TABLE IF EXISTS purchase_orders;
+DROP TABLE IF EXISTS suppliers;
@@ -0,0 +1,51 @@
+-- suppliers company stock is bought from
+CREATE TABLE suppliers (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    contact_name VARCHAR(100),
+    phone_number VARCHAR(20),
+    email VARCHAR(100),
+    address TEXT,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- an order placed with a supplier. Lines can only be changed while the order is a draft, once
+-- sent it is received line by line and moves to PARTIALLY_RECEIVED then RECEIVED
+CREATE TABLE purchase_orders (
+    id BIGSERIAL PRIMARY KEY,
+    supplier_id BIGINT NOT NULL REFERENCES suppliers(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'DRAFT' CHECK (status IN ('DRAFT', 'SENT', 'PARTIALLY_RECEIVED', 'RECEIVED')),
+    expected_date DATE,
+    note TEXT,
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    sent_at TIMESTAMPTZ,
+    received_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT purchase_orders_sent_check CHECK ((status = 'DRAFT') = (sent_at IS NULL)),
+    CONSTRAINT purchase_orders_received_check CHECK ((status = 'RECEIVED') = (received_at IS NOT NULL))
+);
+
+CREATE INDEX idx_purchase_orders_supplier_id ON purchase_orders (supplier_id);
+CREATE INDEX idx_purchase_orders_status ON purchase_orders (status);
+
+-- expected_price is the unit price agreed on the order, the price actually paid is the
+-- purchase_price of the batches received against the line
+CREATE TABLE purchase_order_lines (
+    id BIGSERIAL PRIMARY KEY,
+    purchase_order_id BIGINT NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    quantity_ordered BIGINT NOT NULL CHECK (quantity_ordered > 0),
+    quantity_received BIGINT NOT NULL DEFAULT 0 CHECK (quantity_received >= 0),
+    expected_price NUMERIC(10,2) NOT NULL CHECK (expected_price > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT purchase_order_lines_received_check CHECK (quantity_received <= quantity_ordered),
+    UNIQUE (purchase_order_id, product_id)
+);
+
+CREATE INDEX idx_purchase_order_lines_purchase_order_id ON purchase_order_lines (purchase_order_id);
+
+-- batches received against an order line, batches added directly have none
+ALTER TABLE product_batches ADD COLUMN purchase_order_line_id BIGINT REFERENCES purchase_order_lines(id);
+
+CREATE INDEX idx_product_batches_purchase_order_line_id ON product_batches (purchase_order_line_id);
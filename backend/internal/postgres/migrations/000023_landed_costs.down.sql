@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS landed_cost_allocations;
+DROP TABLE IF EXISTS landed_costs;
+
+ALTER TABLE product_batches DROP COLUMN IF EXISTS unit_cost;
+ALTER TABLE product_batches DROP COLUMN IF EXISTS landed_unit_cost;
@@ -0,0 +1,29 @@
+-- landed costs (freight, clearing, duty) spread over the batches they were paid for. The
+-- purchase_price is what the supplier was paid and is kept as is, unit_cost is the cost used
+-- to value the batch once its share of the landed costs is added.
+ALTER TABLE product_batches ADD COLUMN landed_unit_cost NUMERIC(12,4) NOT NULL DEFAULT 0 CHECK (landed_unit_cost >= 0);
+ALTER TABLE product_batches ADD COLUMN unit_cost NUMERIC(12,4) GENERATED ALWAYS AS (purchase_price + landed_unit_cost) STORED;
+
+CREATE TABLE landed_costs (
+    id BIGSERIAL PRIMARY KEY,
+    cost_type VARCHAR(20) NOT NULL CHECK (cost_type IN ('FREIGHT', 'CLEARING', 'DUTY', 'OTHER')),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    allocation_method VARCHAR(20) NOT NULL CHECK (allocation_method IN ('QUANTITY', 'VALUE')),
+    reference VARCHAR(100),
+    note TEXT,
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- the share of a landed cost given to one batch, unit_cost_added is amount over the batch quantity
+CREATE TABLE landed_cost_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    landed_cost_id BIGINT NOT NULL REFERENCES landed_costs(id) ON DELETE CASCADE,
+    batch_id BIGINT NOT NULL REFERENCES product_batches(id),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+    unit_cost_added NUMERIC(12,4) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (landed_cost_id, batch_id)
+);
+
+CREATE INDEX idx_landed_cost_allocations_batch_id ON landed_cost_allocations (batch_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stock_movements_movement_date;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS movement_date;
@@ -0,0 +1,37 @@
+-- movement_date is the business date of the document behind the movement (date_distributed,
+-- date_sold, date_returned) so reports built on movements land in the same period as the
+-- documents, created_at stays the time the row was written.
+ALTER TABLE stock_movements ADD COLUMN movement_date TIMESTAMPTZ;
+
+UPDATE stock_movements SET movement_date = created_at;
+
+-- distributions and sales were written in the same transaction as their movements so they
+-- share the transaction timestamp
+UPDATE stock_movements sm
+SET movement_date = sd.date_distributed
+FROM stock_distributions sd
+WHERE sm.created_at = sd.created_at
+    AND sm.product_id = sd.product_id
+    AND (
+        (sm.owner_type = 'COMPANY' AND sm.source = 'DISTRIBUTION')
+        OR (sm.owner_type = 'RESELLER' AND sm.owner_id = sd.reseller_id AND sm.source = 'PURCHASE')
+    );
+
+UPDATE stock_movements sm
+SET movement_date = rs.date_sold
+FROM reseller_sales rs
+WHERE sm.created_at = rs.created_at
+    AND sm.product_id = rs.product_id
+    AND sm.owner_type = 'RESELLER'
+    AND sm.owner_id = rs.reseller_id
+    AND sm.source = 'SALE';
+
+UPDATE stock_movements sm
+SET movement_date = sr.date_returned
+FROM stock_returns sr
+WHERE sm.id IN (sr.company_movement_id, sr.reseller_movement_id);
+
+ALTER TABLE stock_movements ALTER COLUMN movement_date SET NOT NULL;
+ALTER TABLE stock_movements ALTER COLUMN movement_date SET DEFAULT now();
+
+CREATE INDEX idx_stock_movements_movement_date ON stock_movements (movement_date);
@@ -0,0 +1,16 @@
+-- closed orders go back to what they had received
+UPDATE purchase_orders po
+SET status = CASE
+    WHEN EXISTS (SELECT 1 FROM purchase_order_lines pol WHERE pol.purchase_order_id = po.id AND pol.quantity_received > 0)
+    THEN 'PARTIALLY_RECEIVED'
+    ELSE 'SENT'
+END
+WHERE po.status = 'CLOSED';
+
+ALTER TABLE purchase_orders DROP CONSTRAINT IF EXISTS purchase_orders_closed_check;
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS close_reason;
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS closed_at;
+
+ALTER TABLE purchase_orders DROP CONSTRAINT purchase_orders_status_check;
+ALTER TABLE purchase_orders ADD CONSTRAINT purchase_orders_status_check
+    CHECK (status IN ('DRAFT', 'SENT', 'PARTIALLY_RECEIVED', 'RECEIVED'));
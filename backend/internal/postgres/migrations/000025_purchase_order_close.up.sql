@@ -0,0 +1,9 @@
+-- an order the supplier will not fill is closed short, the lines keep what was received and
+-- the rest is no longer expected
+ALTER TABLE purchase_orders DROP CONSTRAINT purchase_orders_status_check;
+ALTER TABLE purchase_orders ADD CONSTRAINT purchase_orders_status_check
+    CHECK (status IN ('DRAFT', 'SENT', 'PARTIALLY_RECEIVED', 'RECEIVED', 'CLOSED'));
+
+ALTER TABLE purchase_orders ADD COLUMN closed_at TIMESTAMPTZ;
+ALTER TABLE purchase_orders ADD COLUMN close_reason TEXT;
+ALTER TABLE purchase_orders ADD CONSTRAINT purchase_orders_closed_check CHECK ((status = 'CLOSED') = (closed_at IS NOT NULL));
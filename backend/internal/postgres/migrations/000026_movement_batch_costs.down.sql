@@ -0,0 +1,5 @@
+ALTER TABLE landed_cost_allocations DROP CONSTRAINT IF EXISTS landed_cost_allocations_quantity_check;
+ALTER TABLE landed_cost_allocations DROP COLUMN IF EXISTS rounding_difference;
+ALTER TABLE landed_cost_allocations DROP COLUMN IF EXISTS quantity;
+
+ALTER TABLE stock_movement_batches DROP COLUMN IF EXISTS batch_unit_cost;
@@ -0,0 +1,31 @@
+-- the batch unit cost when the stock moved, so landed costs added later do not change the
+-- cost of stock already issued. Past rows are costed with the landed costs allocated before
+-- them
+ALTER TABLE stock_movement_batches ADD COLUMN batch_unit_cost NUMERIC(12,4);
+
+UPDATE stock_movement_batches smb
+SET batch_unit_cost = pb.purchase_price + COALESCE((
+    SELECT SUM(lca.unit_cost_added)
+    FROM landed_cost_allocations lca
+    WHERE lca.batch_id = smb.batch_id
+      AND lca.created_at <= smb.created_at
+), 0)
+FROM product_batches pb
+WHERE pb.id = smb.batch_id;
+
+ALTER TABLE stock_movement_batches ALTER COLUMN batch_unit_cost SET NOT NULL;
+
+-- landed costs are spread over the units still in company stock. quantity is the units the
+-- share was spread over and rounding_difference what the 4 decimal unit_cost_added leaves
+-- off it, so amount = unit_cost_added * quantity + rounding_difference
+ALTER TABLE landed_cost_allocations ADD COLUMN quantity BIGINT;
+ALTER TABLE landed_cost_allocations ADD COLUMN rounding_difference NUMERIC(12,4) NOT NULL DEFAULT 0;
+
+UPDATE landed_cost_allocations lca
+SET quantity = pb.quantity,
+    rounding_difference = lca.amount - lca.unit_cost_added * pb.quantity
+FROM product_batches pb
+WHERE pb.id = lca.batch_id;
+
+ALTER TABLE landed_cost_allocations ALTER COLUMN quantity SET NOT NULL;
+ALTER TABLE landed_cost_allocations ADD CONSTRAINT landed_cost_allocations_quantity_check CHECK (quantity > 0);
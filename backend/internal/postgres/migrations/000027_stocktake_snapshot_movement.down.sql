@@ -0,0 +1 @@
+ALTER TABLE stocktakes DROP COLUMN IF EXISTS snapshot_movement_id;
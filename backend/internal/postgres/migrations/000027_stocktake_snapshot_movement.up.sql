@@ -0,0 +1,12 @@
+-- the last stock movement in the snapshot. Movements after it are netted off the count, ids
+-- are compared as a movement committed after the snapshot can have been created before it
+ALTER TABLE stocktakes ADD COLUMN snapshot_movement_id BIGINT;
+
+UPDATE stocktakes s
+SET snapshot_movement_id = COALESCE((
+    SELECT MAX(sm.id)
+    FROM stock_movements sm
+    WHERE sm.created_at <= s.created_at
+), 0);
+
+ALTER TABLE stocktakes ALTER COLUMN snapshot_movement_id SET NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE reseller_batch_inventory DROP COLUMN IF EXISTS batch_unit_cost;
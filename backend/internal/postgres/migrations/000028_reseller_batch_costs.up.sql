@@ -0,0 +1,16 @@
+-- the company's cost of the units when they were distributed, kept on the layer through
+-- transfers so returned units go back into company stock at the cost they left it at. Past
+-- layers are costed with the landed costs allocated before them
+ALTER TABLE reseller_batch_inventory ADD COLUMN batch_unit_cost NUMERIC(12,4);
+
+UPDATE reseller_batch_inventory rbi
+SET batch_unit_cost = pb.purchase_price + COALESCE((
+    SELECT SUM(lca.unit_cost_added)
+    FROM landed_cost_allocations lca
+    WHERE lca.batch_id = rbi.source_batch_id
+      AND lca.created_at <= rbi.created_at
+), 0)
+FROM product_batches pb
+WHERE pb.id = rbi.source_batch_id;
+
+ALTER TABLE reseller_batch_inventory ALTER COLUMN batch_unit_cost SET NOT NULL;
@@ -0,0 +1,422 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.MpesaRepository = (*MpesaRepository)(nil)
+
+type MpesaRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewMpesaRepository(db *Store) *MpesaRepository {
+	return &MpesaRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (mr *MpesaRepository) CreateStkRequest(ctx context.Context, request *repository.MpesaStkRequest) (*repository.MpesaStkRequest, error) {
+	pgRequest, err := mr.queries.CreateMpesaStkRequest(ctx, generated.CreateMpesaStkRequestParams{
+		ResellerID:        int64(request.ResellerID),
+		PhoneNumber:       request.PhoneNumber,
+		Amount:            pkg.Float64ToPgTypeNumeric(request.Amount),
+		MerchantRequestID: request.MerchantRequestID,
+		CheckoutRequestID: request.CheckoutRequestID,
+	})
+	if err != nil {
+		if pkg.PgxErrorCode(err) == pkg.UNIQUE_VIOLATION {
+			return nil, pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "stk request %s already exists", request.CheckoutRequestID)
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stk request: %s", err.Error())
+	}
+
+	return convertGeneratedMpesaStkRequest(pgRequest), nil
+}
+
+func (mr *MpesaRepository) GetStkRequest(ctx context.Context, id uint32) (*repository.MpesaStkRequest, error) {
+	pgRequest, err := mr.queries.GetMpesaStkRequest(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "stk request not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get stk request: %s", err.Error())
+	}
+
+	return convertGeneratedMpesaStkRequest(pgRequest), nil
+}
+
+func (mr *MpesaRepository) CompleteStkRequest(ctx context.Context, result *repository.MpesaStkResult) (*repository.MpesaStkRequest, error) {
+	var request *repository.MpesaStkRequest
+
+	err := mr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		// lock the request so concurrent deliveries of the same callback are applied once
+		pgRequest, err := q.GetMpesaStkRequestByCheckoutIDForUpdate(ctx, result.CheckoutRequestID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "stk request %s not found", result.CheckoutRequestID)
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get stk request: %s", err.Error())
+		}
+
+		if pgRequest.Status != repository.MPESA_STK_PENDING {
+			request = convertGeneratedMpesaStkRequest(pgRequest)
+			return nil
+		}
+
+		completeParams := generated.CompleteMpesaStkRequestParams{
+			ID:           pgRequest.ID,
+			Status:       repository.MPESA_STK_FAILED,
+			ResultCode:   pgtype.Int4{Int32: result.ResultCode, Valid: true},
+			ResultDesc:   pgtype.Text{String: result.ResultDesc, Valid: true},
+			MpesaReceipt: pgtype.Text{Valid: false},
+			PaymentID:    pgtype.Int8{Valid: false},
+		}
+
+		if result.ResultCode == 0 {
+			// a successful result has to be for the payment that was asked for, a mismatch
+			// leaves the request pending for the real callback
+			if result.MerchantRequestID != pgRequest.MerchantRequestID ||
+				roundCents(result.Amount) != roundCents(pkg.PgTypeNumericToFloat64(pgRequest.Amount)) ||
+				result.PhoneNumber != pgRequest.PhoneNumber {
+				return pkg.Errorf(pkg.INVALID_ERROR, "stk callback for %s does not match the request", result.CheckoutRequestID)
+			}
+
+			payment := &repository.Payment{
+				ResellerID: uint32(pgRequest.ResellerID),
+				Amount:     result.Amount,
+				Method:     "MPESA",
+				Reference:  result.MpesaReceipt,
+				RecordedBy: "SYSTEM",
+				DatePaid:   result.TransactionDate,
+			}
+
+			if err := createPayment(ctx, q, payment); err != nil {
+				return err
+			}
+
+			completeParams.Status = repository.MPESA_STK_SUCCESS
+			completeParams.MpesaReceipt = pgtype.Text{String: result.MpesaReceipt, Valid: true}
+			completeParams.PaymentID = pgtype.Int8{Int64: int64(payment.ID), Valid: true}
+		}
+
+		pgRequest, err = q.CompleteMpesaStkRequest(ctx, completeParams)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update stk request: %s", err.Error())
+		}
+
+		request = convertGeneratedMpesaStkRequest(pgRequest)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (mr *MpesaRepository) RecordC2BTransaction(ctx context.Context, transaction *repository.MpesaC2BTransaction) (*repository.MpesaC2BTransaction, error) {
+	var recorded *repository.MpesaC2BTransaction
+
+	err := mr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgTransaction, err := q.CreateMpesaC2bTransaction(ctx, generated.CreateMpesaC2bTransactionParams{
+			TransID:       transaction.TransID,
+			TransType:     transaction.TransType,
+			TransTime:     transaction.TransTime,
+			Amount:        pkg.Float64ToPgTypeNumeric(transaction.Amount),
+			BillRefNumber: transaction.BillRefNumber,
+			Msisdn:        transaction.Msisdn,
+			PayerName:     transaction.PayerName,
+		})
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create c2b transaction: %s", err.Error())
+			}
+
+			// the transaction was already confirmed
+			pgTransaction, err = q.GetMpesaC2bTransactionByTransID(ctx, transaction.TransID)
+			if err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get c2b transaction: %s", err.Error())
+			}
+
+			recorded = convertGeneratedMpesaC2BTransaction(pgTransaction)
+			return nil
+		}
+
+		resellerID, err := matchC2BPayer(ctx, q, transaction)
+		if err != nil {
+			return err
+		}
+
+		if resellerID != 0 {
+			pgTransaction, err = allocateC2BTransaction(ctx, q, pgTransaction, resellerID, "SYSTEM")
+			if err != nil {
+				return err
+			}
+		}
+
+		recorded = convertGeneratedMpesaC2BTransaction(pgTransaction)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recorded, nil
+}
+
+func (mr *MpesaRepository) ListC2BTransactions(ctx context.Context, filter *repository.MpesaC2BFilter) ([]*repository.MpesaC2BTransaction, *pkg.Pagination, error) {
+	listParams := generated.ListMpesaC2bTransactionsParams{
+		Limit:  int32(filter.Pagination.PageSize),
+		Offset: pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		Status: pgtype.Text{Valid: false},
+		Search: pgtype.Text{Valid: false},
+	}
+
+	countParams := generated.ListMpesaC2bTransactionsCountParams{
+		Status: pgtype.Text{Valid: false},
+		Search: pgtype.Text{Valid: false},
+	}
+
+	if filter.Status != nil {
+		listParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+		countParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+	}
+
+	if filter.Search != nil {
+		s := strings.ToLower(*filter.Search)
+		listParams.Search = pgtype.Text{String: "%" + s + "%", Valid: true}
+		countParams.Search = pgtype.Text{String: "%" + s + "%", Valid: true}
+	}
+
+	pgTransactions, err := mr.queries.ListMpesaC2bTransactions(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list c2b transactions: %s", err.Error())
+	}
+
+	totalCount, err := mr.queries.ListMpesaC2bTransactionsCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count c2b transactions: %s", err.Error())
+	}
+
+	transactions := make([]*repository.MpesaC2BTransaction, len(pgTransactions))
+	for i, pgTransaction := range pgTransactions {
+		transactions[i] = convertGeneratedMpesaC2BTransaction(pgTransaction)
+	}
+
+	return transactions, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+func (mr *MpesaRepository) AllocateC2BTransaction(ctx context.Context, id uint32, resellerID uint32) (*repository.MpesaC2BTransaction, error) {
+	var allocated *repository.MpesaC2BTransaction
+
+	err := mr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgTransaction, err := q.GetMpesaC2bTransactionForUpdate(ctx, int64(id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "c2b transaction not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get c2b transaction: %s", err.Error())
+		}
+
+		if pgTransaction.Status != repository.MPESA_C2B_SUSPENSE {
+			return pkg.Errorf(pkg.INVALID_ERROR, "c2b transaction %s is already allocated", pgTransaction.TransID)
+		}
+
+		if _, err := q.GetActiveResellerID(ctx, int64(resellerID)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+		}
+
+		pgTransaction, err = allocateC2BTransaction(ctx, q, pgTransaction, int64(resellerID), "ADMIN")
+		if err != nil {
+			return err
+		}
+
+		allocated = convertGeneratedMpesaC2BTransaction(pgTransaction)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allocated, nil
+}
+
+// allocateC2BTransaction records the paybill transaction as a payment by the reseller.
+func allocateC2BTransaction(ctx context.Context, q *generated.Queries, pgTransaction generated.MpesaC2bTransaction, resellerID int64, allocatedBy string) (generated.MpesaC2bTransaction, error) {
+	payment := &repository.Payment{
+		ResellerID: uint32(resellerID),
+		Amount:     pkg.PgTypeNumericToFloat64(pgTransaction.Amount),
+		Method:     "MPESA",
+		Reference:  pgTransaction.TransID,
+		RecordedBy: allocatedBy,
+		DatePaid:   pgTransaction.TransTime,
+	}
+
+	if err := createPayment(ctx, q, payment); err != nil {
+		return generated.MpesaC2bTransaction{}, err
+	}
+
+	pgTransaction, err := q.AllocateMpesaC2bTransaction(ctx, generated.AllocateMpesaC2bTransactionParams{
+		ID:          pgTransaction.ID,
+		ResellerID:  pgtype.Int8{Int64: resellerID, Valid: true},
+		PaymentID:   pgtype.Int8{Int64: int64(payment.ID), Valid: true},
+		AllocatedBy: pgtype.Text{String: allocatedBy, Valid: true},
+	})
+	if err != nil {
+		return generated.MpesaC2bTransaction{}, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to allocate c2b transaction: %s", err.Error())
+	}
+
+	return pgTransaction, nil
+}
+
+// matchC2BPayer finds the reseller a paybill payment belongs to, see matchPayer.
+func matchC2BPayer(ctx context.Context, q *generated.Queries, transaction *repository.MpesaC2BTransaction) (int64, error) {
+	return matchPayer(ctx, q, transaction.BillRefNumber, transaction.Msisdn)
+}
+
+// matchPayer finds the reseller a payment belongs to, first by a reseller account number
+// and then by the account number or one of the paying phone numbers being a reseller's phone.
+// Returns 0 when there is no single match.
+func matchPayer(ctx context.Context, q *generated.Queries, accountNumber string, phoneNumbers ...string) (int64, error) {
+	ref := strings.ToUpper(strings.TrimSpace(accountNumber))
+	if strings.HasPrefix(ref, repository.MPESA_ACCOUNT_PREFIX) {
+		if id, err := strconv.ParseInt(strings.TrimPrefix(ref, repository.MPESA_ACCOUNT_PREFIX), 10, 64); err == nil {
+			resellerID, err := q.GetActiveResellerID(ctx, id)
+			if err == nil {
+				return resellerID, nil
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return 0, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+			}
+		}
+	}
+
+	for _, phone := range append([]string{accountNumber}, phoneNumbers...) {
+		suffix, ok := phoneNumberSuffix(phone)
+		if !ok {
+			continue
+		}
+
+		resellerIDs, err := q.ListResellerIDsByPhoneSuffix(ctx, suffix)
+		if err != nil {
+			return 0, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to match reseller phone number: %s", err.Error())
+		}
+
+		if len(resellerIDs) == 1 {
+			return resellerIDs[0], nil
+		}
+	}
+
+	return 0, nil
+}
+
+// phoneNumberSuffix returns the last nine digits of a phone number. Daraja masks or hashes
+// the payer's number on some shortcodes so anything that is not plain digits is skipped.
+func phoneNumberSuffix(phoneNumber string) (string, bool) {
+	phone := strings.NewReplacer(" ", "", "-", "", "+", "").Replace(phoneNumber)
+	if len(phone) < 9 || len(phone) > 12 {
+		return "", false
+	}
+
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+
+	return phone[len(phone)-9:], true
+}
+
+func convertGeneratedMpesaStkRequest(pgRequest generated.MpesaStkRequest) *repository.MpesaStkRequest {
+	request := &repository.MpesaStkRequest{
+		ID:                uint32(pgRequest.ID),
+		ResellerID:        uint32(pgRequest.ResellerID),
+		PhoneNumber:       pgRequest.PhoneNumber,
+		Amount:            pkg.PgTypeNumericToFloat64(pgRequest.Amount),
+		MerchantRequestID: pgRequest.MerchantRequestID,
+		CheckoutRequestID: pgRequest.CheckoutRequestID,
+		Status:            pgRequest.Status,
+		ResultCode:        nil,
+		ResultDesc:        "",
+		MpesaReceipt:      "",
+		PaymentID:         nil,
+		UpdatedAt:         pgRequest.UpdatedAt,
+		CreatedAt:         pgRequest.CreatedAt,
+	}
+
+	if pgRequest.ResultCode.Valid {
+		request.ResultCode = &pgRequest.ResultCode.Int32
+	}
+
+	if pgRequest.ResultDesc.Valid {
+		request.ResultDesc = pgRequest.ResultDesc.String
+	}
+
+	if pgRequest.MpesaReceipt.Valid {
+		request.MpesaReceipt = pgRequest.MpesaReceipt.String
+	}
+
+	if pgRequest.PaymentID.Valid {
+		paymentID := uint32(pgRequest.PaymentID.Int64)
+		request.PaymentID = &paymentID
+	}
+
+	return request
+}
+
+func convertGeneratedMpesaC2BTransaction(pgTransaction generated.MpesaC2bTransaction) *repository.MpesaC2BTransaction {
+	transaction := &repository.MpesaC2BTransaction{
+		ID:            uint32(pgTransaction.ID),
+		TransID:       pgTransaction.TransID,
+		TransType:     pgTransaction.TransType,
+		TransTime:     pgTransaction.TransTime,
+		Amount:        pkg.PgTypeNumericToFloat64(pgTransaction.Amount),
+		BillRefNumber: pgTransaction.BillRefNumber,
+		Msisdn:        pgTransaction.Msisdn,
+		PayerName:     pgTransaction.PayerName,
+		Status:        pgTransaction.Status,
+		ResellerID:    nil,
+		PaymentID:     nil,
+		AllocatedBy:   "",
+		AllocatedAt:   nil,
+		CreatedAt:     pgTransaction.CreatedAt,
+	}
+
+	if pgTransaction.ResellerID.Valid {
+		resellerID := uint32(pgTransaction.ResellerID.Int64)
+		transaction.ResellerID = &resellerID
+	}
+
+	if pgTransaction.PaymentID.Valid {
+		paymentID := uint32(pgTransaction.PaymentID.Int64)
+		transaction.PaymentID = &paymentID
+	}
+
+	if pgTransaction.AllocatedBy.Valid {
+		transaction.AllocatedBy = pgTransaction.AllocatedBy.String
+	}
+
+	if pgTransaction.AllocatedAt.Valid {
+		transaction.AllocatedAt = &pgTransaction.AllocatedAt.Time
+	}
+
+	return transaction
+}
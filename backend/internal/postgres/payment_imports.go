@@ -0,0 +1,435 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.PaymentImportRepository = (*PaymentImportRepository)(nil)
+
+type PaymentImportRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewPaymentImportRepository(db *Store) *PaymentImportRepository {
+	return &PaymentImportRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (pr *PaymentImportRepository) PreviewPaymentImport(ctx context.Context, paymentImport *repository.PaymentImport) (*repository.PaymentImport, error) {
+	var importID int64
+
+	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgImport, err := q.CreatePaymentImport(ctx, generated.CreatePaymentImportParams{
+			Source:     paymentImport.Source,
+			Method:     paymentImport.Method,
+			FileName:   paymentImport.FileName,
+			UploadedBy: int64(paymentImport.UploadedBy),
+		})
+		if err != nil {
+			if pkg.PgxErrorCode(err) == pkg.FOREIGN_KEY_VIOLATION {
+				return pkg.Errorf(pkg.INVALID_ERROR, "unknown payment method %s", paymentImport.Method)
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create payment import: %s", err.Error())
+		}
+		importID = pgImport.ID
+
+		references := make([]string, 0, len(paymentImport.Rows))
+		for _, row := range paymentImport.Rows {
+			if row.Reference != "" {
+				references = append(references, row.Reference)
+			}
+		}
+
+		duplicates, err := paymentImportDuplicates(ctx, q, paymentImport.Source, references)
+		if err != nil {
+			return err
+		}
+
+		// first row each reference is seen on, a statement can list a transaction twice
+		seen := make(map[string]int32, len(paymentImport.Rows))
+
+		for _, row := range paymentImport.Rows {
+			createParams := generated.CreatePaymentImportRowParams{
+				ImportID:           importID,
+				RowNumber:          row.RowNumber,
+				Reference:          row.Reference,
+				Amount:             pkg.Float64ToPgTypeNumeric(row.Amount),
+				DatePaid:           row.DatePaid,
+				PayerName:          row.PayerName,
+				PayerPhone:         row.PayerPhone,
+				AccountNumber:      row.AccountNumber,
+				Details:            row.Details,
+				ResellerID:         pgtype.Int8{Valid: false},
+				Status:             repository.PAYMENT_IMPORT_ROW_UNMATCHED,
+				Note:               "",
+				DuplicatePaymentID: pgtype.Int8{Valid: false},
+			}
+
+			resellerID, err := matchPayer(ctx, q, row.AccountNumber, row.PayerPhone)
+			if err != nil {
+				return err
+			}
+			if resellerID != 0 {
+				createParams.ResellerID = pgtype.Int8{Int64: resellerID, Valid: true}
+				createParams.Status = repository.PAYMENT_IMPORT_ROW_MATCHED
+			} else {
+				createParams.Note = "no single reseller matches the account number or phone number"
+			}
+
+			firstRow, repeated := seen[row.Reference]
+			duplicate, recorded := duplicates[row.Reference]
+
+			switch {
+			case row.Reference != "" && repeated:
+				createParams.Status = repository.PAYMENT_IMPORT_ROW_DUPLICATE
+				createParams.Note = fmt.Sprintf("reference is repeated from row %d", firstRow)
+			case recorded:
+				createParams.Status = repository.PAYMENT_IMPORT_ROW_DUPLICATE
+				createParams.Note = duplicate.note
+				createParams.DuplicatePaymentID = duplicate.paymentID
+			default:
+				err := validatePaymentMethod(ctx, q, &repository.Payment{
+					Method:    paymentImport.Method,
+					Reference: row.Reference,
+				})
+				if err != nil {
+					if pkg.ErrorCode(err) != pkg.INVALID_ERROR {
+						return err
+					}
+					createParams.Status = repository.PAYMENT_IMPORT_ROW_INVALID
+					createParams.Note = pkg.ErrorMessage(err)
+				}
+			}
+
+			if row.Reference != "" && !repeated {
+				seen[row.Reference] = row.RowNumber
+			}
+
+			if _, err := q.CreatePaymentImportRow(ctx, createParams); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create payment import row: %s", err.Error())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.GetPaymentImport(ctx, uint32(importID))
+}
+
+func (pr *PaymentImportRepository) GetPaymentImport(ctx context.Context, id uint32) (*repository.PaymentImport, error) {
+	pgImport, err := pr.queries.GetPaymentImport(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "payment import not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get payment import: %s", err.Error())
+	}
+
+	paymentImport := pgPaymentImportToRepoPaymentImport(pgImport)
+
+	pgRows, err := pr.queries.ListPaymentImportRows(ctx, pgImport.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list payment import rows: %s", err.Error())
+	}
+
+	paymentImport.Rows = make([]*repository.PaymentImportRow, len(pgRows))
+	for i, pgRow := range pgRows {
+		row := &repository.PaymentImportRow{
+			ID:                 uint32(pgRow.ID),
+			ImportID:           uint32(pgRow.ImportID),
+			RowNumber:          pgRow.RowNumber,
+			Reference:          pgRow.Reference,
+			Amount:             pkg.PgTypeNumericToFloat64(pgRow.Amount),
+			DatePaid:           pgRow.DatePaid,
+			PayerName:          pgRow.PayerName,
+			PayerPhone:         pgRow.PayerPhone,
+			AccountNumber:      pgRow.AccountNumber,
+			Details:            pgRow.Details,
+			ResellerID:         nil,
+			Status:             pgRow.Status,
+			Note:               pgRow.Note,
+			DuplicatePaymentID: nil,
+			PaymentID:          nil,
+			CreatedAt:          pgRow.CreatedAt,
+		}
+
+		if pgRow.ResellerID.Valid {
+			resellerID := uint32(pgRow.ResellerID.Int64)
+			row.ResellerID = &resellerID
+			row.User = &repository.UserShort{
+				ID:          resellerID,
+				Name:        pgRow.ResellerName.String,
+				PhoneNumber: pgRow.ResellerPhoneNumber.String,
+			}
+		}
+
+		if pgRow.DuplicatePaymentID.Valid {
+			paymentID := uint32(pgRow.DuplicatePaymentID.Int64)
+			row.DuplicatePaymentID = &paymentID
+		}
+
+		if pgRow.PaymentID.Valid {
+			paymentID := uint32(pgRow.PaymentID.Int64)
+			row.PaymentID = &paymentID
+		}
+
+		paymentImport.Rows[i] = row
+	}
+
+	return paymentImport, nil
+}
+
+func (pr *PaymentImportRepository) ListPaymentImports(ctx context.Context, filter *repository.PaymentImportFilter) ([]*repository.PaymentImport, *pkg.Pagination, error) {
+	listParams := generated.ListPaymentImportsParams{
+		Limit:  int32(filter.Pagination.PageSize),
+		Offset: pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		Status: pgtype.Text{Valid: false},
+	}
+
+	countStatus := pgtype.Text{Valid: false}
+
+	if filter.Status != nil {
+		listParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+		countStatus = pgtype.Text{String: *filter.Status, Valid: true}
+	}
+
+	pgImports, err := pr.queries.ListPaymentImports(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list payment imports: %s", err.Error())
+	}
+
+	totalCount, err := pr.queries.ListPaymentImportsCount(ctx, countStatus)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count payment imports: %s", err.Error())
+	}
+
+	imports := make([]*repository.PaymentImport, len(pgImports))
+	for i, pgImport := range pgImports {
+		imports[i] = pgPaymentImportToRepoPaymentImport(pgImport)
+	}
+
+	return imports, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+func (pr *PaymentImportRepository) CommitPaymentImport(ctx context.Context, id uint32, decisions []*repository.PaymentImportDecision) (*repository.PaymentImport, error) {
+	if len(decisions) == 0 {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "no rows were accepted")
+	}
+
+	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		// lock the import so it can't be committed twice at the same time
+		pgImport, err := q.GetPaymentImportForUpdate(ctx, int64(id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "payment import not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get payment import: %s", err.Error())
+		}
+
+		if pgImport.Status != repository.PAYMENT_IMPORT_PREVIEW {
+			return pkg.Errorf(pkg.INVALID_ERROR, "payment import %d is already committed", id)
+		}
+
+		pgRows, err := q.ListPaymentImportRows(ctx, pgImport.ID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list payment import rows: %s", err.Error())
+		}
+
+		rowsByID := make(map[uint32]generated.ListPaymentImportRowsRow, len(pgRows))
+		for _, pgRow := range pgRows {
+			rowsByID[uint32(pgRow.ID)] = pgRow
+		}
+
+		type acceptedRow struct {
+			row        generated.ListPaymentImportRowsRow
+			resellerID int64
+		}
+
+		accepted := make([]acceptedRow, 0, len(decisions))
+		references := make([]string, 0, len(decisions))
+		decided := make(map[uint32]bool, len(decisions))
+
+		for _, decision := range decisions {
+			row, ok := rowsByID[decision.RowID]
+			if !ok {
+				return pkg.Errorf(pkg.INVALID_ERROR, "row %d is not part of payment import %d", decision.RowID, id)
+			}
+			if decided[decision.RowID] {
+				return pkg.Errorf(pkg.INVALID_ERROR, "row %d is accepted more than once", row.RowNumber)
+			}
+			decided[decision.RowID] = true
+
+			if row.Status != repository.PAYMENT_IMPORT_ROW_MATCHED && row.Status != repository.PAYMENT_IMPORT_ROW_UNMATCHED {
+				return pkg.Errorf(pkg.INVALID_ERROR, "row %d is %s and cannot be imported: %s", row.RowNumber, strings.ToLower(row.Status), row.Note)
+			}
+
+			resellerID := row.ResellerID.Int64
+			if decision.ResellerID != nil {
+				resellerID, err = q.GetActiveResellerID(ctx, int64(*decision.ResellerID))
+				if err != nil {
+					if errors.Is(err, sql.ErrNoRows) {
+						return pkg.Errorf(pkg.INVALID_ERROR, "row %d: reseller %d not found", row.RowNumber, *decision.ResellerID)
+					}
+					return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+				}
+			}
+			if resellerID == 0 {
+				return pkg.Errorf(pkg.INVALID_ERROR, "row %d has no reseller, pick one to import it", row.RowNumber)
+			}
+
+			accepted = append(accepted, acceptedRow{row: row, resellerID: resellerID})
+			if row.Reference != "" {
+				references = append(references, row.Reference)
+			}
+		}
+
+		// payments may have been recorded for these references since the preview
+		duplicates, err := paymentImportDuplicates(ctx, q, pgImport.Source, references)
+		if err != nil {
+			return err
+		}
+
+		// record older payments first so they settle the oldest invoices
+		sort.Slice(accepted, func(i, j int) bool {
+			if !accepted[i].row.DatePaid.Equal(accepted[j].row.DatePaid) {
+				return accepted[i].row.DatePaid.Before(accepted[j].row.DatePaid)
+			}
+			return accepted[i].row.RowNumber < accepted[j].row.RowNumber
+		})
+
+		for _, a := range accepted {
+			if duplicate, ok := duplicates[a.row.Reference]; ok {
+				return pkg.Errorf(pkg.INVALID_ERROR, "row %d: %s", a.row.RowNumber, duplicate.note)
+			}
+
+			payment := &repository.Payment{
+				ResellerID: uint32(a.resellerID),
+				Amount:     pkg.PgTypeNumericToFloat64(a.row.Amount),
+				Method:     pgImport.Method,
+				Reference:  a.row.Reference,
+				RecordedBy: "ADMIN",
+				DatePaid:   a.row.DatePaid,
+			}
+
+			if err := validatePaymentMethod(ctx, q, payment); err != nil {
+				return pkg.Errorf(pkg.ErrorCode(err), "row %d: %s", a.row.RowNumber, pkg.ErrorMessage(err))
+			}
+
+			if err := createPayment(ctx, q, payment); err != nil {
+				return err
+			}
+
+			if err := q.ImportPaymentImportRow(ctx, generated.ImportPaymentImportRowParams{
+				ResellerID: pgtype.Int8{Int64: a.resellerID, Valid: true},
+				PaymentID:  pgtype.Int8{Int64: int64(payment.ID), Valid: true},
+				ID:         a.row.ID,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update payment import row: %s", err.Error())
+			}
+		}
+
+		if err := q.SkipPaymentImportRows(ctx, pgImport.ID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to skip payment import rows: %s", err.Error())
+		}
+
+		if err := q.CommitPaymentImport(ctx, pgImport.ID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to commit payment import: %s", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.GetPaymentImport(ctx, id)
+}
+
+type paymentImportDuplicate struct {
+	paymentID pgtype.Int8
+	note      string
+}
+
+// paymentImportDuplicates returns the references that are already recorded as payments. For
+// M-Pesa statements paybill payments still waiting in the suspense queue count as well, they
+// become payments when they are allocated.
+func paymentImportDuplicates(ctx context.Context, q *generated.Queries, source string, references []string) (map[string]paymentImportDuplicate, error) {
+	duplicates := make(map[string]paymentImportDuplicate)
+	if len(references) == 0 {
+		return duplicates, nil
+	}
+
+	payments, err := q.ListPaymentsByReferences(ctx, references)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list payments by reference: %s", err.Error())
+	}
+
+	for _, payment := range payments {
+		duplicates[payment.Reference] = paymentImportDuplicate{
+			paymentID: pgtype.Int8{Int64: payment.ID, Valid: true},
+			note:      fmt.Sprintf("reference %s is already recorded as payment %d", payment.Reference, payment.ID),
+		}
+	}
+
+	if source != repository.PAYMENT_IMPORT_SOURCE_MPESA {
+		return duplicates, nil
+	}
+
+	for _, reference := range references {
+		if _, ok := duplicates[reference]; ok {
+			continue
+		}
+
+		transaction, err := q.GetMpesaC2bTransactionByTransID(ctx, reference)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get c2b transaction: %s", err.Error())
+		}
+
+		if transaction.Status == repository.MPESA_C2B_SUSPENSE {
+			duplicates[reference] = paymentImportDuplicate{
+				paymentID: pgtype.Int8{Valid: false},
+				note:      fmt.Sprintf("reference %s was received by the paybill and is waiting in the suspense queue", reference),
+			}
+		}
+	}
+
+	return duplicates, nil
+}
+
+func pgPaymentImportToRepoPaymentImport(pgImport generated.PaymentImport) *repository.PaymentImport {
+	paymentImport := &repository.PaymentImport{
+		ID:          uint32(pgImport.ID),
+		Source:      pgImport.Source,
+		Method:      pgImport.Method,
+		FileName:    pgImport.FileName,
+		Status:      pgImport.Status,
+		UploadedBy:  uint32(pgImport.UploadedBy),
+		CommittedAt: nil,
+		CreatedAt:   pgImport.CreatedAt,
+	}
+
+	if pgImport.CommittedAt.Valid {
+		paymentImport.CommittedAt = &pgImport.CommittedAt.Time
+	}
+
+	return paymentImport
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PaymentRepository) CreatePaymentMethod(ctx context.Context, method *repository.PaymentMethod) (*repository.PaymentMethod, error) {
+	if _, err := regexp.Compile(method.ReferencePattern); err != nil {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid reference_pattern: %s", err.Error())
+	}
+
+	createParams := generated.CreatePaymentMethodParams{
+		Code:              method.Code,
+		Name:              method.Name,
+		ReferenceRequired: method.ReferenceRequired,
+		ReferencePattern:  pgtype.Text{Valid: false},
+		Active:            method.Active,
+	}
+
+	if method.ReferencePattern != "" {
+		createParams.ReferencePattern = pgtype.Text{String: method.ReferencePattern, Valid: true}
+	}
+
+	pgMethod, err := pr.queries.CreatePaymentMethod(ctx, createParams)
+	if err != nil {
+		if pkg.PgxErrorCode(err) == pkg.UNIQUE_VIOLATION {
+			return nil, pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "payment method %s already exists", method.Code)
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create payment method: %s", err.Error())
+	}
+
+	return pgPaymentMethodToRepoPaymentMethod(pgMethod), nil
+}
+
+func (pr *PaymentRepository) UpdatePaymentMethod(ctx context.Context, code string, update *repository.PaymentMethodUpdate) (*repository.PaymentMethod, error) {
+	updateParams := generated.UpdatePaymentMethodParams{
+		Name:              pgtype.Text{Valid: false},
+		ReferenceRequired: pgtype.Bool{Valid: false},
+		ReferencePattern:  pgtype.Text{Valid: false},
+		Active:            pgtype.Bool{Valid: false},
+		Code:              code,
+	}
+	if update.Name != nil {
+		updateParams.Name = pgtype.Text{String: *update.Name, Valid: true}
+	}
+	if update.ReferenceRequired != nil {
+		updateParams.ReferenceRequired = pgtype.Bool{Bool: *update.ReferenceRequired, Valid: true}
+	}
+	if update.ReferencePattern != nil {
+		if _, err := regexp.Compile(*update.ReferencePattern); err != nil {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid reference_pattern: %s", err.Error())
+		}
+		updateParams.ReferencePattern = pgtype.Text{String: *update.ReferencePattern, Valid: true}
+	}
+	if update.Active != nil {
+		updateParams.Active = pgtype.Bool{Bool: *update.Active, Valid: true}
+	}
+
+	pgMethod, err := pr.queries.UpdatePaymentMethod(ctx, updateParams)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "payment method not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update payment method: %s", err.Error())
+	}
+
+	return pgPaymentMethodToRepoPaymentMethod(pgMethod), nil
+}
+
+func (pr *PaymentRepository) ListPaymentMethods(ctx context.Context, active *bool) ([]*repository.PaymentMethod, error) {
+	activeParam := pgtype.Bool{Valid: false}
+	if active != nil {
+		activeParam = pgtype.Bool{Bool: *active, Valid: true}
+	}
+
+	pgMethods, err := pr.queries.ListPaymentMethods(ctx, activeParam)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list payment methods: %s", err.Error())
+	}
+
+	methods := make([]*repository.PaymentMethod, len(pgMethods))
+	for i, pgMethod := range pgMethods {
+		methods[i] = pgPaymentMethodToRepoPaymentMethod(pgMethod)
+	}
+
+	return methods, nil
+}
+
+// validatePaymentMethod checks that the payment's method is active and that its reference
+// meets the method's settings.
+func validatePaymentMethod(ctx context.Context, q *generated.Queries, payment *repository.Payment) error {
+	method, err := q.GetPaymentMethodByCode(ctx, payment.Method)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkg.Errorf(pkg.INVALID_ERROR, "unknown payment method %s", payment.Method)
+		}
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get payment method: %s", err.Error())
+	}
+
+	if !method.Active {
+		return pkg.Errorf(pkg.INVALID_ERROR, "payment method %s is not active", payment.Method)
+	}
+
+	if payment.Reference == "" {
+		if method.ReferenceRequired {
+			return pkg.Errorf(pkg.INVALID_ERROR, "a reference is required for %s payments", method.Name)
+		}
+		return nil
+	}
+
+	if method.ReferencePattern.Valid {
+		pattern, err := regexp.Compile(method.ReferencePattern.String)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "invalid reference pattern for %s: %s", method.Code, err.Error())
+		}
+
+		if !pattern.MatchString(payment.Reference) {
+			return pkg.Errorf(pkg.INVALID_ERROR, "reference %q does not match the %s reference format %s", payment.Reference, method.Name, method.ReferencePattern.String)
+		}
+	}
+
+	return nil
+}
+
+func pgPaymentMethodToRepoPaymentMethod(method generated.PaymentMethod) *repository.PaymentMethod {
+	return &repository.PaymentMethod{
+		ID:                uint32(method.ID),
+		Code:              method.Code,
+		Name:              method.Name,
+		ReferenceRequired: method.ReferenceRequired,
+		ReferencePattern:  method.ReferencePattern.String,
+		Active:            method.Active,
+		UpdatedAt:         method.UpdatedAt,
+		CreatedAt:         method.CreatedAt,
+	}
+}
@@ -2,8 +2,11 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/EmilioCliff/boffo/internal/postgres/generated"
 	"github.com/EmilioCliff/boffo/internal/repository"
@@ -27,85 +30,193 @@ func NewPaymentRepository(db *Store) *PaymentRepository {
 
 func (pr *PaymentRepository) CreatePayment(ctx context.Context, payment *repository.Payment) (*repository.Payment, error) {
 	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
-		// create payment record
-		createParams := generated.CreatePaymentParams{
-			ResellerID: int64(payment.ResellerID),
-			Amount:     pkg.Float64ToPgTypeNumeric(payment.Amount),
-			Method:     payment.Method,
-			Reference:  pgtype.Text{Valid: false},
-			RecordedBy: payment.RecordedBy,
-			DatePaid:   payment.DatePaid,
+		if err := validatePaymentMethod(ctx, q, payment); err != nil {
+			return err
 		}
 
-		if payment.Reference != "" {
-			createParams.Reference = pgtype.Text{String: payment.Reference, Valid: true}
-		}
+		return createPayment(ctx, q, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		pgPayment, err := q.CreatePayment(ctx, createParams)
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create payment: %s", err.Error())
-		}
+	return payment, nil
+}
 
-		payment.ID = uint32(pgPayment.ID)
-		payment.CreatedAt = pgPayment.CreatedAt
+func (pr *PaymentRepository) ReversePayment(ctx context.Context, id uint32, reason string) (*repository.Payment, error) {
+	var reversal *repository.Payment
 
-		// update reseller account balance
-		resellerAccount, err := q.GetResellerAccount(ctx, int64(payment.ResellerID))
+	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		// lock the original so it can't be reversed twice at the same time
+		original, err := q.GetPaymentForUpdate(ctx, int64(id))
 		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "payment not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get payment: %s", err.Error())
+		}
+
+		if original.ReversalOf.Valid {
+			return pkg.Errorf(pkg.INVALID_ERROR, "payment %d is a reversal and cannot be reversed", id)
 		}
 
-		_, err = q.UpdateResellerAccount(ctx, generated.UpdateResellerAccountParams{
-			ResellerID:         int64(payment.ResellerID),
-			TotalStockReceived: pgtype.Int8{Valid: false},
-			TotalValueReceived: pgtype.Numeric{Valid: false},
-			TotalSalesValue:    pgtype.Numeric{Valid: false},
-			TotalPaid:          pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(resellerAccount.TotalPaid) + payment.Amount),
-			TotalCogs:          pgtype.Numeric{Valid: false},
-			Balance:            pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(resellerAccount.Balance) - payment.Amount),
+		amount := pkg.PgTypeNumericToFloat64(original.Amount)
+
+		pgReversal, err := q.CreatePaymentReversal(ctx, generated.CreatePaymentReversalParams{
+			ResellerID:     original.ResellerID,
+			Amount:         pkg.Float64ToPgTypeNumeric(-amount),
+			Method:         original.Method,
+			Reference:      original.Reference,
+			RecordedBy:     "ADMIN",
+			DatePaid:       time.Now(),
+			ReversalOf:     pgtype.Int8{Int64: original.ID, Valid: true},
+			ReversalReason: pgtype.Text{String: reason, Valid: true},
 		})
 		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update reseller account: %s", err.Error())
+			if pkg.PgxErrorCode(err) == pkg.UNIQUE_VIOLATION {
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "payment %d has already been reversed", id)
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create payment reversal: %s", err.Error())
 		}
 
-		// update admin account balance
-		adminstats, err := q.GetAdminStats(ctx, 1)
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats: %s", err.Error())
+		if err := applyPayment(ctx, q, uint32(original.ResellerID), -amount); err != nil {
+			return err
 		}
 
-		_, err = q.UpdateAdminStats(ctx, generated.UpdateAdminStatsParams{
-			ID:                    1,
-			TotalCompanyStock:     pgtype.Int8{Valid: false},
-			TotalStockDistributed: pgtype.Int8{Valid: false},
-			TotalValueDistributed: pgtype.Numeric{Valid: false},
-			TotalPaymentsReceived: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(adminstats.TotalPaymentsReceived) + payment.Amount),
-		})
-		if err != nil {
-			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update admin stats: %s", err.Error())
+		// invoices the payment settled are reopened and other unallocated payments may settle them
+		if err := unallocatePayment(ctx, q, original.ID); err != nil {
+			return err
+		}
+
+		if _, err := allocateCredit(ctx, q, original.ResellerID); err != nil {
+			return err
 		}
 
-		resellerName, err := q.GetResellerNameByID(ctx, int64(payment.ResellerID))
+		resellerName, err := q.GetResellerNameByID(ctx, original.ResellerID)
 		if err != nil {
 			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
 		}
 
-		// create alert
 		if err = q.CreateAlert(ctx, generated.CreateAlertParams{
-			Type:        "PAYMENT_RECEIVED",
-			Title:       "Payment Received",
-			Description: fmt.Sprintf("KES %.0f from %s", payment.Amount, resellerName),
+			Type:        "PAYMENT_REVERSED",
+			Title:       "Payment Reversed",
+			Description: fmt.Sprintf("KES %.0f from %s reversed: %s", amount, resellerName, reason),
 		}); err != nil {
 			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
 		}
 
+		reversal = &repository.Payment{
+			ID:             uint32(pgReversal.ID),
+			ResellerID:     uint32(pgReversal.ResellerID),
+			Amount:         pkg.PgTypeNumericToFloat64(pgReversal.Amount),
+			Method:         pgReversal.Method,
+			Reference:      pgReversal.Reference.String,
+			RecordedBy:     pgReversal.RecordedBy,
+			DatePaid:       pgReversal.DatePaid,
+			CreatedAt:      pgReversal.CreatedAt,
+			ReversalOf:     &id,
+			ReversalReason: reason,
+		}
+
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return payment, nil
+	return reversal, nil
+}
+
+// createPayment records the payment, applies it to the reseller account and admin stats and
+// allocates it to invoices using the caller's transaction.
+func createPayment(ctx context.Context, q *generated.Queries, payment *repository.Payment) error {
+	// create payment record
+	createParams := generated.CreatePaymentParams{
+		ResellerID: int64(payment.ResellerID),
+		Amount:     pkg.Float64ToPgTypeNumeric(payment.Amount),
+		Method:     payment.Method,
+		Reference:  pgtype.Text{Valid: false},
+		RecordedBy: payment.RecordedBy,
+		DatePaid:   payment.DatePaid,
+	}
+
+	if payment.Reference != "" {
+		createParams.Reference = pgtype.Text{String: payment.Reference, Valid: true}
+	}
+
+	pgPayment, err := q.CreatePayment(ctx, createParams)
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create payment: %s", err.Error())
+	}
+
+	payment.ID = uint32(pgPayment.ID)
+	payment.CreatedAt = pgPayment.CreatedAt
+
+	if err := applyPayment(ctx, q, payment.ResellerID, payment.Amount); err != nil {
+		return err
+	}
+
+	if err := allocatePayment(ctx, q, payment); err != nil {
+		return err
+	}
+
+	resellerName, err := q.GetResellerNameByID(ctx, int64(payment.ResellerID))
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+	}
+
+	// create alert
+	if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+		Type:        "PAYMENT_RECEIVED",
+		Title:       "Payment Received",
+		Description: fmt.Sprintf("KES %.0f from %s", payment.Amount, resellerName),
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+	}
+
+	return nil
+}
+
+// applyPayment moves amount off the reseller's balance into total paid and the admin's
+// payments received, a negative amount takes a payment back.
+func applyPayment(ctx context.Context, q *generated.Queries, resellerID uint32, amount float64) error {
+	// update reseller account balance
+	resellerAccount, err := q.GetResellerAccount(ctx, int64(resellerID))
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
+	}
+
+	_, err = q.UpdateResellerAccount(ctx, generated.UpdateResellerAccountParams{
+		ResellerID:         int64(resellerID),
+		TotalStockReceived: pgtype.Int8{Valid: false},
+		TotalValueReceived: pgtype.Numeric{Valid: false},
+		TotalSalesValue:    pgtype.Numeric{Valid: false},
+		TotalPaid:          pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(resellerAccount.TotalPaid) + amount),
+		TotalCogs:          pgtype.Numeric{Valid: false},
+		Balance:            pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(resellerAccount.Balance) - amount),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update reseller account: %s", err.Error())
+	}
+
+	// update admin account balance
+	adminstats, err := q.GetAdminStats(ctx, 1)
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats: %s", err.Error())
+	}
+
+	_, err = q.UpdateAdminStats(ctx, generated.UpdateAdminStatsParams{
+		ID:                    1,
+		TotalCompanyStock:     pgtype.Int8{Valid: false},
+		TotalStockDistributed: pgtype.Int8{Valid: false},
+		TotalValueDistributed: pgtype.Numeric{Valid: false},
+		TotalPaymentsReceived: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(adminstats.TotalPaymentsReceived) + amount),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update admin stats: %s", err.Error())
+	}
+
+	return nil
 }
 
 func (pr *PaymentRepository) ListPayments(ctx context.Context, filter *repository.PaymentFilter) ([]*repository.Payment, *pkg.Pagination, error) {
@@ -191,6 +302,17 @@ func (pr *PaymentRepository) ListPayments(ctx context.Context, filter *repositor
 		if pgPayment.Reference.Valid {
 			payments[i].Reference = pgPayment.Reference.String
 		}
+
+		if pgPayment.ReversalOf.Valid {
+			reversalOf := uint32(pgPayment.ReversalOf.Int64)
+			payments[i].ReversalOf = &reversalOf
+			payments[i].ReversalReason = pgPayment.ReversalReason.String
+		}
+
+		if pgPayment.ReversedBy.Valid {
+			reversedBy := uint32(pgPayment.ReversedBy.Int64)
+			payments[i].ReversedBy = &reversedBy
+		}
 	}
 
 	return payments, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
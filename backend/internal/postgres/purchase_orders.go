@@ -0,0 +1,647 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.PurchaseOrderRepository = (*PurchaseOrderRepository)(nil)
+
+type PurchaseOrderRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewPurchaseOrderRepository(db *Store) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (pr *PurchaseOrderRepository) CreateSupplier(ctx context.Context, supplier *repository.Supplier) (*repository.Supplier, error) {
+	pgSupplier, err := pr.queries.CreateSupplier(ctx, generated.CreateSupplierParams{
+		Name:        supplier.Name,
+		ContactName: pgtype.Text{String: supplier.ContactName, Valid: supplier.ContactName != ""},
+		PhoneNumber: pgtype.Text{String: supplier.PhoneNumber, Valid: supplier.PhoneNumber != ""},
+		Email:       pgtype.Text{String: supplier.Email, Valid: supplier.Email != ""},
+		Address:     pgtype.Text{String: supplier.Address, Valid: supplier.Address != ""},
+	})
+	if err != nil {
+		if pkg.PgxErrorCode(err) == pkg.UNIQUE_VIOLATION {
+			return nil, pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "supplier %s already exists", supplier.Name)
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create supplier: %s", err.Error())
+	}
+
+	return newSupplier(pgSupplier), nil
+}
+
+func (pr *PurchaseOrderRepository) GetSupplier(ctx context.Context, id uint32) (*repository.Supplier, error) {
+	pgSupplier, err := pr.queries.GetSupplier(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "supplier not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get supplier: %s", err.Error())
+	}
+
+	return newSupplier(pgSupplier), nil
+}
+
+func (pr *PurchaseOrderRepository) UpdateSupplier(ctx context.Context, id uint32, update *repository.SupplierUpdate) (*repository.Supplier, error) {
+	params := generated.UpdateSupplierParams{
+		Name:        pgtype.Text{Valid: false},
+		ContactName: pgtype.Text{Valid: false},
+		PhoneNumber: pgtype.Text{Valid: false},
+		Email:       pgtype.Text{Valid: false},
+		Address:     pgtype.Text{Valid: false},
+		Active:      pgtype.Bool{Valid: false},
+		ID:          int64(id),
+	}
+
+	if update.Name != nil {
+		params.Name = pgtype.Text{String: *update.Name, Valid: true}
+	}
+
+	if update.ContactName != nil {
+		params.ContactName = pgtype.Text{String: *update.ContactName, Valid: true}
+	}
+
+	if update.PhoneNumber != nil {
+		params.PhoneNumber = pgtype.Text{String: *update.PhoneNumber, Valid: true}
+	}
+
+	if update.Email != nil {
+		params.Email = pgtype.Text{String: *update.Email, Valid: true}
+	}
+
+	if update.Address != nil {
+		params.Address = pgtype.Text{String: *update.Address, Valid: true}
+	}
+
+	if update.Active != nil {
+		params.Active = pgtype.Bool{Bool: *update.Active, Valid: true}
+	}
+
+	pgSupplier, err := pr.queries.UpdateSupplier(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "supplier not found")
+		}
+		if pkg.PgxErrorCode(err) == pkg.UNIQUE_VIOLATION {
+			return nil, pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "supplier %s already exists", params.Name.String)
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update supplier: %s", err.Error())
+	}
+
+	return newSupplier(pgSupplier), nil
+}
+
+func (pr *PurchaseOrderRepository) ListSuppliers(ctx context.Context, filter *repository.SupplierFilter) ([]*repository.Supplier, *pkg.Pagination, error) {
+	listParams := generated.ListSuppliersParams{
+		Limit:  int32(filter.Pagination.PageSize),
+		Offset: pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		Active: pgtype.Bool{Valid: false},
+		Search: nil,
+	}
+
+	countParams := generated.ListSuppliersCountParams{
+		Active: pgtype.Bool{Valid: false},
+		Search: nil,
+	}
+
+	if filter.Search != nil {
+		search := strings.ToLower(*filter.Search)
+		listParams.Search = "%" + search + "%"
+		countParams.Search = "%" + search + "%"
+	}
+
+	if filter.Active != nil {
+		listParams.Active = pgtype.Bool{Bool: *filter.Active, Valid: true}
+		countParams.Active = pgtype.Bool{Bool: *filter.Active, Valid: true}
+	}
+
+	pgSuppliers, err := pr.queries.ListSuppliers(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list suppliers: %s", err.Error())
+	}
+
+	totalCount, err := pr.queries.ListSuppliersCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count suppliers: %s", err.Error())
+	}
+
+	suppliers := make([]*repository.Supplier, len(pgSuppliers))
+	for i, pgSupplier := range pgSuppliers {
+		suppliers[i] = newSupplier(pgSupplier)
+	}
+
+	return suppliers, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+func (pr *PurchaseOrderRepository) CreatePurchaseOrder(ctx context.Context, order *repository.PurchaseOrder) (*repository.PurchaseOrder, error) {
+	if err := validatePurchaseOrderLines(order.Lines); err != nil {
+		return nil, err
+	}
+
+	var orderID int64
+
+	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		if err := checkSupplierActive(ctx, q, order.SupplierID); err != nil {
+			return err
+		}
+
+		expectedDate := pgtype.Date{Valid: false}
+		if order.ExpectedDate != nil {
+			expectedDate = pgtype.Date{Time: *order.ExpectedDate, Valid: true}
+		}
+
+		pgOrder, err := q.CreatePurchaseOrder(ctx, generated.CreatePurchaseOrderParams{
+			SupplierID:   int64(order.SupplierID),
+			ExpectedDate: expectedDate,
+			Note:         pgtype.Text{String: order.Note, Valid: order.Note != ""},
+			CreatedBy:    int64(order.CreatedBy),
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create purchase order: %s", err.Error())
+		}
+		orderID = pgOrder.ID
+
+		return createPurchaseOrderLines(ctx, q, pgOrder.ID, order.Lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.GetPurchaseOrder(ctx, uint32(orderID))
+}
+
+func (pr *PurchaseOrderRepository) GetPurchaseOrder(ctx context.Context, id uint32) (*repository.PurchaseOrder, error) {
+	pgOrder, err := pr.queries.GetPurchaseOrder(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "purchase order not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get purchase order: %s", err.Error())
+	}
+
+	order := newPurchaseOrder(generated.PurchaseOrder{
+		ID:           pgOrder.ID,
+		SupplierID:   pgOrder.SupplierID,
+		Status:       pgOrder.Status,
+		ExpectedDate: pgOrder.ExpectedDate,
+		Note:         pgOrder.Note,
+		CreatedBy:    pgOrder.CreatedBy,
+		SentAt:       pgOrder.SentAt,
+		ReceivedAt:   pgOrder.ReceivedAt,
+		CreatedAt:    pgOrder.CreatedAt,
+		ClosedAt:     pgOrder.ClosedAt,
+		CloseReason:  pgOrder.CloseReason,
+	})
+	order.QuantityOrdered = pgOrder.QuantityOrdered
+	order.QuantityReceived = pgOrder.QuantityReceived
+	order.TotalValue = pkg.PgTypeNumericToFloat64(pgOrder.TotalValue)
+	order.Supplier = &repository.SupplierShort{
+		ID:   uint32(pgOrder.SupplierID),
+		Name: pgOrder.SupplierName,
+	}
+
+	pgLines, err := pr.queries.ListPurchaseOrderLines(ctx, pgOrder.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list purchase order lines: %s", err.Error())
+	}
+
+	pgBatches, err := pr.queries.ListPurchaseOrderBatches(ctx, pgOrder.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list purchase order batches: %s", err.Error())
+	}
+
+	lines := make(map[int64]*repository.PurchaseOrderLine, len(pgLines))
+	order.Lines = make([]*repository.PurchaseOrderLine, len(pgLines))
+	for i, pgLine := range pgLines {
+		order.Lines[i] = &repository.PurchaseOrderLine{
+			ID:               uint32(pgLine.ID),
+			PurchaseOrderID:  uint32(pgLine.PurchaseOrderID),
+			ProductID:        uint32(pgLine.ProductID),
+			QuantityOrdered:  pgLine.QuantityOrdered,
+			QuantityReceived: pgLine.QuantityReceived,
+			ExpectedPrice:    pkg.PgTypeNumericToFloat64(pgLine.ExpectedPrice),
+			CreatedAt:        pgLine.CreatedAt,
+			Product: &repository.ProductShort{
+				ID:   uint32(pgLine.ProductID),
+				Name: pgLine.ProductName,
+				Unit: pgLine.ProductUnit,
+			},
+		}
+		lines[pgLine.ID] = order.Lines[i]
+	}
+
+	for _, pgBatch := range pgBatches {
+		line, ok := lines[pgBatch.PurchaseOrderLineID.Int64]
+		if !ok {
+			continue
+		}
+
+		lineID := line.ID
+		batch := &repository.ProductBatch{
+			ID:                  uint32(pgBatch.ID),
+			ProductID:           line.ProductID,
+			BatchNumber:         pgBatch.BatchNumber,
+			Quantity:            pgBatch.Quantity,
+			PurchasePrice:       pkg.PgTypeNumericToFloat64(pgBatch.PurchasePrice),
+			DateReceived:        pgBatch.DateReceived,
+			LocationID:          uint32(pgBatch.LocationID),
+			PurchaseOrderLineID: &lineID,
+			CreatedAt:           pgBatch.CreatedAt,
+		}
+		if pgBatch.ExpiryDate.Valid {
+			batch.ExpiryDate = &pgBatch.ExpiryDate.Time
+		}
+		line.Batches = append(line.Batches, batch)
+	}
+
+	return order, nil
+}
+
+func (pr *PurchaseOrderRepository) UpdatePurchaseOrder(ctx context.Context, id uint32, update *repository.PurchaseOrderUpdate) (*repository.PurchaseOrder, error) {
+	if update.Lines != nil {
+		if err := validatePurchaseOrderLines(update.Lines); err != nil {
+			return nil, err
+		}
+	}
+
+	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgOrder, err := getPurchaseOrderForUpdate(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		if pgOrder.Status != repository.PURCHASE_ORDER_DRAFT {
+			return pkg.Errorf(pkg.INVALID_ERROR, "purchase order is already %s, only drafts can be changed", pgOrder.Status)
+		}
+
+		params := generated.UpdatePurchaseOrderParams{
+			SupplierID:   pgtype.Int8{Valid: false},
+			ExpectedDate: pgtype.Date{Valid: false},
+			Note:         pgtype.Text{Valid: false},
+			ID:           pgOrder.ID,
+		}
+
+		if update.SupplierID != nil {
+			if err := checkSupplierActive(ctx, q, *update.SupplierID); err != nil {
+				return err
+			}
+			params.SupplierID = pgtype.Int8{Int64: int64(*update.SupplierID), Valid: true}
+		}
+
+		if update.ExpectedDate != nil {
+			params.ExpectedDate = pgtype.Date{Time: *update.ExpectedDate, Valid: true}
+		}
+
+		if update.Note != nil {
+			params.Note = pgtype.Text{String: *update.Note, Valid: true}
+		}
+
+		if _, err := q.UpdatePurchaseOrder(ctx, params); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update purchase order: %s", err.Error())
+		}
+
+		if update.Lines == nil {
+			return nil
+		}
+
+		if err := q.DeletePurchaseOrderLines(ctx, pgOrder.ID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete purchase order lines: %s", err.Error())
+		}
+
+		return createPurchaseOrderLines(ctx, q, pgOrder.ID, update.Lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.GetPurchaseOrder(ctx, id)
+}
+
+func (pr *PurchaseOrderRepository) SendPurchaseOrder(ctx context.Context, id uint32) (*repository.PurchaseOrder, error) {
+	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgOrder, err := getPurchaseOrderForUpdate(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		if pgOrder.Status != repository.PURCHASE_ORDER_DRAFT {
+			return pkg.Errorf(pkg.INVALID_ERROR, "purchase order is already %s", pgOrder.Status)
+		}
+
+		lines, err := q.CountPurchaseOrderLines(ctx, pgOrder.ID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count purchase order lines: %s", err.Error())
+		}
+
+		if lines == 0 {
+			return pkg.Errorf(pkg.INVALID_ERROR, "purchase order has no lines")
+		}
+
+		if _, err := q.UpdatePurchaseOrderStatus(ctx, generated.UpdatePurchaseOrderStatusParams{
+			Status: repository.PURCHASE_ORDER_SENT,
+			ID:     pgOrder.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update purchase order status: %s", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.GetPurchaseOrder(ctx, id)
+}
+
+func (pr *PurchaseOrderRepository) ReceivePurchaseOrderLine(ctx context.Context, id uint32, lineID uint32, batch *repository.ProductBatch) (*repository.PurchaseOrder, error) {
+	if batch.Quantity <= 0 {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "quantity must be greater than zero")
+	}
+
+	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgOrder, err := getPurchaseOrderForUpdate(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		if pgOrder.Status != repository.PURCHASE_ORDER_SENT && pgOrder.Status != repository.PURCHASE_ORDER_PARTIALLY_RECEIVED {
+			return pkg.Errorf(pkg.INVALID_ERROR, "cannot receive against a %s purchase order", pgOrder.Status)
+		}
+
+		pgLine, err := q.GetPurchaseOrderLineForUpdate(ctx, generated.GetPurchaseOrderLineForUpdateParams{
+			ID:              int64(lineID),
+			PurchaseOrderID: pgOrder.ID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "purchase order line not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get purchase order line: %s", err.Error())
+		}
+
+		outstanding := pgLine.QuantityOrdered - pgLine.QuantityReceived
+		if batch.Quantity > outstanding {
+			return pkg.Errorf(pkg.INVALID_ERROR, "only %d units are outstanding on the line, got %d", outstanding, batch.Quantity)
+		}
+
+		batch.ProductID = uint32(pgLine.ProductID)
+		batch.PurchaseOrderLineID = &lineID
+		if batch.PurchasePrice == 0 {
+			batch.PurchasePrice = pkg.PgTypeNumericToFloat64(pgLine.ExpectedPrice)
+		}
+
+		if err := addProductBatch(ctx, q, batch); err != nil {
+			return err
+		}
+
+		if _, err := q.AddPurchaseOrderLineReceived(ctx, generated.AddPurchaseOrderLineReceivedParams{
+			Quantity: batch.Quantity,
+			ID:       pgLine.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update purchase order line: %s", err.Error())
+		}
+
+		remaining, err := q.CountOutstandingPurchaseOrderLines(ctx, pgOrder.ID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count outstanding purchase order lines: %s", err.Error())
+		}
+
+		status := repository.PURCHASE_ORDER_PARTIALLY_RECEIVED
+		if remaining == 0 {
+			status = repository.PURCHASE_ORDER_RECEIVED
+		}
+
+		if status != pgOrder.Status {
+			if _, err := q.UpdatePurchaseOrderStatus(ctx, generated.UpdatePurchaseOrderStatusParams{
+				Status: status,
+				ID:     pgOrder.ID,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update purchase order status: %s", err.Error())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.GetPurchaseOrder(ctx, id)
+}
+
+func (pr *PurchaseOrderRepository) ClosePurchaseOrder(ctx context.Context, id uint32, reason string) (*repository.PurchaseOrder, error) {
+	err := pr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgOrder, err := getPurchaseOrderForUpdate(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		if pgOrder.Status != repository.PURCHASE_ORDER_SENT && pgOrder.Status != repository.PURCHASE_ORDER_PARTIALLY_RECEIVED {
+			return pkg.Errorf(pkg.INVALID_ERROR, "cannot close a %s purchase order", pgOrder.Status)
+		}
+
+		if _, err := q.ClosePurchaseOrder(ctx, generated.ClosePurchaseOrderParams{
+			CloseReason: pgtype.Text{String: reason, Valid: true},
+			ID:          pgOrder.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to close purchase order: %s", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr.GetPurchaseOrder(ctx, id)
+}
+
+func (pr *PurchaseOrderRepository) ListPurchaseOrders(ctx context.Context, filter *repository.PurchaseOrderFilter) ([]*repository.PurchaseOrder, *pkg.Pagination, error) {
+	listParams := generated.ListPurchaseOrdersParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		SupplierID: pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+	}
+
+	countParams := generated.ListPurchaseOrdersCountParams{
+		SupplierID: pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+	}
+
+	if filter.SupplierID != nil {
+		listParams.SupplierID = pgtype.Int8{Int64: int64(*filter.SupplierID), Valid: true}
+		countParams.SupplierID = pgtype.Int8{Int64: int64(*filter.SupplierID), Valid: true}
+	}
+
+	if filter.Status != nil {
+		listParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+		countParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+	}
+
+	if filter.ProductID != nil {
+		listParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+		countParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	pgOrders, err := pr.queries.ListPurchaseOrders(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list purchase orders: %s", err.Error())
+	}
+
+	totalCount, err := pr.queries.ListPurchaseOrdersCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count purchase orders: %s", err.Error())
+	}
+
+	orders := make([]*repository.PurchaseOrder, len(pgOrders))
+	for i, pgOrder := range pgOrders {
+		orders[i] = newPurchaseOrder(generated.PurchaseOrder{
+			ID:           pgOrder.ID,
+			SupplierID:   pgOrder.SupplierID,
+			Status:       pgOrder.Status,
+			ExpectedDate: pgOrder.ExpectedDate,
+			Note:         pgOrder.Note,
+			CreatedBy:    pgOrder.CreatedBy,
+			SentAt:       pgOrder.SentAt,
+			ReceivedAt:   pgOrder.ReceivedAt,
+			CreatedAt:    pgOrder.CreatedAt,
+			ClosedAt:     pgOrder.ClosedAt,
+			CloseReason:  pgOrder.CloseReason,
+		})
+		orders[i].QuantityOrdered = pgOrder.QuantityOrdered
+		orders[i].QuantityReceived = pgOrder.QuantityReceived
+		orders[i].TotalValue = pkg.PgTypeNumericToFloat64(pgOrder.TotalValue)
+		orders[i].Supplier = &repository.SupplierShort{
+			ID:   uint32(pgOrder.SupplierID),
+			Name: pgOrder.SupplierName,
+		}
+	}
+
+	return orders, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+func validatePurchaseOrderLines(lines []*repository.PurchaseOrderLine) error {
+	seen := make(map[uint32]bool, len(lines))
+	for _, line := range lines {
+		if line.QuantityOrdered <= 0 {
+			return pkg.Errorf(pkg.INVALID_ERROR, "quantity ordered must be greater than zero")
+		}
+
+		if line.ExpectedPrice <= 0 {
+			return pkg.Errorf(pkg.INVALID_ERROR, "expected price must be greater than zero")
+		}
+
+		if seen[line.ProductID] {
+			return pkg.Errorf(pkg.INVALID_ERROR, "product %d is on the purchase order more than once", line.ProductID)
+		}
+		seen[line.ProductID] = true
+	}
+
+	return nil
+}
+
+func createPurchaseOrderLines(ctx context.Context, q *generated.Queries, orderID int64, lines []*repository.PurchaseOrderLine) error {
+	for _, line := range lines {
+		if _, err := q.CreatePurchaseOrderLine(ctx, generated.CreatePurchaseOrderLineParams{
+			PurchaseOrderID: orderID,
+			ProductID:       int64(line.ProductID),
+			QuantityOrdered: line.QuantityOrdered,
+			ExpectedPrice:   pkg.Float64ToPgTypeNumeric(line.ExpectedPrice),
+		}); err != nil {
+			if pkg.PgxErrorCode(err) == pkg.FOREIGN_KEY_VIOLATION {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "product %d not found", line.ProductID)
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create purchase order line: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func checkSupplierActive(ctx context.Context, q *generated.Queries, id uint32) error {
+	pgSupplier, err := q.GetSupplier(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkg.Errorf(pkg.NOT_FOUND_ERROR, "supplier not found")
+		}
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get supplier: %s", err.Error())
+	}
+
+	if !pgSupplier.Active {
+		return pkg.Errorf(pkg.INVALID_ERROR, "supplier %s is inactive", pgSupplier.Name)
+	}
+
+	return nil
+}
+
+func getPurchaseOrderForUpdate(ctx context.Context, q *generated.Queries, id uint32) (generated.PurchaseOrder, error) {
+	pgOrder, err := q.GetPurchaseOrderForUpdate(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.PurchaseOrder{}, pkg.Errorf(pkg.NOT_FOUND_ERROR, "purchase order not found")
+		}
+		return generated.PurchaseOrder{}, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get purchase order: %s", err.Error())
+	}
+
+	return pgOrder, nil
+}
+
+func newSupplier(pgSupplier generated.Supplier) *repository.Supplier {
+	return &repository.Supplier{
+		ID:          uint32(pgSupplier.ID),
+		Name:        pgSupplier.Name,
+		ContactName: pgSupplier.ContactName.String,
+		PhoneNumber: pgSupplier.PhoneNumber.String,
+		Email:       pgSupplier.Email.String,
+		Address:     pgSupplier.Address.String,
+		Active:      pgSupplier.Active,
+		CreatedAt:   pgSupplier.CreatedAt,
+	}
+}
+
+func newPurchaseOrder(pgOrder generated.PurchaseOrder) *repository.PurchaseOrder {
+	order := &repository.PurchaseOrder{
+		ID:          uint32(pgOrder.ID),
+		SupplierID:  uint32(pgOrder.SupplierID),
+		Status:      pgOrder.Status,
+		Note:        pgOrder.Note.String,
+		CloseReason: pgOrder.CloseReason.String,
+		CreatedBy:   uint32(pgOrder.CreatedBy),
+		CreatedAt:   pgOrder.CreatedAt,
+	}
+
+	if pgOrder.ExpectedDate.Valid {
+		order.ExpectedDate = &pgOrder.ExpectedDate.Time
+	}
+
+	if pgOrder.SentAt.Valid {
+		order.SentAt = &pgOrder.SentAt.Time
+	}
+
+	if pgOrder.ReceivedAt.Valid {
+		order.ReceivedAt = &pgOrder.ReceivedAt.Time
+	}
+
+	if pgOrder.ClosedAt.Valid {
+		order.ClosedAt = &pgOrder.ClosedAt.Time
+	}
+
+	return order
+}
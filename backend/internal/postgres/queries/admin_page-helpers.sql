@@ -2,18 +2,25 @@
 WITH total_stock AS (
   SELECT COALESCE(SUM(cs.quantity), 0)::bigint AS total_units
   FROM company_stock cs
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR cs.location_id = sqlc.narg('location_id'))
+),
+product_stock AS (
+  SELECT p.id, p.low_stock_threshold, COALESCE(SUM(cs.quantity), 0)::bigint AS quantity
+  FROM products p
+  LEFT JOIN company_stock cs ON cs.product_id = p.id
+    AND (sqlc.narg('location_id')::bigint IS NULL OR cs.location_id = sqlc.narg('location_id'))
+  WHERE p.deleted = false
+  GROUP BY p.id, p.low_stock_threshold
 ),
 low_stock_items AS (
   SELECT COUNT(*)::bigint AS low_stock_count
-  FROM products p
-  JOIN company_stock cs ON cs.product_id = p.id
-  WHERE p.deleted = false AND cs.quantity <= p.low_stock_threshold
+  FROM product_stock
+  WHERE quantity <= low_stock_threshold
 ),
 out_of_stock AS (
   SELECT COUNT(*)::bigint AS out_of_stock_count
-  FROM products p
-  JOIN company_stock cs ON cs.product_id = p.id
-  WHERE p.deleted = false AND cs.quantity = 0
+  FROM product_stock
+  WHERE quantity = 0
 )
 SELECT 
   json_build_object(
@@ -25,23 +32,27 @@ SELECT
 -- name: GetAdminBatchesPageStats :one
 WITH total_batches AS (
   SELECT COUNT(*)::bigint AS batch_count
-  FROM product_batches
+  FROM product_batches pb
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR pb.location_id = sqlc.narg('location_id'))
 ),
 active_batches AS (
   SELECT COUNT(DISTINCT pb.id)::bigint AS active_count
   FROM product_batches pb
   JOIN batch_inventory bi ON bi.batch_id = pb.id
   WHERE bi.remaining_quantity > 0
+    AND (sqlc.narg('location_id')::bigint IS NULL OR bi.location_id = sqlc.narg('location_id'))
 ),
 total_value AS (
-  SELECT COALESCE(SUM(pb.quantity * pb.purchase_price), 0)::numeric AS batch_value
+  SELECT COALESCE(SUM(pb.quantity * pb.unit_cost), 0)::numeric AS batch_value
   FROM product_batches pb
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR pb.location_id = sqlc.narg('location_id'))
 ),
 remaining_value AS (
-  SELECT COALESCE(SUM(bi.remaining_quantity * pb.purchase_price), 0)::numeric AS remaining_stock_value
+  SELECT COALESCE(SUM(bi.remaining_quantity * pb.unit_cost), 0)::numeric AS remaining_stock_value
   FROM batch_inventory bi
   JOIN product_batches pb ON pb.id = bi.batch_id
   WHERE bi.remaining_quantity > 0
+    AND (sqlc.narg('location_id')::bigint IS NULL OR bi.location_id = sqlc.narg('location_id'))
 )
 SELECT 
   json_build_object(
@@ -52,21 +63,26 @@ SELECT
   ) AS batches_stats;
 
 -- name: GetAdminDistributionPageStats :one
-WITH total_distributions AS (
+WITH distributions AS (
+  SELECT sd.reseller_id, sd.quantity, sd.total_price
+  FROM stock_distributions sd
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR sd.location_id = sqlc.narg('location_id'))
+),
+total_distributions AS (
   SELECT COUNT(*)::bigint AS distribution_count
-  FROM stock_distributions
+  FROM distributions
 ),
 units_distributed AS (
   SELECT COALESCE(SUM(quantity), 0)::bigint AS units
-  FROM stock_distributions
+  FROM distributions
 ),
 total_value AS (
   SELECT COALESCE(SUM(total_price), 0)::numeric AS value
-  FROM stock_distributions
+  FROM distributions
 ),
 active_resellers_count AS (
   SELECT COUNT(DISTINCT reseller_id)::bigint AS reseller_count
-  FROM stock_distributions
+  FROM distributions
 )
 SELECT 
   json_build_object(
@@ -97,18 +113,29 @@ SELECT
 -- name: GetAdminPaymentsPageStats :one
 WITH payment_stats AS (
   SELECT 
-    COUNT(*)::bigint AS total_payments,
+    COUNT(*) FILTER (WHERE reversal_of IS NULL)::bigint AS total_payments,
     COALESCE(SUM(amount), 0)::numeric AS total_amount_received,
     COALESCE(SUM(amount) FILTER (WHERE method = 'MPESA'), 0)::numeric AS mpesa_total,
     COALESCE(SUM(amount) FILTER (WHERE method = 'CASH'), 0)::numeric AS cash_total
   FROM payments
+),
+method_totals AS (
+  SELECT pm.id, pm.code, pm.name, COALESCE(SUM(p.amount), 0)::numeric AS total
+  FROM payment_methods pm
+  LEFT JOIN payments p ON p.method = pm.code
+  GROUP BY pm.id, pm.code, pm.name
 )
 SELECT 
   json_build_object(
     'total_payments', (SELECT total_payments FROM payment_stats),
     'total_received', (SELECT total_amount_received FROM payment_stats),
+    -- kept for clients reading the fixed keys, method_totals covers every method
     'mpesa_total', (SELECT mpesa_total FROM payment_stats),
-    'cash_total', (SELECT cash_total FROM payment_stats)
+    'cash_total', (SELECT cash_total FROM payment_stats),
+    'method_totals', (
+      SELECT COALESCE(json_agg(json_build_object('method', code, 'name', name, 'total', total) ORDER BY id), '[]'::json)
+      FROM method_totals
+    )
   ) AS payments_stats;
 
 -- name: GetAdminResellersPageStats :one
@@ -145,7 +172,8 @@ WITH movement_stats AS (
     COUNT(*)::bigint AS total_movements,
     COALESCE(SUM(quantity) FILTER (WHERE movement_type = 'IN'), 0)::bigint AS stock_in,
     COALESCE(SUM(quantity) FILTER (WHERE movement_type = 'OUT'), 0)::bigint AS stock_out
-  FROM stock_movements
+  FROM stock_movements sm
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR sm.location_id = sqlc.narg('location_id'))
 )
 SELECT 
   json_build_object(
@@ -160,16 +188,27 @@ SELECT
 
 -- name: GetAdminDashboardStats :one
 WITH company_stock_total AS (
-  SELECT COALESCE(SUM(quantity), 0)::bigint AS total_stock
-  FROM company_stock
+  SELECT COALESCE(SUM(cs.quantity), 0)::bigint AS total_stock
+  FROM company_stock cs
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR cs.location_id = sqlc.narg('location_id'))
+),
+product_stock AS (
+  SELECT p.id, p.name, p.low_stock_threshold, COALESCE(SUM(cs.quantity), 0)::bigint AS quantity
+  FROM products p
+  LEFT JOIN company_stock cs ON cs.product_id = p.id
+    AND (sqlc.narg('location_id')::bigint IS NULL OR cs.location_id = sqlc.narg('location_id'))
+  WHERE p.deleted = false
+  GROUP BY p.id, p.name, p.low_stock_threshold
 ),
 distribution_units AS (
-  SELECT COALESCE(SUM(quantity), 0)::bigint AS units
-  FROM stock_distributions
+  SELECT COALESCE(SUM(sd.quantity), 0)::bigint AS units
+  FROM stock_distributions sd
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR sd.location_id = sqlc.narg('location_id'))
 ),
 distribution_value AS (
-  SELECT COALESCE(SUM(total_price), 0)::numeric AS value
-  FROM stock_distributions
+  SELECT COALESCE(SUM(sd.total_price), 0)::numeric AS value
+  FROM stock_distributions sd
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR sd.location_id = sqlc.narg('location_id'))
 ),
 payments_received AS (
   SELECT COALESCE(SUM(amount), 0)::numeric AS total
@@ -177,9 +216,8 @@ payments_received AS (
 ),
 low_stock_products AS (
   SELECT COUNT(*)::bigint AS low_stock_count
-  FROM products p
-  JOIN company_stock cs ON cs.product_id = p.id
-  WHERE p.deleted = false AND cs.quantity <= p.low_stock_threshold
+  FROM product_stock ps
+  WHERE ps.quantity <= ps.low_stock_threshold
 ),
 pending_requests AS (
   SELECT COUNT(*)::bigint AS pending_count
@@ -200,19 +238,17 @@ recent_activities AS (
 ),
 stock_alerts AS (
   SELECT 
-    p.id,
-    p.name AS product_name,
-    cs.quantity,
-    p.low_stock_threshold,
+    ps.id,
+    ps.name AS product_name,
+    ps.quantity,
+    ps.low_stock_threshold,
     CASE 
-      WHEN cs.quantity = 0 THEN 'OUT_OF_STOCK'
+      WHEN ps.quantity = 0 THEN 'OUT_OF_STOCK'
       ELSE 'LOW_STOCK'
     END AS alert_type
-  FROM products p
-  JOIN company_stock cs ON cs.product_id = p.id
-  WHERE p.deleted = false 
-    AND cs.quantity <= p.low_stock_threshold
-  ORDER BY cs.quantity ASC, p.name
+  FROM product_stock ps
+  WHERE ps.quantity <= ps.low_stock_threshold
+  ORDER BY ps.quantity ASC, ps.name
 ),
 top_resellers AS (
   SELECT 
@@ -266,13 +302,15 @@ distributed AS (
   SELECT
     DATE(date_distributed)::date AS day,
     COALESCE(SUM(quantity), 0)::bigint AS units_distributed
-  FROM stock_distributions
+  FROM stock_distributions sd
   WHERE date_distributed >= CURRENT_DATE - INTERVAL '6 days'
+    AND (sqlc.narg('location_id')::bigint IS NULL OR sd.location_id = sqlc.narg('location_id'))
   GROUP BY DATE(date_distributed)
 ),
 current_in_stock AS (
-  SELECT COALESCE(SUM(quantity), 0)::bigint AS current_units
-  FROM company_stock
+  SELECT COALESCE(SUM(cs.quantity), 0)::bigint AS current_units
+  FROM company_stock cs
+  WHERE (sqlc.narg('location_id')::bigint IS NULL OR cs.location_id = sqlc.narg('location_id'))
 ),
 future_distributions AS (
   -- cumulative distributions after a given day (to reconstruct past stock)
@@ -281,6 +319,7 @@ future_distributions AS (
            SELECT SUM(quantity)
            FROM stock_distributions sd
            WHERE DATE(sd.date_distributed) > ds.day
+             AND (sqlc.narg('location_id')::bigint IS NULL OR sd.location_id = sqlc.narg('location_id'))
          ), 0)::bigint AS future_units
   FROM date_series ds
 )
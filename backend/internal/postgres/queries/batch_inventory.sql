@@ -1,25 +1,30 @@
 -- name: CreateBatchInventoryRecord :one
-INSERT INTO batch_inventory (batch_id, product_id, remaining_quantity)
-VALUES ($1, $2, $3)
+INSERT INTO batch_inventory (batch_id, location_id, product_id, remaining_quantity)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: AddBatchInventoryQuantity :one
-UPDATE batch_inventory
-SET remaining_quantity = remaining_quantity + sqlc.arg('quantity')
-WHERE batch_id = sqlc.arg('batch_id') AND product_id = sqlc.arg('product_id')
+INSERT INTO batch_inventory (batch_id, location_id, product_id, remaining_quantity)
+VALUES (sqlc.arg('batch_id'), sqlc.arg('location_id'), sqlc.arg('product_id'), sqlc.arg('quantity'))
+ON CONFLICT (batch_id, location_id) DO UPDATE
+SET remaining_quantity = batch_inventory.remaining_quantity + EXCLUDED.remaining_quantity
 RETURNING *;
 
 -- name: RemoveBatchInventoryQuantity :one
 UPDATE batch_inventory
 SET remaining_quantity = remaining_quantity - sqlc.arg('quantity')
-WHERE batch_id = sqlc.arg('batch_id') AND product_id = sqlc.arg('product_id') AND remaining_quantity >= sqlc.arg('quantity')
+WHERE batch_id = sqlc.arg('batch_id') AND location_id = sqlc.arg('location_id') AND remaining_quantity >= sqlc.arg('quantity')
 RETURNING *;
 
 -- name: GetBatchInventoryProductSum :one
-SELECT COALESCE(SUM(remaining_quantity), 0)::bigint AS total_remaining
-FROM batch_inventory
-WHERE product_id = sqlc.arg('product_id')
-      AND remaining_quantity > 0;
+SELECT 
+    COALESCE(SUM(bi.remaining_quantity) FILTER (WHERE pb.expiry_date IS NULL OR pb.expiry_date >= CURRENT_DATE), 0)::bigint AS total_remaining,
+    COALESCE(SUM(bi.remaining_quantity) FILTER (WHERE pb.expiry_date < CURRENT_DATE), 0)::bigint AS total_expired
+FROM batch_inventory bi
+JOIN product_batches pb ON pb.id = bi.batch_id
+WHERE bi.product_id = sqlc.arg('product_id')
+      AND bi.location_id = sqlc.arg('location_id')
+      AND bi.remaining_quantity > 0;
 
 -- name: ListBatchInventoryForUpdate :many
 SELECT 
@@ -29,15 +34,34 @@ FROM batch_inventory bi
 JOIN product_batches pb ON pb.id = bi.batch_id
 WHERE 
     bi.product_id = sqlc.arg('product_id')
+    AND bi.location_id = sqlc.arg('location_id')
     AND bi.remaining_quantity > 0
-ORDER BY pb.date_received ASC
+    AND (pb.expiry_date IS NULL OR pb.expiry_date >= CURRENT_DATE)
+ORDER BY
+    CASE WHEN sqlc.arg('strategy')::text = 'LIFO' THEN pb.date_received END DESC,
+    CASE WHEN sqlc.arg('strategy')::text = 'LIFO' THEN pb.id END DESC,
+    CASE WHEN sqlc.arg('strategy')::text = 'FEFO' THEN pb.expiry_date END ASC NULLS LAST,
+    pb.date_received ASC,
+    pb.id ASC
+FOR UPDATE;
+
+-- name: ListBatchInventoryByBatchIDsForUpdate :many
+SELECT * FROM batch_inventory
+WHERE batch_id = ANY(sqlc.arg('batch_ids')::bigint[])
+ORDER BY batch_id ASC, location_id ASC
 FOR UPDATE;
 
 -- name: ListBatchInventory :many
-SELECT pb.*, p.name AS product_name, bi.remaining_quantity, p.price AS product_price, p.unit AS product_unit, p.low_stock_threshold AS product_low_stock_threshold, p.category AS product_category
+SELECT pb.*, p.name AS product_name, bi.remaining_quantity, p.price AS product_price, p.unit AS product_unit, p.low_stock_threshold AS product_low_stock_threshold, p.category AS product_category, l.name AS location_name
 FROM product_batches pb
 JOIN products p ON p.id = pb.product_id
-JOIN batch_inventory bi ON bi.batch_id = pb.id
+JOIN locations l ON l.id = pb.location_id
+JOIN LATERAL (
+    SELECT COUNT(*) AS locations, COALESCE(SUM(remaining_quantity), 0)::bigint AS remaining_quantity
+    FROM batch_inventory
+    WHERE batch_id = pb.id
+      AND (sqlc.narg('location_id')::bigint IS NULL OR location_id = sqlc.narg('location_id'))
+) bi ON bi.locations > 0
 WHERE 
     (
         sqlc.narg('product_id')::bigint IS NULL
@@ -61,7 +85,12 @@ LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 SELECT COUNT(*) AS total_batches
 FROM product_batches pb
 LEFT JOIN products p ON p.id = pb.product_id
-JOIN batch_inventory bi ON bi.batch_id = pb.id
+JOIN LATERAL (
+    SELECT COUNT(*) AS locations, COALESCE(SUM(remaining_quantity), 0)::bigint AS remaining_quantity
+    FROM batch_inventory
+    WHERE batch_id = pb.id
+      AND (sqlc.narg('location_id')::bigint IS NULL OR location_id = sqlc.narg('location_id'))
+) bi ON bi.locations > 0
 WHERE 
     (
         sqlc.narg('product_id')::bigint IS NULL
@@ -78,3 +107,21 @@ WHERE
         OR (sqlc.narg('in_stock') = true AND bi.remaining_quantity > 0)
         OR (sqlc.narg('in_stock') = false AND bi.remaining_quantity = 0)
     );
+
+-- name: ListBatchInventoryForAdjustment :many
+SELECT 
+    bi.*,
+    pb.batch_number,
+    pb.unit_cost
+FROM batch_inventory bi
+JOIN product_batches pb ON pb.id = bi.batch_id
+WHERE 
+    bi.product_id = sqlc.arg('product_id')
+    AND bi.location_id = sqlc.arg('location_id')
+    AND (
+        sqlc.narg('batch_id')::bigint IS NULL
+        OR bi.batch_id = sqlc.narg('batch_id')
+    )
+    AND (bi.remaining_quantity > 0 OR sqlc.narg('batch_id')::bigint IS NOT NULL)
+ORDER BY pb.date_received ASC
+FOR UPDATE;
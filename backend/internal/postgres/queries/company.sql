@@ -1,18 +1,19 @@
 -- name: CreateCompanyStock :one
-INSERT INTO company_stock (product_id)
-VALUES ($1)
+INSERT INTO company_stock (product_id, location_id)
+SELECT $1, id FROM locations WHERE is_default
 RETURNING *;
 
 -- name: AddCompanyStock :one
-UPDATE company_stock
-SET quantity = quantity + sqlc.arg('quantity')
-WHERE product_id = sqlc.arg('product_id')
+INSERT INTO company_stock (product_id, location_id, quantity)
+VALUES (sqlc.arg('product_id'), sqlc.arg('location_id'), sqlc.arg('quantity'))
+ON CONFLICT (product_id, location_id) DO UPDATE
+SET quantity = company_stock.quantity + EXCLUDED.quantity
 RETURNING *;
 
 -- name: RemoveCompanyStock :one
 UPDATE company_stock
 SET quantity = quantity - sqlc.arg('quantity')
-WHERE product_id = sqlc.arg('product_id') AND quantity >= sqlc.arg('quantity')
+WHERE product_id = sqlc.arg('product_id') AND location_id = sqlc.arg('location_id') AND quantity >= sqlc.arg('quantity')
 RETURNING *;
 
 -- name: ListCompanyStock :many
@@ -25,8 +26,13 @@ SELECT
     p.low_stock_threshold,
     p.description,
     cs.quantity AS company_quantity
-FROM company_stock cs
-JOIN products p ON p.id = cs.product_id
+FROM products p
+JOIN LATERAL (
+    SELECT COALESCE(SUM(quantity), 0)::bigint AS quantity
+    FROM company_stock
+    WHERE product_id = p.id
+      AND (sqlc.narg('location_id')::bigint IS NULL OR location_id = sqlc.narg('location_id'))
+) cs ON true
 WHERE 
     (
         COALESCE(sqlc.narg('search'), '') = '' 
@@ -44,8 +50,13 @@ LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: ListCompanyStockCount :one
 SELECT COUNT(*) AS total_items
-FROM company_stock cs
-JOIN products p ON p.id = cs.product_id
+FROM products p
+JOIN LATERAL (
+    SELECT COALESCE(SUM(quantity), 0)::bigint AS quantity
+    FROM company_stock
+    WHERE product_id = p.id
+      AND (sqlc.narg('location_id')::bigint IS NULL OR location_id = sqlc.narg('location_id'))
+) cs ON true
 WHERE 
     (
         COALESCE(sqlc.narg('search'), '') = '' 
@@ -0,0 +1,52 @@
+-- name: CreateCreditHold :one
+INSERT INTO credit_holds (reseller_id, product_id, quantity, unit_price, date_distributed, defer_invoice, due_date, balance, credit_limit, status, justification, distribution_id, decided_at, location_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING *;
+
+-- name: GetCreditHoldForUpdate :one
+SELECT * FROM credit_holds
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ResolveCreditHold :one
+UPDATE credit_holds
+SET status = sqlc.arg('status'),
+    justification = sqlc.arg('justification'),
+    distribution_id = sqlc.narg('distribution_id'),
+    decided_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListCreditHolds :many
+SELECT ch.*,
+    u.name AS reseller_name,
+    u.phone_number AS reseller_phone_number,
+    p.name AS product_name,
+    p.unit AS product_unit
+FROM credit_holds ch
+JOIN users u ON u.id = ch.reseller_id
+JOIN products p ON p.id = ch.product_id
+WHERE 
+    (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR ch.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR ch.status = sqlc.narg('status')
+    )
+ORDER BY ch.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListCreditHoldsCount :one
+SELECT COUNT(*) AS total_holds
+FROM credit_holds ch
+WHERE 
+    (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR ch.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR ch.status = sqlc.narg('status')
+    );
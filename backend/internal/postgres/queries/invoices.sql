@@ -0,0 +1,143 @@
+-- name: NextInvoiceNumber :one
+UPDATE invoice_counter
+SET last_number = last_number + 1
+WHERE id = 1
+RETURNING last_number;
+
+-- name: CreateInvoice :one
+INSERT INTO invoices (invoice_number, reseller_id, total_amount, issue_date, due_date)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetInvoice :one
+SELECT i.*,
+    u.name AS reseller_name,
+    u.phone_number AS reseller_phone_number
+FROM invoices i
+JOIN users u ON u.id = i.reseller_id
+WHERE i.id = $1;
+
+-- name: GetInvoiceForUpdate :one
+SELECT * FROM invoices
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListOpenInvoicesForUpdate :many
+SELECT * FROM invoices
+WHERE reseller_id = $1 AND status <> 'PAID'
+ORDER BY due_date, issue_date, id
+FOR UPDATE;
+
+-- name: AddInvoicePayment :one
+UPDATE invoices
+SET amount_paid = amount_paid + sqlc.arg('amount'),
+    status = CASE
+        WHEN amount_paid + sqlc.arg('amount') >= total_amount THEN 'PAID'
+        WHEN amount_paid + sqlc.arg('amount') > 0 THEN 'PARTIAL'
+        ELSE 'UNPAID'
+    END,
+    updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListInvoices :many
+SELECT i.*,
+    u.name AS reseller_name,
+    u.phone_number AS reseller_phone_number
+FROM invoices i
+JOIN users u ON u.id = i.reseller_id
+WHERE 
+    (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR i.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR i.status = sqlc.narg('status')
+    )
+    AND (
+        sqlc.narg('open')::boolean IS NULL
+        OR (i.status <> 'PAID') = sqlc.narg('open')
+    )
+    AND (
+        COALESCE(sqlc.narg('search'), '') = '' 
+        OR LOWER(i.invoice_number) LIKE sqlc.narg('search')
+        OR LOWER(u.name) LIKE sqlc.narg('search')
+    )
+ORDER BY i.issue_date DESC, i.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListInvoicesCount :one
+SELECT COUNT(*) AS total_invoices
+FROM invoices i
+JOIN users u ON u.id = i.reseller_id
+WHERE 
+    (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR i.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR i.status = sqlc.narg('status')
+    )
+    AND (
+        sqlc.narg('open')::boolean IS NULL
+        OR (i.status <> 'PAID') = sqlc.narg('open')
+    )
+    AND (
+        COALESCE(sqlc.narg('search'), '') = '' 
+        OR LOWER(i.invoice_number) LIKE sqlc.narg('search')
+        OR LOWER(u.name) LIKE sqlc.narg('search')
+    );
+
+-- name: ListStockDistributionsForInvoice :many
+SELECT * FROM stock_distributions
+WHERE id = ANY(sqlc.arg('ids')::bigint[])
+ORDER BY date_distributed, id
+FOR UPDATE;
+
+-- name: SetStockDistributionInvoice :exec
+UPDATE stock_distributions
+SET invoice_id = sqlc.arg('invoice_id')
+WHERE id = ANY(sqlc.arg('ids')::bigint[]);
+
+-- name: ListInvoiceDistributions :many
+SELECT sd.*,
+    p.name AS product_name,
+    p.unit AS product_unit
+FROM stock_distributions sd
+JOIN products p ON p.id = sd.product_id
+WHERE sd.invoice_id = $1
+ORDER BY sd.date_distributed, sd.id;
+
+-- name: CreatePaymentAllocation :one
+INSERT INTO payment_allocations (payment_id, invoice_id, amount, allocated_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeletePaymentAllocations :many
+DELETE FROM payment_allocations
+WHERE payment_id = $1
+RETURNING *;
+
+-- name: ListInvoiceAllocations :many
+SELECT pa.*,
+    p.method,
+    p.reference,
+    p.date_paid
+FROM payment_allocations pa
+JOIN payments p ON p.id = pa.payment_id
+WHERE pa.invoice_id = $1
+ORDER BY pa.created_at, pa.id;
+
+-- name: ListUnallocatedPayments :many
+SELECT p.id,
+    (p.amount - COALESCE(SUM(pa.amount), 0))::numeric AS unallocated
+FROM payments p
+LEFT JOIN payment_allocations pa ON pa.payment_id = p.id
+WHERE p.reseller_id = $1
+    AND p.reversal_of IS NULL
+    AND NOT EXISTS (SELECT 1 FROM payments r WHERE r.reversal_of = p.id)
+GROUP BY p.id
+HAVING p.amount - COALESCE(SUM(pa.amount), 0) > 0
+ORDER BY p.date_paid, p.id;
@@ -0,0 +1,79 @@
+-- name: CreateLandedCost :one
+INSERT INTO landed_costs (cost_type, amount, allocation_method, reference, note, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetLandedCost :one
+SELECT * FROM landed_costs
+WHERE id = $1;
+
+-- name: ListLandedCosts :many
+SELECT lc.*, 
+    (SELECT COUNT(*) FROM landed_cost_allocations lca WHERE lca.landed_cost_id = lc.id)::bigint AS batch_count
+FROM landed_costs lc
+WHERE 
+    (
+        sqlc.narg('cost_type')::text IS NULL
+        OR lc.cost_type = sqlc.narg('cost_type')
+    )
+    AND (
+        sqlc.narg('batch_id')::bigint IS NULL
+        OR EXISTS (
+            SELECT 1 FROM landed_cost_allocations lca
+            WHERE lca.landed_cost_id = lc.id AND lca.batch_id = sqlc.narg('batch_id')
+        )
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR EXISTS (
+            SELECT 1 FROM landed_cost_allocations lca
+            JOIN product_batches pb ON pb.id = lca.batch_id
+            WHERE lca.landed_cost_id = lc.id AND pb.product_id = sqlc.narg('product_id')
+        )
+    )
+ORDER BY lc.created_at DESC, lc.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListLandedCostsCount :one
+SELECT COUNT(*) AS total_landed_costs
+FROM landed_costs lc
+WHERE 
+    (
+        sqlc.narg('cost_type')::text IS NULL
+        OR lc.cost_type = sqlc.narg('cost_type')
+    )
+    AND (
+        sqlc.narg('batch_id')::bigint IS NULL
+        OR EXISTS (
+            SELECT 1 FROM landed_cost_allocations lca
+            WHERE lca.landed_cost_id = lc.id AND lca.batch_id = sqlc.narg('batch_id')
+        )
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR EXISTS (
+            SELECT 1 FROM landed_cost_allocations lca
+            JOIN product_batches pb ON pb.id = lca.batch_id
+            WHERE lca.landed_cost_id = lc.id AND pb.product_id = sqlc.narg('product_id')
+        )
+    );
+
+-- name: CreateLandedCostAllocation :one
+INSERT INTO landed_cost_allocations (landed_cost_id, batch_id, amount, unit_cost_added, quantity, rounding_difference)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListLandedCostAllocations :many
+SELECT lca.*,
+    pb.batch_number,
+    pb.product_id,
+    p.name AS product_name,
+    p.unit AS product_unit,
+    pb.quantity AS batch_quantity,
+    pb.purchase_price,
+    pb.unit_cost
+FROM landed_cost_allocations lca
+JOIN product_batches pb ON pb.id = lca.batch_id
+JOIN products p ON p.id = pb.product_id
+WHERE lca.landed_cost_id = $1
+ORDER BY lca.id ASC;
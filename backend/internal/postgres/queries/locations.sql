@@ -0,0 +1,113 @@
+-- name: CreateLocation :one
+INSERT INTO locations (name, address)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetLocation :one
+SELECT * FROM locations
+WHERE id = $1;
+
+-- name: GetDefaultLocation :one
+SELECT * FROM locations
+WHERE is_default;
+
+-- name: UpdateLocation :one
+UPDATE locations
+SET name = coalesce(sqlc.narg('name'), name),
+    address = coalesce(sqlc.narg('address'), address),
+    active = coalesce(sqlc.narg('active'), active),
+    is_default = coalesce(sqlc.narg('is_default'), is_default)
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ClearDefaultLocation :exec
+UPDATE locations
+SET is_default = false
+WHERE is_default AND id <> $1;
+
+-- name: GetLocationStockQuantity :one
+SELECT COALESCE(SUM(quantity), 0)::bigint AS total_quantity
+FROM company_stock
+WHERE location_id = $1;
+
+-- name: ListLocations :many
+SELECT l.*,
+    totals.total_quantity,
+    totals.total_value
+FROM locations l
+JOIN LATERAL (
+    SELECT
+        COALESCE(SUM(bi.remaining_quantity), 0)::bigint AS total_quantity,
+        COALESCE(SUM(bi.remaining_quantity * pb.unit_cost), 0)::numeric AS total_value
+    FROM batch_inventory bi
+    JOIN product_batches pb ON pb.id = bi.batch_id
+    WHERE bi.location_id = l.id
+) totals ON true
+WHERE 
+    (
+        sqlc.narg('active')::boolean IS NULL
+        OR l.active = sqlc.narg('active')
+    )
+    AND (
+        COALESCE(sqlc.narg('search'), '') = '' 
+        OR LOWER(l.name) LIKE sqlc.narg('search')
+        OR LOWER(l.address) LIKE sqlc.narg('search')
+    )
+ORDER BY l.is_default DESC, l.name ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListLocationsCount :one
+SELECT COUNT(*) AS total_locations
+FROM locations l
+WHERE 
+    (
+        sqlc.narg('active')::boolean IS NULL
+        OR l.active = sqlc.narg('active')
+    )
+    AND (
+        COALESCE(sqlc.narg('search'), '') = '' 
+        OR LOWER(l.name) LIKE sqlc.narg('search')
+        OR LOWER(l.address) LIKE sqlc.narg('search')
+    );
+
+-- name: CreateLocationTransfer :one
+INSERT INTO location_transfers (product_id, from_location_id, to_location_id, batch_id, quantity, total_value, note, from_movement_id, to_movement_id, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: ListLocationTransfers :many
+SELECT lt.*,
+    p.name AS product_name,
+    p.unit AS product_unit,
+    fl.name AS from_location_name,
+    tl.name AS to_location_name
+FROM location_transfers lt
+JOIN products p ON p.id = lt.product_id
+JOIN locations fl ON fl.id = lt.from_location_id
+JOIN locations tl ON tl.id = lt.to_location_id
+WHERE 
+    (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR lt.from_location_id = sqlc.narg('location_id')
+        OR lt.to_location_id = sqlc.narg('location_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR lt.product_id = sqlc.narg('product_id')
+    )
+ORDER BY lt.created_at DESC, lt.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListLocationTransfersCount :one
+SELECT COUNT(*) AS total_transfers
+FROM location_transfers lt
+WHERE 
+    (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR lt.from_location_id = sqlc.narg('location_id')
+        OR lt.to_location_id = sqlc.narg('location_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR lt.product_id = sqlc.narg('product_id')
+    );
@@ -0,0 +1,71 @@
+-- name: CreateMpesaC2bTransaction :one
+INSERT INTO mpesa_c2b_transactions (trans_id, trans_type, trans_time, amount, bill_ref_number, msisdn, payer_name)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (trans_id) DO NOTHING
+RETURNING *;
+
+-- name: GetMpesaC2bTransactionByTransID :one
+SELECT * FROM mpesa_c2b_transactions
+WHERE trans_id = $1;
+
+-- name: GetMpesaC2bTransactionForUpdate :one
+SELECT * FROM mpesa_c2b_transactions
+WHERE id = $1
+FOR UPDATE;
+
+-- name: AllocateMpesaC2bTransaction :one
+UPDATE mpesa_c2b_transactions
+SET status = 'ALLOCATED',
+    reseller_id = sqlc.arg('reseller_id'),
+    payment_id = sqlc.arg('payment_id'),
+    allocated_by = sqlc.arg('allocated_by'),
+    allocated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListMpesaC2bTransactions :many
+SELECT * FROM mpesa_c2b_transactions
+WHERE 
+    (
+        sqlc.narg('status')::text IS NULL
+        OR status = sqlc.narg('status')
+    )
+    AND (
+        COALESCE(sqlc.narg('search'), '') = ''
+        OR LOWER(trans_id) LIKE sqlc.narg('search')
+        OR LOWER(bill_ref_number) LIKE sqlc.narg('search')
+        OR LOWER(msisdn) LIKE sqlc.narg('search')
+        OR LOWER(payer_name) LIKE sqlc.narg('search')
+    )
+ORDER BY trans_time DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListMpesaC2bTransactionsCount :one
+SELECT COUNT(*) AS total_transactions
+FROM mpesa_c2b_transactions
+WHERE 
+    (
+        sqlc.narg('status')::text IS NULL
+        OR status = sqlc.narg('status')
+    )
+    AND (
+        COALESCE(sqlc.narg('search'), '') = ''
+        OR LOWER(trans_id) LIKE sqlc.narg('search')
+        OR LOWER(bill_ref_number) LIKE sqlc.narg('search')
+        OR LOWER(msisdn) LIKE sqlc.narg('search')
+        OR LOWER(payer_name) LIKE sqlc.narg('search')
+    );
+
+-- name: GetActiveResellerID :one
+SELECT id FROM users
+WHERE id = $1
+    AND role = 'staff'
+    AND deleted = false;
+
+-- name: ListResellerIDsByPhoneSuffix :many
+SELECT id FROM users
+WHERE role = 'staff'
+    AND deleted = false
+    -- phone numbers are stored in mixed formats so only the subscriber digits are compared
+    AND RIGHT(regexp_replace(phone_number, '[^0-9]', '', 'g'), 9) = sqlc.arg('phone_suffix')
+LIMIT 2;
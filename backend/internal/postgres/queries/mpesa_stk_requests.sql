@@ -0,0 +1,24 @@
+-- name: CreateMpesaStkRequest :one
+INSERT INTO mpesa_stk_requests (reseller_id, phone_number, amount, merchant_request_id, checkout_request_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetMpesaStkRequest :one
+SELECT * FROM mpesa_stk_requests
+WHERE id = $1;
+
+-- name: GetMpesaStkRequestByCheckoutIDForUpdate :one
+SELECT * FROM mpesa_stk_requests
+WHERE checkout_request_id = $1
+FOR UPDATE;
+
+-- name: CompleteMpesaStkRequest :one
+UPDATE mpesa_stk_requests
+SET status = sqlc.arg('status'),
+    result_code = sqlc.arg('result_code'),
+    result_desc = sqlc.arg('result_desc'),
+    mpesa_receipt = sqlc.narg('mpesa_receipt'),
+    payment_id = sqlc.narg('payment_id'),
+    updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
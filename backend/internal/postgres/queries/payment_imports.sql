@@ -0,0 +1,60 @@
+-- name: CreatePaymentImport :one
+INSERT INTO payment_imports (source, method, file_name, uploaded_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: CreatePaymentImportRow :one
+INSERT INTO payment_import_rows (
+    import_id, row_number, reference, amount, date_paid, payer_name, payer_phone,
+    account_number, details, reseller_id, status, note, duplicate_payment_id
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING *;
+
+-- name: GetPaymentImport :one
+SELECT * FROM payment_imports WHERE id = $1;
+
+-- name: GetPaymentImportForUpdate :one
+SELECT * FROM payment_imports
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CommitPaymentImport :exec
+UPDATE payment_imports
+SET status = 'COMMITTED',
+    committed_at = now()
+WHERE id = $1;
+
+-- name: ListPaymentImportRows :many
+SELECT r.*, u.name AS reseller_name, u.phone_number AS reseller_phone_number
+FROM payment_import_rows r
+LEFT JOIN users u ON u.id = r.reseller_id
+WHERE r.import_id = $1
+ORDER BY r.row_number;
+
+-- name: ImportPaymentImportRow :exec
+UPDATE payment_import_rows
+SET status = 'IMPORTED',
+    reseller_id = sqlc.arg('reseller_id'),
+    payment_id = sqlc.arg('payment_id')
+WHERE id = sqlc.arg('id');
+
+-- name: SkipPaymentImportRows :exec
+UPDATE payment_import_rows
+SET status = 'SKIPPED'
+WHERE import_id = $1 AND status <> 'IMPORTED';
+
+-- name: ListPaymentImports :many
+SELECT * FROM payment_imports
+WHERE 
+    sqlc.narg('status')::text IS NULL
+    OR status = sqlc.narg('status')
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListPaymentImportsCount :one
+SELECT COUNT(*) AS total_imports
+FROM payment_imports
+WHERE 
+    sqlc.narg('status')::text IS NULL
+    OR status = sqlc.narg('status');
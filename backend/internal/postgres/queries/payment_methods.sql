@@ -0,0 +1,25 @@
+-- name: CreatePaymentMethod :one
+INSERT INTO payment_methods (code, name, reference_required, reference_pattern, active)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetPaymentMethodByCode :one
+SELECT * FROM payment_methods WHERE code = $1;
+
+-- name: UpdatePaymentMethod :one
+UPDATE payment_methods
+SET name = coalesce(sqlc.narg('name'), name),
+    reference_required = coalesce(sqlc.narg('reference_required'), reference_required),
+    reference_pattern = CASE
+        WHEN sqlc.narg('reference_pattern')::text IS NULL THEN reference_pattern
+        ELSE NULLIF(sqlc.narg('reference_pattern'), '')
+    END,
+    active = coalesce(sqlc.narg('active'), active),
+    updated_at = now()
+WHERE code = sqlc.arg('code')
+RETURNING *;
+
+-- name: ListPaymentMethods :many
+SELECT * FROM payment_methods
+WHERE sqlc.narg('active')::boolean IS NULL OR active = sqlc.narg('active')
+ORDER BY id;
@@ -3,10 +3,21 @@ INSERT INTO payments (reseller_id, amount, method, reference, recorded_by, date_
 VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
+-- name: CreatePaymentReversal :one
+INSERT INTO payments (reseller_id, amount, method, reference, recorded_by, date_paid, reversal_of, reversal_reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetPaymentForUpdate :one
+SELECT * FROM payments
+WHERE id = $1
+FOR UPDATE;
+
 -- name: ListPayments :many
-SELECT p.*, u.name, u.phone_number 
+SELECT p.*, u.name, u.phone_number, r.id AS reversed_by
 FROM payments p
 JOIN users u ON u.id = p.reseller_id
+LEFT JOIN payments r ON r.reversal_of = p.id
 WHERE 
     (
         sqlc.narg('reseller_id')::bigint IS NULL
@@ -65,4 +76,10 @@ WHERE
     AND (
         sqlc.narg('date_to')::date IS NULL
         OR p.date_paid::date <= sqlc.narg('date_to')
-    );
\ No newline at end of file
+    );
+-- name: ListPaymentsByReferences :many
+SELECT p.id, UPPER(p.reference)::text AS reference
+FROM payments p
+WHERE p.reversal_of IS NULL
+    AND UPPER(p.reference) = ANY(sqlc.arg('references')::text[])
+    AND NOT EXISTS (SELECT 1 FROM payments r WHERE r.reversal_of = p.id);
@@ -1,6 +1,6 @@
 -- name: CreateProductBatchRecord :one
-INSERT INTO product_batches (product_id, batch_number, quantity, purchase_price, date_received)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO product_batches (product_id, batch_number, quantity, purchase_price, date_received, expiry_date, location_id, purchase_order_line_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: ListProductBatches :many
@@ -36,3 +36,15 @@ WHERE
         OR LOWER(p.category) LIKE sqlc.narg('search')
         OR LOWER(pb.batch_number) LIKE sqlc.narg('search')
     );
+
+-- name: ListProductBatchesForUpdate :many
+SELECT * FROM product_batches
+WHERE id = ANY(sqlc.arg('ids')::bigint[])
+ORDER BY id ASC
+FOR UPDATE;
+
+-- name: AddProductBatchLandedCost :one
+UPDATE product_batches
+SET landed_unit_cost = landed_unit_cost + sqlc.arg('landed_unit_cost')
+WHERE id = sqlc.arg('id')
+RETURNING *;
@@ -0,0 +1,150 @@
+-- name: CreatePurchaseOrder :one
+INSERT INTO purchase_orders (supplier_id, expected_date, note, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetPurchaseOrder :one
+SELECT po.*,
+    s.name AS supplier_name,
+    totals.quantity_ordered,
+    totals.quantity_received,
+    totals.total_value
+FROM purchase_orders po
+JOIN suppliers s ON s.id = po.supplier_id
+JOIN LATERAL (
+    SELECT
+        COALESCE(SUM(pol.quantity_ordered), 0)::bigint AS quantity_ordered,
+        COALESCE(SUM(pol.quantity_received), 0)::bigint AS quantity_received,
+        COALESCE(SUM(pol.quantity_ordered * pol.expected_price), 0)::numeric AS total_value
+    FROM purchase_order_lines pol
+    WHERE pol.purchase_order_id = po.id
+) totals ON true
+WHERE po.id = $1;
+
+-- name: GetPurchaseOrderForUpdate :one
+SELECT * FROM purchase_orders
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdatePurchaseOrder :one
+UPDATE purchase_orders
+SET supplier_id = coalesce(sqlc.narg('supplier_id'), supplier_id),
+    expected_date = coalesce(sqlc.narg('expected_date'), expected_date),
+    note = coalesce(sqlc.narg('note'), note)
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: UpdatePurchaseOrderStatus :one
+UPDATE purchase_orders
+SET status = sqlc.arg('status'),
+    sent_at = CASE WHEN sqlc.arg('status') <> 'DRAFT' THEN COALESCE(sent_at, now()) END,
+    received_at = CASE WHEN sqlc.arg('status') = 'RECEIVED' THEN COALESCE(received_at, now()) END
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ClosePurchaseOrder :one
+UPDATE purchase_orders
+SET status = 'CLOSED',
+    closed_at = now(),
+    close_reason = sqlc.arg('close_reason')
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListPurchaseOrders :many
+SELECT po.*,
+    s.name AS supplier_name,
+    totals.quantity_ordered,
+    totals.quantity_received,
+    totals.total_value
+FROM purchase_orders po
+JOIN suppliers s ON s.id = po.supplier_id
+JOIN LATERAL (
+    SELECT
+        COALESCE(SUM(pol.quantity_ordered), 0)::bigint AS quantity_ordered,
+        COALESCE(SUM(pol.quantity_received), 0)::bigint AS quantity_received,
+        COALESCE(SUM(pol.quantity_ordered * pol.expected_price), 0)::numeric AS total_value
+    FROM purchase_order_lines pol
+    WHERE pol.purchase_order_id = po.id
+) totals ON true
+WHERE 
+    (
+        sqlc.narg('supplier_id')::bigint IS NULL
+        OR po.supplier_id = sqlc.narg('supplier_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR po.status = sqlc.narg('status')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR EXISTS (
+            SELECT 1 FROM purchase_order_lines pol
+            WHERE pol.purchase_order_id = po.id AND pol.product_id = sqlc.narg('product_id')
+        )
+    )
+ORDER BY po.created_at DESC, po.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListPurchaseOrdersCount :one
+SELECT COUNT(*) AS total_purchase_orders
+FROM purchase_orders po
+WHERE 
+    (
+        sqlc.narg('supplier_id')::bigint IS NULL
+        OR po.supplier_id = sqlc.narg('supplier_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR po.status = sqlc.narg('status')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR EXISTS (
+            SELECT 1 FROM purchase_order_lines pol
+            WHERE pol.purchase_order_id = po.id AND pol.product_id = sqlc.narg('product_id')
+        )
+    );
+
+-- name: CreatePurchaseOrderLine :one
+INSERT INTO purchase_order_lines (purchase_order_id, product_id, quantity_ordered, expected_price)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeletePurchaseOrderLines :exec
+DELETE FROM purchase_order_lines
+WHERE purchase_order_id = $1;
+
+-- name: ListPurchaseOrderLines :many
+SELECT pol.*, p.name AS product_name, p.unit AS product_unit
+FROM purchase_order_lines pol
+JOIN products p ON p.id = pol.product_id
+WHERE pol.purchase_order_id = $1
+ORDER BY pol.id ASC;
+
+-- name: GetPurchaseOrderLineForUpdate :one
+SELECT * FROM purchase_order_lines
+WHERE id = sqlc.arg('id') AND purchase_order_id = sqlc.arg('purchase_order_id')
+FOR UPDATE;
+
+-- name: AddPurchaseOrderLineReceived :one
+UPDATE purchase_order_lines
+SET quantity_received = quantity_received + sqlc.arg('quantity')
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: CountPurchaseOrderLines :one
+SELECT COUNT(*) AS total_lines
+FROM purchase_order_lines
+WHERE purchase_order_id = $1;
+
+-- name: CountOutstandingPurchaseOrderLines :one
+SELECT COUNT(*) AS outstanding_lines
+FROM purchase_order_lines
+WHERE purchase_order_id = $1 AND quantity_received < quantity_ordered;
+
+-- name: ListPurchaseOrderBatches :many
+SELECT pb.id, pb.batch_number, pb.quantity, pb.purchase_price, pb.date_received, pb.expiry_date, pb.location_id, pb.purchase_order_line_id, pb.created_at
+FROM product_batches pb
+JOIN purchase_order_lines pol ON pol.id = pb.purchase_order_line_id
+WHERE pol.purchase_order_id = $1
+ORDER BY pb.date_received ASC, pb.id ASC;
@@ -0,0 +1,79 @@
+-- name: CreateReportRun :one
+INSERT INTO report_runs (report_type, parameters, triggered_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetReportRun :one
+SELECT * FROM report_runs
+WHERE id = $1;
+
+-- name: ClaimReportRun :one
+UPDATE report_runs
+SET status = 'RUNNING',
+    attempts = attempts + 1,
+    error = NULL,
+    started_at = now(),
+    completed_at = NULL,
+    updated_at = now()
+WHERE id = $1
+    AND status IN ('PENDING', 'FAILED')
+RETURNING *;
+
+-- name: CompleteReportRun :exec
+UPDATE report_runs
+SET status = 'SUCCESS',
+    file_name = sqlc.arg('file_name'),
+    file_path = sqlc.arg('file_path'),
+    content_type = sqlc.arg('content_type'),
+    completed_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg('id');
+
+-- name: FailReportRun :exec
+UPDATE report_runs
+SET status = 'FAILED',
+    error = sqlc.arg('error'),
+    completed_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg('id');
+
+-- name: FailInterruptedReportRuns :execrows
+UPDATE report_runs
+SET status = 'FAILED',
+    error = 'interrupted before completion',
+    completed_at = now(),
+    updated_at = now()
+WHERE status = 'RUNNING';
+
+-- name: ListRetryableReportRuns :many
+SELECT * FROM report_runs
+WHERE (status = 'FAILED' AND attempts < sqlc.arg('max_attempts'))
+    OR (status = 'PENDING' AND created_at < now() - interval '5 minutes')
+ORDER BY created_at ASC;
+
+-- name: ListReportRuns :many
+SELECT * FROM report_runs
+WHERE 
+    (
+        sqlc.narg('report_type')::text IS NULL
+        OR report_type = sqlc.narg('report_type')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR status = sqlc.narg('status')
+    )
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListReportRunsCount :one
+SELECT COUNT(*) AS total_runs
+FROM report_runs
+WHERE 
+    (
+        sqlc.narg('report_type')::text IS NULL
+        OR report_type = sqlc.narg('report_type')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR status = sqlc.narg('status')
+    );
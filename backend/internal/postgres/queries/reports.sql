@@ -0,0 +1,436 @@
+-- name: GetResellerOpeningBalance :one
+SELECT (
+    COALESCE((
+        SELECT SUM(sd.total_price)
+        FROM stock_distributions sd
+        WHERE sd.reseller_id = sqlc.arg('reseller_id')
+            AND sd.date_distributed::date < sqlc.arg('date_from')::date
+    ), 0)
+    - COALESCE((
+        SELECT SUM(pm.amount)
+        FROM payments pm
+        WHERE pm.reseller_id = sqlc.arg('reseller_id')
+            AND pm.date_paid::date < sqlc.arg('date_from')::date
+    ), 0)
+    - COALESCE((
+        SELECT SUM(sr.total_value)
+        FROM stock_returns sr
+        WHERE sr.reseller_id = sqlc.arg('reseller_id')
+            AND sr.date_returned::date < sqlc.arg('date_from')::date
+    ), 0)
+    + COALESCE((
+        SELECT SUM(st.total_value)
+        FROM stock_transfers st
+        WHERE st.to_reseller_id = sqlc.arg('reseller_id')
+            AND st.status = 'COMPLETED'
+            AND st.date_transferred::date < sqlc.arg('date_from')::date
+    ), 0)
+    - COALESCE((
+        SELECT SUM(st.total_value)
+        FROM stock_transfers st
+        WHERE st.from_reseller_id = sqlc.arg('reseller_id')
+            AND st.status = 'COMPLETED'
+            AND st.date_transferred::date < sqlc.arg('date_from')::date
+    ), 0)
+)::numeric AS opening_balance;
+
+-- name: ListResellerStatementEntries :many
+SELECT entry_type, reference_id, entry_date, description, debit, credit
+FROM (
+    SELECT
+        'DISTRIBUTION'::text AS entry_type,
+        sd.id AS reference_id,
+        sd.date_distributed AS entry_date,
+        (p.name || ' x ' || sd.quantity || ' @ ' || sd.unit_price)::text AS description,
+        sd.total_price::numeric AS debit,
+        0::numeric AS credit,
+        sd.created_at
+    FROM stock_distributions sd
+    JOIN products p ON p.id = sd.product_id
+    WHERE sd.reseller_id = sqlc.arg('reseller_id')
+        AND sd.date_distributed::date >= sqlc.arg('date_from')::date
+        AND sd.date_distributed::date <= sqlc.arg('date_to')::date
+    UNION ALL
+    SELECT
+        (CASE WHEN pm.reversal_of IS NULL THEN 'PAYMENT' ELSE 'PAYMENT_REVERSAL' END)::text AS entry_type,
+        pm.id AS reference_id,
+        pm.date_paid AS entry_date,
+        (pm.method || COALESCE(' - ' || pm.reference, '') || COALESCE(' (' || pm.reversal_reason || ')', ''))::text AS description,
+        -- reversals are negative payments and add back to what the reseller owes
+        GREATEST(-pm.amount, 0)::numeric AS debit,
+        GREATEST(pm.amount, 0)::numeric AS credit,
+        pm.created_at
+    FROM payments pm
+    WHERE pm.reseller_id = sqlc.arg('reseller_id')
+        AND pm.date_paid::date >= sqlc.arg('date_from')::date
+        AND pm.date_paid::date <= sqlc.arg('date_to')::date
+    UNION ALL
+    SELECT
+        'RETURN'::text AS entry_type,
+        sr.id AS reference_id,
+        sr.date_returned AS entry_date,
+        (p.name || ' x ' || sr.quantity || ' returned (' || sr.reason || ')')::text AS description,
+        0::numeric AS debit,
+        sr.total_value::numeric AS credit,
+        sr.created_at
+    FROM stock_returns sr
+    JOIN products p ON p.id = sr.product_id
+    WHERE sr.reseller_id = sqlc.arg('reseller_id')
+        AND sr.date_returned::date >= sqlc.arg('date_from')::date
+        AND sr.date_returned::date <= sqlc.arg('date_to')::date
+    UNION ALL
+    SELECT
+        (CASE WHEN st.to_reseller_id = sqlc.arg('reseller_id') THEN 'TRANSFER_IN' ELSE 'TRANSFER_OUT' END)::text AS entry_type,
+        st.id AS reference_id,
+        st.date_transferred AS entry_date,
+        (p.name || ' x ' || st.quantity || CASE WHEN st.to_reseller_id = sqlc.arg('reseller_id') THEN ' from ' || fu.name ELSE ' to ' || tu.name END)::text AS description,
+        (CASE WHEN st.to_reseller_id = sqlc.arg('reseller_id') THEN st.total_value ELSE 0 END)::numeric AS debit,
+        (CASE WHEN st.from_reseller_id = sqlc.arg('reseller_id') THEN st.total_value ELSE 0 END)::numeric AS credit,
+        st.created_at
+    FROM stock_transfers st
+    JOIN products p ON p.id = st.product_id
+    JOIN users fu ON fu.id = st.from_reseller_id
+    JOIN users tu ON tu.id = st.to_reseller_id
+    WHERE (st.from_reseller_id = sqlc.arg('reseller_id') OR st.to_reseller_id = sqlc.arg('reseller_id'))
+        AND st.status = 'COMPLETED'
+        AND st.date_transferred::date >= sqlc.arg('date_from')::date
+        AND st.date_transferred::date <= sqlc.arg('date_to')::date
+) entries
+ORDER BY entry_date ASC, created_at ASC;
+
+-- name: ListProfitAndLossLines :many
+SELECT
+    sm.owner_type,
+    COALESCE(sm.owner_id, 0)::bigint AS owner_id,
+    COALESCE(u.name, '')::text AS owner_name,
+    COALESCE(u.phone_number, '')::text AS owner_phone,
+    p.id AS product_id,
+    p.name AS product_name,
+    p.category,
+    SUM(CASE WHEN sm.movement_type = 'IN' THEN -smb.quantity ELSE smb.quantity END)::bigint AS quantity,
+    SUM(CASE WHEN sm.movement_type = 'IN' THEN -smb.quantity * smb.unit_cost ELSE smb.quantity * sm.unit_price END)::numeric AS revenue,
+    SUM(CASE WHEN sm.movement_type = 'IN' THEN -smb.quantity ELSE smb.quantity END
+        * CASE WHEN sm.owner_type = 'COMPANY' THEN smb.batch_unit_cost ELSE smb.unit_cost END)::numeric AS cogs
+FROM stock_movements sm
+JOIN stock_movement_batches smb ON smb.stock_movement_id = sm.id
+JOIN products p ON p.id = sm.product_id
+LEFT JOIN users u ON u.id = sm.owner_id
+WHERE (
+        (sm.owner_type = 'COMPANY' AND sm.movement_type = 'OUT' AND sm.source = 'DISTRIBUTION')
+        OR (sm.owner_type = 'COMPANY' AND sm.movement_type = 'IN' AND sm.source = 'RETURN')
+        OR (sm.owner_type = 'RESELLER' AND sm.movement_type = 'OUT' AND sm.source = 'SALE')
+    )
+    AND sm.movement_date::date >= sqlc.arg('date_from')::date
+    AND sm.movement_date::date <= sqlc.arg('date_to')::date
+    AND (sqlc.narg('category')::text IS NULL OR p.category = sqlc.narg('category'))
+    AND (sqlc.narg('product_id')::bigint IS NULL OR p.id = sqlc.narg('product_id'))
+GROUP BY sm.owner_type, sm.owner_id, u.name, u.phone_number, p.id, p.name, p.category
+ORDER BY sm.owner_type, sm.owner_id, p.name;
+
+-- name: ListCompanyInventoryValuation :many
+SELECT batch_id, batch_number, product_id, product_name, category, unit_cost, remaining_quantity
+FROM (
+    SELECT
+        pb.id AS batch_id,
+        pb.batch_number,
+        pb.product_id,
+        p.name AS product_name,
+        p.category,
+        -- only the landed costs booked by then, freight booked later does not change it
+        (pb.purchase_price + COALESCE((
+            SELECT SUM(lca.unit_cost_added)
+            FROM landed_cost_allocations lca
+            WHERE lca.batch_id = pb.id
+                AND lca.created_at <= sqlc.arg('as_of')
+        ), 0))::numeric AS unit_cost,
+        (pb.quantity + COALESCE((
+            SELECT SUM(CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END)
+            FROM stock_movement_batches smb
+            JOIN stock_movements sm ON sm.id = smb.stock_movement_id
+            WHERE smb.batch_id = pb.id
+                AND smb.owner = 'COMPANY'
+                AND sm.owner_type = 'COMPANY'
+                AND sm.movement_date <= sqlc.arg('as_of')
+        ), 0))::bigint AS remaining_quantity
+    FROM product_batches pb
+    JOIN products p ON p.id = pb.product_id
+    WHERE pb.date_received <= sqlc.arg('as_of')
+        AND (sqlc.narg('product_id')::bigint IS NULL OR pb.product_id = sqlc.narg('product_id'))
+) batches
+WHERE remaining_quantity > 0
+ORDER BY product_name, batch_id;
+
+-- name: ListResellerInventoryValuation :many
+SELECT
+    sm.owner_id::bigint AS reseller_id,
+    u.name AS reseller_name,
+    u.phone_number AS reseller_phone,
+    smb.batch_id,
+    smb.batch_number,
+    sm.product_id,
+    p.name AS product_name,
+    p.category,
+    smb.unit_cost,
+    SUM(CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END)::bigint AS remaining_quantity
+FROM stock_movement_batches smb
+JOIN stock_movements sm ON sm.id = smb.stock_movement_id
+JOIN products p ON p.id = sm.product_id
+JOIN users u ON u.id = sm.owner_id
+WHERE smb.owner = 'RESELLER'
+    AND sm.owner_type = 'RESELLER'
+    AND sm.movement_date <= sqlc.arg('as_of')
+    AND (sqlc.narg('reseller_id')::bigint IS NULL OR sm.owner_id = sqlc.narg('reseller_id'))
+    AND (sqlc.narg('product_id')::bigint IS NULL OR sm.product_id = sqlc.narg('product_id'))
+GROUP BY sm.owner_id, u.name, u.phone_number, smb.batch_id, smb.batch_number, sm.product_id, p.name, p.category, smb.unit_cost
+HAVING SUM(CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END) > 0
+ORDER BY u.name, p.name, smb.batch_id;
+
+-- name: ListReceivablesAging :many
+SELECT
+    u.id AS reseller_id,
+    u.name,
+    u.phone_number,
+    ra.balance,
+    COALESCE(ag.days_0_30, 0)::numeric AS days_0_30,
+    COALESCE(ag.days_31_60, 0)::numeric AS days_31_60,
+    COALESCE(ag.days_61_90, 0)::numeric AS days_61_90,
+    COALESCE(ag.days_over_90, 0)::numeric AS days_over_90,
+    COALESCE(ag.total_outstanding, 0)::numeric AS total_outstanding,
+    ag.oldest_unpaid_date,
+    COALESCE(ag.days_overdue, 0)::bigint AS days_overdue
+FROM users u
+JOIN reseller_accounts ra ON ra.reseller_id = u.id
+LEFT JOIN reseller_receivables_aging ag ON ag.reseller_id = u.id
+WHERE u.role = 'staff' AND u.deleted = false
+    AND (sqlc.narg('reseller_id')::bigint IS NULL OR u.id = sqlc.narg('reseller_id'))
+ORDER BY days_overdue DESC, total_outstanding DESC, u.name;
+
+-- name: ListBatchesByBatchNumber :many
+SELECT
+    pb.id,
+    pb.batch_number,
+    pb.product_id,
+    p.name AS product_name,
+    p.unit AS product_unit,
+    pb.quantity,
+    pb.purchase_price,
+    pb.unit_cost,
+    pb.date_received,
+    COALESCE(bi.remaining_quantity, 0)::bigint AS company_remaining
+FROM product_batches pb
+JOIN products p ON p.id = pb.product_id
+LEFT JOIN (
+    SELECT batch_id, SUM(remaining_quantity) AS remaining_quantity
+    FROM batch_inventory
+    GROUP BY batch_id
+) bi ON bi.batch_id = pb.id
+WHERE pb.batch_number = sqlc.arg('batch_number')
+ORDER BY pb.date_received ASC, pb.id ASC;
+
+-- name: ListBatchTraceMovements :many
+SELECT
+    smb.batch_id,
+    sm.id AS movement_id,
+    sm.movement_type,
+    sm.source,
+    u.id AS reseller_id,
+    u.name AS reseller_name,
+    u.phone_number AS reseller_phone,
+    smb.quantity,
+    smb.unit_cost,
+    sm.unit_price,
+    sm.created_at
+FROM stock_movement_batches smb
+JOIN stock_movements sm ON sm.id = smb.stock_movement_id
+JOIN product_batches pb ON pb.id = smb.batch_id
+JOIN users u ON u.id = sm.owner_id
+WHERE pb.batch_number = sqlc.arg('batch_number')
+    AND smb.owner = 'RESELLER'
+    AND sm.owner_type = 'RESELLER'
+ORDER BY sm.created_at ASC, sm.id ASC;
+
+-- name: ListBatchResellerHoldings :many
+SELECT
+    rbi.source_batch_id AS batch_id,
+    u.id AS reseller_id,
+    u.name AS reseller_name,
+    u.phone_number AS reseller_phone,
+    SUM(rbi.remaining_quantity)::bigint AS remaining_quantity
+FROM reseller_batch_inventory rbi
+JOIN product_batches pb ON pb.id = rbi.source_batch_id
+JOIN users u ON u.id = rbi.reseller_id
+WHERE pb.batch_number = sqlc.arg('batch_number')
+GROUP BY rbi.source_batch_id, u.id, u.name, u.phone_number
+HAVING SUM(rbi.remaining_quantity) > 0
+ORDER BY u.name ASC;
+
+-- name: ListAnalyticsSeries :many
+WITH events AS (
+    SELECT
+        sm.movement_date AS event_date,
+        sm.product_id,
+        sm.owner_id AS reseller_id,
+        SUM(smb.quantity * sm.unit_price) AS sales_value,
+        SUM(smb.quantity) AS units_sold,
+        SUM(smb.quantity * smb.unit_cost) AS sales_cogs,
+        0 AS distribution_value,
+        0 AS distribution_cost,
+        0 AS payments_received
+    FROM stock_movements sm
+    JOIN stock_movement_batches smb ON smb.stock_movement_id = sm.id
+    WHERE sm.owner_type = 'RESELLER' AND sm.movement_type = 'OUT' AND sm.source = 'SALE'
+    GROUP BY sm.id
+    UNION ALL
+    SELECT
+        sm.movement_date,
+        sm.product_id,
+        sm.owner_id,
+        0,
+        0,
+        0,
+        SUM(smb.quantity * smb.unit_cost),
+        SUM(smb.quantity * smb.batch_unit_cost),
+        0
+    FROM stock_movements sm
+    JOIN stock_movement_batches smb ON smb.stock_movement_id = sm.id
+    WHERE sm.owner_type = 'RESELLER' AND sm.movement_type = 'IN' AND sm.source = 'PURCHASE'
+    GROUP BY sm.id
+    UNION ALL
+    SELECT
+        sm.movement_date,
+        sm.product_id,
+        sm.owner_id,
+        0,
+        0,
+        0,
+        -SUM(smb.quantity * smb.unit_cost),
+        -SUM(smb.quantity * smb.batch_unit_cost),
+        0
+    FROM stock_movements sm
+    JOIN stock_movement_batches smb ON smb.stock_movement_id = sm.id
+    WHERE sm.owner_type = 'RESELLER' AND sm.movement_type = 'OUT' AND sm.source = 'RETURN'
+    GROUP BY sm.id
+    UNION ALL
+    SELECT pm.date_paid, NULL, pm.reseller_id, 0, 0, 0, 0, 0, pm.amount
+    FROM payments pm
+)
+SELECT
+    date_trunc(sqlc.arg('interval')::text, e.event_date)::date AS bucket,
+    (CASE sqlc.arg('group_by')::text
+        WHEN 'product' THEN COALESCE(p.id::text, '')
+        WHEN 'category' THEN COALESCE(p.category, '')
+        WHEN 'reseller' THEN COALESCE(u.id::text, '')
+        ELSE ''
+    END)::text AS group_key,
+    (CASE sqlc.arg('group_by')::text
+        WHEN 'product' THEN COALESCE(p.name, '')
+        WHEN 'category' THEN COALESCE(p.category, '')
+        WHEN 'reseller' THEN COALESCE(u.name, '')
+        ELSE ''
+    END)::text AS group_name,
+    SUM(e.sales_value)::numeric AS sales_value,
+    SUM(e.units_sold)::bigint AS units_sold,
+    SUM(e.sales_cogs)::numeric AS sales_cogs,
+    SUM(e.distribution_value)::numeric AS distribution_value,
+    SUM(e.distribution_cost)::numeric AS distribution_cost,
+    SUM(e.payments_received)::numeric AS payments_received
+FROM events e
+LEFT JOIN products p ON p.id = e.product_id
+LEFT JOIN users u ON u.id = e.reseller_id
+WHERE e.event_date::date >= sqlc.arg('date_from')::date
+    AND e.event_date::date <= sqlc.arg('date_to')::date
+    AND (sqlc.narg('reseller_id')::bigint IS NULL OR e.reseller_id = sqlc.narg('reseller_id'))
+    AND (sqlc.narg('product_id')::bigint IS NULL OR e.product_id = sqlc.narg('product_id'))
+    AND (sqlc.narg('category')::text IS NULL OR p.category = sqlc.narg('category'))
+GROUP BY bucket, group_key, group_name
+ORDER BY bucket ASC, group_name ASC;
+
+-- name: ListResellerBalances :many
+SELECT u.id AS reseller_id, u.name, u.phone_number,
+       ra.total_stock_received, ra.total_value_received, ra.total_paid, ra.balance,
+       COALESCE(ag.days_overdue, 0)::bigint AS days_overdue
+FROM users u
+JOIN reseller_accounts ra ON ra.reseller_id = u.id
+LEFT JOIN reseller_receivables_aging ag ON ag.reseller_id = u.id
+WHERE u.role = 'staff' AND u.deleted = false
+ORDER BY ra.balance DESC, u.name ASC;
+
+-- name: ListPaymentsSummary :many
+SELECT pm.reseller_id, u.name, u.phone_number, pm.method,
+       COUNT(*) FILTER (WHERE pm.reversal_of IS NULL)::bigint AS payment_count,
+       SUM(pm.amount)::numeric AS total_amount
+FROM payments pm
+JOIN users u ON u.id = pm.reseller_id
+WHERE pm.date_paid::date >= sqlc.arg('date_from')::date
+    AND pm.date_paid::date <= sqlc.arg('date_to')::date
+GROUP BY pm.reseller_id, u.name, u.phone_number, pm.method
+ORDER BY u.name ASC, pm.method ASC;
+
+-- name: ListProfitAndLossAdjustments :many
+SELECT
+    sm.owner_type,
+    COALESCE(sm.owner_id, 0)::bigint AS owner_id,
+    COALESCE(u.name, '')::text AS owner_name,
+    COALESCE(u.phone_number, '')::text AS owner_phone,
+    p.id AS product_id,
+    p.name AS product_name,
+    p.category,
+    SUM(CASE WHEN sm.movement_type = 'OUT' THEN smb.quantity ELSE -smb.quantity END)::bigint AS quantity,
+    SUM(CASE WHEN sm.movement_type = 'OUT' THEN smb.quantity ELSE -smb.quantity END * smb.unit_cost)::numeric AS write_off_value
+FROM stock_movements sm
+JOIN stock_movement_batches smb ON smb.stock_movement_id = sm.id
+JOIN products p ON p.id = sm.product_id
+LEFT JOIN users u ON u.id = sm.owner_id
+WHERE sm.source = 'ADJUSTMENT'
+    AND sm.movement_date::date >= sqlc.arg('date_from')::date
+    AND sm.movement_date::date <= sqlc.arg('date_to')::date
+    AND (sqlc.narg('category')::text IS NULL OR p.category = sqlc.narg('category'))
+    AND (sqlc.narg('product_id')::bigint IS NULL OR p.id = sqlc.narg('product_id'))
+GROUP BY sm.owner_type, sm.owner_id, u.name, u.phone_number, p.id, p.name, p.category
+ORDER BY sm.owner_type, sm.owner_id, p.name;
+
+-- name: ListPurchaseOrderFillRates :many
+SELECT
+    po.id,
+    po.status,
+    po.sent_at,
+    s.id AS supplier_id,
+    s.name AS supplier_name,
+    COUNT(pol.id)::bigint AS lines,
+    COUNT(pol.id) FILTER (WHERE pol.quantity_received >= pol.quantity_ordered)::bigint AS lines_filled,
+    COALESCE(SUM(pol.quantity_ordered), 0)::bigint AS quantity_ordered,
+    COALESCE(SUM(pol.quantity_received), 0)::bigint AS quantity_received
+FROM purchase_orders po
+JOIN suppliers s ON s.id = po.supplier_id
+JOIN purchase_order_lines pol ON pol.purchase_order_id = po.id
+WHERE po.status <> 'DRAFT'
+    AND po.sent_at::date >= sqlc.arg('date_from')::date
+    AND po.sent_at::date <= sqlc.arg('date_to')::date
+    AND (sqlc.narg('supplier_id')::bigint IS NULL OR po.supplier_id = sqlc.narg('supplier_id'))
+GROUP BY po.id, po.status, po.sent_at, s.id, s.name
+ORDER BY s.name ASC, po.sent_at ASC, po.id ASC;
+
+-- name: ListPurchasePriceVariances :many
+SELECT
+    po.id AS purchase_order_id,
+    s.id AS supplier_id,
+    s.name AS supplier_name,
+    p.id AS product_id,
+    p.name AS product_name,
+    p.unit AS product_unit,
+    pb.id AS batch_id,
+    pb.batch_number,
+    pb.date_received,
+    pb.quantity,
+    pol.expected_price,
+    pb.purchase_price
+FROM product_batches pb
+JOIN purchase_order_lines pol ON pol.id = pb.purchase_order_line_id
+JOIN purchase_orders po ON po.id = pol.purchase_order_id
+JOIN suppliers s ON s.id = po.supplier_id
+JOIN products p ON p.id = pb.product_id
+WHERE pb.date_received::date >= sqlc.arg('date_from')::date
+    AND pb.date_received::date <= sqlc.arg('date_to')::date
+    AND (sqlc.narg('supplier_id')::bigint IS NULL OR po.supplier_id = sqlc.narg('supplier_id'))
+    AND (sqlc.narg('product_id')::bigint IS NULL OR pb.product_id = sqlc.narg('product_id'))
+ORDER BY s.name ASC, p.name ASC, pb.date_received ASC, pb.id ASC;
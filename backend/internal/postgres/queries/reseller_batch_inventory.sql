@@ -5,9 +5,10 @@ INSERT INTO reseller_batch_inventory (
           source_batch_id,
           batch_number,
           remaining_quantity,
-          unit_cost
+          unit_cost,
+          batch_unit_cost
       )
-VALUES  ($1, $2, $3, $4, $5, $6)
+VALUES  ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: ListResellerBatchInventoryForUpdate :many
@@ -18,15 +19,26 @@ WHERE
     rbi.reseller_id = sqlc.arg('reseller_id')
     AND rbi.product_id = sqlc.arg('product_id')
     AND rbi.remaining_quantity > 0
-ORDER BY pb.date_received ASC
+    AND (pb.expiry_date IS NULL OR pb.expiry_date >= CURRENT_DATE)
+ORDER BY
+    CASE WHEN sqlc.arg('strategy')::text = 'LIFO' THEN pb.date_received END DESC,
+    CASE WHEN sqlc.arg('strategy')::text = 'LIFO' THEN pb.id END DESC,
+    CASE WHEN sqlc.arg('strategy')::text = 'FEFO' THEN pb.expiry_date END ASC NULLS LAST,
+    pb.date_received ASC,
+    pb.id ASC,
+    CASE WHEN sqlc.arg('strategy')::text = 'LIFO' THEN rbi.id END DESC,
+    rbi.id ASC
 FOR UPDATE;
 
 -- name: GetResellerBatchInventoryProductSum :one
-SELECT COALESCE(SUM(remaining_quantity), 0)::bigint AS total_remaining
-FROM reseller_batch_inventory
-WHERE reseller_id = sqlc.arg('reseller_id')
-      AND product_id = sqlc.arg('product_id')
-      AND remaining_quantity > 0;
+SELECT 
+    COALESCE(SUM(rbi.remaining_quantity) FILTER (WHERE pb.expiry_date IS NULL OR pb.expiry_date >= CURRENT_DATE), 0)::bigint AS total_remaining,
+    COALESCE(SUM(rbi.remaining_quantity) FILTER (WHERE pb.expiry_date < CURRENT_DATE), 0)::bigint AS total_expired
+FROM reseller_batch_inventory rbi
+JOIN product_batches pb ON pb.id = rbi.source_batch_id
+WHERE rbi.reseller_id = sqlc.arg('reseller_id')
+      AND rbi.product_id = sqlc.arg('product_id')
+      AND rbi.remaining_quantity > 0;
 
 -- name: RemoveResellerBatchInventoryQuantity :one
 UPDATE reseller_batch_inventory
@@ -34,3 +46,35 @@ SET remaining_quantity = remaining_quantity - sqlc.arg('quantity')
 WHERE id = sqlc.arg('inventory_id')
   AND remaining_quantity >= sqlc.arg('quantity')
 RETURNING *;
+
+-- name: ListResellerBatchInventoryForReturn :many
+SELECT * FROM reseller_batch_inventory
+WHERE 
+    reseller_id = sqlc.arg('reseller_id')
+    AND product_id = sqlc.arg('product_id')
+    AND remaining_quantity > 0
+    AND (
+        sqlc.narg('batch_id')::bigint IS NULL
+        OR source_batch_id = sqlc.narg('batch_id')
+    )
+ORDER BY created_at DESC, id DESC
+FOR UPDATE;
+
+-- name: ListResellerBatchInventoryForAdjustment :many
+SELECT * FROM reseller_batch_inventory
+WHERE 
+    reseller_id = sqlc.arg('reseller_id')
+    AND product_id = sqlc.arg('product_id')
+    AND (
+        sqlc.narg('batch_id')::bigint IS NULL
+        OR source_batch_id = sqlc.narg('batch_id')
+    )
+    AND (remaining_quantity > 0 OR sqlc.narg('batch_id')::bigint IS NOT NULL)
+ORDER BY created_at ASC, id ASC
+FOR UPDATE;
+
+-- name: AddResellerBatchInventoryQuantity :one
+UPDATE reseller_batch_inventory
+SET remaining_quantity = remaining_quantity + sqlc.arg('quantity')
+WHERE id = sqlc.arg('inventory_id')
+RETURNING *;
@@ -109,17 +109,28 @@ WHERE reseller_id = sqlc.arg('reseller_id') AND cancelled = false;
 -- name: GetResellerPaymentsPageStats :one
 WITH payment_stats AS (
   SELECT 
-    COUNT(*)::bigint AS total_payments,
+    COUNT(*) FILTER (WHERE reversal_of IS NULL)::bigint AS total_payments,
     COALESCE(SUM(amount), 0)::numeric AS total_amount_received,
     COALESCE(SUM(amount) FILTER (WHERE method = 'MPESA'), 0)::numeric AS mpesa_total,
     COALESCE(SUM(amount) FILTER (WHERE method = 'CASH'), 0)::numeric AS cash_total
   FROM payments
   WHERE reseller_id = sqlc.arg('reseller_id')
+),
+method_totals AS (
+  SELECT pm.id, pm.code, pm.name, COALESCE(SUM(p.amount), 0)::numeric AS total
+  FROM payment_methods pm
+  LEFT JOIN payments p ON p.method = pm.code AND p.reseller_id = sqlc.arg('reseller_id')
+  GROUP BY pm.id, pm.code, pm.name
 )
 SELECT 
   json_build_object(
     'total_payments', (SELECT total_payments FROM payment_stats),
     'total_received', (SELECT total_amount_received FROM payment_stats),
+    -- kept for clients reading the fixed keys, method_totals covers every method
     'mpesa_total', (SELECT mpesa_total FROM payment_stats),
-    'cash_total', (SELECT cash_total FROM payment_stats)
+    'cash_total', (SELECT cash_total FROM payment_stats),
+    'method_totals', (
+      SELECT COALESCE(json_agg(json_build_object('method', code, 'name', name, 'total', total) ORDER BY id), '[]'::json)
+      FROM method_totals
+    )
   ) AS payments_stats;
@@ -8,9 +8,16 @@ WHERE
 
 -- name: ListResellersWithAccount :many
 SELECT u.id as user_id, u.name, u.phone_number, u.email, ra.*,
-       COALESCE((SELECT SUM(quantity) FROM reseller_stock WHERE reseller_id = u.id), 0)::bigint AS current_stock_units
+       COALESCE((SELECT SUM(quantity) FROM reseller_stock WHERE reseller_id = u.id), 0)::bigint AS current_stock_units,
+       COALESCE(ag.days_0_30, 0)::numeric AS days_0_30,
+       COALESCE(ag.days_31_60, 0)::numeric AS days_31_60,
+       COALESCE(ag.days_61_90, 0)::numeric AS days_61_90,
+       COALESCE(ag.days_over_90, 0)::numeric AS days_over_90,
+       COALESCE(ag.total_outstanding, 0)::numeric AS total_outstanding,
+       COALESCE(ag.days_overdue, 0)::bigint AS days_overdue
 FROM users u
 JOIN reseller_accounts ra ON ra.reseller_id = u.id
+LEFT JOIN reseller_receivables_aging ag ON ag.reseller_id = u.id
 WHERE 
     role = 'staff' AND deleted = false
     AND (
@@ -19,7 +26,11 @@ WHERE
         OR LOWER(u.phone_number) LIKE sqlc.narg('search')
          OR LOWER(u.email) LIKE sqlc.narg('search')
     )
-ORDER BY u.created_at DESC
+ORDER BY
+    CASE WHEN sqlc.narg('sort')::text = 'most_overdue' THEN COALESCE(ag.days_overdue, 0) END DESC,
+    CASE WHEN sqlc.narg('sort')::text = 'most_overdue' THEN COALESCE(ag.total_outstanding, 0) END DESC,
+    CASE WHEN sqlc.narg('sort')::text = 'credit_utilisation' THEN ra.balance / NULLIF(ra.credit_limit, 0) END DESC NULLS LAST,
+    u.created_at DESC
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: ListResellersWithAccountCount :one
@@ -131,4 +142,20 @@ SET total_stock_received = coalesce(sqlc.narg('total_stock_received'), total_sto
     total_cogs = coalesce(sqlc.narg('total_cogs'), total_cogs),
     balance = coalesce(sqlc.narg('balance'), balance)
 WHERE reseller_id = sqlc.arg('reseller_id')
+RETURNING *;
+
+-- name: LockResellerAccount :exec
+SELECT reseller_id FROM reseller_accounts
+WHERE reseller_id = $1
+FOR UPDATE;
+
+-- name: GetResellerAccountForUpdate :one
+SELECT * FROM reseller_accounts
+WHERE reseller_id = $1
+FOR UPDATE;
+
+-- name: UpdateResellerCreditLimit :one
+UPDATE reseller_accounts
+SET credit_limit = sqlc.narg('credit_limit')
+WHERE reseller_id = sqlc.arg('reseller_id')
 RETURNING *;
\ No newline at end of file
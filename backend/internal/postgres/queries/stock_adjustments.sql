@@ -0,0 +1,54 @@
+-- name: CreateStockAdjustment :one
+INSERT INTO stock_adjustments (owner_type, reseller_id, product_id, batch_id, quantity, total_value, reason, note, stock_movement_id, created_by, location_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: ListStockAdjustments :many
+SELECT sa.*,
+    COALESCE(u.name, '')::text AS reseller_name,
+    COALESCE(u.phone_number, '')::text AS reseller_phone_number,
+    p.name AS product_name,
+    p.unit AS product_unit
+FROM stock_adjustments sa
+LEFT JOIN users u ON u.id = sa.reseller_id
+JOIN products p ON p.id = sa.product_id
+WHERE 
+    (
+        sqlc.narg('owner_type')::text IS NULL
+        OR sa.owner_type = sqlc.narg('owner_type')
+    )
+    AND (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR sa.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR sa.product_id = sqlc.narg('product_id')
+    )
+    AND (
+        sqlc.narg('reason')::text IS NULL
+        OR sa.reason = sqlc.narg('reason')
+    )
+ORDER BY sa.created_at DESC, sa.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListStockAdjustmentsCount :one
+SELECT COUNT(*) AS total_adjustments
+FROM stock_adjustments sa
+WHERE 
+    (
+        sqlc.narg('owner_type')::text IS NULL
+        OR sa.owner_type = sqlc.narg('owner_type')
+    )
+    AND (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR sa.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR sa.product_id = sqlc.narg('product_id')
+    )
+    AND (
+        sqlc.narg('reason')::text IS NULL
+        OR sa.reason = sqlc.narg('reason')
+    );
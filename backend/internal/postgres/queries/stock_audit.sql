@@ -0,0 +1,320 @@
+-- name: ListStockDrifts :many
+WITH company_ledger AS (
+    SELECT product_id, location_id,
+        SUM(CASE WHEN movement_type = 'IN' THEN quantity ELSE -quantity END) AS quantity
+    FROM stock_movements
+    WHERE owner_type = 'COMPANY'
+    GROUP BY product_id, location_id
+),
+reseller_ledger AS (
+    SELECT owner_id AS reseller_id, product_id,
+        SUM(CASE WHEN movement_type = 'IN' THEN quantity ELSE -quantity END) AS quantity
+    FROM stock_movements
+    WHERE owner_type = 'RESELLER'
+    GROUP BY owner_id, product_id
+),
+-- movements whose batch lines do not add up to the movement quantity, batch level checks
+-- are skipped for these since their batches cannot be replayed
+untraced AS (
+    SELECT sm.owner_type, COALESCE(sm.owner_id, 0) AS owner_id, sm.product_id,
+        SUM(COALESCE(b.quantity, 0)) AS batch_quantity,
+        SUM(sm.quantity) AS movement_quantity
+    FROM stock_movements sm
+    LEFT JOIN (
+        SELECT stock_movement_id, SUM(quantity) AS quantity
+        FROM stock_movement_batches
+        GROUP BY stock_movement_id
+    ) b ON b.stock_movement_id = sm.id
+    -- company purchases are tracked by product_batches instead of batch lines
+    WHERE NOT (sm.owner_type = 'COMPANY' AND sm.movement_type = 'IN' AND sm.source = 'PURCHASE')
+        AND COALESCE(b.quantity, 0) <> sm.quantity
+    GROUP BY sm.owner_type, COALESCE(sm.owner_id, 0), sm.product_id
+),
+-- a batch is received into its location and its batch lines move it between locations
+batch_ledger AS (
+    SELECT e.batch_id, e.product_id, e.location_id, SUM(e.quantity) AS quantity
+    FROM (
+        SELECT pb.id AS batch_id, pb.product_id, pb.location_id, pb.quantity
+        FROM product_batches pb
+        UNION ALL
+        SELECT smb.batch_id, sm.product_id, sm.location_id,
+            CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END
+        FROM stock_movement_batches smb
+        JOIN stock_movements sm ON sm.id = smb.stock_movement_id
+        WHERE smb.owner = 'COMPANY'
+    ) e
+    GROUP BY e.batch_id, e.product_id, e.location_id
+),
+reseller_batch_ledger AS (
+    SELECT sm.owner_id AS reseller_id, sm.product_id, smb.batch_id, smb.unit_cost,
+        SUM(CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END) AS quantity
+    FROM stock_movements sm
+    JOIN stock_movement_batches smb ON smb.stock_movement_id = sm.id AND smb.owner = 'RESELLER'
+    WHERE sm.owner_type = 'RESELLER'
+    GROUP BY sm.owner_id, sm.product_id, smb.batch_id, smb.unit_cost
+),
+reseller_batch_recorded AS (
+    SELECT reseller_id, product_id, source_batch_id AS batch_id, unit_cost,
+        SUM(remaining_quantity) AS quantity
+    FROM reseller_batch_inventory
+    GROUP BY reseller_id, product_id, source_batch_id, unit_cost
+),
+drifts AS (
+    SELECT 'ADMIN_STATS_COMPANY_STOCK'::text AS check_name,
+        NULL::bigint AS reseller_id,
+        NULL::bigint AS location_id,
+        NULL::bigint AS product_id,
+        NULL::bigint AS batch_id,
+        NULL::numeric AS unit_cost,
+        a.total_company_stock::bigint AS recorded,
+        COALESCE((SELECT SUM(quantity) FROM company_stock), 0)::bigint AS expected
+    FROM admin_stats a
+    WHERE a.id = 1
+    UNION ALL
+    SELECT 'COMPANY_STOCK_BATCHES', NULL, k.location_id, k.product_id, NULL, NULL,
+        COALESCE(cs.quantity, 0),
+        COALESCE(bi.quantity, 0)
+    FROM (
+        SELECT product_id, location_id FROM company_stock
+        UNION
+        SELECT product_id, location_id FROM batch_inventory
+    ) k
+    LEFT JOIN company_stock cs ON cs.product_id = k.product_id AND cs.location_id = k.location_id
+    LEFT JOIN (
+        SELECT product_id, location_id, SUM(remaining_quantity) AS quantity
+        FROM batch_inventory
+        GROUP BY product_id, location_id
+    ) bi ON bi.product_id = k.product_id AND bi.location_id = k.location_id
+    UNION ALL
+    SELECT 'RESELLER_STOCK_BATCHES', k.reseller_id, NULL, k.product_id, NULL, NULL,
+        COALESCE(rs.quantity, 0),
+        COALESCE(rbi.quantity, 0)
+    FROM (
+        SELECT reseller_id, product_id FROM reseller_stock
+        UNION
+        SELECT reseller_id, product_id FROM reseller_batch_inventory
+    ) k
+    LEFT JOIN reseller_stock rs ON rs.reseller_id = k.reseller_id AND rs.product_id = k.product_id
+    LEFT JOIN (
+        SELECT reseller_id, product_id, SUM(remaining_quantity) AS quantity
+        FROM reseller_batch_inventory
+        GROUP BY reseller_id, product_id
+    ) rbi ON rbi.reseller_id = k.reseller_id AND rbi.product_id = k.product_id
+    UNION ALL
+    SELECT 'COMPANY_STOCK_MOVEMENTS', NULL, k.location_id, k.product_id, NULL, NULL,
+        COALESCE(cs.quantity, 0),
+        COALESCE(l.quantity, 0)
+    FROM (
+        SELECT product_id, location_id FROM company_stock
+        UNION
+        SELECT product_id, location_id FROM company_ledger
+    ) k
+    LEFT JOIN company_stock cs ON cs.product_id = k.product_id AND cs.location_id = k.location_id
+    LEFT JOIN company_ledger l ON l.product_id = k.product_id AND l.location_id = k.location_id
+    UNION ALL
+    SELECT 'RESELLER_STOCK_MOVEMENTS', k.reseller_id, NULL, k.product_id, NULL, NULL,
+        COALESCE(rs.quantity, 0),
+        COALESCE(l.quantity, 0)
+    FROM (
+        SELECT reseller_id, product_id FROM reseller_stock
+        UNION
+        SELECT reseller_id, product_id FROM reseller_ledger
+    ) k
+    LEFT JOIN reseller_stock rs ON rs.reseller_id = k.reseller_id AND rs.product_id = k.product_id
+    LEFT JOIN reseller_ledger l ON l.reseller_id = k.reseller_id AND l.product_id = k.product_id
+    UNION ALL
+    SELECT 'BATCH_INVENTORY_MOVEMENTS', NULL, l.location_id, l.product_id, l.batch_id, NULL,
+        COALESCE(bi.remaining_quantity, 0),
+        l.quantity
+    FROM batch_ledger l
+    LEFT JOIN batch_inventory bi ON bi.batch_id = l.batch_id AND bi.location_id = l.location_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM untraced u
+        WHERE u.owner_type = 'COMPANY' AND u.product_id = l.product_id
+    )
+    UNION ALL
+    SELECT 'RESELLER_BATCH_MOVEMENTS', k.reseller_id, NULL, k.product_id, k.batch_id, k.unit_cost,
+        COALESCE(r.quantity, 0),
+        COALESCE(l.quantity, 0)
+    FROM (
+        SELECT reseller_id, product_id, batch_id, unit_cost FROM reseller_batch_recorded
+        UNION
+        SELECT reseller_id, product_id, batch_id, unit_cost FROM reseller_batch_ledger
+    ) k
+    LEFT JOIN reseller_batch_recorded r ON r.reseller_id = k.reseller_id AND r.batch_id = k.batch_id AND r.unit_cost = k.unit_cost
+    LEFT JOIN reseller_batch_ledger l ON l.reseller_id = k.reseller_id AND l.batch_id = k.batch_id AND l.unit_cost = k.unit_cost
+    WHERE NOT EXISTS (
+        SELECT 1 FROM untraced u
+        WHERE u.owner_type = 'RESELLER' AND u.owner_id = k.reseller_id AND u.product_id = k.product_id
+    )
+    UNION ALL
+    SELECT 'UNTRACED_MOVEMENTS', NULLIF(u.owner_id, 0), NULL, u.product_id, NULL, NULL,
+        u.batch_quantity,
+        u.movement_quantity
+    FROM untraced u
+)
+SELECT d.check_name, d.reseller_id, u.name AS reseller_name, d.location_id, l.name AS location_name,
+       d.product_id, p.name AS product_name, d.batch_id, pb.batch_number, d.unit_cost,
+       d.recorded::bigint AS recorded, d.expected::bigint AS expected
+FROM drifts d
+LEFT JOIN users u ON u.id = d.reseller_id
+LEFT JOIN locations l ON l.id = d.location_id
+LEFT JOIN products p ON p.id = d.product_id
+LEFT JOIN product_batches pb ON pb.id = d.batch_id
+WHERE d.recorded <> d.expected
+ORDER BY d.check_name, p.name, u.name, l.name, pb.batch_number;
+
+-- name: LockStockTables :exec
+LOCK TABLE company_stock, batch_inventory, reseller_stock, reseller_batch_inventory, admin_stats IN SHARE ROW EXCLUSIVE MODE;
+
+-- name: RebuildCompanyStock :execrows
+INSERT INTO company_stock (product_id, location_id, quantity)
+SELECT k.product_id, k.location_id, COALESCE(l.quantity, 0)
+FROM (
+    SELECT product_id, location_id FROM company_stock
+    UNION
+    SELECT product_id, location_id FROM stock_movements WHERE owner_type = 'COMPANY'
+) k
+LEFT JOIN (
+    SELECT product_id, location_id,
+        SUM(CASE WHEN movement_type = 'IN' THEN quantity ELSE -quantity END)::bigint AS quantity
+    FROM stock_movements
+    WHERE owner_type = 'COMPANY'
+    GROUP BY product_id, location_id
+) l ON l.product_id = k.product_id AND l.location_id = k.location_id
+ON CONFLICT (product_id, location_id) DO UPDATE
+SET quantity = EXCLUDED.quantity
+WHERE company_stock.quantity <> EXCLUDED.quantity;
+
+-- name: RebuildResellerStock :execrows
+INSERT INTO reseller_stock (reseller_id, product_id, quantity)
+SELECT k.reseller_id, k.product_id, COALESCE(l.quantity, 0)
+FROM (
+    SELECT reseller_id, product_id FROM reseller_stock
+    UNION
+    SELECT owner_id, product_id FROM stock_movements WHERE owner_type = 'RESELLER'
+) k
+LEFT JOIN (
+    SELECT owner_id AS reseller_id, product_id,
+        SUM(CASE WHEN movement_type = 'IN' THEN quantity ELSE -quantity END)::bigint AS quantity
+    FROM stock_movements
+    WHERE owner_type = 'RESELLER'
+    GROUP BY owner_id, product_id
+) l ON l.reseller_id = k.reseller_id AND l.product_id = k.product_id
+ON CONFLICT (reseller_id, product_id) DO UPDATE
+SET quantity = EXCLUDED.quantity
+WHERE reseller_stock.quantity <> EXCLUDED.quantity;
+
+-- name: RebuildBatchInventory :execrows
+INSERT INTO batch_inventory (batch_id, location_id, product_id, remaining_quantity)
+SELECT e.batch_id, e.location_id, e.product_id, GREATEST(SUM(e.quantity), 0)::bigint
+FROM (
+    SELECT pb.id AS batch_id, pb.product_id, pb.location_id, pb.quantity
+    FROM product_batches pb
+    UNION ALL
+    SELECT smb.batch_id, sm.product_id, sm.location_id,
+        CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END
+    FROM stock_movement_batches smb
+    JOIN stock_movements sm ON sm.id = smb.stock_movement_id
+    WHERE smb.owner = 'COMPANY'
+) e
+-- products with untraced company movements are left alone
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM stock_movements usm
+    LEFT JOIN (
+        SELECT stock_movement_id, SUM(quantity) AS quantity
+        FROM stock_movement_batches
+        GROUP BY stock_movement_id
+    ) b ON b.stock_movement_id = usm.id
+    WHERE usm.owner_type = 'COMPANY'
+        AND usm.product_id = e.product_id
+        AND NOT (usm.movement_type = 'IN' AND usm.source = 'PURCHASE')
+        AND COALESCE(b.quantity, 0) <> usm.quantity
+)
+GROUP BY e.batch_id, e.location_id, e.product_id
+ON CONFLICT (batch_id, location_id) DO UPDATE
+SET remaining_quantity = EXCLUDED.remaining_quantity
+WHERE batch_inventory.remaining_quantity <> EXCLUDED.remaining_quantity;
+
+-- name: RebuildResellerBatchInventory :execrows
+WITH ledger AS (
+    SELECT sm.owner_id AS reseller_id, smb.batch_id, smb.unit_cost,
+        SUM(CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END) AS quantity
+    FROM stock_movements sm
+    JOIN stock_movement_batches smb ON smb.stock_movement_id = sm.id AND smb.owner = 'RESELLER'
+    WHERE sm.owner_type = 'RESELLER'
+    GROUP BY sm.owner_id, smb.batch_id, smb.unit_cost
+),
+-- rows sharing a reseller, batch and unit cost are interchangeable, so a group that has
+-- drifted gets its whole replayed quantity on the newest row and the older rows are emptied
+ranked AS (
+    SELECT rbi.id,
+        ROW_NUMBER() OVER w AS rn,
+        SUM(rbi.remaining_quantity) OVER w AS group_quantity,
+        GREATEST(COALESCE(l.quantity, 0), 0)::bigint AS quantity
+    FROM reseller_batch_inventory rbi
+    LEFT JOIN ledger l ON l.reseller_id = rbi.reseller_id AND l.batch_id = rbi.source_batch_id AND l.unit_cost = rbi.unit_cost
+    -- resellers with untraced movements for the product are left alone
+    WHERE NOT EXISTS (
+        SELECT 1
+        FROM stock_movements usm
+        LEFT JOIN (
+            SELECT stock_movement_id, SUM(quantity) AS quantity
+            FROM stock_movement_batches
+            GROUP BY stock_movement_id
+        ) b ON b.stock_movement_id = usm.id
+        WHERE usm.owner_type = 'RESELLER'
+            AND usm.owner_id = rbi.reseller_id
+            AND usm.product_id = rbi.product_id
+            AND COALESCE(b.quantity, 0) <> usm.quantity
+    )
+    WINDOW w AS (PARTITION BY rbi.reseller_id, rbi.source_batch_id, rbi.unit_cost ORDER BY rbi.id DESC
+                 ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)
+)
+UPDATE reseller_batch_inventory rbi
+SET remaining_quantity = CASE WHEN r.rn = 1 THEN r.quantity ELSE 0 END
+FROM ranked r
+WHERE r.id = rbi.id
+    AND r.group_quantity <> r.quantity
+    AND rbi.remaining_quantity <> CASE WHEN r.rn = 1 THEN r.quantity ELSE 0 END;
+
+-- name: CreateMissingResellerBatchInventory :execrows
+INSERT INTO reseller_batch_inventory (reseller_id, product_id, source_batch_id, batch_number, unit_cost, remaining_quantity, batch_unit_cost)
+SELECT sm.owner_id, sm.product_id, smb.batch_id, MIN(smb.batch_number), smb.unit_cost,
+    SUM(CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END)::bigint,
+    -- the average company cost of the units the reseller received
+    ROUND(COALESCE(
+        SUM(smb.quantity * smb.batch_unit_cost) FILTER (WHERE sm.movement_type = 'IN')
+            / NULLIF(SUM(smb.quantity) FILTER (WHERE sm.movement_type = 'IN'), 0),
+        MAX(smb.batch_unit_cost)
+    ), 4)
+FROM stock_movements sm
+JOIN stock_movement_batches smb ON smb.stock_movement_id = sm.id AND smb.owner = 'RESELLER'
+WHERE sm.owner_type = 'RESELLER'
+    AND NOT EXISTS (
+        SELECT 1 FROM reseller_batch_inventory rbi
+        WHERE rbi.reseller_id = sm.owner_id AND rbi.source_batch_id = smb.batch_id AND rbi.unit_cost = smb.unit_cost
+    )
+    AND NOT EXISTS (
+        SELECT 1
+        FROM stock_movements usm
+        LEFT JOIN (
+            SELECT stock_movement_id, SUM(quantity) AS quantity
+            FROM stock_movement_batches
+            GROUP BY stock_movement_id
+        ) b ON b.stock_movement_id = usm.id
+        WHERE usm.owner_type = 'RESELLER'
+            AND usm.owner_id = sm.owner_id
+            AND usm.product_id = sm.product_id
+            AND COALESCE(b.quantity, 0) <> usm.quantity
+    )
+GROUP BY sm.owner_id, sm.product_id, smb.batch_id, smb.unit_cost
+HAVING SUM(CASE WHEN sm.movement_type = 'IN' THEN smb.quantity ELSE -smb.quantity END) > 0;
+
+-- name: RebuildAdminStatsCompanyStock :execrows
+UPDATE admin_stats
+SET total_company_stock = s.quantity
+FROM (SELECT COALESCE(SUM(quantity), 0)::bigint AS quantity FROM company_stock) s
+WHERE admin_stats.id = 1
+    AND admin_stats.total_company_stock <> s.quantity;
@@ -1,6 +1,6 @@
 -- name: CreateStockDistributionRecord :one
-INSERT INTO stock_distributions (reseller_id, product_id, quantity, unit_price, date_distributed)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO stock_distributions (reseller_id, product_id, quantity, unit_price, date_distributed, location_id)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: ListStockDistributions :many
@@ -10,10 +10,12 @@ SELECT sd.*,
     p.unit AS product_unit,
     p.low_stock_threshold AS product_low_stock_threshold,
     u.name AS reseller_name,
-    u.phone_number AS reseller_phone_number
+    u.phone_number AS reseller_phone_number,
+    l.name AS location_name
 FROM stock_distributions sd
 LEFT JOIN products p ON p.id = sd.product_id
 LEFT JOIN users u ON u.id = sd.reseller_id
+LEFT JOIN locations l ON l.id = sd.location_id
 WHERE 
     (
         sqlc.narg('reseller_id')::bigint IS NULL
@@ -23,6 +25,10 @@ WHERE
         sqlc.narg('product_id')::bigint IS NULL
         OR sd.product_id = sqlc.narg('product_id')
     )
+    AND (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR sd.location_id = sqlc.narg('location_id')
+    )
     AND (
         COALESCE(sqlc.narg('search'), '') = '' 
         OR LOWER(p.name) LIKE sqlc.narg('search')
@@ -44,6 +50,10 @@ WHERE
         sqlc.narg('product_id')::bigint IS NULL
         OR sd.product_id = sqlc.narg('product_id')
     )
+    AND (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR sd.location_id = sqlc.narg('location_id')
+    )
     AND (
         COALESCE(sqlc.narg('search'), '') = '' 
         OR LOWER(p.name) LIKE sqlc.narg('search')
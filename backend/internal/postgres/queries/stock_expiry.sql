@@ -0,0 +1,121 @@
+-- name: ListNearExpiryStock :many
+WITH layers AS (
+    SELECT
+        'COMPANY'::text AS owner_type,
+        NULL::bigint AS reseller_id,
+        bi.location_id,
+        bi.product_id,
+        bi.batch_id,
+        pb.batch_number,
+        pb.expiry_date,
+        bi.remaining_quantity,
+        pb.unit_cost
+    FROM batch_inventory bi
+    JOIN product_batches pb ON pb.id = bi.batch_id
+    WHERE bi.remaining_quantity > 0
+      AND pb.expiry_date IS NOT NULL
+
+    UNION ALL
+
+    SELECT
+        'RESELLER'::text AS owner_type,
+        rbi.reseller_id,
+        NULL::bigint AS location_id,
+        rbi.product_id,
+        rbi.source_batch_id AS batch_id,
+        rbi.batch_number,
+        pb.expiry_date,
+        rbi.remaining_quantity,
+        rbi.unit_cost
+    FROM reseller_batch_inventory rbi
+    JOIN product_batches pb ON pb.id = rbi.source_batch_id
+    WHERE rbi.remaining_quantity > 0
+      AND pb.expiry_date IS NOT NULL
+)
+SELECT
+    l.owner_type,
+    l.reseller_id,
+    l.location_id,
+    l.product_id,
+    l.batch_id,
+    l.batch_number,
+    l.expiry_date,
+    (l.expiry_date - CURRENT_DATE)::int AS days_to_expiry,
+    l.remaining_quantity,
+    l.unit_cost,
+    p.name AS product_name,
+    p.unit AS product_unit,
+    COALESCE(u.name, '') AS reseller_name,
+    COALESCE(u.phone_number, '') AS reseller_phone_number,
+    COALESCE(loc.name, '') AS location_name
+FROM layers l
+JOIN products p ON p.id = l.product_id
+LEFT JOIN users u ON u.id = l.reseller_id
+LEFT JOIN locations loc ON loc.id = l.location_id
+WHERE 
+    l.expiry_date <= CURRENT_DATE + sqlc.arg('days')::int
+    AND (
+        sqlc.narg('owner_type')::text IS NULL
+        OR l.owner_type = sqlc.narg('owner_type')
+    )
+    AND (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR l.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR l.product_id = sqlc.narg('product_id')
+    )
+    AND (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR l.location_id = sqlc.narg('location_id')
+    )
+ORDER BY l.expiry_date ASC, l.owner_type ASC, l.reseller_id ASC, l.batch_id ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListNearExpiryStockCount :one
+WITH layers AS (
+    SELECT
+        'COMPANY'::text AS owner_type,
+        NULL::bigint AS reseller_id,
+        bi.location_id,
+        bi.product_id,
+        pb.expiry_date
+    FROM batch_inventory bi
+    JOIN product_batches pb ON pb.id = bi.batch_id
+    WHERE bi.remaining_quantity > 0
+      AND pb.expiry_date IS NOT NULL
+
+    UNION ALL
+
+    SELECT
+        'RESELLER'::text AS owner_type,
+        rbi.reseller_id,
+        NULL::bigint AS location_id,
+        rbi.product_id,
+        pb.expiry_date
+    FROM reseller_batch_inventory rbi
+    JOIN product_batches pb ON pb.id = rbi.source_batch_id
+    WHERE rbi.remaining_quantity > 0
+      AND pb.expiry_date IS NOT NULL
+)
+SELECT COUNT(*) AS total_layers
+FROM layers l
+WHERE 
+    l.expiry_date <= CURRENT_DATE + sqlc.arg('days')::int
+    AND (
+        sqlc.narg('owner_type')::text IS NULL
+        OR l.owner_type = sqlc.narg('owner_type')
+    )
+    AND (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR l.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR l.product_id = sqlc.narg('product_id')
+    )
+    AND (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR l.location_id = sqlc.narg('location_id')
+    );
@@ -1,6 +1,16 @@
 -- name: CreateStockMovementBatchRecord :one
-INSERT INTO stock_movement_batches (owner, batch_number, stock_movement_id, batch_id, quantity, unit_cost)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO stock_movement_batches (owner, batch_number, stock_movement_id, batch_id, quantity, unit_cost, batch_unit_cost)
+VALUES (
+    sqlc.arg('owner'),
+    sqlc.arg('batch_number'),
+    sqlc.arg('stock_movement_id'),
+    sqlc.arg('batch_id'),
+    sqlc.arg('quantity'),
+    sqlc.arg('unit_cost'),
+    -- reseller stock carries the cost it left the company at, anything else is costed at the
+    -- batch's cost now
+    COALESCE(sqlc.narg('batch_unit_cost')::numeric, (SELECT pb.unit_cost FROM product_batches pb WHERE pb.id = sqlc.arg('batch_id')))
+)
 RETURNING *;
 
 -- name: ListStockMovementBatchesByBatchID :many
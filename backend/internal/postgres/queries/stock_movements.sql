@@ -1,14 +1,19 @@
 -- name: CreateStockMovementRecord :one
-INSERT INTO stock_movements (product_id, owner_type, owner_id, movement_type, quantity, unit_price, source, note)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO stock_movements (product_id, owner_type, owner_id, movement_type, quantity, unit_price, source, note, location_id, movement_date)
+VALUES (
+    sqlc.arg('product_id'), sqlc.arg('owner_type'), sqlc.narg('owner_id'), sqlc.arg('movement_type'), sqlc.arg('quantity'),
+    sqlc.arg('unit_price'), sqlc.arg('source'), sqlc.arg('note'), sqlc.narg('location_id'),
+    COALESCE(sqlc.narg('movement_date')::timestamptz, now())
+)
 RETURNING *;
 
 -- name: ListStockMovements :many
 SELECT sm.*, p.name AS product_name, p.unit AS product_unit, p.category AS product_category,
-    u.name AS owner_name, u.phone_number AS owner_phone_number
+    u.name AS owner_name, u.phone_number AS owner_phone_number, l.name AS location_name
 FROM stock_movements sm
 LEFT JOIN products p ON p.id = sm.product_id
 LEFT JOIN users u ON u.id = sm.owner_id AND sm.owner_type = 'RESELLER'
+LEFT JOIN locations l ON l.id = sm.location_id
 WHERE 
     (
         sqlc.narg('owner_type')::text IS NULL
@@ -35,6 +40,10 @@ WHERE
         sqlc.narg('source')::text IS NULL
         OR sm.source = sqlc.narg('source')
     )
+    AND (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR sm.location_id = sqlc.narg('location_id')
+    )
 ORDER BY sm.created_at DESC
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
@@ -68,4 +77,8 @@ WHERE
     AND (
         sqlc.narg('source')::text IS NULL
         OR sm.source = sqlc.narg('source')
+    )
+    AND (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR sm.location_id = sqlc.narg('location_id')
     );
\ No newline at end of file
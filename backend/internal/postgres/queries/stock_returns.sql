@@ -0,0 +1,65 @@
+-- name: CreateStockReturn :one
+INSERT INTO stock_returns (reseller_id, product_id, batch_id, quantity, total_value, reason, reseller_movement_id, company_movement_id, date_returned, created_by, location_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: ListStockReturns :many
+SELECT sr.*,
+    u.name AS reseller_name,
+    u.phone_number AS reseller_phone_number,
+    p.name AS product_name,
+    p.unit AS product_unit
+FROM stock_returns sr
+JOIN users u ON u.id = sr.reseller_id
+JOIN products p ON p.id = sr.product_id
+WHERE 
+    (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR sr.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR sr.product_id = sqlc.narg('product_id')
+    )
+ORDER BY sr.date_returned DESC, sr.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListStockReturnsCount :one
+SELECT COUNT(*) AS total_returns
+FROM stock_returns sr
+WHERE 
+    (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR sr.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR sr.product_id = sqlc.narg('product_id')
+    );
+
+-- name: CreateStockReturnAllocation :one
+INSERT INTO stock_return_allocations (stock_return_id, invoice_id, amount)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListInvoiceStockReturnAllocations :many
+SELECT sra.*,
+    sr.product_id,
+    sr.quantity,
+    sr.date_returned,
+    p.name AS product_name
+FROM stock_return_allocations sra
+JOIN stock_returns sr ON sr.id = sra.stock_return_id
+JOIN products p ON p.id = sr.product_id
+WHERE sra.invoice_id = $1
+ORDER BY sra.created_at, sra.id;
+
+-- name: ListUnallocatedStockReturns :many
+SELECT sr.id,
+    (sr.total_value - COALESCE(SUM(sra.amount), 0))::numeric AS unallocated
+FROM stock_returns sr
+LEFT JOIN stock_return_allocations sra ON sra.stock_return_id = sr.id
+WHERE sr.reseller_id = $1
+GROUP BY sr.id
+HAVING sr.total_value - COALESCE(SUM(sra.amount), 0) > 0
+ORDER BY sr.date_returned, sr.id;
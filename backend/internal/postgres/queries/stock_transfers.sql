@@ -0,0 +1,120 @@
+-- name: CreateStockTransfer :one
+INSERT INTO stock_transfers (from_reseller_id, to_reseller_id, product_id, batch_id, quantity, note, requested_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetStockTransferForUpdate :one
+SELECT * FROM stock_transfers
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CompleteStockTransfer :one
+UPDATE stock_transfers
+SET status = 'COMPLETED',
+    total_value = sqlc.arg('total_value'),
+    from_movement_id = sqlc.arg('from_movement_id'),
+    to_movement_id = sqlc.arg('to_movement_id'),
+    invoice_id = sqlc.narg('invoice_id'),
+    decided_by = sqlc.narg('decided_by'),
+    decided_at = now(),
+    date_transferred = now()
+WHERE id = sqlc.arg('id') AND status = 'PENDING'
+RETURNING *;
+
+-- name: RejectStockTransfer :one
+UPDATE stock_transfers
+SET status = 'REJECTED',
+    rejection_reason = sqlc.arg('rejection_reason'),
+    decided_by = sqlc.arg('decided_by'),
+    decided_at = now()
+WHERE id = sqlc.arg('id') AND status = 'PENDING'
+RETURNING *;
+
+-- name: ListStockTransfers :many
+SELECT st.*,
+    fu.name AS from_reseller_name,
+    fu.phone_number AS from_reseller_phone_number,
+    tu.name AS to_reseller_name,
+    tu.phone_number AS to_reseller_phone_number,
+    p.name AS product_name,
+    p.unit AS product_unit
+FROM stock_transfers st
+JOIN users fu ON fu.id = st.from_reseller_id
+JOIN users tu ON tu.id = st.to_reseller_id
+JOIN products p ON p.id = st.product_id
+WHERE 
+    (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR st.from_reseller_id = sqlc.narg('reseller_id')
+        OR st.to_reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR st.product_id = sqlc.narg('product_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR st.status = sqlc.narg('status')
+    )
+ORDER BY st.created_at DESC, st.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListStockTransfersCount :one
+SELECT COUNT(*) AS total_transfers
+FROM stock_transfers st
+WHERE 
+    (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR st.from_reseller_id = sqlc.narg('reseller_id')
+        OR st.to_reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('product_id')::bigint IS NULL
+        OR st.product_id = sqlc.narg('product_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR st.status = sqlc.narg('status')
+    );
+
+-- name: CreateStockTransferAllocation :one
+INSERT INTO stock_transfer_allocations (stock_transfer_id, invoice_id, amount)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListInvoiceStockTransfers :many
+SELECT st.id,
+    st.from_reseller_id,
+    st.product_id,
+    st.quantity,
+    st.total_value,
+    st.date_transferred,
+    p.name AS product_name,
+    p.unit AS product_unit
+FROM stock_transfers st
+JOIN products p ON p.id = st.product_id
+WHERE st.invoice_id = $1
+ORDER BY st.date_transferred, st.id;
+
+-- name: ListInvoiceStockTransferAllocations :many
+SELECT sta.*,
+    st.product_id,
+    st.quantity,
+    st.date_transferred,
+    p.name AS product_name
+FROM stock_transfer_allocations sta
+JOIN stock_transfers st ON st.id = sta.stock_transfer_id
+JOIN products p ON p.id = st.product_id
+WHERE sta.invoice_id = $1
+ORDER BY sta.created_at, sta.id;
+
+-- name: ListUnallocatedStockTransfers :many
+SELECT st.id,
+    (st.total_value - COALESCE(SUM(sta.amount), 0))::numeric AS unallocated
+FROM stock_transfers st
+LEFT JOIN stock_transfer_allocations sta ON sta.stock_transfer_id = st.id
+WHERE st.from_reseller_id = $1
+    AND st.status = 'COMPLETED'
+GROUP BY st.id
+HAVING st.total_value - COALESCE(SUM(sta.amount), 0) > 0
+ORDER BY st.date_transferred, st.id;
@@ -0,0 +1,205 @@
+-- name: LockStockMovements :exec
+LOCK TABLE stock_movements IN SHARE MODE;
+
+-- name: CreateStocktake :one
+INSERT INTO stocktakes (owner_type, reseller_id, note, created_by, location_id, snapshot_movement_id)
+VALUES ($1, $2, $3, $4, $5, (SELECT COALESCE(MAX(id), 0) FROM stock_movements))
+RETURNING *;
+
+-- name: CreateCompanyStocktakeLines :exec
+INSERT INTO stocktake_lines (stocktake_id, product_id, expected_quantity, unit_cost)
+SELECT
+    sqlc.arg('stocktake_id')::bigint,
+    cs.product_id,
+    cs.quantity,
+    ROUND(COALESCE(layers.average_cost, latest.unit_cost, 0), 2)
+FROM company_stock cs
+JOIN stocktakes s ON s.id = sqlc.arg('stocktake_id') AND s.location_id = cs.location_id
+JOIN products p ON p.id = cs.product_id
+LEFT JOIN LATERAL (
+    SELECT SUM(bi.remaining_quantity * pb.unit_cost) / NULLIF(SUM(bi.remaining_quantity), 0) AS average_cost
+    FROM batch_inventory bi
+    JOIN product_batches pb ON pb.id = bi.batch_id
+    WHERE bi.product_id = cs.product_id
+      AND bi.location_id = cs.location_id
+      AND bi.remaining_quantity > 0
+) layers ON true
+LEFT JOIN LATERAL (
+    SELECT pb.unit_cost
+    FROM product_batches pb
+    WHERE pb.product_id = cs.product_id
+    ORDER BY pb.date_received DESC, pb.id DESC
+    LIMIT 1
+) latest ON true
+WHERE NOT p.deleted OR cs.quantity <> 0;
+
+-- name: CreateResellerStocktakeLines :exec
+INSERT INTO stocktake_lines (stocktake_id, product_id, expected_quantity, unit_cost)
+SELECT
+    sqlc.arg('stocktake_id')::bigint,
+    rs.product_id,
+    rs.quantity,
+    ROUND(COALESCE(layers.average_cost, latest.unit_cost, 0), 2)
+FROM reseller_stock rs
+JOIN products p ON p.id = rs.product_id
+LEFT JOIN LATERAL (
+    SELECT SUM(rbi.remaining_quantity * rbi.unit_cost) / NULLIF(SUM(rbi.remaining_quantity), 0) AS average_cost
+    FROM reseller_batch_inventory rbi
+    WHERE rbi.reseller_id = rs.reseller_id
+      AND rbi.product_id = rs.product_id
+      AND rbi.remaining_quantity > 0
+) layers ON true
+LEFT JOIN LATERAL (
+    SELECT rbi.unit_cost
+    FROM reseller_batch_inventory rbi
+    WHERE rbi.reseller_id = rs.reseller_id
+      AND rbi.product_id = rs.product_id
+    ORDER BY rbi.created_at DESC, rbi.id DESC
+    LIMIT 1
+) latest ON true
+WHERE rs.reseller_id = sqlc.arg('reseller_id')
+  AND (NOT p.deleted OR rs.quantity <> 0);
+
+-- name: GetStocktake :one
+SELECT s.*,
+    COALESCE(u.name, '')::text AS reseller_name,
+    COALESCE(u.phone_number, '')::text AS reseller_phone_number,
+    COALESCE(l.name, '')::text AS location_name
+FROM stocktakes s
+LEFT JOIN users u ON u.id = s.reseller_id
+LEFT JOIN locations l ON l.id = s.location_id
+WHERE s.id = $1;
+
+-- name: GetStocktakeForUpdate :one
+SELECT * FROM stocktakes
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListStocktakeLines :many
+SELECT sl.*,
+    p.name AS product_name,
+    p.unit AS product_unit,
+    sa.total_value AS posted_value,
+    moved.quantity AS moved_quantity
+FROM stocktake_lines sl
+JOIN stocktakes s ON s.id = sl.stocktake_id
+JOIN products p ON p.id = sl.product_id
+LEFT JOIN stock_adjustments sa ON sa.id = sl.adjustment_id
+-- the owner's stock movements between the snapshot and the count
+JOIN LATERAL (
+    SELECT COALESCE(SUM(CASE WHEN sm.movement_type = 'IN' THEN sm.quantity ELSE -sm.quantity END), 0)::bigint AS quantity
+    FROM stock_movements sm
+    WHERE sm.product_id = sl.product_id
+      AND sm.owner_type = s.owner_type
+      AND sm.owner_id IS NOT DISTINCT FROM s.reseller_id
+      AND sm.location_id IS NOT DISTINCT FROM s.location_id
+      AND sm.id > s.snapshot_movement_id
+      AND sm.created_at <= COALESCE(sl.counted_at, s.closed_at, now())
+) moved ON true
+WHERE sl.stocktake_id = $1
+ORDER BY p.name ASC, sl.product_id ASC;
+
+-- name: RecordStocktakeCount :execrows
+UPDATE stocktake_lines
+SET counted_quantity = sqlc.arg('counted_quantity'),
+    counted_at = now()
+WHERE stocktake_id = sqlc.arg('stocktake_id')
+  AND product_id = sqlc.arg('product_id');
+
+-- name: SetStocktakeLineAdjustment :exec
+UPDATE stocktake_lines
+SET adjustment_id = sqlc.arg('adjustment_id')
+WHERE id = sqlc.arg('id');
+
+-- name: CloseStocktake :exec
+UPDATE stocktakes
+SET status = sqlc.arg('status'),
+    closed_by = sqlc.arg('closed_by'),
+    closed_at = now()
+WHERE id = sqlc.arg('id');
+
+-- name: GetLatestCompanyBatchID :one
+SELECT pb.id FROM product_batches pb
+JOIN batch_inventory bi ON bi.batch_id = pb.id AND bi.location_id = sqlc.arg('location_id')
+WHERE pb.product_id = sqlc.arg('product_id')
+ORDER BY pb.date_received DESC, pb.id DESC
+LIMIT 1;
+
+-- name: GetLatestResellerBatchID :one
+SELECT source_batch_id FROM reseller_batch_inventory
+WHERE reseller_id = $1
+  AND product_id = $2
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListStocktakes :many
+SELECT s.*,
+    COALESCE(u.name, '')::text AS reseller_name,
+    COALESCE(u.phone_number, '')::text AS reseller_phone_number,
+    COALESCE(l.name, '')::text AS location_name,
+    totals.total_lines,
+    totals.counted_lines,
+    totals.variance_quantity,
+    totals.variance_value
+FROM stocktakes s
+LEFT JOIN users u ON u.id = s.reseller_id
+LEFT JOIN locations l ON l.id = s.location_id
+JOIN LATERAL (
+    SELECT
+        COUNT(*)::bigint AS total_lines,
+        COUNT(sl.counted_quantity)::bigint AS counted_lines,
+        COALESCE(SUM(sl.counted_quantity - sl.expected_quantity - moved.quantity), 0)::bigint AS variance_quantity,
+        COALESCE(SUM((sl.counted_quantity - sl.expected_quantity - moved.quantity) * sl.unit_cost), 0)::numeric AS variance_value
+    FROM stocktake_lines sl
+    JOIN LATERAL (
+        SELECT COALESCE(SUM(CASE WHEN sm.movement_type = 'IN' THEN sm.quantity ELSE -sm.quantity END), 0)::bigint AS quantity
+        FROM stock_movements sm
+        WHERE sm.product_id = sl.product_id
+          AND sm.owner_type = s.owner_type
+          AND sm.owner_id IS NOT DISTINCT FROM s.reseller_id
+          AND sm.location_id IS NOT DISTINCT FROM s.location_id
+          AND sm.id > s.snapshot_movement_id
+          AND sm.created_at <= COALESCE(sl.counted_at, s.closed_at, now())
+    ) moved ON true
+    WHERE sl.stocktake_id = s.id
+) totals ON true
+WHERE 
+    (
+        sqlc.narg('owner_type')::text IS NULL
+        OR s.owner_type = sqlc.narg('owner_type')
+    )
+    AND (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR s.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR s.location_id = sqlc.narg('location_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR s.status = sqlc.narg('status')
+    )
+ORDER BY s.created_at DESC, s.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListStocktakesCount :one
+SELECT COUNT(*) AS total_stocktakes
+FROM stocktakes s
+WHERE 
+    (
+        sqlc.narg('owner_type')::text IS NULL
+        OR s.owner_type = sqlc.narg('owner_type')
+    )
+    AND (
+        sqlc.narg('reseller_id')::bigint IS NULL
+        OR s.reseller_id = sqlc.narg('reseller_id')
+    )
+    AND (
+        sqlc.narg('location_id')::bigint IS NULL
+        OR s.location_id = sqlc.narg('location_id')
+    )
+    AND (
+        sqlc.narg('status')::text IS NULL
+        OR s.status = sqlc.narg('status')
+    );
@@ -0,0 +1,50 @@
+-- name: CreateSupplier :one
+INSERT INTO suppliers (name, contact_name, phone_number, email, address)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetSupplier :one
+SELECT * FROM suppliers
+WHERE id = $1;
+
+-- name: UpdateSupplier :one
+UPDATE suppliers
+SET name = coalesce(sqlc.narg('name'), name),
+    contact_name = coalesce(sqlc.narg('contact_name'), contact_name),
+    phone_number = coalesce(sqlc.narg('phone_number'), phone_number),
+    email = coalesce(sqlc.narg('email'), email),
+    address = coalesce(sqlc.narg('address'), address),
+    active = coalesce(sqlc.narg('active'), active)
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListSuppliers :many
+SELECT * FROM suppliers s
+WHERE 
+    (
+        sqlc.narg('active')::boolean IS NULL
+        OR s.active = sqlc.narg('active')
+    )
+    AND (
+        COALESCE(sqlc.narg('search'), '') = '' 
+        OR LOWER(s.name) LIKE sqlc.narg('search')
+        OR LOWER(s.contact_name) LIKE sqlc.narg('search')
+        OR LOWER(s.phone_number) LIKE sqlc.narg('search')
+    )
+ORDER BY s.name ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListSuppliersCount :one
+SELECT COUNT(*) AS total_suppliers
+FROM suppliers s
+WHERE 
+    (
+        sqlc.narg('active')::boolean IS NULL
+        OR s.active = sqlc.narg('active')
+    )
+    AND (
+        COALESCE(sqlc.narg('search'), '') = '' 
+        OR LOWER(s.name) LIKE sqlc.narg('search')
+        OR LOWER(s.contact_name) LIKE sqlc.narg('search')
+        OR LOWER(s.phone_number) LIKE sqlc.narg('search')
+    );
@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.ReportRunRepository = (*ReportRunRepository)(nil)
+
+type ReportRunRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewReportRunRepository(db *Store) *ReportRunRepository {
+	return &ReportRunRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (rr *ReportRunRepository) CreateReportRun(ctx context.Context, run *repository.ReportRun) (*repository.ReportRun, error) {
+	params, err := json.Marshal(run.Parameters)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to encode report run parameters: %s", err.Error())
+	}
+
+	pgRun, err := rr.queries.CreateReportRun(ctx, generated.CreateReportRunParams{
+		ReportType:  run.ReportType,
+		Parameters:  params,
+		TriggeredBy: run.TriggeredBy,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create report run: %s", err.Error())
+	}
+
+	return convertGeneratedReportRun(pgRun)
+}
+
+func (rr *ReportRunRepository) GetReportRun(ctx context.Context, id uint32) (*repository.ReportRun, error) {
+	pgRun, err := rr.queries.GetReportRun(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "report run not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get report run: %s", err.Error())
+	}
+
+	return convertGeneratedReportRun(pgRun)
+}
+
+func (rr *ReportRunRepository) ListReportRuns(ctx context.Context, filter *repository.ReportRunFilter) ([]*repository.ReportRun, *pkg.Pagination, error) {
+	listParams := generated.ListReportRunsParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		ReportType: pgtype.Text{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+	}
+
+	countParams := generated.ListReportRunsCountParams{
+		ReportType: pgtype.Text{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+	}
+
+	if filter.ReportType != nil {
+		listParams.ReportType = pgtype.Text{String: *filter.ReportType, Valid: true}
+		countParams.ReportType = pgtype.Text{String: *filter.ReportType, Valid: true}
+	}
+
+	if filter.Status != nil {
+		listParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+		countParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+	}
+
+	pgRuns, err := rr.queries.ListReportRuns(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list report runs: %s", err.Error())
+	}
+
+	totalCount, err := rr.queries.ListReportRunsCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count report runs: %s", err.Error())
+	}
+
+	runs, err := convertGeneratedReportRuns(pgRuns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return runs, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+func (rr *ReportRunRepository) ClaimReportRun(ctx context.Context, id uint32) (*repository.ReportRun, error) {
+	pgRun, err := rr.queries.ClaimReportRun(ctx, int64(id))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to claim report run: %s", err.Error())
+		}
+
+		// nothing was updated, either the run does not exist or it is not in a runnable state
+		run, err := rr.GetReportRun(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "report run %d is %s", run.ID, run.Status)
+	}
+
+	return convertGeneratedReportRun(pgRun)
+}
+
+func (rr *ReportRunRepository) CompleteReportRun(ctx context.Context, id uint32, fileName, filePath, contentType string) error {
+	if err := rr.queries.CompleteReportRun(ctx, generated.CompleteReportRunParams{
+		ID:          int64(id),
+		FileName:    pgtype.Text{String: fileName, Valid: true},
+		FilePath:    pgtype.Text{String: filePath, Valid: true},
+		ContentType: pgtype.Text{String: contentType, Valid: true},
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to complete report run: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (rr *ReportRunRepository) FailReportRun(ctx context.Context, id uint32, reason string) error {
+	if err := rr.queries.FailReportRun(ctx, generated.FailReportRunParams{
+		ID:    int64(id),
+		Error: pgtype.Text{String: reason, Valid: true},
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to mark report run as failed: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (rr *ReportRunRepository) FailInterruptedReportRuns(ctx context.Context) (int64, error) {
+	count, err := rr.queries.FailInterruptedReportRuns(ctx)
+	if err != nil {
+		return 0, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to mark interrupted report runs as failed: %s", err.Error())
+	}
+
+	return count, nil
+}
+
+func (rr *ReportRunRepository) ListRetryableReportRuns(ctx context.Context, maxAttempts int32) ([]*repository.ReportRun, error) {
+	pgRuns, err := rr.queries.ListRetryableReportRuns(ctx, maxAttempts)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list retryable report runs: %s", err.Error())
+	}
+
+	return convertGeneratedReportRuns(pgRuns)
+}
+
+func convertGeneratedReportRuns(pgRuns []generated.ReportRun) ([]*repository.ReportRun, error) {
+	runs := make([]*repository.ReportRun, len(pgRuns))
+	for i, pgRun := range pgRuns {
+		run, err := convertGeneratedReportRun(pgRun)
+		if err != nil {
+			return nil, err
+		}
+
+		runs[i] = run
+	}
+
+	return runs, nil
+}
+
+func convertGeneratedReportRun(pgRun generated.ReportRun) (*repository.ReportRun, error) {
+	run := &repository.ReportRun{
+		ID:          uint32(pgRun.ID),
+		ReportType:  pgRun.ReportType,
+		Status:      pgRun.Status,
+		TriggeredBy: pgRun.TriggeredBy,
+		Attempts:    pgRun.Attempts,
+		FileName:    pgRun.FileName.String,
+		FilePath:    pgRun.FilePath.String,
+		ContentType: pgRun.ContentType.String,
+		Error:       pgRun.Error.String,
+		UpdatedAt:   pgRun.UpdatedAt,
+		CreatedAt:   pgRun.CreatedAt,
+	}
+
+	if err := json.Unmarshal(pgRun.Parameters, &run.Parameters); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to decode report run parameters: %s", err.Error())
+	}
+
+	if pgRun.StartedAt.Valid {
+		run.StartedAt = &pgRun.StartedAt.Time
+	}
+
+	if pgRun.CompletedAt.Valid {
+		run.CompletedAt = &pgRun.CompletedAt.Time
+	}
+
+	return run, nil
+}
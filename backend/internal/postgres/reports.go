@@ -0,0 +1,908 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.ReportRepository = (*ReportRepository)(nil)
+
+const maxAnalyticsBuckets = 1000
+
+type ReportRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewReportRepository(db *Store) *ReportRepository {
+	return &ReportRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (rr *ReportRepository) GetResellerStatement(ctx context.Context, filter *repository.StatementFilter) (*repository.ResellerStatement, error) {
+	pgReseller, err := rr.queries.GetResellerWithAccountByID(ctx, int64(filter.ResellerID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller by ID: %s", err.Error())
+	}
+
+	openingBalance, err := rr.queries.GetResellerOpeningBalance(ctx, generated.GetResellerOpeningBalanceParams{
+		ResellerID: int64(filter.ResellerID),
+		DateFrom:   pgtype.Date{Time: filter.DateFrom, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller opening balance: %s", err.Error())
+	}
+
+	pgEntries, err := rr.queries.ListResellerStatementEntries(ctx, generated.ListResellerStatementEntriesParams{
+		ResellerID: int64(filter.ResellerID),
+		DateFrom:   pgtype.Date{Time: filter.DateFrom, Valid: true},
+		DateTo:     pgtype.Date{Time: filter.DateTo, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reseller statement entries: %s", err.Error())
+	}
+
+	statement := &repository.ResellerStatement{
+		Reseller: repository.UserShort{
+			ID:          uint32(pgReseller.ID),
+			Name:        pgReseller.Name,
+			PhoneNumber: pgReseller.PhoneNumber,
+			Email:       pgReseller.Email,
+		},
+		DateFrom:       filter.DateFrom,
+		DateTo:         filter.DateTo,
+		OpeningBalance: pkg.PgTypeNumericToFloat64(openingBalance),
+		AccountBalance: pkg.PgTypeNumericToFloat64(pgReseller.Balance),
+		Lines:          make([]*repository.StatementLine, len(pgEntries)),
+		GeneratedAt:    time.Now(),
+	}
+
+	// running balance: distributions increase what the reseller owes, payments reduce it
+	balance := statement.OpeningBalance
+	for i, pgEntry := range pgEntries {
+		debit := pkg.PgTypeNumericToFloat64(pgEntry.Debit)
+		credit := pkg.PgTypeNumericToFloat64(pgEntry.Credit)
+		balance += debit - credit
+
+		statement.TotalDebits += debit
+		statement.TotalCredits += credit
+		statement.Lines[i] = &repository.StatementLine{
+			EntryType:   pgEntry.EntryType,
+			ReferenceID: uint32(pgEntry.ReferenceID),
+			EntryDate:   pgEntry.EntryDate,
+			Description: pgEntry.Description,
+			Debit:       debit,
+			Credit:      credit,
+			Balance:     balance,
+		}
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
+
+func (rr *ReportRepository) GetProfitAndLoss(ctx context.Context, filter *repository.ProfitAndLossFilter) (*repository.ProfitAndLoss, error) {
+	params := generated.ListProfitAndLossLinesParams{
+		DateFrom:  pgtype.Date{Time: filter.DateFrom, Valid: true},
+		DateTo:    pgtype.Date{Time: filter.DateTo, Valid: true},
+		Category:  pgtype.Text{Valid: false},
+		ProductID: pgtype.Int8{Valid: false},
+	}
+
+	if filter.Category != nil {
+		params.Category = pgtype.Text{String: *filter.Category, Valid: true}
+	}
+	if filter.ProductID != nil {
+		params.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	pgLines, err := rr.queries.ListProfitAndLossLines(ctx, params)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list profit and loss lines: %s", err.Error())
+	}
+
+	pgAdjustments, err := rr.queries.ListProfitAndLossAdjustments(ctx, generated.ListProfitAndLossAdjustmentsParams{
+		DateFrom:  params.DateFrom,
+		DateTo:    params.DateTo,
+		Category:  params.Category,
+		ProductID: params.ProductID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list profit and loss adjustments: %s", err.Error())
+	}
+
+	company := newProfitAndLossBuilder()
+	resellers := make(map[int64]*repository.ResellerProfitAndLoss)
+	resellerBuilders := make(map[int64]*profitAndLossBuilder)
+	resellerOrder := []int64{}
+
+	builderFor := func(ownerType string, ownerID int64, ownerName, ownerPhone string) *profitAndLossBuilder {
+		if ownerType != "RESELLER" {
+			return company
+		}
+
+		if _, ok := resellerBuilders[ownerID]; !ok {
+			resellerBuilders[ownerID] = newProfitAndLossBuilder()
+			resellers[ownerID] = &repository.ResellerProfitAndLoss{
+				Reseller: repository.UserShort{
+					ID:          uint32(ownerID),
+					Name:        ownerName,
+					PhoneNumber: ownerPhone,
+				},
+			}
+			resellerOrder = append(resellerOrder, ownerID)
+		}
+
+		return resellerBuilders[ownerID]
+	}
+
+	for _, pgLine := range pgLines {
+		builderFor(pgLine.OwnerType, pgLine.OwnerID, pgLine.OwnerName, pgLine.OwnerPhone).add(pgLine)
+	}
+
+	for _, pgAdjustment := range pgAdjustments {
+		builderFor(pgAdjustment.OwnerType, pgAdjustment.OwnerID, pgAdjustment.OwnerName, pgAdjustment.OwnerPhone).addAdjustment(pgAdjustment)
+	}
+
+	report := &repository.ProfitAndLoss{
+		DateFrom:    filter.DateFrom,
+		DateTo:      filter.DateTo,
+		Company:     company.build(),
+		Resellers:   make([]*repository.ResellerProfitAndLoss, len(resellerOrder)),
+		GeneratedAt: time.Now(),
+	}
+
+	for i, resellerID := range resellerOrder {
+		resellers[resellerID].ProfitAndLossSection = *resellerBuilders[resellerID].build()
+		report.Resellers[i] = resellers[resellerID]
+	}
+
+	return report, nil
+}
+
+func (rr *ReportRepository) GetInventoryValuation(ctx context.Context, filter *repository.InventoryValuationFilter) (*repository.InventoryValuation, error) {
+	productID := pgtype.Int8{Valid: false}
+	if filter.ProductID != nil {
+		productID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	resellerID := pgtype.Int8{Valid: false}
+	if filter.ResellerID != nil {
+		resellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	pgCompanyBatches, err := rr.queries.ListCompanyInventoryValuation(ctx, generated.ListCompanyInventoryValuationParams{
+		AsOf:      filter.AsOf,
+		ProductID: productID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list company inventory valuation: %s", err.Error())
+	}
+
+	pgResellerBatches, err := rr.queries.ListResellerInventoryValuation(ctx, generated.ListResellerInventoryValuationParams{
+		AsOf:       filter.AsOf,
+		ResellerID: resellerID,
+		ProductID:  productID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reseller inventory valuation: %s", err.Error())
+	}
+
+	company := newInventoryValuationBuilder()
+	for _, pgBatch := range pgCompanyBatches {
+		company.add(&repository.InventoryValuationBatch{
+			BatchID:     uint32(pgBatch.BatchID),
+			BatchNumber: pgBatch.BatchNumber,
+			ProductID:   uint32(pgBatch.ProductID),
+			ProductName: pgBatch.ProductName,
+			Category:    pgBatch.Category,
+			Quantity:    pgBatch.RemainingQuantity,
+			UnitCost:    pkg.PgTypeNumericToFloat64(pgBatch.UnitCost),
+		})
+	}
+
+	report := &repository.InventoryValuation{
+		AsOf:        filter.AsOf,
+		Company:     company.section,
+		Resellers:   []*repository.ResellerInventoryValuation{},
+		GeneratedAt: time.Now(),
+	}
+
+	resellerBuilders := make(map[int64]*inventoryValuationBuilder)
+	for _, pgBatch := range pgResellerBatches {
+		builder, ok := resellerBuilders[pgBatch.ResellerID]
+		if !ok {
+			builder = newInventoryValuationBuilder()
+			resellerBuilders[pgBatch.ResellerID] = builder
+			report.Resellers = append(report.Resellers, &repository.ResellerInventoryValuation{
+				Reseller: repository.UserShort{
+					ID:          uint32(pgBatch.ResellerID),
+					Name:        pgBatch.ResellerName,
+					PhoneNumber: pgBatch.ResellerPhone,
+				},
+			})
+		}
+
+		builder.add(&repository.InventoryValuationBatch{
+			BatchID:     uint32(pgBatch.BatchID),
+			BatchNumber: pgBatch.BatchNumber,
+			ProductID:   uint32(pgBatch.ProductID),
+			ProductName: pgBatch.ProductName,
+			Category:    pgBatch.Category,
+			Quantity:    pgBatch.RemainingQuantity,
+			UnitCost:    pkg.PgTypeNumericToFloat64(pgBatch.UnitCost),
+		})
+	}
+
+	report.TotalValue = report.Company.Value
+	for _, reseller := range report.Resellers {
+		reseller.InventoryValuationSection = *resellerBuilders[int64(reseller.Reseller.ID)].section
+		report.TotalValue += reseller.Value
+	}
+
+	return report, nil
+}
+
+func (rr *ReportRepository) GetReceivablesAging(ctx context.Context, filter *repository.ReceivablesAgingFilter) (*repository.ReceivablesAgingReport, error) {
+	resellerID := pgtype.Int8{Valid: false}
+	if filter.ResellerID != nil {
+		resellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	pgRows, err := rr.queries.ListReceivablesAging(ctx, resellerID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list receivables aging: %s", err.Error())
+	}
+
+	report := &repository.ReceivablesAgingReport{
+		Resellers:   make([]*repository.ResellerReceivablesAging, len(pgRows)),
+		GeneratedAt: time.Now(),
+	}
+
+	for i, pgRow := range pgRows {
+		aging := &repository.ResellerReceivablesAging{
+			Reseller: repository.UserShort{
+				ID:          uint32(pgRow.ResellerID),
+				Name:        pgRow.Name,
+				PhoneNumber: pgRow.PhoneNumber,
+			},
+			Balance: pkg.PgTypeNumericToFloat64(pgRow.Balance),
+			ReceivablesAging: repository.ReceivablesAging{
+				Days0To30:        pkg.PgTypeNumericToFloat64(pgRow.Days030),
+				Days31To60:       pkg.PgTypeNumericToFloat64(pgRow.Days3160),
+				Days61To90:       pkg.PgTypeNumericToFloat64(pgRow.Days6190),
+				DaysOver90:       pkg.PgTypeNumericToFloat64(pgRow.DaysOver90),
+				TotalOutstanding: pkg.PgTypeNumericToFloat64(pgRow.TotalOutstanding),
+				DaysOverdue:      pgRow.DaysOverdue,
+			},
+		}
+
+		if pgRow.OldestUnpaidDate.Valid {
+			aging.OldestUnpaidDate = &pgRow.OldestUnpaidDate.Time
+		}
+
+		report.Totals.Days0To30 += aging.Days0To30
+		report.Totals.Days31To60 += aging.Days31To60
+		report.Totals.Days61To90 += aging.Days61To90
+		report.Totals.DaysOver90 += aging.DaysOver90
+		report.Totals.TotalOutstanding += aging.TotalOutstanding
+		report.Totals.DaysOverdue = max(report.Totals.DaysOverdue, aging.DaysOverdue)
+
+		report.Resellers[i] = aging
+	}
+
+	return report, nil
+}
+
+func (rr *ReportRepository) TraceBatch(ctx context.Context, batchNumber string) ([]*repository.BatchTrace, error) {
+	pgBatches, err := rr.queries.ListBatchesByBatchNumber(ctx, batchNumber)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list batches by batch number: %s", err.Error())
+	}
+
+	if len(pgBatches) == 0 {
+		return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "batch %s not found", batchNumber)
+	}
+
+	pgMovements, err := rr.queries.ListBatchTraceMovements(ctx, batchNumber)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list batch trace movements: %s", err.Error())
+	}
+
+	pgHoldings, err := rr.queries.ListBatchResellerHoldings(ctx, batchNumber)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list batch reseller holdings: %s", err.Error())
+	}
+
+	// a batch number can be reused across products so each matching batch gets its own trail
+	traces := make([]*repository.BatchTrace, len(pgBatches))
+	tracesByID := make(map[int64]*repository.BatchTrace, len(pgBatches))
+	for i, pgBatch := range pgBatches {
+		traces[i] = &repository.BatchTrace{
+			BatchID:     uint32(pgBatch.ID),
+			BatchNumber: pgBatch.BatchNumber,
+			Product: repository.ProductShort{
+				ID:   uint32(pgBatch.ProductID),
+				Name: pgBatch.ProductName,
+				Unit: pgBatch.ProductUnit,
+			},
+			QuantityReceived: pgBatch.Quantity,
+			PurchasePrice:    pkg.PgTypeNumericToFloat64(pgBatch.PurchasePrice),
+			UnitCost:         pkg.PgTypeNumericToFloat64(pgBatch.UnitCost),
+			DateReceived:     pgBatch.DateReceived,
+			CompanyRemaining: pgBatch.CompanyRemaining,
+			Trail:            []*repository.BatchTraceEvent{},
+			ResellerHoldings: []*repository.BatchResellerHolding{},
+		}
+		tracesByID[pgBatch.ID] = traces[i]
+	}
+
+	for _, pgMovement := range pgMovements {
+		trace, ok := tracesByID[pgMovement.BatchID]
+		if !ok {
+			continue
+		}
+
+		// reseller IN movements are the distributions, the matching company OUT adds nothing new
+		eventType := pgMovement.Source
+		switch {
+		case pgMovement.MovementType == "IN" && pgMovement.Source == "PURCHASE":
+			eventType = repository.TRACE_EVENT_DISTRIBUTION
+			trace.TotalDistributed += pgMovement.Quantity
+		case pgMovement.MovementType == "OUT" && pgMovement.Source == "SALE":
+			eventType = repository.TRACE_EVENT_SALE
+			trace.TotalSold += pgMovement.Quantity
+		case pgMovement.MovementType == "OUT" && pgMovement.Source == "RETURN":
+			eventType = repository.TRACE_EVENT_RETURN
+			trace.TotalReturned += pgMovement.Quantity
+		case pgMovement.MovementType == "IN" && pgMovement.Source == "TRANSFER":
+			eventType = repository.TRACE_EVENT_TRANSFER_IN
+		case pgMovement.MovementType == "OUT" && pgMovement.Source == "TRANSFER":
+			eventType = repository.TRACE_EVENT_TRANSFER_OUT
+		}
+
+		trace.Trail = append(trace.Trail, &repository.BatchTraceEvent{
+			MovementID: uint32(pgMovement.MovementID),
+			EventType:  eventType,
+			Reseller: repository.UserShort{
+				ID:          uint32(pgMovement.ResellerID),
+				Name:        pgMovement.ResellerName,
+				PhoneNumber: pgMovement.ResellerPhone,
+			},
+			Quantity:  pgMovement.Quantity,
+			UnitCost:  pkg.PgTypeNumericToFloat64(pgMovement.UnitCost),
+			UnitPrice: pkg.PgTypeNumericToFloat64(pgMovement.UnitPrice),
+			Date:      pgMovement.CreatedAt,
+		})
+	}
+
+	for _, pgHolding := range pgHoldings {
+		trace, ok := tracesByID[pgHolding.BatchID]
+		if !ok {
+			continue
+		}
+
+		trace.ResellerRemaining += pgHolding.RemainingQuantity
+		trace.ResellerHoldings = append(trace.ResellerHoldings, &repository.BatchResellerHolding{
+			Reseller: repository.UserShort{
+				ID:          uint32(pgHolding.ResellerID),
+				Name:        pgHolding.ResellerName,
+				PhoneNumber: pgHolding.ResellerPhone,
+			},
+			Quantity: pgHolding.RemainingQuantity,
+		})
+	}
+
+	return traces, nil
+}
+
+func (rr *ReportRepository) GetAnalytics(ctx context.Context, filter *repository.AnalyticsFilter) (*repository.Analytics, error) {
+	params := generated.ListAnalyticsSeriesParams{
+		Interval:   filter.Interval,
+		GroupBy:    "",
+		DateFrom:   pgtype.Date{Time: filter.DateFrom, Valid: true},
+		DateTo:     pgtype.Date{Time: filter.DateTo, Valid: true},
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		Category:   pgtype.Text{Valid: false},
+	}
+
+	if filter.GroupBy != nil {
+		params.GroupBy = *filter.GroupBy
+	}
+	if filter.ResellerID != nil {
+		params.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+	if filter.ProductID != nil {
+		params.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+	if filter.Category != nil {
+		params.Category = pgtype.Text{String: *filter.Category, Valid: true}
+	}
+
+	// every series gets a point for each bucket in the range so charts have no gaps
+	buckets := analyticsBuckets(filter.Interval, filter.DateFrom, filter.DateTo)
+	if len(buckets) > maxAnalyticsBuckets {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "date range too large for %s interval, use a wider interval", filter.Interval)
+	}
+
+	pgRows, err := rr.queries.ListAnalyticsSeries(ctx, params)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list analytics series: %s", err.Error())
+	}
+
+	newSeries := func(key, name string) *repository.AnalyticsSeries {
+		series := &repository.AnalyticsSeries{
+			Key:    key,
+			Name:   name,
+			Points: make([]*repository.AnalyticsPoint, len(buckets)),
+		}
+		for i, bucket := range buckets {
+			series.Points[i] = &repository.AnalyticsPoint{Bucket: bucket}
+		}
+
+		return series
+	}
+
+	bucketIndex := make(map[string]int, len(buckets))
+	for i, bucket := range buckets {
+		bucketIndex[bucket.Format(time.DateOnly)] = i
+	}
+
+	analytics := &repository.Analytics{
+		Interval: filter.Interval,
+		GroupBy:  params.GroupBy,
+		DateFrom: filter.DateFrom,
+		DateTo:   filter.DateTo,
+		Totals:   newSeries("", "Total"),
+		Groups:   []*repository.AnalyticsSeries{},
+	}
+
+	groups := make(map[string]*repository.AnalyticsSeries)
+	for _, pgRow := range pgRows {
+		i, ok := bucketIndex[pgRow.Bucket.Time.Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+
+		row := &repository.AnalyticsPoint{
+			SalesValue:        pkg.PgTypeNumericToFloat64(pgRow.SalesValue),
+			UnitsSold:         pgRow.UnitsSold,
+			SalesCogs:         pkg.PgTypeNumericToFloat64(pgRow.SalesCogs),
+			DistributionValue: pkg.PgTypeNumericToFloat64(pgRow.DistributionValue),
+			PaymentsReceived:  pkg.PgTypeNumericToFloat64(pgRow.PaymentsReceived),
+		}
+
+		// the company cost of stock stays with admins
+		if filter.CompanyCosts {
+			row.DistributionCost = pkg.PgTypeNumericToFloat64(pgRow.DistributionCost)
+		}
+
+		addAnalyticsPoint(analytics.Totals.Points[i], row)
+
+		// payments have no product so they only count toward the totals when grouping by
+		// product or category
+		if params.GroupBy == "" || pgRow.GroupKey == "" {
+			continue
+		}
+
+		group, ok := groups[pgRow.GroupKey]
+		if !ok {
+			group = newSeries(pgRow.GroupKey, pgRow.GroupName)
+			groups[pgRow.GroupKey] = group
+			analytics.Groups = append(analytics.Groups, group)
+		}
+		addAnalyticsPoint(group.Points[i], row)
+	}
+
+	if !filter.CompanyCosts {
+		for _, series := range append([]*repository.AnalyticsSeries{analytics.Totals}, analytics.Groups...) {
+			for _, point := range series.Points {
+				point.GrossMargin = 0
+			}
+		}
+	}
+
+	return analytics, nil
+}
+
+func (rr *ReportRepository) GetResellerBalances(ctx context.Context) (*repository.ResellerBalances, error) {
+	pgRows, err := rr.queries.ListResellerBalances(ctx)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reseller balances: %s", err.Error())
+	}
+
+	report := &repository.ResellerBalances{
+		Resellers:   make([]*repository.ResellerBalance, len(pgRows)),
+		GeneratedAt: time.Now(),
+	}
+
+	for i, pgRow := range pgRows {
+		balance := &repository.ResellerBalance{
+			Reseller: repository.UserShort{
+				ID:          uint32(pgRow.ResellerID),
+				Name:        pgRow.Name,
+				PhoneNumber: pgRow.PhoneNumber,
+			},
+			TotalStockReceived: pgRow.TotalStockReceived,
+			TotalValueReceived: pkg.PgTypeNumericToFloat64(pgRow.TotalValueReceived),
+			TotalPaid:          pkg.PgTypeNumericToFloat64(pgRow.TotalPaid),
+			Balance:            pkg.PgTypeNumericToFloat64(pgRow.Balance),
+			DaysOverdue:        pgRow.DaysOverdue,
+		}
+
+		report.TotalValueReceived += balance.TotalValueReceived
+		report.TotalPaid += balance.TotalPaid
+		report.TotalBalance += balance.Balance
+
+		report.Resellers[i] = balance
+	}
+
+	return report, nil
+}
+
+func (rr *ReportRepository) GetPaymentsSummary(ctx context.Context, filter *repository.PaymentsSummaryFilter) (*repository.PaymentsSummary, error) {
+	pgRows, err := rr.queries.ListPaymentsSummary(ctx, generated.ListPaymentsSummaryParams{
+		DateFrom: pgtype.Date{Time: filter.DateFrom, Valid: true},
+		DateTo:   pgtype.Date{Time: filter.DateTo, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list payments summary: %s", err.Error())
+	}
+
+	summary := &repository.PaymentsSummary{
+		DateFrom:    filter.DateFrom,
+		DateTo:      filter.DateTo,
+		Lines:       make([]*repository.PaymentsSummaryLine, len(pgRows)),
+		ByMethod:    make(map[string]float64),
+		GeneratedAt: time.Now(),
+	}
+
+	for i, pgRow := range pgRows {
+		line := &repository.PaymentsSummaryLine{
+			Reseller: repository.UserShort{
+				ID:          uint32(pgRow.ResellerID),
+				Name:        pgRow.Name,
+				PhoneNumber: pgRow.PhoneNumber,
+			},
+			Method: pgRow.Method,
+			Count:  pgRow.PaymentCount,
+			Amount: pkg.PgTypeNumericToFloat64(pgRow.TotalAmount),
+		}
+
+		summary.ByMethod[line.Method] += line.Amount
+		summary.TotalCount += line.Count
+		summary.TotalAmount += line.Amount
+
+		summary.Lines[i] = line
+	}
+
+	return summary, nil
+}
+
+func (rr *ReportRepository) GetPurchaseOrderFillRate(ctx context.Context, filter *repository.PurchaseOrderFillRateFilter) (*repository.PurchaseOrderFillRate, error) {
+	params := generated.ListPurchaseOrderFillRatesParams{
+		DateFrom:   pgtype.Date{Time: filter.DateFrom, Valid: true},
+		DateTo:     pgtype.Date{Time: filter.DateTo, Valid: true},
+		SupplierID: pgtype.Int8{Valid: false},
+	}
+
+	if filter.SupplierID != nil {
+		params.SupplierID = pgtype.Int8{Int64: int64(*filter.SupplierID), Valid: true}
+	}
+
+	pgRows, err := rr.queries.ListPurchaseOrderFillRates(ctx, params)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list purchase order fill rates: %s", err.Error())
+	}
+
+	report := &repository.PurchaseOrderFillRate{
+		DateFrom:    filter.DateFrom,
+		DateTo:      filter.DateTo,
+		Orders:      make([]*repository.PurchaseOrderFill, len(pgRows)),
+		BySupplier:  []*repository.SupplierFillRate{},
+		GeneratedAt: time.Now(),
+	}
+
+	suppliers := make(map[int64]*repository.SupplierFillRate)
+	for i, pgRow := range pgRows {
+		order := &repository.PurchaseOrderFill{
+			PurchaseOrderID: uint32(pgRow.ID),
+			Status:          pgRow.Status,
+			SentAt:          pgRow.SentAt.Time,
+			Supplier: repository.SupplierShort{
+				ID:   uint32(pgRow.SupplierID),
+				Name: pgRow.SupplierName,
+			},
+			FillRateFigures: repository.FillRateFigures{
+				Lines:            pgRow.Lines,
+				LinesFilled:      pgRow.LinesFilled,
+				QuantityOrdered:  pgRow.QuantityOrdered,
+				QuantityReceived: pgRow.QuantityReceived,
+			},
+		}
+		setFillRates(&order.FillRateFigures)
+
+		supplier, ok := suppliers[pgRow.SupplierID]
+		if !ok {
+			supplier = &repository.SupplierFillRate{Supplier: order.Supplier}
+			suppliers[pgRow.SupplierID] = supplier
+			report.BySupplier = append(report.BySupplier, supplier)
+		}
+		supplier.Orders++
+		addFillRateFigures(&supplier.FillRateFigures, order.FillRateFigures)
+		addFillRateFigures(&report.Total, order.FillRateFigures)
+
+		report.Orders[i] = order
+	}
+
+	for _, supplier := range report.BySupplier {
+		setFillRates(&supplier.FillRateFigures)
+	}
+	setFillRates(&report.Total)
+
+	return report, nil
+}
+
+func (rr *ReportRepository) GetPurchasePriceVariance(ctx context.Context, filter *repository.PurchasePriceVarianceFilter) (*repository.PurchasePriceVariance, error) {
+	params := generated.ListPurchasePriceVariancesParams{
+		DateFrom:   pgtype.Date{Time: filter.DateFrom, Valid: true},
+		DateTo:     pgtype.Date{Time: filter.DateTo, Valid: true},
+		SupplierID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+	}
+
+	if filter.SupplierID != nil {
+		params.SupplierID = pgtype.Int8{Int64: int64(*filter.SupplierID), Valid: true}
+	}
+
+	if filter.ProductID != nil {
+		params.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	pgRows, err := rr.queries.ListPurchasePriceVariances(ctx, params)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list purchase price variances: %s", err.Error())
+	}
+
+	report := &repository.PurchasePriceVariance{
+		DateFrom:    filter.DateFrom,
+		DateTo:      filter.DateTo,
+		Lines:       make([]*repository.PurchasePriceVarianceLine, len(pgRows)),
+		GeneratedAt: time.Now(),
+	}
+
+	for i, pgRow := range pgRows {
+		line := &repository.PurchasePriceVarianceLine{
+			PurchaseOrderID: uint32(pgRow.PurchaseOrderID),
+			Supplier: repository.SupplierShort{
+				ID:   uint32(pgRow.SupplierID),
+				Name: pgRow.SupplierName,
+			},
+			Product: repository.ProductShort{
+				ID:   uint32(pgRow.ProductID),
+				Name: pgRow.ProductName,
+				Unit: pgRow.ProductUnit,
+			},
+			BatchID:       uint32(pgRow.BatchID),
+			BatchNumber:   pgRow.BatchNumber,
+			DateReceived:  pgRow.DateReceived,
+			Quantity:      pgRow.Quantity,
+			ExpectedPrice: pkg.PgTypeNumericToFloat64(pgRow.ExpectedPrice),
+			PurchasePrice: pkg.PgTypeNumericToFloat64(pgRow.PurchasePrice),
+		}
+		line.UnitVariance = roundCents(line.PurchasePrice - line.ExpectedPrice)
+		line.Variance = roundCents(line.UnitVariance * float64(line.Quantity))
+		line.VariancePct = line.UnitVariance / line.ExpectedPrice * 100
+
+		report.ExpectedValue = roundCents(report.ExpectedValue + line.ExpectedPrice*float64(line.Quantity))
+		report.ActualValue = roundCents(report.ActualValue + line.PurchasePrice*float64(line.Quantity))
+
+		report.Lines[i] = line
+	}
+
+	report.TotalVariance = roundCents(report.ActualValue - report.ExpectedValue)
+	if report.ExpectedValue != 0 {
+		report.VariancePct = report.TotalVariance / report.ExpectedValue * 100
+	}
+
+	return report, nil
+}
+
+// profitAndLossBuilder accumulates product level rows into a section with product and category breakdowns.
+type profitAndLossBuilder struct {
+	section    *repository.ProfitAndLossSection
+	products   map[string]*repository.ProfitAndLossLine
+	categories map[string]*repository.ProfitAndLossLine
+}
+
+func newProfitAndLossBuilder() *profitAndLossBuilder {
+	return &profitAndLossBuilder{
+		section: &repository.ProfitAndLossSection{
+			ByProduct:  []*repository.ProfitAndLossLine{},
+			ByCategory: []*repository.ProfitAndLossLine{},
+		},
+		products:   make(map[string]*repository.ProfitAndLossLine),
+		categories: make(map[string]*repository.ProfitAndLossLine),
+	}
+}
+
+func (b *profitAndLossBuilder) add(pgLine generated.ListProfitAndLossLinesRow) {
+	revenue := pkg.PgTypeNumericToFloat64(pgLine.Revenue)
+	cogs := pkg.PgTypeNumericToFloat64(pgLine.Cogs)
+
+	productKey := fmt.Sprintf("%d", pgLine.ProductID)
+	product, ok := b.products[productKey]
+	if !ok {
+		product = &repository.ProfitAndLossLine{Key: productKey, Name: pgLine.ProductName}
+		b.products[productKey] = product
+		b.section.ByProduct = append(b.section.ByProduct, product)
+	}
+
+	category, ok := b.categories[pgLine.Category]
+	if !ok {
+		category = &repository.ProfitAndLossLine{Key: pgLine.Category, Name: pgLine.Category}
+		b.categories[pgLine.Category] = category
+		b.section.ByCategory = append(b.section.ByCategory, category)
+	}
+
+	for _, line := range []*repository.ProfitAndLossLine{product, category} {
+		line.Quantity += pgLine.Quantity
+		line.Revenue += revenue
+		line.Cogs += cogs
+	}
+
+	b.section.Quantity += pgLine.Quantity
+	b.section.Revenue += revenue
+	b.section.Cogs += cogs
+}
+
+// addAdjustment records the value of stock written off, increases from count corrections
+// come through as negative write offs.
+func (b *profitAndLossBuilder) addAdjustment(pgAdjustment generated.ListProfitAndLossAdjustmentsRow) {
+	writeOff := pkg.PgTypeNumericToFloat64(pgAdjustment.WriteOffValue)
+
+	productKey := fmt.Sprintf("%d", pgAdjustment.ProductID)
+	product, ok := b.products[productKey]
+	if !ok {
+		product = &repository.ProfitAndLossLine{Key: productKey, Name: pgAdjustment.ProductName}
+		b.products[productKey] = product
+		b.section.ByProduct = append(b.section.ByProduct, product)
+	}
+
+	category, ok := b.categories[pgAdjustment.Category]
+	if !ok {
+		category = &repository.ProfitAndLossLine{Key: pgAdjustment.Category, Name: pgAdjustment.Category}
+		b.categories[pgAdjustment.Category] = category
+		b.section.ByCategory = append(b.section.ByCategory, category)
+	}
+
+	product.Adjustments += writeOff
+	category.Adjustments += writeOff
+	b.section.Adjustments += writeOff
+}
+
+func (b *profitAndLossBuilder) build() *repository.ProfitAndLossSection {
+	for _, lines := range [][]*repository.ProfitAndLossLine{b.section.ByProduct, b.section.ByCategory} {
+		for _, line := range lines {
+			line.GrossMargin, line.MarginPct = grossMargin(line.Revenue, line.Cogs)
+			line.NetMargin = line.GrossMargin - line.Adjustments
+		}
+	}
+	b.section.GrossMargin, b.section.MarginPct = grossMargin(b.section.Revenue, b.section.Cogs)
+	b.section.NetMargin = b.section.GrossMargin - b.section.Adjustments
+
+	return b.section
+}
+
+func grossMargin(revenue, cogs float64) (float64, float64) {
+	margin := revenue - cogs
+	if revenue == 0 {
+		return margin, 0
+	}
+
+	return margin, margin / revenue * 100
+}
+
+func addFillRateFigures(total *repository.FillRateFigures, figures repository.FillRateFigures) {
+	total.Lines += figures.Lines
+	total.LinesFilled += figures.LinesFilled
+	total.QuantityOrdered += figures.QuantityOrdered
+	total.QuantityReceived += figures.QuantityReceived
+}
+
+func setFillRates(figures *repository.FillRateFigures) {
+	if figures.QuantityOrdered != 0 {
+		figures.FillRatePct = float64(figures.QuantityReceived) / float64(figures.QuantityOrdered) * 100
+	}
+
+	if figures.Lines != 0 {
+		figures.LineFillRatePct = float64(figures.LinesFilled) / float64(figures.Lines) * 100
+	}
+}
+
+// inventoryValuationBuilder accumulates batch level quantities into a section with a product breakdown.
+type inventoryValuationBuilder struct {
+	section  *repository.InventoryValuationSection
+	products map[uint32]*repository.InventoryValuationProduct
+}
+
+func newInventoryValuationBuilder() *inventoryValuationBuilder {
+	return &inventoryValuationBuilder{
+		section: &repository.InventoryValuationSection{
+			ByProduct: []*repository.InventoryValuationProduct{},
+			ByBatch:   []*repository.InventoryValuationBatch{},
+		},
+		products: make(map[uint32]*repository.InventoryValuationProduct),
+	}
+}
+
+func (b *inventoryValuationBuilder) add(batch *repository.InventoryValuationBatch) {
+	batch.Value = float64(batch.Quantity) * batch.UnitCost
+
+	product, ok := b.products[batch.ProductID]
+	if !ok {
+		product = &repository.InventoryValuationProduct{
+			ProductID:   batch.ProductID,
+			ProductName: batch.ProductName,
+			Category:    batch.Category,
+		}
+		b.products[batch.ProductID] = product
+		b.section.ByProduct = append(b.section.ByProduct, product)
+	}
+
+	product.Quantity += batch.Quantity
+	product.Value += batch.Value
+
+	b.section.Quantity += batch.Quantity
+	b.section.Value += batch.Value
+	b.section.ByBatch = append(b.section.ByBatch, batch)
+}
+
+func addAnalyticsPoint(point, row *repository.AnalyticsPoint) {
+	point.SalesValue += row.SalesValue
+	point.UnitsSold += row.UnitsSold
+	point.SalesCogs += row.SalesCogs
+	point.ResellerMargin = point.SalesValue - point.SalesCogs
+	point.DistributionValue += row.DistributionValue
+	point.DistributionCost += row.DistributionCost
+	point.GrossMargin = point.DistributionValue - point.DistributionCost
+	point.PaymentsReceived += row.PaymentsReceived
+}
+
+// analyticsBuckets returns the start date of every bucket between from and to, matching
+// postgres date_trunc where weeks start on Monday.
+func analyticsBuckets(interval string, from, to time.Time) []time.Time {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	next := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	switch interval {
+	case repository.ANALYTICS_INTERVAL_WEEK:
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case repository.ANALYTICS_INTERVAL_MONTH:
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	}
+
+	buckets := []time.Time{}
+	for bucket := start; !bucket.After(end); bucket = next(bucket) {
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets
+}
@@ -56,6 +56,7 @@ func (rr *ResellerRepository) GetResellerByID(ctx context.Context, id uint32) (*
 			Balance:            pkg.PgTypeNumericToFloat64(pgReseller.Balance),
 		},
 	}
+	setCreditUsage(&reseller.Account, pgReseller.CreditLimit)
 
 	return reseller, nil
 }
@@ -65,6 +66,11 @@ func (rr *ResellerRepository) ListResellersWithAccount(ctx context.Context, filt
 		Limit:  int32(filter.Pagination.PageSize),
 		Offset: pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
 		Search: pgtype.Text{Valid: false},
+		Sort:   pgtype.Text{Valid: false},
+	}
+
+	if filter.Sort != nil {
+		listParams.Sort = pgtype.Text{String: *filter.Sort, Valid: true}
 	}
 
 	countSearchParm := pgtype.Text{Valid: false}
@@ -103,7 +109,16 @@ func (rr *ResellerRepository) ListResellersWithAccount(ctx context.Context, filt
 				TotalCogs:          pkg.PgTypeNumericToFloat64(pgReseller.TotalCogs),
 				Balance:            pkg.PgTypeNumericToFloat64(pgReseller.Balance),
 			},
+			Aging: &repository.ReceivablesAging{
+				Days0To30:        pkg.PgTypeNumericToFloat64(pgReseller.Days030),
+				Days31To60:       pkg.PgTypeNumericToFloat64(pgReseller.Days3160),
+				Days61To90:       pkg.PgTypeNumericToFloat64(pgReseller.Days6190),
+				DaysOver90:       pkg.PgTypeNumericToFloat64(pgReseller.DaysOver90),
+				TotalOutstanding: pkg.PgTypeNumericToFloat64(pgReseller.TotalOutstanding),
+				DaysOverdue:      pgReseller.DaysOverdue,
+			},
 		}
+		setCreditUsage(&reseller.Account, pgReseller.CreditLimit)
 		resellers[i] = reseller
 	}
 
@@ -111,8 +126,10 @@ func (rr *ResellerRepository) ListResellersWithAccount(ctx context.Context, filt
 }
 
 func (rr *ResellerRepository) CreateResellerSale(ctx context.Context, sale *repository.ResellerSale) (*repository.ResellerSale, error) {
+	sale.AllocationStrategy = rr.db.allocationStrategy(sale.AllocationStrategy)
+
 	err := rr.db.ExecTx(ctx, func(q *generated.Queries) error {
-		totalAvailable, err := q.GetResellerBatchInventoryProductSum(ctx, generated.GetResellerBatchInventoryProductSumParams{
+		available, err := q.GetResellerBatchInventoryProductSum(ctx, generated.GetResellerBatchInventoryProductSumParams{
 			ResellerID: int64(sale.ResellerID),
 			ProductID:  int64(sale.ProductID),
 		})
@@ -120,7 +137,11 @@ func (rr *ResellerRepository) CreateResellerSale(ctx context.Context, sale *repo
 			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller batch inventory product sum: %s", err.Error())
 		}
 
-		if totalAvailable < int64(sale.Quantity) {
+		// expired layers cannot be sold, they have to be returned or written off
+		if available.TotalRemaining < int64(sale.Quantity) {
+			if available.TotalExpired > 0 {
+				return pkg.Errorf(pkg.INVALID_ERROR, "insufficient stock for reseller sale, %d units are in expired batches", available.TotalExpired)
+			}
 			return pkg.Errorf(pkg.INVALID_ERROR, "insufficient stock for reseller sale")
 		}
 
@@ -149,6 +170,7 @@ func (rr *ResellerRepository) CreateResellerSale(ctx context.Context, sale *repo
 			UnitPrice:    pkg.Float64ToPgTypeNumeric(sale.SellingPrice),
 			Source:       "SALE",
 			Note:         "Reseller Sale",
+			MovementDate: pgtype.Timestamptz{Time: sale.DateSold, Valid: true},
 		})
 		if err != nil {
 			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
@@ -167,21 +189,33 @@ func (rr *ResellerRepository) CreateResellerSale(ctx context.Context, sale *repo
 		batches, err := q.ListResellerBatchInventoryForUpdate(ctx, generated.ListResellerBatchInventoryForUpdateParams{
 			ResellerID: int64(sale.ResellerID),
 			ProductID:  int64(sale.ProductID),
+			Strategy:   sale.AllocationStrategy,
 		})
 		if err != nil {
 			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reseller batch inventory for update: %s", err.Error())
 		}
 
-		quantityToDeduct := int64(sale.Quantity)
+		// a source batch can have several layers at the reseller, one per distribution
+		batchIDs := make([]int64, len(batches))
+		remaining := make([]int64, len(batches))
+		for i, batch := range batches {
+			batchIDs[i] = batch.SourceBatchID
+			remaining[i] = batch.RemainingQuantity
+		}
+
+		takes, err := allocateBatches(batchIDs, remaining, int64(sale.Quantity), sale.Batches)
+		if err != nil {
+			return err
+		}
+
 		saleCogs := 0.0
 
-		for _, batch := range batches {
-			if quantityToDeduct <= 0 {
-				break
+		for i, batch := range batches {
+			takeQty := takes[i]
+			if takeQty == 0 {
+				continue
 			}
 
-			takeQty := min(batch.RemainingQuantity, quantityToDeduct)
-
 			_, err = q.RemoveResellerBatchInventoryQuantity(ctx, generated.RemoveResellerBatchInventoryQuantityParams{
 				InventoryID: batch.ID,
 				Quantity:    takeQty,
@@ -197,13 +231,13 @@ func (rr *ResellerRepository) CreateResellerSale(ctx context.Context, sale *repo
 				BatchNumber:     batch.BatchNumber,
 				Quantity:        takeQty,
 				UnitCost:        batch.UnitCost,
+				BatchUnitCost:   batch.BatchUnitCost,
 			})
 			if err != nil {
 				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
 			}
 
 			saleCogs += float64(takeQty) * pkg.PgTypeNumericToFloat64(batch.UnitCost)
-			quantityToDeduct -= takeQty
 		}
 
 		// update reseller account
@@ -397,10 +431,66 @@ func (rr *ResellerRepository) GetResellerAccount(ctx context.Context, resellerID
 		TotalCogs:          pkg.PgTypeNumericToFloat64(pgResellerAccount.TotalCogs),
 		Balance:            pkg.PgTypeNumericToFloat64(pgResellerAccount.Balance),
 	}
+	setCreditUsage(resellerAccount, pgResellerAccount.CreditLimit)
+
+	return resellerAccount, nil
+}
+
+func (rr *ResellerRepository) UpdateCreditLimit(ctx context.Context, resellerID uint32, creditLimit *float64) (*repository.ResellerAccount, error) {
+	params := generated.UpdateResellerCreditLimitParams{
+		ResellerID:  int64(resellerID),
+		CreditLimit: pgtype.Numeric{Valid: false},
+	}
+
+	if creditLimit != nil {
+		params.CreditLimit = pkg.Float64ToPgTypeNumeric(*creditLimit)
+	}
+
+	pgResellerAccount, err := rr.queries.UpdateResellerCreditLimit(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller account not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update credit limit: %s", err.Error())
+	}
+
+	resellerAccount := &repository.ResellerAccount{
+		ResellerID:         resellerID,
+		TotalStockReceived: pgResellerAccount.TotalStockReceived,
+		TotalValueReceived: pkg.PgTypeNumericToFloat64(pgResellerAccount.TotalValueReceived),
+		TotalSalesValue:    pkg.PgTypeNumericToFloat64(pgResellerAccount.TotalSalesValue),
+		TotalPaid:          pkg.PgTypeNumericToFloat64(pgResellerAccount.TotalPaid),
+		TotalCogs:          pkg.PgTypeNumericToFloat64(pgResellerAccount.TotalCogs),
+		Balance:            pkg.PgTypeNumericToFloat64(pgResellerAccount.Balance),
+	}
+	setCreditUsage(resellerAccount, pgResellerAccount.CreditLimit)
 
 	return resellerAccount, nil
 }
 
+// setCreditUsage fills in the credit limit, headroom and utilisation (percent of the limit
+// owed) when the reseller has a limit.
+func setCreditUsage(account *repository.ResellerAccount, creditLimit pgtype.Numeric) {
+	if !creditLimit.Valid {
+		return
+	}
+
+	limit := pkg.PgTypeNumericToFloat64(creditLimit)
+	headroom := roundCents(limit - account.Balance)
+
+	var utilisation float64
+	switch {
+	case limit > 0:
+		utilisation = roundCents(account.Balance / limit * 100)
+	case account.Balance > 0:
+		utilisation = 100
+	}
+
+	account.CreditLimit = &limit
+	account.CreditHeadroom = &headroom
+	account.CreditUtilisation = &utilisation
+}
+
 func (rr *ResellerRepository) ListResellerStockFormHelpers(ctx context.Context, resellerID uint32) (any, error) {
 	pgHelpers, err := rr.queries.ResellerStockFormHelpers(ctx, int64(resellerID))
 	if err != nil {
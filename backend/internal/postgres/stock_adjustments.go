@@ -0,0 +1,472 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (cr *CompanyRepository) AdjustStock(ctx context.Context, adjustment *repository.StockAdjustment) (*repository.StockAdjustment, error) {
+	err := cr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		return adjustStock(ctx, q, adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// adjustStock writes the adjustment off the owner's batch layers and stock using the caller's
+// transaction. A reseller's account is left as is, they still owe for the stock they lost.
+func adjustStock(ctx context.Context, q *generated.Queries, adjustment *repository.StockAdjustment) error {
+	if adjustment.Quantity > 0 && (adjustment.Reason != repository.ADJUSTMENT_COUNT_CORRECTION || adjustment.BatchID == nil) {
+		return pkg.Errorf(pkg.INVALID_ERROR, "only count corrections of a chosen batch can add stock")
+	}
+
+	batchID := pgtype.Int8{Valid: false}
+	if adjustment.BatchID != nil {
+		batchID = pgtype.Int8{Int64: int64(*adjustment.BatchID), Valid: true}
+	}
+
+	ownerID := pgtype.Int8{Valid: false}
+	ownerName := "Company"
+	if adjustment.OwnerType == repository.STOCK_OWNER_RESELLER {
+		if adjustment.ResellerID == nil {
+			return pkg.Errorf(pkg.INVALID_ERROR, "reseller_id is required for reseller stock")
+		}
+		ownerID = pgtype.Int8{Int64: int64(*adjustment.ResellerID), Valid: true}
+
+		name, err := q.GetResellerNameByID(ctx, ownerID.Int64)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+		}
+		ownerName = name
+	}
+
+	// company stock is adjusted at one location, the default one unless it is given
+	locationID := pgtype.Int8{Valid: false}
+	if adjustment.OwnerType == repository.STOCK_OWNER_COMPANY {
+		var id uint32
+		if adjustment.LocationID != nil {
+			id = *adjustment.LocationID
+		}
+
+		location, err := resolveLocation(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		id = uint32(location.ID)
+		adjustment.LocationID = &id
+		locationID = pgtype.Int8{Int64: location.ID, Valid: true}
+	} else {
+		adjustment.LocationID = nil
+	}
+
+	var (
+		lines []generated.CreateStockMovementBatchRecordParams
+		err   error
+	)
+	if adjustment.OwnerType == repository.STOCK_OWNER_RESELLER {
+		lines, err = adjustResellerBatchLayers(ctx, q, ownerID.Int64, adjustment, batchID)
+	} else {
+		lines, err = adjustCompanyBatchLayers(ctx, q, locationID.Int64, adjustment, batchID)
+	}
+	if err != nil {
+		return err
+	}
+
+	quantity := int64(adjustment.Quantity)
+	movementType := "IN"
+	if quantity < 0 {
+		quantity = -quantity
+		movementType = "OUT"
+	}
+
+	var value float64
+	adjustment.Batches = make([]*repository.StockAdjustmentBatch, len(lines))
+	for i, line := range lines {
+		unitCost := pkg.PgTypeNumericToFloat64(line.UnitCost)
+		value = roundCents(value + float64(line.Quantity)*unitCost)
+
+		adjustment.Batches[i] = &repository.StockAdjustmentBatch{
+			BatchID:     uint32(line.BatchID),
+			BatchNumber: line.BatchNumber,
+			Quantity:    line.Quantity,
+			UnitCost:    unitCost,
+		}
+	}
+
+	adjustment.TotalValue = value
+	if adjustment.Quantity < 0 {
+		adjustment.TotalValue = -value
+	}
+
+	note := fmt.Sprintf("%s adjustment (%s)", ownerName, strings.ToLower(strings.ReplaceAll(adjustment.Reason, "_", " ")))
+	if adjustment.Note != "" {
+		note = fmt.Sprintf("%s: %s", note, adjustment.Note)
+	}
+
+	stockMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+		ProductID:    int64(adjustment.ProductID),
+		OwnerType:    adjustment.OwnerType,
+		OwnerID:      ownerID,
+		MovementType: movementType,
+		Quantity:     quantity,
+		UnitPrice:    pkg.Float64ToPgTypeNumeric(roundCents(value / float64(quantity))),
+		Source:       "ADJUSTMENT",
+		Note:         note,
+		LocationID:   locationID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+	}
+
+	for _, line := range lines {
+		line.Owner = adjustment.OwnerType
+		line.StockMovementID = stockMovement.ID
+		if _, err := q.CreateStockMovementBatchRecord(ctx, line); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
+		}
+	}
+
+	if adjustment.OwnerType == repository.STOCK_OWNER_RESELLER {
+		if adjustment.Quantity < 0 {
+			_, err = q.SubtractResellerStockQuantity(ctx, generated.SubtractResellerStockQuantityParams{
+				ResellerID: ownerID.Int64,
+				ProductID:  int64(adjustment.ProductID),
+				Quantity:   quantity,
+			})
+		} else {
+			_, err = q.AddResellerStockQuantity(ctx, generated.AddResellerStockQuantityParams{
+				ResellerID: ownerID.Int64,
+				ProductID:  int64(adjustment.ProductID),
+				Quantity:   quantity,
+			})
+		}
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update reseller stock: %s", err.Error())
+		}
+	} else {
+		if adjustment.Quantity < 0 {
+			_, err = q.RemoveCompanyStock(ctx, generated.RemoveCompanyStockParams{
+				ProductID:  int64(adjustment.ProductID),
+				LocationID: locationID.Int64,
+				Quantity:   quantity,
+			})
+		} else {
+			_, err = q.AddCompanyStock(ctx, generated.AddCompanyStockParams{
+				ProductID:  int64(adjustment.ProductID),
+				LocationID: locationID.Int64,
+				Quantity:   quantity,
+			})
+		}
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update company stock: %s", err.Error())
+		}
+
+		adminstats, err := q.GetAdminStats(ctx, 1)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats: %s", err.Error())
+		}
+
+		_, err = q.UpdateAdminStats(ctx, generated.UpdateAdminStatsParams{
+			ID:                    1,
+			TotalCompanyStock:     pgtype.Int8{Int64: adminstats.TotalCompanyStock + int64(adjustment.Quantity), Valid: true},
+			TotalStockDistributed: pgtype.Int8{Valid: false},
+			TotalValueDistributed: pgtype.Numeric{Valid: false},
+			TotalPaymentsReceived: pgtype.Numeric{Valid: false},
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update admin stats: %s", err.Error())
+		}
+	}
+
+	pgAdjustment, err := q.CreateStockAdjustment(ctx, generated.CreateStockAdjustmentParams{
+		OwnerType:       adjustment.OwnerType,
+		ResellerID:      ownerID,
+		ProductID:       int64(adjustment.ProductID),
+		BatchID:         batchID,
+		Quantity:        adjustment.Quantity,
+		TotalValue:      pkg.Float64ToPgTypeNumeric(adjustment.TotalValue),
+		Reason:          adjustment.Reason,
+		Note:            pgtype.Text{String: adjustment.Note, Valid: adjustment.Note != ""},
+		StockMovementID: stockMovement.ID,
+		CreatedBy:       int64(adjustment.CreatedBy),
+		LocationID:      locationID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock adjustment: %s", err.Error())
+	}
+
+	adjustment.ID = uint32(pgAdjustment.ID)
+	adjustment.CreatedAt = pgAdjustment.CreatedAt
+
+	// create alert
+	if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+		Type:        "STOCK_ADJUSTED",
+		Title:       "Stock adjusted",
+		Description: fmt.Sprintf("%s - %d units (%s)", ownerName, adjustment.Quantity, adjustment.Reason),
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+	}
+
+	return nil
+}
+
+// adjustCompanyBatchLayers applies the adjustment to company batch inventory at the location
+// and returns the batch lines valued at the batch purchase price.
+func adjustCompanyBatchLayers(ctx context.Context, q *generated.Queries, locationID int64, adjustment *repository.StockAdjustment, batchID pgtype.Int8) ([]generated.CreateStockMovementBatchRecordParams, error) {
+	layers, err := q.ListBatchInventoryForAdjustment(ctx, generated.ListBatchInventoryForAdjustmentParams{
+		ProductID:  int64(adjustment.ProductID),
+		LocationID: locationID,
+		BatchID:    batchID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list batch inventory for update: %s", err.Error())
+	}
+
+	if adjustment.Quantity > 0 {
+		if len(layers) == 0 {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "batch not found for the product at the location")
+		}
+
+		layer := layers[0]
+		if _, err := q.AddBatchInventoryQuantity(ctx, generated.AddBatchInventoryQuantityParams{
+			BatchID:    layer.BatchID,
+			LocationID: layer.LocationID,
+			ProductID:  layer.ProductID,
+			Quantity:   int64(adjustment.Quantity),
+		}); err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add batch inventory quantity: %s", err.Error())
+		}
+
+		return []generated.CreateStockMovementBatchRecordParams{{
+			BatchID:     layer.BatchID,
+			BatchNumber: layer.BatchNumber,
+			Quantity:    int64(adjustment.Quantity),
+			UnitCost:    layer.UnitCost,
+		}}, nil
+	}
+
+	remainingToRemove := -int64(adjustment.Quantity)
+
+	var available int64
+	for _, layer := range layers {
+		available += layer.RemainingQuantity
+	}
+
+	if available < remainingToRemove {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "only %d units of the product are in stock", available)
+	}
+
+	lines := []generated.CreateStockMovementBatchRecordParams{}
+	for _, layer := range layers {
+		if remainingToRemove <= 0 {
+			break
+		}
+
+		takeQty := min(layer.RemainingQuantity, remainingToRemove)
+		if takeQty == 0 {
+			continue
+		}
+
+		if _, err := q.RemoveBatchInventoryQuantity(ctx, generated.RemoveBatchInventoryQuantityParams{
+			Quantity:   takeQty,
+			BatchID:    layer.BatchID,
+			LocationID: layer.LocationID,
+		}); err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove batch inventory quantity: %s", err.Error())
+		}
+
+		lines = append(lines, generated.CreateStockMovementBatchRecordParams{
+			BatchID:     layer.BatchID,
+			BatchNumber: layer.BatchNumber,
+			Quantity:    takeQty,
+			UnitCost:    layer.UnitCost,
+		})
+
+		remainingToRemove -= takeQty
+	}
+
+	return lines, nil
+}
+
+// adjustResellerBatchLayers applies the adjustment to the reseller's batch inventory and
+// returns the batch lines valued at what the reseller was charged.
+func adjustResellerBatchLayers(ctx context.Context, q *generated.Queries, resellerID int64, adjustment *repository.StockAdjustment, batchID pgtype.Int8) ([]generated.CreateStockMovementBatchRecordParams, error) {
+	layers, err := q.ListResellerBatchInventoryForAdjustment(ctx, generated.ListResellerBatchInventoryForAdjustmentParams{
+		ResellerID: resellerID,
+		ProductID:  int64(adjustment.ProductID),
+		BatchID:    batchID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reseller batch inventory for update: %s", err.Error())
+	}
+
+	if adjustment.Quantity > 0 {
+		if len(layers) == 0 {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "reseller has not received the batch")
+		}
+
+		// corrections top up the layer the reseller received last
+		layer := layers[len(layers)-1]
+		if _, err := q.AddResellerBatchInventoryQuantity(ctx, generated.AddResellerBatchInventoryQuantityParams{
+			Quantity:    int64(adjustment.Quantity),
+			InventoryID: layer.ID,
+		}); err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add reseller batch inventory quantity: %s", err.Error())
+		}
+
+		return []generated.CreateStockMovementBatchRecordParams{{
+			BatchID:       layer.SourceBatchID,
+			BatchNumber:   layer.BatchNumber,
+			Quantity:      int64(adjustment.Quantity),
+			UnitCost:      layer.UnitCost,
+			BatchUnitCost: layer.BatchUnitCost,
+		}}, nil
+	}
+
+	remainingToRemove := -int64(adjustment.Quantity)
+
+	var available int64
+	for _, layer := range layers {
+		available += layer.RemainingQuantity
+	}
+
+	if available < remainingToRemove {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "reseller only holds %d units of the product", available)
+	}
+
+	lines := []generated.CreateStockMovementBatchRecordParams{}
+	for _, layer := range layers {
+		if remainingToRemove <= 0 {
+			break
+		}
+
+		takeQty := min(layer.RemainingQuantity, remainingToRemove)
+		if takeQty == 0 {
+			continue
+		}
+
+		if _, err := q.RemoveResellerBatchInventoryQuantity(ctx, generated.RemoveResellerBatchInventoryQuantityParams{
+			Quantity:    takeQty,
+			InventoryID: layer.ID,
+		}); err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove reseller batch inventory quantity: %s", err.Error())
+		}
+
+		lines = append(lines, generated.CreateStockMovementBatchRecordParams{
+			BatchID:       layer.SourceBatchID,
+			BatchNumber:   layer.BatchNumber,
+			Quantity:      takeQty,
+			UnitCost:      layer.UnitCost,
+			BatchUnitCost: layer.BatchUnitCost,
+		})
+
+		remainingToRemove -= takeQty
+	}
+
+	return lines, nil
+}
+
+func (cr *CompanyRepository) ListStockAdjustments(ctx context.Context, filter *repository.StockAdjustmentFilter) ([]*repository.StockAdjustment, *pkg.Pagination, error) {
+	listParams := generated.ListStockAdjustmentsParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		OwnerType:  pgtype.Text{Valid: false},
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		Reason:     pgtype.Text{Valid: false},
+	}
+
+	countParams := generated.ListStockAdjustmentsCountParams{
+		OwnerType:  pgtype.Text{Valid: false},
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		Reason:     pgtype.Text{Valid: false},
+	}
+
+	if filter.OwnerType != nil {
+		listParams.OwnerType = pgtype.Text{String: *filter.OwnerType, Valid: true}
+		countParams.OwnerType = pgtype.Text{String: *filter.OwnerType, Valid: true}
+	}
+
+	if filter.ResellerID != nil {
+		listParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+		countParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	if filter.ProductID != nil {
+		listParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+		countParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	if filter.Reason != nil {
+		listParams.Reason = pgtype.Text{String: *filter.Reason, Valid: true}
+		countParams.Reason = pgtype.Text{String: *filter.Reason, Valid: true}
+	}
+
+	pgAdjustments, err := cr.queries.ListStockAdjustments(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stock adjustments: %s", err.Error())
+	}
+
+	totalCount, err := cr.queries.ListStockAdjustmentsCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count stock adjustments: %s", err.Error())
+	}
+
+	adjustments := make([]*repository.StockAdjustment, len(pgAdjustments))
+	for i, pgAdjustment := range pgAdjustments {
+		adjustments[i] = &repository.StockAdjustment{
+			ID:         uint32(pgAdjustment.ID),
+			OwnerType:  pgAdjustment.OwnerType,
+			ProductID:  uint32(pgAdjustment.ProductID),
+			Quantity:   pgAdjustment.Quantity,
+			TotalValue: pkg.PgTypeNumericToFloat64(pgAdjustment.TotalValue),
+			Reason:     pgAdjustment.Reason,
+			Note:       pgAdjustment.Note.String,
+			CreatedBy:  uint32(pgAdjustment.CreatedBy),
+			CreatedAt:  pgAdjustment.CreatedAt,
+			Product: &repository.ProductShort{
+				ID:   uint32(pgAdjustment.ProductID),
+				Name: pgAdjustment.ProductName,
+				Unit: pgAdjustment.ProductUnit,
+			},
+		}
+
+		if pgAdjustment.ResellerID.Valid {
+			id := uint32(pgAdjustment.ResellerID.Int64)
+			adjustments[i].ResellerID = &id
+			adjustments[i].User = &repository.UserShort{
+				ID:          id,
+				Name:        pgAdjustment.ResellerName,
+				PhoneNumber: pgAdjustment.ResellerPhoneNumber,
+			}
+		}
+
+		if pgAdjustment.BatchID.Valid {
+			id := uint32(pgAdjustment.BatchID.Int64)
+			adjustments[i].BatchID = &id
+		}
+
+		if pgAdjustment.LocationID.Valid {
+			id := uint32(pgAdjustment.LocationID.Int64)
+			adjustments[i].LocationID = &id
+		}
+	}
+
+	return adjustments, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+var _ repository.StockAuditRepository = (*StockAuditRepository)(nil)
+
+type StockAuditRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewStockAuditRepository(db *Store) *StockAuditRepository {
+	return &StockAuditRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (sr *StockAuditRepository) AuditStock(ctx context.Context) (*repository.StockAudit, error) {
+	pgDrifts, err := sr.queries.ListStockDrifts(ctx)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stock drifts: %s", err.Error())
+	}
+
+	audit := &repository.StockAudit{
+		Consistent: len(pgDrifts) == 0,
+		Drifts:     make([]*repository.StockDrift, len(pgDrifts)),
+		CheckedAt:  time.Now(),
+	}
+
+	for i, pgDrift := range pgDrifts {
+		drift := &repository.StockDrift{
+			Check:      pgDrift.CheckName,
+			Recorded:   pgDrift.Recorded,
+			Expected:   pgDrift.Expected,
+			Difference: pgDrift.Recorded - pgDrift.Expected,
+		}
+
+		if pgDrift.ResellerID.Valid {
+			drift.Reseller = &repository.UserShort{
+				ID:   uint32(pgDrift.ResellerID.Int64),
+				Name: pgDrift.ResellerName.String,
+			}
+		}
+
+		if pgDrift.LocationID.Valid {
+			drift.Location = &repository.LocationShort{
+				ID:   uint32(pgDrift.LocationID.Int64),
+				Name: pgDrift.LocationName.String,
+			}
+		}
+
+		if pgDrift.ProductID.Valid {
+			drift.Product = &repository.ProductShort{
+				ID:   uint32(pgDrift.ProductID.Int64),
+				Name: pgDrift.ProductName.String,
+			}
+		}
+
+		if pgDrift.BatchID.Valid {
+			batchID := uint32(pgDrift.BatchID.Int64)
+			drift.BatchID = &batchID
+			drift.BatchNumber = pgDrift.BatchNumber.String
+		}
+
+		if pgDrift.UnitCost.Valid {
+			unitCost := pkg.PgTypeNumericToFloat64(pgDrift.UnitCost)
+			drift.UnitCost = &unitCost
+		}
+
+		audit.Drifts[i] = drift
+	}
+
+	return audit, nil
+}
+
+func (sr *StockAuditRepository) RebuildStock(ctx context.Context) (*repository.StockAudit, error) {
+	rebuild := &repository.StockRebuild{}
+
+	err := sr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		// writers queue behind the lock and apply their changes on top of the rebuilt quantities
+		if err := q.LockStockTables(ctx); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to lock stock tables: %s", err.Error())
+		}
+
+		var err error
+		if rebuild.CompanyStock, err = q.RebuildCompanyStock(ctx); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to rebuild company stock: %s", err.Error())
+		}
+
+		if rebuild.ResellerStock, err = q.RebuildResellerStock(ctx); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to rebuild reseller stock: %s", err.Error())
+		}
+
+		if rebuild.BatchInventory, err = q.RebuildBatchInventory(ctx); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to rebuild batch inventory: %s", err.Error())
+		}
+
+		if rebuild.ResellerBatchInventory, err = q.RebuildResellerBatchInventory(ctx); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to rebuild reseller batch inventory: %s", err.Error())
+		}
+
+		created, err := q.CreateMissingResellerBatchInventory(ctx)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create missing reseller batch inventory: %s", err.Error())
+		}
+		rebuild.ResellerBatchInventory += created
+
+		// admin stats last so it sums the rebuilt company stock
+		if rebuild.AdminStats, err = q.RebuildAdminStatsCompanyStock(ctx); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to rebuild admin stats: %s", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	audit, err := sr.AuditStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	audit.Rebuilt = rebuild
+
+	return audit, nil
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (cr *CompanyRepository) ListNearExpiryStock(ctx context.Context, filter *repository.NearExpiryStockFilter) ([]*repository.NearExpiryStock, *pkg.Pagination, error) {
+	listParams := generated.ListNearExpiryStockParams{
+		Days:       filter.Days,
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		OwnerType:  pgtype.Text{Valid: false},
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
+	}
+
+	countParams := generated.ListNearExpiryStockCountParams{
+		Days:       filter.Days,
+		OwnerType:  pgtype.Text{Valid: false},
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
+	}
+
+	if filter.OwnerType != nil {
+		listParams.OwnerType = pgtype.Text{String: *filter.OwnerType, Valid: true}
+		countParams.OwnerType = pgtype.Text{String: *filter.OwnerType, Valid: true}
+	}
+
+	if filter.ResellerID != nil {
+		listParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+		countParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	if filter.ProductID != nil {
+		listParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+		countParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	if filter.LocationID != nil {
+		listParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+		countParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+	}
+
+	pgLayers, err := cr.queries.ListNearExpiryStock(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list near expiry stock: %s", err.Error())
+	}
+
+	totalCount, err := cr.queries.ListNearExpiryStockCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count near expiry stock: %s", err.Error())
+	}
+
+	layers := make([]*repository.NearExpiryStock, len(pgLayers))
+	for i, pgLayer := range pgLayers {
+		unitCost := pkg.PgTypeNumericToFloat64(pgLayer.UnitCost)
+		layers[i] = &repository.NearExpiryStock{
+			OwnerType:         pgLayer.OwnerType,
+			ProductID:         uint32(pgLayer.ProductID),
+			BatchID:           uint32(pgLayer.BatchID),
+			BatchNumber:       pgLayer.BatchNumber,
+			ExpiryDate:        pgLayer.ExpiryDate.Time,
+			DaysToExpiry:      pgLayer.DaysToExpiry,
+			Expired:           pgLayer.DaysToExpiry < 0,
+			RemainingQuantity: pgLayer.RemainingQuantity,
+			UnitCost:          unitCost,
+			TotalValue:        float64(pgLayer.RemainingQuantity) * unitCost,
+			Product: &repository.ProductShort{
+				ID:   uint32(pgLayer.ProductID),
+				Name: pgLayer.ProductName,
+				Unit: pgLayer.ProductUnit,
+			},
+		}
+
+		if pgLayer.ResellerID.Valid {
+			id := uint32(pgLayer.ResellerID.Int64)
+			layers[i].ResellerID = &id
+			layers[i].User = &repository.UserShort{
+				ID:          id,
+				Name:        pgLayer.ResellerName,
+				PhoneNumber: pgLayer.ResellerPhoneNumber,
+			}
+		}
+
+		if pgLayer.LocationID.Valid {
+			id := uint32(pgLayer.LocationID.Int64)
+			layers[i].LocationID = &id
+			layers[i].Location = &repository.LocationShort{
+				ID:   id,
+				Name: pgLayer.LocationName,
+			}
+		}
+	}
+
+	return layers, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
@@ -34,6 +34,7 @@ func (smr *StockMovementRepository) List(ctx context.Context, filter *repository
 		ProductID:    pgtype.Int8{Valid: false},
 		MovementType: pgtype.Text{Valid: false},
 		Source:       pgtype.Text{Valid: false},
+		LocationID:   pgtype.Int8{Valid: false},
 	}
 
 	countParams := generated.ListStockMovementsCountParams{
@@ -43,6 +44,7 @@ func (smr *StockMovementRepository) List(ctx context.Context, filter *repository
 		ProductID:    pgtype.Int8{Valid: false},
 		MovementType: pgtype.Text{Valid: false},
 		Source:       pgtype.Text{Valid: false},
+		LocationID:   pgtype.Int8{Valid: false},
 	}
 
 	if filter.Search != nil {
@@ -76,6 +78,11 @@ func (smr *StockMovementRepository) List(ctx context.Context, filter *repository
 		countParams.Source = pgtype.Text{String: *filter.Source, Valid: true}
 	}
 
+	if filter.LocationID != nil {
+		listParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+		countParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+	}
+
 	pgStockMovements, err := smr.queries.ListStockMovements(ctx, listParams)
 	if err != nil {
 		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stock movements: %s", err.Error())
@@ -98,6 +105,7 @@ func (smr *StockMovementRepository) List(ctx context.Context, filter *repository
 			UnitPrice:    pkg.PgTypeNumericToFloat64(pgStockMovement.UnitPrice),
 			Source:       pgStockMovement.Source,
 			Note:         pgStockMovement.Note,
+			MovementDate: pgStockMovement.MovementDate,
 			CreatedAt:    pgStockMovement.CreatedAt,
 			Product: &repository.ProductShort{
 				ID: uint32(pgStockMovement.ProductID),
@@ -114,6 +122,15 @@ func (smr *StockMovementRepository) List(ctx context.Context, filter *repository
 			stockMovement.OwnerID = &oid
 		}
 
+		if pgStockMovement.LocationID.Valid {
+			locationID := uint32(pgStockMovement.LocationID.Int64)
+			stockMovement.LocationID = &locationID
+			stockMovement.Location = &repository.LocationShort{
+				ID:   locationID,
+				Name: pgStockMovement.LocationName.String,
+			}
+		}
+
 		if pgStockMovement.ProductName.Valid {
 			stockMovement.Product.Name = pgStockMovement.ProductName.String
 		}
@@ -0,0 +1,329 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (cr *CompanyRepository) ReturnStock(ctx context.Context, stockReturn *repository.StockReturn) (*repository.StockReturn, error) {
+	err := cr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		account, err := q.GetResellerAccountForUpdate(ctx, int64(stockReturn.ResellerID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller account not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
+		}
+
+		// returned units go back into the location they are received at
+		location, err := resolveLocation(ctx, q, stockReturn.LocationID)
+		if err != nil {
+			return err
+		}
+		stockReturn.LocationID = uint32(location.ID)
+
+		batchID := pgtype.Int8{Valid: false}
+		if stockReturn.BatchID != nil {
+			batchID = pgtype.Int8{Int64: int64(*stockReturn.BatchID), Valid: true}
+		}
+
+		// newest first unless a batch is chosen, the units the reseller received last are the
+		// ones most likely to be returned unsold
+		inventory, err := q.ListResellerBatchInventoryForReturn(ctx, generated.ListResellerBatchInventoryForReturnParams{
+			ResellerID: int64(stockReturn.ResellerID),
+			ProductID:  int64(stockReturn.ProductID),
+			BatchID:    batchID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reseller batch inventory for update: %s", err.Error())
+		}
+
+		var available int64
+		for _, item := range inventory {
+			available += item.RemainingQuantity
+		}
+
+		if available < int64(stockReturn.Quantity) {
+			if stockReturn.BatchID != nil {
+				return pkg.Errorf(pkg.INVALID_ERROR, "reseller only holds %d units of the product from the batch", available)
+			}
+			return pkg.Errorf(pkg.INVALID_ERROR, "reseller only holds %d units of the product", available)
+		}
+
+		resellerName, err := q.GetResellerNameByID(ctx, int64(stockReturn.ResellerID))
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+		}
+
+		remainingToReturn := int64(stockReturn.Quantity)
+		returnedBatches := []generated.CreateStockMovementBatchRecordParams{}
+		stockReturn.Batches = []*repository.StockReturnBatch{}
+		stockReturn.TotalValue = 0
+
+		for _, item := range inventory {
+			if remainingToReturn <= 0 {
+				break
+			}
+
+			takeQty := min(item.RemainingQuantity, remainingToReturn)
+
+			_, err = q.RemoveResellerBatchInventoryQuantity(ctx, generated.RemoveResellerBatchInventoryQuantityParams{
+				Quantity:    takeQty,
+				InventoryID: item.ID,
+			})
+			if err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove reseller batch inventory quantity: %s", err.Error())
+			}
+
+			_, err = q.AddBatchInventoryQuantity(ctx, generated.AddBatchInventoryQuantityParams{
+				BatchID:    item.SourceBatchID,
+				LocationID: location.ID,
+				ProductID:  item.ProductID,
+				Quantity:   takeQty,
+			})
+			if err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add batch inventory quantity: %s", err.Error())
+			}
+
+			// the reseller is credited what they were charged for the batch
+			unitCost := pkg.PgTypeNumericToFloat64(item.UnitCost)
+			stockReturn.TotalValue = roundCents(stockReturn.TotalValue + float64(takeQty)*unitCost)
+
+			// the units go back into company stock at the cost they left it at, not the batch's
+			// cost now, so landed costs booked since are not counted twice
+			returnedBatches = append(returnedBatches, generated.CreateStockMovementBatchRecordParams{
+				BatchID:       item.SourceBatchID,
+				BatchNumber:   item.BatchNumber,
+				Quantity:      takeQty,
+				UnitCost:      item.UnitCost,
+				BatchUnitCost: item.BatchUnitCost,
+			})
+			stockReturn.Batches = append(stockReturn.Batches, &repository.StockReturnBatch{
+				BatchID:     uint32(item.SourceBatchID),
+				BatchNumber: item.BatchNumber,
+				Quantity:    takeQty,
+				UnitCost:    unitCost,
+			})
+
+			remainingToReturn -= takeQty
+		}
+
+		if remainingToReturn > 0 {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "insufficient reseller stock")
+		}
+
+		unitPrice := pkg.Float64ToPgTypeNumeric(roundCents(stockReturn.TotalValue / float64(stockReturn.Quantity)))
+
+		// create stock movement records for reseller (OUT) and company (IN) with the batches
+		// returned so both sides can be replayed per batch
+		resellerStockMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+			ProductID:    int64(stockReturn.ProductID),
+			OwnerType:    "RESELLER",
+			OwnerID:      pgtype.Int8{Int64: int64(stockReturn.ResellerID), Valid: true},
+			MovementType: "OUT",
+			Quantity:     int64(stockReturn.Quantity),
+			UnitPrice:    unitPrice,
+			Source:       "RETURN",
+			Note:         fmt.Sprintf("%s returned products worth: %.2f (%s)", resellerName, stockReturn.TotalValue, stockReturn.Reason),
+			LocationID:   pgtype.Int8{Valid: false},
+			MovementDate: pgtype.Timestamptz{Time: stockReturn.DateReturned, Valid: true},
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+		}
+
+		companyStockMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+			ProductID:    int64(stockReturn.ProductID),
+			OwnerType:    "COMPANY",
+			OwnerID:      pgtype.Int8{Valid: false},
+			MovementType: "IN",
+			Quantity:     int64(stockReturn.Quantity),
+			UnitPrice:    unitPrice,
+			Source:       "RETURN",
+			Note:         fmt.Sprintf("Returned by: %s", resellerName),
+			LocationID:   pgtype.Int8{Int64: location.ID, Valid: true},
+			MovementDate: pgtype.Timestamptz{Time: stockReturn.DateReturned, Valid: true},
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+		}
+
+		for _, returnedBatch := range returnedBatches {
+			returnedBatch.Owner = "RESELLER"
+			returnedBatch.StockMovementID = resellerStockMovement.ID
+			if _, err = q.CreateStockMovementBatchRecord(ctx, returnedBatch); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
+			}
+
+			returnedBatch.Owner = "COMPANY"
+			returnedBatch.StockMovementID = companyStockMovement.ID
+			if _, err = q.CreateStockMovementBatchRecord(ctx, returnedBatch); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
+			}
+		}
+
+		_, err = q.SubtractResellerStockQuantity(ctx, generated.SubtractResellerStockQuantityParams{
+			ResellerID: int64(stockReturn.ResellerID),
+			ProductID:  int64(stockReturn.ProductID),
+			Quantity:   int64(stockReturn.Quantity),
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to subtract reseller stock quantity: %s", err.Error())
+		}
+
+		_, err = q.AddCompanyStock(ctx, generated.AddCompanyStockParams{
+			ProductID:  int64(stockReturn.ProductID),
+			LocationID: location.ID,
+			Quantity:   int64(stockReturn.Quantity),
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add company stock: %s", err.Error())
+		}
+
+		// update admin stats (company stock, stock_distributed, value_distributed)
+		adminstats, err := q.GetAdminStats(ctx, 1)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get admin stats: %s", err.Error())
+		}
+
+		_, err = q.UpdateAdminStats(ctx, generated.UpdateAdminStatsParams{
+			ID:                    1,
+			TotalCompanyStock:     pgtype.Int8{Int64: adminstats.TotalCompanyStock + int64(stockReturn.Quantity), Valid: true},
+			TotalStockDistributed: pgtype.Int8{Int64: adminstats.TotalStockDistributed - int64(stockReturn.Quantity), Valid: true},
+			TotalValueDistributed: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(adminstats.TotalValueDistributed) - stockReturn.TotalValue),
+			TotalPaymentsReceived: pgtype.Numeric{Valid: false},
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update admin stats: %s", err.Error())
+		}
+
+		// update reseller account (stock_received, value_received, balance)
+		_, err = q.UpdateResellerAccount(ctx, generated.UpdateResellerAccountParams{
+			ResellerID:         int64(stockReturn.ResellerID),
+			TotalStockReceived: pgtype.Int8{Int64: account.TotalStockReceived - int64(stockReturn.Quantity), Valid: true},
+			TotalValueReceived: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(account.TotalValueReceived) - stockReturn.TotalValue),
+			TotalSalesValue:    pgtype.Numeric{Valid: false},
+			TotalPaid:          pgtype.Numeric{Valid: false},
+			TotalCogs:          pgtype.Numeric{Valid: false},
+			Balance:            pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(account.Balance) - stockReturn.TotalValue),
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update reseller account: %s", err.Error())
+		}
+
+		pgStockReturn, err := q.CreateStockReturn(ctx, generated.CreateStockReturnParams{
+			ResellerID:         int64(stockReturn.ResellerID),
+			ProductID:          int64(stockReturn.ProductID),
+			BatchID:            batchID,
+			Quantity:           stockReturn.Quantity,
+			TotalValue:         pkg.Float64ToPgTypeNumeric(stockReturn.TotalValue),
+			Reason:             stockReturn.Reason,
+			ResellerMovementID: resellerStockMovement.ID,
+			CompanyMovementID:  companyStockMovement.ID,
+			DateReturned:       stockReturn.DateReturned,
+			CreatedBy:          int64(stockReturn.CreatedBy),
+			LocationID:         location.ID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock return: %s", err.Error())
+		}
+
+		stockReturn.ID = uint32(pgStockReturn.ID)
+		stockReturn.CreatedAt = pgStockReturn.CreatedAt
+
+		// the credit settles open invoices like a payment would
+		if _, err := allocateCredit(ctx, q, int64(stockReturn.ResellerID)); err != nil {
+			return err
+		}
+
+		// create alert
+		if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+			Type:        "STOCK_RETURNED",
+			Title:       "Stock returned",
+			Description: fmt.Sprintf("From %s - %d units", resellerName, stockReturn.Quantity),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stockReturn, nil
+}
+
+func (cr *CompanyRepository) ListStockReturns(ctx context.Context, filter *repository.StockReturnFilter) ([]*repository.StockReturn, *pkg.Pagination, error) {
+	listParams := generated.ListStockReturnsParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+	}
+
+	countParams := generated.ListStockReturnsCountParams{
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+	}
+
+	if filter.ResellerID != nil {
+		listParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+		countParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	if filter.ProductID != nil {
+		listParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+		countParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	pgReturns, err := cr.queries.ListStockReturns(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stock returns: %s", err.Error())
+	}
+
+	totalCount, err := cr.queries.ListStockReturnsCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count stock returns: %s", err.Error())
+	}
+
+	returns := make([]*repository.StockReturn, len(pgReturns))
+	for i, pgReturn := range pgReturns {
+		returns[i] = &repository.StockReturn{
+			ID:           uint32(pgReturn.ID),
+			ResellerID:   uint32(pgReturn.ResellerID),
+			ProductID:    uint32(pgReturn.ProductID),
+			Quantity:     pgReturn.Quantity,
+			TotalValue:   pkg.PgTypeNumericToFloat64(pgReturn.TotalValue),
+			Reason:       pgReturn.Reason,
+			LocationID:   uint32(pgReturn.LocationID),
+			DateReturned: pgReturn.DateReturned,
+			CreatedBy:    uint32(pgReturn.CreatedBy),
+			CreatedAt:    pgReturn.CreatedAt,
+			Product: &repository.ProductShort{
+				ID:   uint32(pgReturn.ProductID),
+				Name: pgReturn.ProductName,
+				Unit: pgReturn.ProductUnit,
+			},
+			User: &repository.UserShort{
+				ID:          uint32(pgReturn.ResellerID),
+				Name:        pgReturn.ResellerName,
+				PhoneNumber: pgReturn.ResellerPhoneNumber,
+			},
+		}
+
+		if pgReturn.BatchID.Valid {
+			id := uint32(pgReturn.BatchID.Int64)
+			returns[i].BatchID = &id
+		}
+	}
+
+	return returns, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
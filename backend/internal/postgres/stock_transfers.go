@@ -0,0 +1,575 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (rr *ResellerRepository) TransferStock(ctx context.Context, transfer *repository.StockTransfer, requireApproval bool) (*repository.StockTransfer, error) {
+	if transfer.FromResellerID == transfer.ToResellerID {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "cannot transfer stock to the same reseller")
+	}
+
+	var result *repository.StockTransfer
+
+	err := rr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		batchID := pgtype.Int8{Valid: false}
+		if transfer.BatchID != nil {
+			batchID = pgtype.Int8{Int64: int64(*transfer.BatchID), Valid: true}
+		}
+
+		pgTransfer, err := q.CreateStockTransfer(ctx, generated.CreateStockTransferParams{
+			FromResellerID: int64(transfer.FromResellerID),
+			ToResellerID:   int64(transfer.ToResellerID),
+			ProductID:      int64(transfer.ProductID),
+			BatchID:        batchID,
+			Quantity:       transfer.Quantity,
+			Note:           pgtype.Text{String: transfer.Note, Valid: transfer.Note != ""},
+			RequestedBy:    int64(transfer.RequestedBy),
+		})
+		if err != nil {
+			if pkg.PgxErrorCode(err) == pkg.FOREIGN_KEY_VIOLATION {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller, product or batch not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock transfer: %s", err.Error())
+		}
+
+		if !requireApproval {
+			result, err = completeStockTransfer(ctx, q, rr.db.config.INVOICE_DUE_DAYS, pgTransfer, transfer.RequestedBy)
+			return err
+		}
+
+		if _, err := q.GetResellerAccount(ctx, pgTransfer.ToResellerID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller account not found")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
+		}
+
+		// the stock is only moved on approval, but a request the sender cannot cover is
+		// turned away straight away
+		layers, err := q.ListResellerBatchInventoryForAdjustment(ctx, generated.ListResellerBatchInventoryForAdjustmentParams{
+			ResellerID: pgTransfer.FromResellerID,
+			ProductID:  pgTransfer.ProductID,
+			BatchID:    pgTransfer.BatchID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reseller batch inventory: %s", err.Error())
+		}
+
+		if err := checkTransferAvailable(layers, pgTransfer); err != nil {
+			return err
+		}
+
+		fromName, err := q.GetResellerNameByID(ctx, pgTransfer.FromResellerID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+		}
+
+		if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+			Type:        "STOCK_TRANSFER_REQUESTED",
+			Title:       "Stock transfer requested",
+			Description: fmt.Sprintf("From %s - %d units", fromName, pgTransfer.Quantity),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+		}
+
+		result = newStockTransfer(pgTransfer)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (rr *ResellerRepository) ApproveStockTransfer(ctx context.Context, id uint32, approvedBy uint32) (*repository.StockTransfer, error) {
+	return rr.completePendingStockTransfer(ctx, id, approvedBy, nil)
+}
+
+func (rr *ResellerRepository) RejectStockTransfer(ctx context.Context, id uint32, rejectedBy uint32, reason string) (*repository.StockTransfer, error) {
+	return rr.rejectPendingStockTransfer(ctx, id, rejectedBy, reason, nil)
+}
+
+func (rr *ResellerRepository) AcceptStockTransfer(ctx context.Context, id uint32, resellerID uint32) (*repository.StockTransfer, error) {
+	return rr.completePendingStockTransfer(ctx, id, resellerID, &resellerID)
+}
+
+func (rr *ResellerRepository) DeclineStockTransfer(ctx context.Context, id uint32, resellerID uint32, reason string) (*repository.StockTransfer, error) {
+	return rr.rejectPendingStockTransfer(ctx, id, resellerID, reason, &resellerID)
+}
+
+// completePendingStockTransfer completes a pending transfer, when toResellerID is set only a
+// transfer sent to that reseller is found.
+func (rr *ResellerRepository) completePendingStockTransfer(ctx context.Context, id uint32, decidedBy uint32, toResellerID *uint32) (*repository.StockTransfer, error) {
+	var result *repository.StockTransfer
+
+	err := rr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgTransfer, err := getPendingStockTransfer(ctx, q, id, toResellerID)
+		if err != nil {
+			return err
+		}
+
+		result, err = completeStockTransfer(ctx, q, rr.db.config.INVOICE_DUE_DAYS, pgTransfer, decidedBy)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (rr *ResellerRepository) rejectPendingStockTransfer(ctx context.Context, id uint32, decidedBy uint32, reason string, toResellerID *uint32) (*repository.StockTransfer, error) {
+	var result *repository.StockTransfer
+
+	err := rr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgTransfer, err := getPendingStockTransfer(ctx, q, id, toResellerID)
+		if err != nil {
+			return err
+		}
+
+		rejected, err := q.RejectStockTransfer(ctx, generated.RejectStockTransferParams{
+			RejectionReason: pgtype.Text{String: reason, Valid: true},
+			DecidedBy:       pgtype.Int8{Int64: int64(decidedBy), Valid: true},
+			ID:              pgTransfer.ID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to reject stock transfer: %s", err.Error())
+		}
+
+		result = newStockTransfer(rejected)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (rr *ResellerRepository) ListStockTransfers(ctx context.Context, filter *repository.StockTransferFilter) ([]*repository.StockTransfer, *pkg.Pagination, error) {
+	listParams := generated.ListStockTransfersParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+	}
+
+	countParams := generated.ListStockTransfersCountParams{
+		ResellerID: pgtype.Int8{Valid: false},
+		ProductID:  pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+	}
+
+	if filter.ResellerID != nil {
+		listParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+		countParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	if filter.ProductID != nil {
+		listParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+		countParams.ProductID = pgtype.Int8{Int64: int64(*filter.ProductID), Valid: true}
+	}
+
+	if filter.Status != nil {
+		listParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+		countParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+	}
+
+	pgTransfers, err := rr.queries.ListStockTransfers(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stock transfers: %s", err.Error())
+	}
+
+	totalCount, err := rr.queries.ListStockTransfersCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count stock transfers: %s", err.Error())
+	}
+
+	transfers := make([]*repository.StockTransfer, len(pgTransfers))
+	for i, pgTransfer := range pgTransfers {
+		transfers[i] = newStockTransfer(generated.StockTransfer{
+			ID:              pgTransfer.ID,
+			FromResellerID:  pgTransfer.FromResellerID,
+			ToResellerID:    pgTransfer.ToResellerID,
+			ProductID:       pgTransfer.ProductID,
+			BatchID:         pgTransfer.BatchID,
+			Quantity:        pgTransfer.Quantity,
+			TotalValue:      pgTransfer.TotalValue,
+			Status:          pgTransfer.Status,
+			Note:            pgTransfer.Note,
+			RejectionReason: pgTransfer.RejectionReason,
+			FromMovementID:  pgTransfer.FromMovementID,
+			ToMovementID:    pgTransfer.ToMovementID,
+			InvoiceID:       pgTransfer.InvoiceID,
+			RequestedBy:     pgTransfer.RequestedBy,
+			DecidedBy:       pgTransfer.DecidedBy,
+			DecidedAt:       pgTransfer.DecidedAt,
+			DateTransferred: pgTransfer.DateTransferred,
+			CreatedAt:       pgTransfer.CreatedAt,
+		})
+		transfers[i].Product = &repository.ProductShort{
+			ID:   uint32(pgTransfer.ProductID),
+			Name: pgTransfer.ProductName,
+			Unit: pgTransfer.ProductUnit,
+		}
+		transfers[i].FromReseller = &repository.UserShort{
+			ID:          uint32(pgTransfer.FromResellerID),
+			Name:        pgTransfer.FromResellerName,
+			PhoneNumber: pgTransfer.FromResellerPhoneNumber,
+		}
+		transfers[i].ToReseller = &repository.UserShort{
+			ID:          uint32(pgTransfer.ToResellerID),
+			Name:        pgTransfer.ToResellerName,
+			PhoneNumber: pgTransfer.ToResellerPhoneNumber,
+		}
+	}
+
+	return transfers, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+// completeStockTransfer moves the sender's batch layers to the receiver at the same unit cost
+// using the caller's transaction. The receiver is invoiced for the stock and the sender's
+// credit settles their open invoices.
+func completeStockTransfer(ctx context.Context, q *generated.Queries, dueDays int, pgTransfer generated.StockTransfer, decidedBy uint32) (*repository.StockTransfer, error) {
+	// both accounts are locked lowest id first so two transfers between the same resellers
+	// going opposite ways cannot deadlock
+	accounts := make(map[int64]generated.ResellerAccount, 2)
+	for _, resellerID := range []int64{min(pgTransfer.FromResellerID, pgTransfer.ToResellerID), max(pgTransfer.FromResellerID, pgTransfer.ToResellerID)} {
+		account, err := q.GetResellerAccountForUpdate(ctx, resellerID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller account not found")
+			}
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller account: %s", err.Error())
+		}
+		accounts[resellerID] = account
+	}
+
+	fromName, err := q.GetResellerNameByID(ctx, pgTransfer.FromResellerID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+	}
+
+	toName, err := q.GetResellerNameByID(ctx, pgTransfer.ToResellerID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+	}
+
+	// the oldest stock the sender holds goes first unless a batch is chosen
+	layers, err := q.ListResellerBatchInventoryForAdjustment(ctx, generated.ListResellerBatchInventoryForAdjustmentParams{
+		ResellerID: pgTransfer.FromResellerID,
+		ProductID:  pgTransfer.ProductID,
+		BatchID:    pgTransfer.BatchID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reseller batch inventory for update: %s", err.Error())
+	}
+
+	if err := checkTransferAvailable(layers, pgTransfer); err != nil {
+		return nil, err
+	}
+
+	remainingToTransfer := int64(pgTransfer.Quantity)
+	transferredBatches := []generated.CreateStockMovementBatchRecordParams{}
+	batches := []*repository.StockTransferBatch{}
+	var totalValue float64
+
+	for _, layer := range layers {
+		if remainingToTransfer <= 0 {
+			break
+		}
+
+		takeQty := min(layer.RemainingQuantity, remainingToTransfer)
+		if takeQty == 0 {
+			continue
+		}
+
+		if _, err := q.RemoveResellerBatchInventoryQuantity(ctx, generated.RemoveResellerBatchInventoryQuantityParams{
+			Quantity:    takeQty,
+			InventoryID: layer.ID,
+		}); err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to remove reseller batch inventory quantity: %s", err.Error())
+		}
+
+		// the layer keeps its batch and the unit cost the sender was charged
+		if _, err := q.CreateResellerBatchInventoryRecord(ctx, generated.CreateResellerBatchInventoryRecordParams{
+			ResellerID:        pgTransfer.ToResellerID,
+			ProductID:         pgTransfer.ProductID,
+			SourceBatchID:     layer.SourceBatchID,
+			BatchNumber:       layer.BatchNumber,
+			RemainingQuantity: takeQty,
+			UnitCost:          layer.UnitCost,
+			BatchUnitCost:     layer.BatchUnitCost,
+		}); err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create reseller batch inventory record: %s", err.Error())
+		}
+
+		unitCost := pkg.PgTypeNumericToFloat64(layer.UnitCost)
+		totalValue = roundCents(totalValue + float64(takeQty)*unitCost)
+
+		transferredBatches = append(transferredBatches, generated.CreateStockMovementBatchRecordParams{
+			Owner:         "RESELLER",
+			BatchID:       layer.SourceBatchID,
+			BatchNumber:   layer.BatchNumber,
+			Quantity:      takeQty,
+			UnitCost:      layer.UnitCost,
+			BatchUnitCost: layer.BatchUnitCost,
+		})
+		batches = append(batches, &repository.StockTransferBatch{
+			BatchID:     uint32(layer.SourceBatchID),
+			BatchNumber: layer.BatchNumber,
+			Quantity:    takeQty,
+			UnitCost:    unitCost,
+		})
+
+		remainingToTransfer -= takeQty
+	}
+
+	if remainingToTransfer > 0 {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "insufficient reseller stock")
+	}
+
+	// the receiver is invoiced like a distribution so the same credit limit applies. There
+	// is no hold to park a transfer on, so it cannot complete until the limit is raised or
+	// the balance comes down
+	receiver := accounts[pgTransfer.ToResellerID]
+	if receiver.CreditLimit.Valid {
+		balance := pkg.PgTypeNumericToFloat64(receiver.Balance)
+		creditLimit := pkg.PgTypeNumericToFloat64(receiver.CreditLimit)
+		if roundCents(balance+totalValue) > creditLimit {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "transfer of %.2f would take the receiver's balance to %.2f, past the credit limit of %.2f", totalValue, roundCents(balance+totalValue), creditLimit)
+		}
+	}
+
+	unitPrice := pkg.Float64ToPgTypeNumeric(roundCents(totalValue / float64(pgTransfer.Quantity)))
+
+	// paired movements for the sender (OUT) and receiver (IN) with the batches moved so both
+	// sides can be replayed per batch
+	fromMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+		ProductID:    pgTransfer.ProductID,
+		OwnerType:    "RESELLER",
+		OwnerID:      pgtype.Int8{Int64: pgTransfer.FromResellerID, Valid: true},
+		MovementType: "OUT",
+		Quantity:     int64(pgTransfer.Quantity),
+		UnitPrice:    unitPrice,
+		Source:       "TRANSFER",
+		Note:         fmt.Sprintf("Transferred to: %s", toName),
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+	}
+
+	toMovement, err := q.CreateStockMovementRecord(ctx, generated.CreateStockMovementRecordParams{
+		ProductID:    pgTransfer.ProductID,
+		OwnerType:    "RESELLER",
+		OwnerID:      pgtype.Int8{Int64: pgTransfer.ToResellerID, Valid: true},
+		MovementType: "IN",
+		Quantity:     int64(pgTransfer.Quantity),
+		UnitPrice:    unitPrice,
+		Source:       "TRANSFER",
+		Note:         fmt.Sprintf("Transferred from: %s", fromName),
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement record: %s", err.Error())
+	}
+
+	for _, transferredBatch := range transferredBatches {
+		for _, movementID := range []int64{fromMovement.ID, toMovement.ID} {
+			transferredBatch.StockMovementID = movementID
+			if _, err := q.CreateStockMovementBatchRecord(ctx, transferredBatch); err != nil {
+				return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stock movement batch record: %s", err.Error())
+			}
+		}
+	}
+
+	if _, err := q.SubtractResellerStockQuantity(ctx, generated.SubtractResellerStockQuantityParams{
+		ResellerID: pgTransfer.FromResellerID,
+		ProductID:  pgTransfer.ProductID,
+		Quantity:   int64(pgTransfer.Quantity),
+	}); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to subtract reseller stock quantity: %s", err.Error())
+	}
+
+	resellerStockExists, err := q.CheckResellerStockExists(ctx, generated.CheckResellerStockExistsParams{
+		ProductID:  pgTransfer.ProductID,
+		ResellerID: pgTransfer.ToResellerID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to check reseller stock exists: %s", err.Error())
+	}
+
+	if !resellerStockExists {
+		_, err = q.CreateResellerStock(ctx, generated.CreateResellerStockParams{
+			ResellerID: pgTransfer.ToResellerID,
+			ProductID:  pgTransfer.ProductID,
+			Quantity:   int64(pgTransfer.Quantity),
+		})
+	} else {
+		_, err = q.AddResellerStockQuantity(ctx, generated.AddResellerStockQuantityParams{
+			ResellerID: pgTransfer.ToResellerID,
+			ProductID:  pgTransfer.ProductID,
+			Quantity:   int64(pgTransfer.Quantity),
+		})
+	}
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add reseller stock quantity: %s", err.Error())
+	}
+
+	// the sender's account drops by what they were charged for the stock and the receiver's
+	// goes up by the same
+	for _, side := range []struct {
+		resellerID int64
+		sign       int64
+	}{
+		{pgTransfer.FromResellerID, -1},
+		{pgTransfer.ToResellerID, 1},
+	} {
+		resellerID, sign := side.resellerID, side.sign
+		account := accounts[resellerID]
+		value := float64(sign) * totalValue
+
+		if _, err := q.UpdateResellerAccount(ctx, generated.UpdateResellerAccountParams{
+			ResellerID:         resellerID,
+			TotalStockReceived: pgtype.Int8{Int64: account.TotalStockReceived + sign*int64(pgTransfer.Quantity), Valid: true},
+			TotalValueReceived: pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(account.TotalValueReceived) + value),
+			TotalSalesValue:    pgtype.Numeric{Valid: false},
+			TotalPaid:          pgtype.Numeric{Valid: false},
+			TotalCogs:          pgtype.Numeric{Valid: false},
+			Balance:            pkg.Float64ToPgTypeNumeric(pkg.PgTypeNumericToFloat64(account.Balance) + value),
+		}); err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update reseller account: %s", err.Error())
+		}
+	}
+
+	invoiceID := pgtype.Int8{Valid: false}
+	if totalValue > 0 {
+		id, err := createInvoice(ctx, q, dueDays, pgTransfer.ToResellerID, nil, totalValue, time.Now(), nil)
+		if err != nil {
+			return nil, err
+		}
+		invoiceID = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	completed, err := q.CompleteStockTransfer(ctx, generated.CompleteStockTransferParams{
+		TotalValue:     pkg.Float64ToPgTypeNumeric(totalValue),
+		FromMovementID: pgtype.Int8{Int64: fromMovement.ID, Valid: true},
+		ToMovementID:   pgtype.Int8{Int64: toMovement.ID, Valid: true},
+		InvoiceID:      invoiceID,
+		DecidedBy:      pgtype.Int8{Int64: int64(decidedBy), Valid: true},
+		ID:             pgTransfer.ID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to complete stock transfer: %s", err.Error())
+	}
+
+	// the credit settles the sender's open invoices like a return would
+	if _, err := allocateCredit(ctx, q, pgTransfer.FromResellerID); err != nil {
+		return nil, err
+	}
+
+	// create alert
+	if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+		Type:        "STOCK_TRANSFERRED",
+		Title:       "Stock transferred",
+		Description: fmt.Sprintf("From %s to %s - %d units", fromName, toName, pgTransfer.Quantity),
+	}); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+	}
+
+	transfer := newStockTransfer(completed)
+	transfer.Batches = batches
+
+	return transfer, nil
+}
+
+func checkTransferAvailable(layers []generated.ResellerBatchInventory, pgTransfer generated.StockTransfer) error {
+	var available int64
+	for _, layer := range layers {
+		available += layer.RemainingQuantity
+	}
+
+	if available < int64(pgTransfer.Quantity) {
+		if pgTransfer.BatchID.Valid {
+			return pkg.Errorf(pkg.INVALID_ERROR, "reseller only holds %d units of the product from the batch", available)
+		}
+		return pkg.Errorf(pkg.INVALID_ERROR, "reseller only holds %d units of the product", available)
+	}
+
+	return nil
+}
+
+func getPendingStockTransfer(ctx context.Context, q *generated.Queries, id uint32, toResellerID *uint32) (generated.StockTransfer, error) {
+	pgTransfer, err := q.GetStockTransferForUpdate(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pgTransfer, pkg.Errorf(pkg.NOT_FOUND_ERROR, "stock transfer not found")
+		}
+		return pgTransfer, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get stock transfer: %s", err.Error())
+	}
+
+	if toResellerID != nil && pgTransfer.ToResellerID != int64(*toResellerID) {
+		return pgTransfer, pkg.Errorf(pkg.NOT_FOUND_ERROR, "stock transfer not found")
+	}
+
+	if pgTransfer.Status != repository.STOCK_TRANSFER_PENDING {
+		return pgTransfer, pkg.Errorf(pkg.INVALID_ERROR, "stock transfer is already %s", pgTransfer.Status)
+	}
+
+	return pgTransfer, nil
+}
+
+func newStockTransfer(pgTransfer generated.StockTransfer) *repository.StockTransfer {
+	transfer := &repository.StockTransfer{
+		ID:              uint32(pgTransfer.ID),
+		FromResellerID:  uint32(pgTransfer.FromResellerID),
+		ToResellerID:    uint32(pgTransfer.ToResellerID),
+		ProductID:       uint32(pgTransfer.ProductID),
+		Quantity:        pgTransfer.Quantity,
+		TotalValue:      pkg.PgTypeNumericToFloat64(pgTransfer.TotalValue),
+		Status:          pgTransfer.Status,
+		Note:            pgTransfer.Note.String,
+		RejectionReason: pgTransfer.RejectionReason.String,
+		RequestedBy:     uint32(pgTransfer.RequestedBy),
+		CreatedAt:       pgTransfer.CreatedAt,
+	}
+
+	if pgTransfer.BatchID.Valid {
+		id := uint32(pgTransfer.BatchID.Int64)
+		transfer.BatchID = &id
+	}
+
+	if pgTransfer.InvoiceID.Valid {
+		id := uint32(pgTransfer.InvoiceID.Int64)
+		transfer.InvoiceID = &id
+	}
+
+	if pgTransfer.DecidedBy.Valid {
+		id := uint32(pgTransfer.DecidedBy.Int64)
+		transfer.DecidedBy = &id
+	}
+
+	if pgTransfer.DecidedAt.Valid {
+		transfer.DecidedAt = &pgTransfer.DecidedAt.Time
+	}
+
+	if pgTransfer.DateTransferred.Valid {
+		transfer.DateTransferred = &pgTransfer.DateTransferred.Time
+	}
+
+	return transfer
+}
@@ -0,0 +1,484 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/EmilioCliff/boffo/internal/postgres/generated"
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var _ repository.StocktakeRepository = (*StocktakeRepository)(nil)
+
+type StocktakeRepository struct {
+	queries *generated.Queries
+	db      *Store
+}
+
+func NewStocktakeRepository(db *Store) *StocktakeRepository {
+	return &StocktakeRepository{
+		db:      db,
+		queries: generated.New(db.pool),
+	}
+}
+
+func (sr *StocktakeRepository) StartStocktake(ctx context.Context, stocktake *repository.Stocktake) (*repository.Stocktake, error) {
+	var stocktakeID int64
+
+	err := sr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		resellerID := pgtype.Int8{Valid: false}
+		if stocktake.OwnerType == repository.STOCK_OWNER_RESELLER {
+			if stocktake.ResellerID == nil {
+				return pkg.Errorf(pkg.INVALID_ERROR, "reseller_id is required for reseller stock")
+			}
+			resellerID = pgtype.Int8{Int64: int64(*stocktake.ResellerID), Valid: true}
+
+			if _, err := q.GetResellerNameByID(ctx, resellerID.Int64); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return pkg.Errorf(pkg.NOT_FOUND_ERROR, "reseller not found")
+				}
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get reseller: %s", err.Error())
+			}
+		}
+
+		// company stock is counted one location at a time
+		locationID := pgtype.Int8{Valid: false}
+		if stocktake.OwnerType == repository.STOCK_OWNER_COMPANY {
+			var id uint32
+			if stocktake.LocationID != nil {
+				id = *stocktake.LocationID
+			}
+
+			location, err := resolveLocation(ctx, q, id)
+			if err != nil {
+				return err
+			}
+			locationID = pgtype.Int8{Int64: location.ID, Valid: true}
+		}
+
+		// movements in flight commit before the snapshot and new ones queue behind it, so every
+		// movement with an id past the snapshot's came after it
+		if err := q.LockStockMovements(ctx); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to lock stock movements: %s", err.Error())
+		}
+
+		pgStocktake, err := q.CreateStocktake(ctx, generated.CreateStocktakeParams{
+			OwnerType:  stocktake.OwnerType,
+			ResellerID: resellerID,
+			Note:       pgtype.Text{String: stocktake.Note, Valid: stocktake.Note != ""},
+			CreatedBy:  int64(stocktake.CreatedBy),
+			LocationID: locationID,
+		})
+		if err != nil {
+			if pkg.PgxErrorCode(err) == pkg.UNIQUE_VIOLATION {
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "there is already an open stocktake for this stock")
+			}
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create stocktake: %s", err.Error())
+		}
+		stocktakeID = pgStocktake.ID
+
+		if resellerID.Valid {
+			err = q.CreateResellerStocktakeLines(ctx, generated.CreateResellerStocktakeLinesParams{
+				StocktakeID: stocktakeID,
+				ResellerID:  resellerID.Int64,
+			})
+		} else {
+			err = q.CreateCompanyStocktakeLines(ctx, stocktakeID)
+		}
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to snapshot stock: %s", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sr.GetStocktake(ctx, uint32(stocktakeID))
+}
+
+func (sr *StocktakeRepository) GetStocktake(ctx context.Context, id uint32) (*repository.Stocktake, error) {
+	pgStocktake, err := sr.queries.GetStocktake(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "stocktake not found")
+		}
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get stocktake: %s", err.Error())
+	}
+
+	stocktake := &repository.Stocktake{
+		ID:        uint32(pgStocktake.ID),
+		OwnerType: pgStocktake.OwnerType,
+		Status:    pgStocktake.Status,
+		Note:      pgStocktake.Note.String,
+		CreatedBy: uint32(pgStocktake.CreatedBy),
+		CreatedAt: pgStocktake.CreatedAt,
+		Lines:     []*repository.StocktakeLine{},
+	}
+	setStocktakeClosed(stocktake, pgStocktake.ClosedBy, pgStocktake.ClosedAt)
+
+	if pgStocktake.ResellerID.Valid {
+		resellerID := uint32(pgStocktake.ResellerID.Int64)
+		stocktake.ResellerID = &resellerID
+		stocktake.User = &repository.UserShort{
+			ID:          resellerID,
+			Name:        pgStocktake.ResellerName,
+			PhoneNumber: pgStocktake.ResellerPhoneNumber,
+		}
+	}
+
+	if pgStocktake.LocationID.Valid {
+		locationID := uint32(pgStocktake.LocationID.Int64)
+		stocktake.LocationID = &locationID
+		stocktake.Location = &repository.LocationShort{
+			ID:   locationID,
+			Name: pgStocktake.LocationName,
+		}
+	}
+
+	pgLines, err := sr.queries.ListStocktakeLines(ctx, pgStocktake.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stocktake lines: %s", err.Error())
+	}
+
+	for _, pgLine := range pgLines {
+		line := &repository.StocktakeLine{
+			ID:               uint32(pgLine.ID),
+			ProductID:        uint32(pgLine.ProductID),
+			ExpectedQuantity: pgLine.ExpectedQuantity,
+			MovedQuantity:    pgLine.MovedQuantity,
+			UnitCost:         pkg.PgTypeNumericToFloat64(pgLine.UnitCost),
+			Product: &repository.ProductShort{
+				ID:   uint32(pgLine.ProductID),
+				Name: pgLine.ProductName,
+				Unit: pgLine.ProductUnit,
+			},
+		}
+
+		stocktake.TotalLines++
+		if pgLine.CountedQuantity.Valid {
+			counted := pgLine.CountedQuantity.Int64
+			line.CountedQuantity = &counted
+			line.CountedAt = &pgLine.CountedAt.Time
+			line.VarianceQuantity = counted - line.ExpectedQuantity - line.MovedQuantity
+			line.VarianceValue = roundCents(float64(line.VarianceQuantity) * line.UnitCost)
+
+			stocktake.CountedLines++
+			stocktake.VarianceQuantity += line.VarianceQuantity
+			stocktake.VarianceValue = roundCents(stocktake.VarianceValue + line.VarianceValue)
+		}
+
+		if pgLine.AdjustmentID.Valid {
+			adjustmentID := uint32(pgLine.AdjustmentID.Int64)
+			postedValue := pkg.PgTypeNumericToFloat64(pgLine.PostedValue)
+			line.AdjustmentID = &adjustmentID
+			line.PostedValue = &postedValue
+		}
+
+		stocktake.Lines = append(stocktake.Lines, line)
+	}
+
+	return stocktake, nil
+}
+
+func (sr *StocktakeRepository) ListStocktakes(ctx context.Context, filter *repository.StocktakeFilter) ([]*repository.Stocktake, *pkg.Pagination, error) {
+	listParams := generated.ListStocktakesParams{
+		Limit:      int32(filter.Pagination.PageSize),
+		Offset:     pkg.Offset(filter.Pagination.Page, filter.Pagination.PageSize),
+		OwnerType:  pgtype.Text{Valid: false},
+		ResellerID: pgtype.Int8{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+	}
+
+	countParams := generated.ListStocktakesCountParams{
+		OwnerType:  pgtype.Text{Valid: false},
+		ResellerID: pgtype.Int8{Valid: false},
+		LocationID: pgtype.Int8{Valid: false},
+		Status:     pgtype.Text{Valid: false},
+	}
+
+	if filter.OwnerType != nil {
+		listParams.OwnerType = pgtype.Text{String: *filter.OwnerType, Valid: true}
+		countParams.OwnerType = pgtype.Text{String: *filter.OwnerType, Valid: true}
+	}
+
+	if filter.ResellerID != nil {
+		listParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+		countParams.ResellerID = pgtype.Int8{Int64: int64(*filter.ResellerID), Valid: true}
+	}
+
+	if filter.LocationID != nil {
+		listParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+		countParams.LocationID = pgtype.Int8{Int64: int64(*filter.LocationID), Valid: true}
+	}
+
+	if filter.Status != nil {
+		listParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+		countParams.Status = pgtype.Text{String: *filter.Status, Valid: true}
+	}
+
+	pgStocktakes, err := sr.queries.ListStocktakes(ctx, listParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stocktakes: %s", err.Error())
+	}
+
+	totalCount, err := sr.queries.ListStocktakesCount(ctx, countParams)
+	if err != nil {
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count stocktakes: %s", err.Error())
+	}
+
+	stocktakes := make([]*repository.Stocktake, len(pgStocktakes))
+	for i, pgStocktake := range pgStocktakes {
+		stocktakes[i] = &repository.Stocktake{
+			ID:               uint32(pgStocktake.ID),
+			OwnerType:        pgStocktake.OwnerType,
+			Status:           pgStocktake.Status,
+			Note:             pgStocktake.Note.String,
+			CreatedBy:        uint32(pgStocktake.CreatedBy),
+			CreatedAt:        pgStocktake.CreatedAt,
+			TotalLines:       pgStocktake.TotalLines,
+			CountedLines:     pgStocktake.CountedLines,
+			VarianceQuantity: pgStocktake.VarianceQuantity,
+			VarianceValue:    roundCents(pkg.PgTypeNumericToFloat64(pgStocktake.VarianceValue)),
+		}
+		setStocktakeClosed(stocktakes[i], pgStocktake.ClosedBy, pgStocktake.ClosedAt)
+
+		if pgStocktake.ResellerID.Valid {
+			resellerID := uint32(pgStocktake.ResellerID.Int64)
+			stocktakes[i].ResellerID = &resellerID
+			stocktakes[i].User = &repository.UserShort{
+				ID:          resellerID,
+				Name:        pgStocktake.ResellerName,
+				PhoneNumber: pgStocktake.ResellerPhoneNumber,
+			}
+		}
+
+		if pgStocktake.LocationID.Valid {
+			locationID := uint32(pgStocktake.LocationID.Int64)
+			stocktakes[i].LocationID = &locationID
+			stocktakes[i].Location = &repository.LocationShort{
+				ID:   locationID,
+				Name: pgStocktake.LocationName,
+			}
+		}
+	}
+
+	return stocktakes, pkg.CalculatePagination(uint32(totalCount), filter.Pagination.PageSize, filter.Pagination.Page), nil
+}
+
+func (sr *StocktakeRepository) RecordStocktakeCounts(ctx context.Context, id uint32, counts []*repository.StocktakeCount) (*repository.Stocktake, error) {
+	if len(counts) == 0 {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "no counts were given")
+	}
+
+	err := sr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		if _, err := getOpenStocktake(ctx, q, id); err != nil {
+			return err
+		}
+
+		for _, count := range counts {
+			updated, err := q.RecordStocktakeCount(ctx, generated.RecordStocktakeCountParams{
+				CountedQuantity: pgtype.Int8{Int64: count.CountedQuantity, Valid: true},
+				StocktakeID:     int64(id),
+				ProductID:       int64(count.ProductID),
+			})
+			if err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to record stocktake count: %s", err.Error())
+			}
+
+			if updated == 0 {
+				return pkg.Errorf(pkg.INVALID_ERROR, "product %d is not part of the stocktake", count.ProductID)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sr.GetStocktake(ctx, id)
+}
+
+func (sr *StocktakeRepository) PostStocktake(ctx context.Context, id uint32, postedBy uint32) (*repository.Stocktake, error) {
+	err := sr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgStocktake, err := getOpenStocktake(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		var resellerID, locationID *uint32
+		if pgStocktake.ResellerID.Valid {
+			ownerID := uint32(pgStocktake.ResellerID.Int64)
+			resellerID = &ownerID
+		}
+		if pgStocktake.LocationID.Valid {
+			id := uint32(pgStocktake.LocationID.Int64)
+			locationID = &id
+		}
+
+		pgLines, err := q.ListStocktakeLines(ctx, pgStocktake.ID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list stocktake lines: %s", err.Error())
+		}
+
+		var (
+			adjusted int
+			value    float64
+		)
+		for _, pgLine := range pgLines {
+			if !pgLine.CountedQuantity.Valid {
+				continue
+			}
+
+			// stock moved between the snapshot and the count is already on the books, the
+			// variance is only what the count differs from it by
+			variance := pgLine.CountedQuantity.Int64 - pgLine.ExpectedQuantity - pgLine.MovedQuantity
+			if variance == 0 {
+				continue
+			}
+
+			adjustment := &repository.StockAdjustment{
+				OwnerType:  pgStocktake.OwnerType,
+				ResellerID: resellerID,
+				LocationID: locationID,
+				ProductID:  uint32(pgLine.ProductID),
+				Quantity:   int32(variance),
+				Reason:     repository.ADJUSTMENT_COUNT_CORRECTION,
+				Note:       fmt.Sprintf("stocktake #%d", pgStocktake.ID),
+				CreatedBy:  postedBy,
+			}
+
+			// units found in the count go back into the batch received last
+			if adjustment.Quantity > 0 {
+				batchID, err := stocktakeCorrectionBatch(ctx, q, pgStocktake, pgLine.ProductID)
+				if err != nil {
+					return err
+				}
+				adjustment.BatchID = &batchID
+			}
+
+			if err := adjustStock(ctx, q, adjustment); err != nil {
+				return pkg.Errorf(pkg.ErrorCode(err), "%s: %s", pgLine.ProductName, pkg.ErrorMessage(err))
+			}
+
+			if err := q.SetStocktakeLineAdjustment(ctx, generated.SetStocktakeLineAdjustmentParams{
+				AdjustmentID: pgtype.Int8{Int64: int64(adjustment.ID), Valid: true},
+				ID:           pgLine.ID,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to link stocktake line to adjustment: %s", err.Error())
+			}
+
+			adjusted++
+			value = roundCents(value + adjustment.TotalValue)
+		}
+
+		if err := q.CloseStocktake(ctx, generated.CloseStocktakeParams{
+			Status:   repository.STOCKTAKE_POSTED,
+			ClosedBy: pgtype.Int8{Int64: int64(postedBy), Valid: true},
+			ID:       pgStocktake.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to close stocktake: %s", err.Error())
+		}
+
+		// create alert
+		if err = q.CreateAlert(ctx, generated.CreateAlertParams{
+			Type:        "STOCKTAKE_POSTED",
+			Title:       "Stocktake posted",
+			Description: fmt.Sprintf("Stocktake #%d adjusted %d products by %.2f", pgStocktake.ID, adjusted, value),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create alert: %s", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sr.GetStocktake(ctx, id)
+}
+
+func (sr *StocktakeRepository) CancelStocktake(ctx context.Context, id uint32, cancelledBy uint32) (*repository.Stocktake, error) {
+	err := sr.db.ExecTx(ctx, func(q *generated.Queries) error {
+		pgStocktake, err := getOpenStocktake(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		if err := q.CloseStocktake(ctx, generated.CloseStocktakeParams{
+			Status:   repository.STOCKTAKE_CANCELLED,
+			ClosedBy: pgtype.Int8{Int64: int64(cancelledBy), Valid: true},
+			ID:       pgStocktake.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to close stocktake: %s", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sr.GetStocktake(ctx, id)
+}
+
+// getOpenStocktake locks the stocktake so counts can't be recorded while it is being posted.
+func getOpenStocktake(ctx context.Context, q *generated.Queries, id uint32) (generated.Stocktake, error) {
+	pgStocktake, err := q.GetStocktakeForUpdate(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.Stocktake{}, pkg.Errorf(pkg.NOT_FOUND_ERROR, "stocktake not found")
+		}
+		return generated.Stocktake{}, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get stocktake: %s", err.Error())
+	}
+
+	if pgStocktake.Status != repository.STOCKTAKE_OPEN {
+		return generated.Stocktake{}, pkg.Errorf(pkg.INVALID_ERROR, "stocktake is already %s", pgStocktake.Status)
+	}
+
+	return pgStocktake, nil
+}
+
+func stocktakeCorrectionBatch(ctx context.Context, q *generated.Queries, pgStocktake generated.Stocktake, productID int64) (uint32, error) {
+	var (
+		batchID int64
+		err     error
+	)
+	if pgStocktake.ResellerID.Valid {
+		batchID, err = q.GetLatestResellerBatchID(ctx, generated.GetLatestResellerBatchIDParams{
+			ResellerID: pgStocktake.ResellerID.Int64,
+			ProductID:  productID,
+		})
+	} else {
+		batchID, err = q.GetLatestCompanyBatchID(ctx, generated.GetLatestCompanyBatchIDParams{
+			LocationID: pgStocktake.LocationID.Int64,
+			ProductID:  productID,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, pkg.Errorf(pkg.INVALID_ERROR, "no batch of the product has been received to add the counted units to")
+		}
+		return 0, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get latest batch: %s", err.Error())
+	}
+
+	return uint32(batchID), nil
+}
+
+func setStocktakeClosed(stocktake *repository.Stocktake, closedBy pgtype.Int8, closedAt pgtype.Timestamptz) {
+	if closedBy.Valid {
+		id := uint32(closedBy.Int64)
+		stocktake.ClosedBy = &id
+	}
+
+	if closedAt.Valid {
+		stocktake.ClosedAt = &closedAt.Time
+	}
+}
@@ -0,0 +1,11 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) Analytics(ctx context.Context, filter *repository.AnalyticsFilter) (*repository.Analytics, error) {
+	return r.store.ReportRepository.GetAnalytics(ctx, filter)
+}
@@ -0,0 +1,11 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) TraceBatch(ctx context.Context, batchNumber string) ([]*repository.BatchTrace, error) {
+	return r.store.ReportRepository.TraceBatch(ctx, batchNumber)
+}
@@ -0,0 +1,11 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) InventoryValuation(ctx context.Context, filter *repository.InventoryValuationFilter) (*repository.InventoryValuation, error) {
+	return r.store.ReportRepository.GetInventoryValuation(ctx, filter)
+}
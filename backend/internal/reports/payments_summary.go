@@ -0,0 +1,61 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) paymentsSummaryPDF(ctx context.Context, filter *repository.PaymentsSummaryFilter) ([]byte, error) {
+	summary, err := r.store.ReportRepository.GetPaymentsSummary(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := newPDFDocument(
+		"Payments Summary",
+		formatPeriod(summary.DateFrom, summary.DateTo),
+		fmt.Sprintf("Generated: %s", summary.GeneratedAt.Format(pdfDateTimeFormat)),
+	)
+
+	methods := make([]string, 0, len(summary.ByMethod))
+	for method := range summary.ByMethod {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	pairs := [][2]string{
+		{"Payments", strconv.FormatInt(summary.TotalCount, 10)},
+		{"Total received", formatAmount(summary.TotalAmount)},
+	}
+	for _, method := range methods {
+		pairs = append(pairs, [2]string{method, formatAmount(summary.ByMethod[method])})
+	}
+	writePDFSummary(pdf, pairs)
+
+	columns := []pdfColumn{
+		{Header: "Reseller", Width: 60, Align: "L"},
+		{Header: "Phone", Width: 35, Align: "L"},
+		{Header: "Method", Width: 30, Align: "L"},
+		{Header: "Payments", Width: 25, Align: "R"},
+		{Header: "Amount", Width: 40, Align: "R"},
+	}
+
+	rows := make([][]string, len(summary.Lines))
+	for i, line := range summary.Lines {
+		rows[i] = []string{
+			line.Reseller.Name,
+			line.Reseller.PhoneNumber,
+			line.Method,
+			strconv.FormatInt(line.Count, 10),
+			formatAmount(line.Amount),
+		}
+	}
+
+	writePDFTable(pdf, columns, rows)
+
+	return outputPDF(pdf)
+}
@@ -0,0 +1,117 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pdfDateFormat     = "02 Jan 2006"
+	pdfDateTimeFormat = "02 Jan 2006 15:04"
+	pdfLineHeight     = 7.0
+)
+
+type pdfColumn struct {
+	Header string
+	Width  float64
+	Align  string
+}
+
+// newPDFDocument creates an A4 document with the report title and subtitle lines already written.
+func newPDFDocument(title string, subtitles ...string) *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(10, 12, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Arial", "I", 8)
+		pdf.CellFormat(0, 8, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(0, 10, title, "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Arial", "", 10)
+	for _, subtitle := range subtitles {
+		pdf.CellFormat(0, 6, subtitle, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	return pdf
+}
+
+// writePDFSummary writes label/value pairs as a two column block.
+func writePDFSummary(pdf *gofpdf.Fpdf, pairs [][2]string) {
+	for _, pair := range pairs {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(50, pdfLineHeight, pair[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 10)
+		pdf.CellFormat(0, pdfLineHeight, pair[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+}
+
+// writePDFTable writes a table, repeating the header row whenever a new page starts.
+func writePDFTable(pdf *gofpdf.Fpdf, columns []pdfColumn, rows [][]string) {
+	writeHeader := func() {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for _, column := range columns {
+			pdf.CellFormat(column.Width, pdfLineHeight, column.Header, "1", 0, column.Align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 9)
+	}
+
+	writeHeader()
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+
+	for _, row := range rows {
+		if pdf.GetY()+pdfLineHeight > pageHeight-bottomMargin {
+			pdf.AddPage()
+			writeHeader()
+		}
+
+		for i, column := range columns {
+			pdf.CellFormat(column.Width, pdfLineHeight, fitPDFText(pdf, row[i], column.Width-2), "1", 0, column.Align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+// fitPDFText trims text that would overflow a cell of the given width.
+func fitPDFText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "..."
+}
+
+func outputPDF(pdf *gofpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to generate pdf: %s", err.Error())
+	}
+
+	return buf.Bytes(), nil
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func formatPeriod(from, to time.Time) string {
+	return fmt.Sprintf("Period: %s - %s", from.Format(pdfDateFormat), to.Format(pdfDateFormat))
+}
@@ -0,0 +1,11 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) ProfitAndLoss(ctx context.Context, filter *repository.ProfitAndLossFilter) (*repository.ProfitAndLoss, error) {
+	return r.store.ReportRepository.GetProfitAndLoss(ctx, filter)
+}
@@ -0,0 +1,11 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) PurchaseOrderFillRate(ctx context.Context, filter *repository.PurchaseOrderFillRateFilter) (*repository.PurchaseOrderFillRate, error) {
+	return r.store.ReportRepository.GetPurchaseOrderFillRate(ctx, filter)
+}
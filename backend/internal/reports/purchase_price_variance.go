@@ -0,0 +1,11 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) PurchasePriceVariance(ctx context.Context, filter *repository.PurchasePriceVarianceFilter) (*repository.PurchasePriceVariance, error) {
+	return r.store.ReportRepository.GetPurchasePriceVariance(ctx, filter)
+}
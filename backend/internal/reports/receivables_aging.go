@@ -0,0 +1,11 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) ReceivablesAging(ctx context.Context, filter *repository.ReceivablesAgingFilter) (*repository.ReceivablesAgingReport, error) {
+	return r.store.ReportRepository.GetReceivablesAging(ctx, filter)
+}
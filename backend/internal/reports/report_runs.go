@@ -0,0 +1,164 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	// failed runs are retried by the scheduler until they reach this many attempts
+	maxReportRunAttempts = 3
+
+	reportContentTypePDF = "application/pdf"
+)
+
+func (r *ReportServiceImpl) CreateReportRun(ctx context.Context, reportType string, params repository.ReportRunParams, triggeredBy string) (*repository.ReportRun, error) {
+	switch reportType {
+	case repository.REPORT_TYPE_PAYMENTS_SUMMARY:
+		if params.DateFrom == nil || params.DateTo == nil {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "date_from and date_to are required for %s", reportType)
+		}
+		if params.DateFrom.After(*params.DateTo) {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "date_from cannot be after date_to")
+		}
+	case repository.REPORT_TYPE_RESELLER_BALANCES, repository.REPORT_TYPE_STOCK_LISTING, repository.REPORT_TYPE_STOCK_AUDIT:
+	default:
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid report type: %s", reportType)
+	}
+
+	return r.store.ReportRunRepository.CreateReportRun(ctx, &repository.ReportRun{
+		ReportType:  reportType,
+		Parameters:  params,
+		TriggeredBy: triggeredBy,
+	})
+}
+
+// ExecuteReportRun generates the file for a pending or failed run and stores it. The run is
+// marked as failed when generation errors so it can be picked up again by RetryReportRuns.
+func (r *ReportServiceImpl) ExecuteReportRun(ctx context.Context, id uint32) (*repository.ReportRun, error) {
+	run, err := r.store.ReportRunRepository.ClaimReportRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.storeReport(ctx, run); err != nil {
+		if failErr := r.store.ReportRunRepository.FailReportRun(ctx, run.ID, err.Error()); failErr != nil {
+			log.Printf("failed to mark report run %d as failed: %v", run.ID, failErr)
+		}
+
+		return nil, err
+	}
+
+	return r.store.ReportRunRepository.GetReportRun(ctx, run.ID)
+}
+
+func (r *ReportServiceImpl) storeReport(ctx context.Context, run *repository.ReportRun) error {
+	data, err := r.generateReport(ctx, run)
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s-%d-%s.pdf", strings.ReplaceAll(strings.ToLower(run.ReportType), "_", "-"), run.ID, time.Now().Format("20060102-150405"))
+	filePath, err := r.storage.Save(strings.ToLower(run.ReportType), fileName, data)
+	if err != nil {
+		return err
+	}
+
+	return r.store.ReportRunRepository.CompleteReportRun(ctx, run.ID, fileName, filePath, reportContentTypePDF)
+}
+
+func (r *ReportServiceImpl) generateReport(ctx context.Context, run *repository.ReportRun) ([]byte, error) {
+	switch run.ReportType {
+	case repository.REPORT_TYPE_RESELLER_BALANCES:
+		return r.resellerBalancesPDF(ctx)
+	case repository.REPORT_TYPE_PAYMENTS_SUMMARY:
+		if run.Parameters.DateFrom == nil || run.Parameters.DateTo == nil {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "report run %d is missing its period", run.ID)
+		}
+
+		return r.paymentsSummaryPDF(ctx, &repository.PaymentsSummaryFilter{
+			DateFrom: *run.Parameters.DateFrom,
+			DateTo:   *run.Parameters.DateTo,
+		})
+	case repository.REPORT_TYPE_STOCK_LISTING:
+		// without an explicit date the listing is as of the time the run was requested,
+		// so a retry reports the same stock position as the original attempt
+		asOf := run.CreatedAt
+		if run.Parameters.AsOf != nil {
+			asOf = *run.Parameters.AsOf
+		}
+
+		return r.stockListingPDF(ctx, asOf)
+	case repository.REPORT_TYPE_STOCK_AUDIT:
+		return r.stockAuditPDF(ctx)
+	default:
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "invalid report type: %s", run.ReportType)
+	}
+}
+
+// RetryReportRuns re-executes failed runs that have attempts left and pending runs that were
+// never started, returning how many of them succeeded.
+func (r *ReportServiceImpl) RetryReportRuns(ctx context.Context) (int, error) {
+	runs, err := r.store.ReportRunRepository.ListRetryableReportRuns(ctx, maxReportRunAttempts)
+	if err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	for _, run := range runs {
+		if _, err := r.ExecuteReportRun(ctx, run.ID); err != nil {
+			log.Printf("retry of report run %d failed: %v", run.ID, err)
+			continue
+		}
+		succeeded++
+	}
+
+	return succeeded, nil
+}
+
+// RecoverReportRuns fails runs left running by a previous process so they become retryable.
+func (r *ReportServiceImpl) RecoverReportRuns(ctx context.Context) error {
+	count, err := r.store.ReportRunRepository.FailInterruptedReportRuns(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		log.Printf("marked %d interrupted report runs as failed", count)
+	}
+
+	return nil
+}
+
+func (r *ReportServiceImpl) GetReportRun(ctx context.Context, id uint32) (*repository.ReportRun, error) {
+	return r.store.ReportRunRepository.GetReportRun(ctx, id)
+}
+
+func (r *ReportServiceImpl) ListReportRuns(ctx context.Context, filter *repository.ReportRunFilter) ([]*repository.ReportRun, *pkg.Pagination, error) {
+	return r.store.ReportRunRepository.ListReportRuns(ctx, filter)
+}
+
+// ReportRunFile returns the run together with the absolute path of its stored file.
+func (r *ReportServiceImpl) ReportRunFile(ctx context.Context, id uint32) (*repository.ReportRun, string, error) {
+	run, err := r.store.ReportRunRepository.GetReportRun(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if run.Status != repository.REPORT_RUN_SUCCESS || run.FilePath == "" {
+		return nil, "", pkg.Errorf(pkg.NOT_FOUND_ERROR, "report run %d has no file, status is %s", run.ID, run.Status)
+	}
+
+	path, err := r.storage.Path(run.FilePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return run, path, nil
+}
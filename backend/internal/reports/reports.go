@@ -3,16 +3,19 @@ package reports
 import (
 	"github.com/EmilioCliff/boffo/internal/postgres"
 	"github.com/EmilioCliff/boffo/internal/services"
+	"github.com/EmilioCliff/boffo/pkg"
 )
 
 var _ services.ReportService = (*ReportServiceImpl)(nil)
 
-func NewReportService(store *postgres.PostgresRepo) services.ReportService {
+func NewReportService(store *postgres.PostgresRepo, config pkg.Config) services.ReportService {
 	return &ReportServiceImpl{
-		store: store,
+		store:   store,
+		storage: newLocalStorage(config.REPORT_STORAGE_PATH),
 	}
 }
 
 type ReportServiceImpl struct {
-	store *postgres.PostgresRepo
+	store   *postgres.PostgresRepo
+	storage *localStorage
 }
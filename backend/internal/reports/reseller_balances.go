@@ -0,0 +1,51 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+func (r *ReportServiceImpl) resellerBalancesPDF(ctx context.Context) ([]byte, error) {
+	report, err := r.store.ReportRepository.GetResellerBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := newPDFDocument(
+		"Reseller Balances",
+		fmt.Sprintf("As of: %s", report.GeneratedAt.Format(pdfDateTimeFormat)),
+	)
+
+	writePDFSummary(pdf, [][2]string{
+		{"Resellers", strconv.Itoa(len(report.Resellers))},
+		{"Total distributed", formatAmount(report.TotalValueReceived)},
+		{"Total paid", formatAmount(report.TotalPaid)},
+		{"Total outstanding", formatAmount(report.TotalBalance)},
+	})
+
+	columns := []pdfColumn{
+		{Header: "Reseller", Width: 46, Align: "L"},
+		{Header: "Phone", Width: 28, Align: "L"},
+		{Header: "Distributed", Width: 30, Align: "R"},
+		{Header: "Paid", Width: 30, Align: "R"},
+		{Header: "Balance", Width: 30, Align: "R"},
+		{Header: "Days overdue", Width: 26, Align: "R"},
+	}
+
+	rows := make([][]string, len(report.Resellers))
+	for i, reseller := range report.Resellers {
+		rows[i] = []string{
+			reseller.Reseller.Name,
+			reseller.Reseller.PhoneNumber,
+			formatAmount(reseller.TotalValueReceived),
+			formatAmount(reseller.TotalPaid),
+			formatAmount(reseller.Balance),
+			strconv.FormatInt(reseller.DaysOverdue, 10),
+		}
+	}
+
+	writePDFTable(pdf, columns, rows)
+
+	return outputPDF(pdf)
+}
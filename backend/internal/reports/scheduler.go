@@ -0,0 +1,120 @@
+package reports
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/internal/services"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// 01:00 on the first of every month
+	monthlyReportSchedule = "0 1 1 * *"
+	// 01:00 every monday
+	weeklyReportSchedule = "0 1 * * 1"
+	// 02:00 every day
+	dailyReportSchedule = "0 2 * * *"
+	retryReportSchedule = "@every 15m"
+)
+
+// Scheduler runs report generation inside the server process.
+type Scheduler struct {
+	report services.ReportService
+	cron   *cron.Cron
+}
+
+func NewScheduler(report services.ReportService) *Scheduler {
+	return &Scheduler{
+		report: report,
+		cron:   cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger))),
+	}
+}
+
+func (s *Scheduler) Start() error {
+	if err := s.report.RecoverReportRuns(context.Background()); err != nil {
+		return err
+	}
+
+	jobs := []struct {
+		schedule string
+		job      func()
+	}{
+		{monthlyReportSchedule, s.monthlyReports},
+		{weeklyReportSchedule, s.weeklyReports},
+		{dailyReportSchedule, s.dailyReports},
+		{retryReportSchedule, s.retryReports},
+	}
+
+	for _, job := range jobs {
+		if _, err := s.cron.AddFunc(job.schedule, job.job); err != nil {
+			return err
+		}
+	}
+
+	s.cron.Start()
+
+	return nil
+}
+
+// Stop stops scheduling new jobs and waits for running ones until ctx is done. Runs cut
+// short are recovered and retried on the next start.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	log.Println("Shutting down report scheduler...")
+
+	select {
+	case <-s.cron.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) monthlyReports() {
+	s.generate(repository.REPORT_TYPE_RESELLER_BALANCES, repository.ReportRunParams{})
+
+	// stock position at the close of the previous month
+	now := time.Now()
+	asOf := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Add(-time.Nanosecond)
+	s.generate(repository.REPORT_TYPE_STOCK_LISTING, repository.ReportRunParams{AsOf: &asOf})
+}
+
+func (s *Scheduler) weeklyReports() {
+	// the previous monday to sunday
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	dateFrom := weekStart.AddDate(0, 0, -7)
+	dateTo := weekStart.AddDate(0, 0, -1)
+
+	s.generate(repository.REPORT_TYPE_PAYMENTS_SUMMARY, repository.ReportRunParams{
+		DateFrom: &dateFrom,
+		DateTo:   &dateTo,
+	})
+}
+
+func (s *Scheduler) dailyReports() {
+	s.generate(repository.REPORT_TYPE_STOCK_AUDIT, repository.ReportRunParams{})
+}
+
+func (s *Scheduler) retryReports() {
+	if _, err := s.report.RetryReportRuns(context.Background()); err != nil {
+		log.Printf("failed to retry report runs: %v", err)
+	}
+}
+
+func (s *Scheduler) generate(reportType string, params repository.ReportRunParams) {
+	ctx := context.Background()
+
+	run, err := s.report.CreateReportRun(ctx, reportType, params, repository.REPORT_TRIGGER_SCHEDULE)
+	if err != nil {
+		log.Printf("failed to create scheduled %s report run: %v", reportType, err)
+		return
+	}
+
+	if _, err := s.report.ExecuteReportRun(ctx, run.ID); err != nil {
+		log.Printf("scheduled report run %d failed: %v", run.ID, err)
+	}
+}
@@ -0,0 +1,69 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+func (r *ReportServiceImpl) ResellerStatement(ctx context.Context, filter *repository.StatementFilter) (*repository.ResellerStatement, error) {
+	return r.store.ReportRepository.GetResellerStatement(ctx, filter)
+}
+
+func (r *ReportServiceImpl) ResellerStatementPDF(ctx context.Context, filter *repository.StatementFilter) ([]byte, error) {
+	statement, err := r.store.ReportRepository.GetResellerStatement(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := newPDFDocument(
+		"Reseller Statement",
+		fmt.Sprintf("%s - %s", statement.Reseller.Name, statement.Reseller.PhoneNumber),
+		formatPeriod(statement.DateFrom, statement.DateTo),
+		fmt.Sprintf("Generated: %s", statement.GeneratedAt.Format(pdfDateTimeFormat)),
+	)
+
+	writePDFSummary(pdf, [][2]string{
+		{"Opening balance", formatAmount(statement.OpeningBalance)},
+		{"Total distributed", formatAmount(statement.TotalDebits)},
+		{"Total paid", formatAmount(statement.TotalCredits)},
+		{"Closing balance", formatAmount(statement.ClosingBalance)},
+		{"Current account balance", formatAmount(statement.AccountBalance)},
+	})
+
+	columns := []pdfColumn{
+		{Header: "Date", Width: 25, Align: "L"},
+		{Header: "Type", Width: 28, Align: "L"},
+		{Header: "Description", Width: 64, Align: "L"},
+		{Header: "Debit", Width: 24, Align: "R"},
+		{Header: "Credit", Width: 24, Align: "R"},
+		{Header: "Balance", Width: 25, Align: "R"},
+	}
+
+	rows := make([][]string, 0, len(statement.Lines)+2)
+	rows = append(rows, []string{statement.DateFrom.Format(pdfDateFormat), "", "Opening balance", "", "", formatAmount(statement.OpeningBalance)})
+	for _, line := range statement.Lines {
+		debit, credit := "", ""
+		if line.Debit != 0 {
+			debit = formatAmount(line.Debit)
+		}
+		if line.Credit != 0 {
+			credit = formatAmount(line.Credit)
+		}
+
+		rows = append(rows, []string{
+			line.EntryDate.Format(pdfDateFormat),
+			line.EntryType,
+			line.Description,
+			debit,
+			credit,
+			formatAmount(line.Balance),
+		})
+	}
+	rows = append(rows, []string{statement.DateTo.Format(pdfDateFormat), "", "Closing balance", formatAmount(statement.TotalDebits), formatAmount(statement.TotalCredits), formatAmount(statement.ClosingBalance)})
+
+	writePDFTable(pdf, columns, rows)
+
+	return outputPDF(pdf)
+}
@@ -0,0 +1,69 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+func (r *ReportServiceImpl) stockAuditPDF(ctx context.Context) ([]byte, error) {
+	audit, err := r.store.StockAuditRepository.AuditStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !audit.Consistent {
+		log.Printf("stock audit found %d drifts", len(audit.Drifts))
+	}
+
+	status := "Consistent"
+	if !audit.Consistent {
+		status = "Drift found"
+	}
+
+	pdf := newPDFDocument(
+		"Stock Consistency Audit",
+		fmt.Sprintf("Checked: %s", audit.CheckedAt.Format(pdfDateTimeFormat)),
+	)
+
+	writePDFSummary(pdf, [][2]string{
+		{"Status", status},
+		{"Drifts", strconv.Itoa(len(audit.Drifts))},
+	})
+
+	columns := []pdfColumn{
+		{Header: "Check", Width: 50, Align: "L"},
+		{Header: "Reseller", Width: 34, Align: "L"},
+		{Header: "Product", Width: 38, Align: "L"},
+		{Header: "Batch", Width: 23, Align: "L"},
+		{Header: "Recorded", Width: 15, Align: "R"},
+		{Header: "Expected", Width: 15, Align: "R"},
+		{Header: "Diff", Width: 15, Align: "R"},
+	}
+
+	rows := make([][]string, len(audit.Drifts))
+	for i, drift := range audit.Drifts {
+		reseller, product := "", ""
+		if drift.Reseller != nil {
+			reseller = drift.Reseller.Name
+		}
+		if drift.Product != nil {
+			product = drift.Product.Name
+		}
+
+		rows[i] = []string{
+			drift.Check,
+			reseller,
+			product,
+			drift.BatchNumber,
+			strconv.FormatInt(drift.Recorded, 10),
+			strconv.FormatInt(drift.Expected, 10),
+			strconv.FormatInt(drift.Difference, 10),
+		}
+	}
+
+	writePDFTable(pdf, columns, rows)
+
+	return outputPDF(pdf)
+}
@@ -0,0 +1,71 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+// stockListingPDF lists company and reseller stock on hand per product, valued at batch cost.
+func (r *ReportServiceImpl) stockListingPDF(ctx context.Context, asOf time.Time) ([]byte, error) {
+	valuation, err := r.store.ReportRepository.GetInventoryValuation(ctx, &repository.InventoryValuationFilter{
+		AsOf: asOf,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := newPDFDocument(
+		"Stock Listing",
+		fmt.Sprintf("As of: %s", valuation.AsOf.Format(pdfDateTimeFormat)),
+		fmt.Sprintf("Generated: %s", valuation.GeneratedAt.Format(pdfDateTimeFormat)),
+	)
+
+	resellerQuantity := int64(0)
+	resellerValue := 0.0
+	for _, reseller := range valuation.Resellers {
+		resellerQuantity += reseller.Quantity
+		resellerValue += reseller.Value
+	}
+
+	writePDFSummary(pdf, [][2]string{
+		{"Company units", strconv.FormatInt(valuation.Company.Quantity, 10)},
+		{"Company value", formatAmount(valuation.Company.Value)},
+		{"Reseller units", strconv.FormatInt(resellerQuantity, 10)},
+		{"Reseller value", formatAmount(resellerValue)},
+		{"Total value", formatAmount(valuation.TotalValue)},
+	})
+
+	columns := []pdfColumn{
+		{Header: "Holder", Width: 50, Align: "L"},
+		{Header: "Product", Width: 55, Align: "L"},
+		{Header: "Category", Width: 35, Align: "L"},
+		{Header: "Units", Width: 20, Align: "R"},
+		{Header: "Value", Width: 30, Align: "R"},
+	}
+
+	rows := make([][]string, 0, len(valuation.Company.ByProduct))
+	addRows := func(holder string, products []*repository.InventoryValuationProduct) {
+		for _, product := range products {
+			rows = append(rows, []string{
+				holder,
+				product.ProductName,
+				product.Category,
+				strconv.FormatInt(product.Quantity, 10),
+				formatAmount(product.Value),
+			})
+		}
+	}
+
+	addRows("Company", valuation.Company.ByProduct)
+	for _, reseller := range valuation.Resellers {
+		addRows(reseller.Reseller.Name, reseller.ByProduct)
+	}
+
+	writePDFTable(pdf, columns, rows)
+
+	return outputPDF(pdf)
+}
@@ -0,0 +1,68 @@
+package reports
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+// localStorage keeps generated report files on the local filesystem. Paths stored on
+// report runs are relative to root so the directory can be moved between deployments.
+type localStorage struct {
+	root string
+}
+
+func newLocalStorage(root string) *localStorage {
+	return &localStorage{
+		root: root,
+	}
+}
+
+// Save writes data to dir/name under the storage root and returns the relative path.
+func (s *localStorage) Save(dir, name string, data []byte) (string, error) {
+	relPath := filepath.Join(dir, name)
+	fullPath, err := s.Path(relPath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create report directory: %s", err.Error())
+	}
+
+	// write to a temp file first so a crash never leaves a half written report behind
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), name+".*.tmp")
+	if err != nil {
+		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create report file: %s", err.Error())
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "failed to write report file: %s", err.Error())
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "failed to write report file: %s", err.Error())
+	}
+
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "failed to save report file: %s", err.Error())
+	}
+
+	return relPath, nil
+}
+
+// Path resolves a relative path against the storage root, rejecting paths that escape it.
+func (s *localStorage) Path(relPath string) (string, error) {
+	fullPath := filepath.Join(s.root, relPath)
+
+	rel, err := filepath.Rel(s.root, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", pkg.Errorf(pkg.INVALID_ERROR, "invalid report path: %s", relPath)
+	}
+
+	return fullPath, nil
+}
@@ -7,6 +7,22 @@ import (
 	"github.com/EmilioCliff/boffo/pkg"
 )
 
+// allocation strategies decide which batches stock is taken from, expired batches are never
+// taken from
+const (
+	// earliest expiry first, batches without an expiry date last
+	ALLOCATION_FEFO = "FEFO"
+	ALLOCATION_FIFO = "FIFO"
+	ALLOCATION_LIFO = "LIFO"
+)
+
+// BatchAllocation asks for a quantity to be taken from one batch, the quantities of all the
+// allocations have to add up to the quantity moved.
+type BatchAllocation struct {
+	BatchID  uint32 `json:"batch_id"`
+	Quantity int64  `json:"quantity"`
+}
+
 type CompanyStock struct {
 	ProductID uint32 `json:"product_id"`
 	Quantity  int64  `json:"quantity"`
@@ -19,6 +35,7 @@ type CompanyStock struct {
 type CompanyStockFilter struct {
 	Pagination *pkg.Pagination
 	Search     *string
+	LocationID *uint32
 	InStock    *bool
 }
 
@@ -35,23 +52,32 @@ type AdminStat struct {
 }
 
 type ProductBatch struct {
-	ID            uint32    `json:"id"`
-	ProductID     uint32    `json:"product_id"`
-	BatchNumber   string    `json:"batch_number"`
-	Quantity      int64     `json:"quantity"`
-	PurchasePrice float64   `json:"purchase_price"`
-	DateReceived  time.Time `json:"date_received"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            uint32  `json:"id"`
+	ProductID     uint32  `json:"product_id"`
+	BatchNumber   string  `json:"batch_number"`
+	Quantity      int64   `json:"quantity"`
+	PurchasePrice float64 `json:"purchase_price"`
+	// UnitCost is the purchase price plus the landed costs allocated to the batch
+	LandedUnitCost float64    `json:"landed_unit_cost"`
+	UnitCost       float64    `json:"unit_cost"`
+	DateReceived   time.Time  `json:"date_received"`
+	ExpiryDate     *time.Time `json:"expiry_date"`
+	LocationID     uint32     `json:"location_id"`
+	// set when the batch was received against a purchase order line
+	PurchaseOrderLineID *uint32   `json:"purchase_order_line_id"`
+	CreatedAt           time.Time `json:"created_at"`
 
 	// expandable fields
-	RemainingQuantity int64         `json:"remaining_quantity,omitempty"`
-	ProductCategory   string        `json:"product_category,omitempty"`
-	Product           *ProductShort `json:"product,omitempty"`
+	RemainingQuantity int64          `json:"remaining_quantity,omitempty"`
+	ProductCategory   string         `json:"product_category,omitempty"`
+	Product           *ProductShort  `json:"product,omitempty"`
+	Location          *LocationShort `json:"location,omitempty"`
 }
 
 type ProductBatchFilter struct {
 	Pagination *pkg.Pagination
 	ProductID  *uint32
+	LocationID *uint32
 	InStock    *bool
 	Search     *string
 }
@@ -64,17 +90,38 @@ type StockDistribution struct {
 	UnitPrice       float64   `json:"unit_price"`
 	TotalPrice      float64   `json:"total_price"`
 	DateDistributed time.Time `json:"date_distributed"`
+	InvoiceID       *uint32   `json:"invoice_id"`
+	LocationID      uint32    `json:"location_id"`
 	CreatedAt       time.Time `json:"created_at"`
 
+	// DeferInvoice leaves the distribution uninvoiced so it can be grouped with others,
+	// otherwise it gets its own invoice due on DueDate or after INVOICE_DUE_DAYS.
+	DeferInvoice bool       `json:"-"`
+	DueDate      *time.Time `json:"-"`
+
+	// a distribution past the reseller's credit limit is rejected unless OverrideReason is
+	// set or HoldOverLimit asks for it to be held for an admin, CreditHold is the record of
+	// either
+	OverrideReason string      `json:"-"`
+	HoldOverLimit  bool        `json:"-"`
+	CreditHold     *CreditHold `json:"credit_hold,omitempty"`
+
+	// AllocationStrategy picks the batch order, STOCK_ALLOCATION_STRATEGY when empty, and
+	// Batches names the batches to ship from instead
+	AllocationStrategy string            `json:"-"`
+	Batches            []BatchAllocation `json:"-"`
+
 	// expandable fields
-	Product *ProductShort `json:"product,omitempty"`
-	User    *UserShort    `json:"user,omitempty"`
+	Product  *ProductShort  `json:"product,omitempty"`
+	User     *UserShort     `json:"user,omitempty"`
+	Location *LocationShort `json:"location,omitempty"`
 }
 
 type StockDistributionFilter struct {
 	Pagination *pkg.Pagination
 	ResellerID *uint32
 	ProductID  *uint32
+	LocationID *uint32
 	Search     *string
 }
 
@@ -88,6 +135,24 @@ type CompanyRepository interface {
 	ListProductBatches(ctx context.Context, filter *ProductBatchFilter) ([]*ProductBatch, *pkg.Pagination, error)
 	DistributeStockToReseller(ctx context.Context, distribution *StockDistribution) (*StockDistribution, error)
 	ListStockDistributions(ctx context.Context, filter *StockDistributionFilter) ([]*StockDistribution, *pkg.Pagination, error)
+	// ListNearExpiryStock lists company and reseller batch layers expiring soonest first.
+	ListNearExpiryStock(ctx context.Context, filter *NearExpiryStockFilter) ([]*NearExpiryStock, *pkg.Pagination, error)
+
+	// Stock returns
+	// ReturnStock takes stock back from a reseller into company stock, credits their account
+	// and applies the credit to their open invoices.
+	ReturnStock(ctx context.Context, stockReturn *StockReturn) (*StockReturn, error)
+	ListStockReturns(ctx context.Context, filter *StockReturnFilter) ([]*StockReturn, *pkg.Pagination, error)
+
+	// Stock adjustments
+	AdjustStock(ctx context.Context, adjustment *StockAdjustment) (*StockAdjustment, error)
+	ListStockAdjustments(ctx context.Context, filter *StockAdjustmentFilter) ([]*StockAdjustment, *pkg.Pagination, error)
+
+	// Credit holds
+	ListCreditHolds(ctx context.Context, filter *CreditHoldFilter) ([]*CreditHold, *pkg.Pagination, error)
+	// OverrideCreditHold distributes a held distribution past the credit limit.
+	OverrideCreditHold(ctx context.Context, id uint32, justification string) (*CreditHold, error)
+	RejectCreditHold(ctx context.Context, id uint32, reason string) (*CreditHold, error)
 
 	ListCompanyStock(ctx context.Context, filter *CompanyStockFilter) ([]*CompanyStock, *pkg.Pagination, error)
 
@@ -95,5 +160,5 @@ type CompanyRepository interface {
 	GetAdminStats(ctx context.Context) (*AdminStat, error)
 
 	// Helpers
-	GetAdminPageData(ctx context.Context, page string) (any, error)
+	GetAdminPageData(ctx context.Context, page string, locationID *uint32) (any, error)
 }
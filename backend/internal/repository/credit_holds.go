@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	CREDIT_HOLD_HELD       = "HELD"
+	CREDIT_HOLD_OVERRIDDEN = "OVERRIDDEN"
+	CREDIT_HOLD_REJECTED   = "REJECTED"
+)
+
+// CreditHold is a distribution that would take the reseller's balance past their credit
+// limit. It is held until an admin overrides the limit or rejects it, distributions made
+// with an override straight away are recorded as overridden.
+type CreditHold struct {
+	ID              uint32     `json:"id"`
+	ResellerID      uint32     `json:"reseller_id"`
+	ProductID       uint32     `json:"product_id"`
+	Quantity        int32      `json:"quantity"`
+	UnitPrice       float64    `json:"unit_price"`
+	TotalPrice      float64    `json:"total_price"`
+	DateDistributed time.Time  `json:"date_distributed"`
+	DeferInvoice    bool       `json:"defer_invoice"`
+	DueDate         *time.Time `json:"due_date"`
+	Balance         float64    `json:"balance"`
+	CreditLimit     float64    `json:"credit_limit"`
+	Status          string     `json:"status"`
+	Justification   string     `json:"justification"`
+	DistributionID  *uint32    `json:"distribution_id"`
+	DecidedAt       *time.Time `json:"decided_at"`
+	LocationID      uint32     `json:"location_id"`
+	CreatedAt       time.Time  `json:"created_at"`
+
+	// expandable fields
+	Product *ProductShort `json:"product,omitempty"`
+	User    *UserShort    `json:"user,omitempty"`
+}
+
+type CreditHoldFilter struct {
+	Pagination *pkg.Pagination
+	ResellerID *uint32
+	Status     *string
+}
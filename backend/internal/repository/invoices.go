@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	INVOICE_UNPAID  = "UNPAID"
+	INVOICE_PARTIAL = "PARTIAL"
+	INVOICE_PAID    = "PAID"
+
+	// invoice numbers are the prefix followed by a zero padded sequence, e.g. INV-000012
+	INVOICE_NUMBER_PREFIX = "INV-"
+)
+
+type Invoice struct {
+	ID            uint32    `json:"id"`
+	InvoiceNumber string    `json:"invoice_number"`
+	ResellerID    uint32    `json:"reseller_id"`
+	TotalAmount   float64   `json:"total_amount"`
+	AmountPaid    float64   `json:"amount_paid"`
+	Balance       float64   `json:"balance"`
+	Status        string    `json:"status"`
+	IssueDate     time.Time `json:"issue_date"`
+	DueDate       time.Time `json:"due_date"`
+	Overdue       bool      `json:"overdue"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// expandable fields
+	User            *UserShort                 `json:"user,omitempty"`
+	Distributions   []*StockDistribution       `json:"distributions,omitempty"`
+	Transfers       []*StockTransfer           `json:"transfers,omitempty"`
+	Allocations     []*PaymentAllocation       `json:"allocations,omitempty"`
+	ReturnCredits   []*StockReturnAllocation   `json:"return_credits,omitempty"`
+	TransferCredits []*StockTransferAllocation `json:"transfer_credits,omitempty"`
+}
+
+// PaymentAllocation is the part of a payment that settles an invoice.
+type PaymentAllocation struct {
+	ID            uint32    `json:"id"`
+	PaymentID     uint32    `json:"payment_id"`
+	InvoiceID     uint32    `json:"invoice_id"`
+	InvoiceNumber string    `json:"invoice_number,omitempty"`
+	Amount        float64   `json:"amount"`
+	AllocatedBy   string    `json:"allocated_by"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// expandable fields
+	Payment *Payment `json:"payment,omitempty"`
+}
+
+type InvoiceFilter struct {
+	Pagination *pkg.Pagination
+	ResellerID *uint32
+	Status     *string
+	// Open limits the list to invoices that are (true) or are not (false) fully paid
+	Open   *bool
+	Search *string
+}
+
+type InvoiceRepository interface {
+	// CreateInvoice raises one invoice for distributions of a single reseller that are not
+	// invoiced yet. The due date defaults to INVOICE_DUE_DAYS from today and any unallocated
+	// payments by the reseller are applied to it straight away.
+	CreateInvoice(ctx context.Context, distributionIDs []uint32, dueDate *time.Time) (*Invoice, error)
+	GetInvoice(ctx context.Context, id uint32) (*Invoice, error)
+	ListInvoices(ctx context.Context, filter *InvoiceFilter) ([]*Invoice, *pkg.Pagination, error)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	LANDED_COST_FREIGHT  = "FREIGHT"
+	LANDED_COST_CLEARING = "CLEARING"
+	LANDED_COST_DUTY     = "DUTY"
+	LANDED_COST_OTHER    = "OTHER"
+)
+
+const (
+	LANDED_COST_BY_QUANTITY = "QUANTITY"
+	LANDED_COST_BY_VALUE    = "VALUE"
+)
+
+// LandedCost is a cost paid on top of the purchase price of one or more batches. It is split
+// over the units of the batches still in company stock, by quantity or by purchase value, and
+// added to their unit cost. Stock already issued keeps the cost it went out at and the
+// purchase price of the batches is left as it is.
+type LandedCost struct {
+	ID               uint32    `json:"id"`
+	CostType         string    `json:"cost_type"`
+	Amount           float64   `json:"amount"`
+	AllocationMethod string    `json:"allocation_method"`
+	Reference        string    `json:"reference"`
+	Note             string    `json:"note"`
+	CreatedBy        uint32    `json:"created_by"`
+	CreatedAt        time.Time `json:"created_at"`
+
+	// batches the cost is spread over when creating
+	BatchIDs []uint32 `json:"-"`
+
+	// expandable fields
+	BatchCount  int64                   `json:"batch_count,omitempty"`
+	Allocations []*LandedCostAllocation `json:"allocations,omitempty"`
+}
+
+// LandedCostAllocation is the share of a landed cost given to one batch, UnitCostAdded is the
+// share over the Quantity the batch had in company stock.
+type LandedCostAllocation struct {
+	ID            uint32  `json:"id"`
+	LandedCostID  uint32  `json:"landed_cost_id"`
+	BatchID       uint32  `json:"batch_id"`
+	Amount        float64 `json:"amount"`
+	UnitCostAdded float64 `json:"unit_cost_added"`
+	Quantity      int64   `json:"quantity"`
+	// what rounding UnitCostAdded to 4 decimals leaves off Amount
+	RoundingDifference float64   `json:"rounding_difference"`
+	CreatedAt          time.Time `json:"created_at"`
+
+	// expandable fields
+	BatchNumber   string        `json:"batch_number,omitempty"`
+	BatchQuantity int64         `json:"batch_quantity,omitempty"`
+	PurchasePrice float64       `json:"purchase_price,omitempty"`
+	UnitCost      float64       `json:"unit_cost,omitempty"`
+	Product       *ProductShort `json:"product,omitempty"`
+}
+
+type LandedCostFilter struct {
+	Pagination *pkg.Pagination
+	CostType   *string
+	BatchID    *uint32
+	ProductID  *uint32
+}
+
+type LandedCostRepository interface {
+	// CreateLandedCost records the cost and adds its share to the unit cost of every batch in
+	// BatchIDs, distributions and valuations after it use the new unit cost.
+	CreateLandedCost(ctx context.Context, cost *LandedCost) (*LandedCost, error)
+	GetLandedCost(ctx context.Context, id uint32) (*LandedCost, error)
+	ListLandedCosts(ctx context.Context, filter *LandedCostFilter) ([]*LandedCost, *pkg.Pagination, error)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+// Location is a warehouse or store company stock is held in. Batches, distributions, returns,
+// adjustments and stocktakes that do not name a location use the default one, there is always
+// exactly one default and it has to stay active.
+type Location struct {
+	ID        uint32    `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	IsDefault bool      `json:"is_default"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// stock held at the location valued at the batch purchase price, only set when listing
+	TotalQuantity int64   `json:"total_quantity"`
+	TotalValue    float64 `json:"total_value"`
+}
+
+type LocationShort struct {
+	ID   uint32 `json:"id"`
+	Name string `json:"name"`
+}
+
+type LocationUpdate struct {
+	Name      *string `json:"name"`
+	Address   *string `json:"address"`
+	Active    *bool   `json:"active"`
+	IsDefault *bool   `json:"is_default"`
+}
+
+type LocationFilter struct {
+	Pagination *pkg.Pagination
+	Search     *string
+	Active     *bool
+}
+
+// LocationTransfer moves company stock from one location to another. The batch layers keep
+// their purchase price, so TotalValue is the cost of the stock moved.
+type LocationTransfer struct {
+	ID             uint32    `json:"id"`
+	ProductID      uint32    `json:"product_id"`
+	FromLocationID uint32    `json:"from_location_id"`
+	ToLocationID   uint32    `json:"to_location_id"`
+	BatchID        *uint32   `json:"batch_id"`
+	Quantity       int32     `json:"quantity"`
+	TotalValue     float64   `json:"total_value"`
+	Note           string    `json:"note"`
+	CreatedBy      uint32    `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// expandable fields
+	Batches      []*LocationTransferBatch `json:"batches,omitempty"`
+	Product      *ProductShort            `json:"product,omitempty"`
+	FromLocation *LocationShort           `json:"from_location,omitempty"`
+	ToLocation   *LocationShort           `json:"to_location,omitempty"`
+}
+
+// LocationTransferBatch is the part of a transfer taken from one batch layer.
+type LocationTransferBatch struct {
+	BatchID     uint32  `json:"batch_id"`
+	BatchNumber string  `json:"batch_number"`
+	Quantity    int64   `json:"quantity"`
+	UnitCost    float64 `json:"unit_cost"`
+}
+
+type LocationTransferFilter struct {
+	Pagination *pkg.Pagination
+	// LocationID matches transfers out of or into the location
+	LocationID *uint32
+	ProductID  *uint32
+}
+
+type LocationRepository interface {
+	CreateLocation(ctx context.Context, location *Location) (*Location, error)
+	GetLocation(ctx context.Context, id uint32) (*Location, error)
+	// UpdateLocation makes the location the default one when IsDefault is set, a location
+	// can only be deactivated once it holds no stock and the default cannot be deactivated.
+	UpdateLocation(ctx context.Context, id uint32, update *LocationUpdate) (*Location, error)
+	ListLocations(ctx context.Context, filter *LocationFilter) ([]*Location, *pkg.Pagination, error)
+
+	// TransferStock moves company stock between locations, taking the batch chosen in
+	// BatchID or the batches expiring first.
+	TransferStock(ctx context.Context, transfer *LocationTransfer) (*LocationTransfer, error)
+	ListLocationTransfers(ctx context.Context, filter *LocationTransferFilter) ([]*LocationTransfer, *pkg.Pagination, error)
+}
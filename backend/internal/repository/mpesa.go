@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	MPESA_STK_PENDING = "PENDING"
+	MPESA_STK_SUCCESS = "SUCCESS"
+	MPESA_STK_FAILED  = "FAILED"
+
+	MPESA_C2B_SUSPENSE  = "SUSPENSE"
+	MPESA_C2B_ALLOCATED = "ALLOCATED"
+
+	// account numbers are the prefix followed by the reseller id, e.g. BOFFO-12
+	MPESA_ACCOUNT_PREFIX = "BOFFO-"
+)
+
+type MpesaStkRequest struct {
+	ID                uint32    `json:"id"`
+	ResellerID        uint32    `json:"reseller_id"`
+	PhoneNumber       string    `json:"phone_number"`
+	Amount            float64   `json:"amount"`
+	MerchantRequestID string    `json:"merchant_request_id"`
+	CheckoutRequestID string    `json:"checkout_request_id"`
+	Status            string    `json:"status"`
+	ResultCode        *int32    `json:"result_code"`
+	ResultDesc        string    `json:"result_desc"`
+	MpesaReceipt      string    `json:"mpesa_receipt"`
+	PaymentID         *uint32   `json:"payment_id"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// MpesaStkResult is the outcome of an stk push as reported by the daraja callback.
+// Amount, MpesaReceipt and TransactionDate are only set when ResultCode is 0.
+type MpesaStkResult struct {
+	MerchantRequestID string
+	CheckoutRequestID string
+	ResultCode        int32
+	ResultDesc        string
+	Amount            float64
+	MpesaReceipt      string
+	PhoneNumber       string
+	TransactionDate   time.Time
+}
+
+// MpesaC2BTransaction is a payment made straight to the paybill. Transactions that could
+// not be matched to a reseller stay in suspense until an admin allocates them.
+type MpesaC2BTransaction struct {
+	ID            uint32     `json:"id"`
+	TransID       string     `json:"trans_id"`
+	TransType     string     `json:"trans_type"`
+	TransTime     time.Time  `json:"trans_time"`
+	Amount        float64    `json:"amount"`
+	BillRefNumber string     `json:"bill_ref_number"`
+	Msisdn        string     `json:"msisdn"`
+	PayerName     string     `json:"payer_name"`
+	Status        string     `json:"status"`
+	ResellerID    *uint32    `json:"reseller_id"`
+	PaymentID     *uint32    `json:"payment_id"`
+	AllocatedBy   string     `json:"allocated_by"`
+	AllocatedAt   *time.Time `json:"allocated_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type MpesaC2BFilter struct {
+	Pagination *pkg.Pagination
+	Status     *string
+	Search     *string
+}
+
+type MpesaRepository interface {
+	CreateStkRequest(ctx context.Context, request *MpesaStkRequest) (*MpesaStkRequest, error)
+	GetStkRequest(ctx context.Context, id uint32) (*MpesaStkRequest, error)
+	// CompleteStkRequest applies a callback result to its pending request and records the
+	// payment when it succeeded. Results for requests that are no longer pending are ignored
+	// so repeated callbacks never record the payment twice.
+	CompleteStkRequest(ctx context.Context, result *MpesaStkResult) (*MpesaStkRequest, error)
+
+	// RecordC2BTransaction stores a paybill confirmation and records the payment when the
+	// payer matches a reseller, otherwise the transaction is left in suspense. Repeated
+	// confirmations return the stored transaction.
+	RecordC2BTransaction(ctx context.Context, transaction *MpesaC2BTransaction) (*MpesaC2BTransaction, error)
+	ListC2BTransactions(ctx context.Context, filter *MpesaC2BFilter) ([]*MpesaC2BTransaction, *pkg.Pagination, error)
+	// AllocateC2BTransaction records a suspense transaction as a payment by the reseller.
+	AllocateC2BTransaction(ctx context.Context, id uint32, resellerID uint32) (*MpesaC2BTransaction, error)
+}
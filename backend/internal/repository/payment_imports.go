@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	PAYMENT_IMPORT_SOURCE_MPESA = "MPESA"
+	PAYMENT_IMPORT_SOURCE_BANK  = "BANK"
+
+	PAYMENT_IMPORT_PREVIEW   = "PREVIEW"
+	PAYMENT_IMPORT_COMMITTED = "COMMITTED"
+
+	// rows are matched when previewed and imported or skipped when the import is committed
+	PAYMENT_IMPORT_ROW_MATCHED   = "MATCHED"
+	PAYMENT_IMPORT_ROW_UNMATCHED = "UNMATCHED"
+	PAYMENT_IMPORT_ROW_DUPLICATE = "DUPLICATE"
+	PAYMENT_IMPORT_ROW_INVALID   = "INVALID"
+	PAYMENT_IMPORT_ROW_IMPORTED  = "IMPORTED"
+	PAYMENT_IMPORT_ROW_SKIPPED   = "SKIPPED"
+)
+
+// PaymentImport is an uploaded M-Pesa or bank statement. Its rows are previewed with the
+// reseller and existing payment they match before the accepted ones are committed.
+type PaymentImport struct {
+	ID          uint32     `json:"id"`
+	Source      string     `json:"source"`
+	Method      string     `json:"method"`
+	FileName    string     `json:"file_name"`
+	Status      string     `json:"status"`
+	UploadedBy  uint32     `json:"uploaded_by"`
+	CommittedAt *time.Time `json:"committed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// expandable fields
+	Rows []*PaymentImportRow `json:"rows,omitempty"`
+}
+
+type PaymentImportRow struct {
+	ID            uint32    `json:"id"`
+	ImportID      uint32    `json:"import_id"`
+	RowNumber     int32     `json:"row_number"`
+	Reference     string    `json:"reference"`
+	Amount        float64   `json:"amount"`
+	DatePaid      time.Time `json:"date_paid"`
+	PayerName     string    `json:"payer_name"`
+	PayerPhone    string    `json:"payer_phone"`
+	AccountNumber string    `json:"account_number"`
+	Details       string    `json:"details"`
+	ResellerID    *uint32   `json:"reseller_id"`
+	Status        string    `json:"status"`
+	// why a row is unmatched, a duplicate or invalid
+	Note               string    `json:"note"`
+	DuplicatePaymentID *uint32   `json:"duplicate_payment_id"`
+	PaymentID          *uint32   `json:"payment_id"`
+	CreatedAt          time.Time `json:"created_at"`
+
+	// expandable fields
+	User *UserShort `json:"user,omitempty"`
+}
+
+// PaymentImportDecision accepts a previewed row, ResellerID overrides the matched reseller.
+type PaymentImportDecision struct {
+	RowID      uint32
+	ResellerID *uint32
+}
+
+type PaymentImportFilter struct {
+	Pagination *pkg.Pagination
+	Status     *string
+}
+
+type PaymentImportRepository interface {
+	// PreviewPaymentImport matches the parsed rows of a statement to resellers and existing
+	// payments and stores them for review, no payments are recorded.
+	PreviewPaymentImport(ctx context.Context, paymentImport *PaymentImport) (*PaymentImport, error)
+	GetPaymentImport(ctx context.Context, id uint32) (*PaymentImport, error)
+	ListPaymentImports(ctx context.Context, filter *PaymentImportFilter) ([]*PaymentImport, *pkg.Pagination, error)
+	// CommitPaymentImport records the accepted rows as payments in one transaction, the
+	// rest of the rows are skipped.
+	CommitPaymentImport(ctx context.Context, id uint32, decisions []*PaymentImportDecision) (*PaymentImport, error)
+}
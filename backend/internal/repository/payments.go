@@ -17,10 +17,40 @@ type Payment struct {
 	DatePaid   time.Time `json:"date_paid"`
 	CreatedAt  time.Time `json:"created_at"`
 
+	// reversals are negative payments linked to the payment they cancel
+	ReversalOf     *uint32 `json:"reversal_of"`
+	ReversalReason string  `json:"reversal_reason"`
+	ReversedBy     *uint32 `json:"reversed_by"`
+
+	// invoices the payment settles, when set on a new payment these are allocated first
+	// and the rest settles the oldest open invoices
+	Allocations []*PaymentAllocation `json:"allocations,omitempty"`
+
 	// expandable fields
 	User *UserShort `json:"user,omitempty"`
 }
 
+// PaymentMethod is an admin managed way of paying, a payment's method must be one of the
+// active methods. When ReferencePattern is set the payment reference must match it.
+type PaymentMethod struct {
+	ID                uint32    `json:"id"`
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	ReferenceRequired bool      `json:"reference_required"`
+	ReferencePattern  string    `json:"reference_pattern"`
+	Active            bool      `json:"active"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type PaymentMethodUpdate struct {
+	Name              *string `json:"name"`
+	ReferenceRequired *bool   `json:"reference_required"`
+	// an empty pattern removes it
+	ReferencePattern *string `json:"reference_pattern"`
+	Active           *bool   `json:"active"`
+}
+
 type PaymentFilter struct {
 	Pagination *pkg.Pagination
 	Search     *string
@@ -33,5 +63,12 @@ type PaymentFilter struct {
 
 type PaymentRepository interface {
 	CreatePayment(ctx context.Context, payment *Payment) (*Payment, error)
+	// ReversePayment records a reversing entry for the payment and takes it back off the
+	// reseller account, admin stats and the invoices it settled. The original payment is kept.
+	ReversePayment(ctx context.Context, id uint32, reason string) (*Payment, error)
 	ListPayments(ctx context.Context, filter *PaymentFilter) ([]*Payment, *pkg.Pagination, error)
+
+	CreatePaymentMethod(ctx context.Context, method *PaymentMethod) (*PaymentMethod, error)
+	UpdatePaymentMethod(ctx context.Context, code string, update *PaymentMethodUpdate) (*PaymentMethod, error)
+	ListPaymentMethods(ctx context.Context, active *bool) ([]*PaymentMethod, error)
 }
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	PURCHASE_ORDER_DRAFT              = "DRAFT"
+	PURCHASE_ORDER_SENT               = "SENT"
+	PURCHASE_ORDER_PARTIALLY_RECEIVED = "PARTIALLY_RECEIVED"
+	PURCHASE_ORDER_RECEIVED           = "RECEIVED"
+	PURCHASE_ORDER_CLOSED             = "CLOSED"
+)
+
+type Supplier struct {
+	ID          uint32    `json:"id"`
+	Name        string    `json:"name"`
+	ContactName string    `json:"contact_name"`
+	PhoneNumber string    `json:"phone_number"`
+	Email       string    `json:"email"`
+	Address     string    `json:"address"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type SupplierShort struct {
+	ID   uint32 `json:"id"`
+	Name string `json:"name"`
+}
+
+type SupplierUpdate struct {
+	Name        *string `json:"name"`
+	ContactName *string `json:"contact_name"`
+	PhoneNumber *string `json:"phone_number"`
+	Email       *string `json:"email"`
+	Address     *string `json:"address"`
+	Active      *bool   `json:"active"`
+}
+
+type SupplierFilter struct {
+	Pagination *pkg.Pagination
+	Search     *string
+	Active     *bool
+}
+
+// PurchaseOrder is stock ordered from a supplier. Lines can only be changed while the order is
+// a draft, once sent each line is received as one or more product batches. An order the
+// supplier will not fill is CLOSED short with what was received so far.
+type PurchaseOrder struct {
+	ID           uint32     `json:"id"`
+	SupplierID   uint32     `json:"supplier_id"`
+	Status       string     `json:"status"`
+	ExpectedDate *time.Time `json:"expected_date"`
+	Note         string     `json:"note"`
+	CreatedBy    uint32     `json:"created_by"`
+	SentAt       *time.Time `json:"sent_at"`
+	ReceivedAt   *time.Time `json:"received_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	CloseReason  string     `json:"close_reason"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	// totals over the lines, TotalValue is the ordered quantity at the expected price
+	QuantityOrdered  int64   `json:"quantity_ordered"`
+	QuantityReceived int64   `json:"quantity_received"`
+	TotalValue       float64 `json:"total_value"`
+
+	// expandable fields
+	Lines    []*PurchaseOrderLine `json:"lines,omitempty"`
+	Supplier *SupplierShort       `json:"supplier,omitempty"`
+}
+
+type PurchaseOrderLine struct {
+	ID               uint32    `json:"id"`
+	PurchaseOrderID  uint32    `json:"purchase_order_id"`
+	ProductID        uint32    `json:"product_id"`
+	QuantityOrdered  int64     `json:"quantity_ordered"`
+	QuantityReceived int64     `json:"quantity_received"`
+	ExpectedPrice    float64   `json:"expected_price"`
+	CreatedAt        time.Time `json:"created_at"`
+
+	// expandable fields
+	Product *ProductShort   `json:"product,omitempty"`
+	Batches []*ProductBatch `json:"batches,omitempty"`
+}
+
+// PurchaseOrderUpdate changes a draft order, nil fields keep their value and a non nil
+// Lines replaces all the order lines.
+type PurchaseOrderUpdate struct {
+	SupplierID   *uint32              `json:"supplier_id"`
+	ExpectedDate *time.Time           `json:"expected_date"`
+	Note         *string              `json:"note"`
+	Lines        []*PurchaseOrderLine `json:"lines"`
+}
+
+type PurchaseOrderFilter struct {
+	Pagination *pkg.Pagination
+	SupplierID *uint32
+	Status     *string
+	ProductID  *uint32
+}
+
+type PurchaseOrderRepository interface {
+	CreateSupplier(ctx context.Context, supplier *Supplier) (*Supplier, error)
+	GetSupplier(ctx context.Context, id uint32) (*Supplier, error)
+	UpdateSupplier(ctx context.Context, id uint32, update *SupplierUpdate) (*Supplier, error)
+	ListSuppliers(ctx context.Context, filter *SupplierFilter) ([]*Supplier, *pkg.Pagination, error)
+
+	// CreatePurchaseOrder creates a draft order with its lines for an active supplier.
+	CreatePurchaseOrder(ctx context.Context, order *PurchaseOrder) (*PurchaseOrder, error)
+	GetPurchaseOrder(ctx context.Context, id uint32) (*PurchaseOrder, error)
+	UpdatePurchaseOrder(ctx context.Context, id uint32, update *PurchaseOrderUpdate) (*PurchaseOrder, error)
+	// SendPurchaseOrder marks a draft order with at least one line as sent to the supplier.
+	SendPurchaseOrder(ctx context.Context, id uint32) (*PurchaseOrder, error)
+	// ReceivePurchaseOrderLine adds the batch as received against the line, the quantity cannot
+	// exceed what is still outstanding and the purchase price defaults to the expected price.
+	ReceivePurchaseOrderLine(ctx context.Context, id uint32, lineID uint32, batch *ProductBatch) (*PurchaseOrder, error)
+	// ClosePurchaseOrder closes a sent order short, nothing more is received against it.
+	ClosePurchaseOrder(ctx context.Context, id uint32, reason string) (*PurchaseOrder, error)
+	ListPurchaseOrders(ctx context.Context, filter *PurchaseOrderFilter) ([]*PurchaseOrder, *pkg.Pagination, error)
+}
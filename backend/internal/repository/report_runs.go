@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	REPORT_TYPE_RESELLER_BALANCES = "RESELLER_BALANCES"
+	REPORT_TYPE_PAYMENTS_SUMMARY  = "PAYMENTS_SUMMARY"
+	REPORT_TYPE_STOCK_LISTING     = "STOCK_LISTING"
+	REPORT_TYPE_STOCK_AUDIT       = "STOCK_AUDIT"
+
+	REPORT_RUN_PENDING = "PENDING"
+	REPORT_RUN_RUNNING = "RUNNING"
+	REPORT_RUN_SUCCESS = "SUCCESS"
+	REPORT_RUN_FAILED  = "FAILED"
+
+	REPORT_TRIGGER_SCHEDULE = "SCHEDULE"
+	REPORT_TRIGGER_ADMIN    = "ADMIN"
+)
+
+// ReportRunParams are stored with the run so a retry produces the same report.
+type ReportRunParams struct {
+	DateFrom *time.Time `json:"date_from,omitempty"`
+	DateTo   *time.Time `json:"date_to,omitempty"`
+	AsOf     *time.Time `json:"as_of,omitempty"`
+}
+
+type ReportRun struct {
+	ID          uint32          `json:"id"`
+	ReportType  string          `json:"report_type"`
+	Status      string          `json:"status"`
+	Parameters  ReportRunParams `json:"parameters"`
+	TriggeredBy string          `json:"triggered_by"`
+	Attempts    int32           `json:"attempts"`
+	FileName    string          `json:"file_name"`
+	FilePath    string          `json:"-"`
+	ContentType string          `json:"content_type"`
+	Error       string          `json:"error"`
+	StartedAt   *time.Time      `json:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type ReportRunFilter struct {
+	Pagination *pkg.Pagination
+	ReportType *string
+	Status     *string
+}
+
+type ReportRunRepository interface {
+	CreateReportRun(ctx context.Context, run *ReportRun) (*ReportRun, error)
+	GetReportRun(ctx context.Context, id uint32) (*ReportRun, error)
+	ListReportRuns(ctx context.Context, filter *ReportRunFilter) ([]*ReportRun, *pkg.Pagination, error)
+	// ClaimReportRun marks a pending or failed run as running, returns an INVALID_ERROR
+	// when the run is already running or has succeeded.
+	ClaimReportRun(ctx context.Context, id uint32) (*ReportRun, error)
+	CompleteReportRun(ctx context.Context, id uint32, fileName, filePath, contentType string) error
+	FailReportRun(ctx context.Context, id uint32, reason string) error
+	FailInterruptedReportRuns(ctx context.Context) (int64, error)
+	ListRetryableReportRuns(ctx context.Context, maxAttempts int32) ([]*ReportRun, error)
+}
@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	STATEMENT_ENTRY_DISTRIBUTION = "DISTRIBUTION"
+	STATEMENT_ENTRY_PAYMENT      = "PAYMENT"
+	STATEMENT_ENTRY_REVERSAL     = "PAYMENT_REVERSAL"
+	STATEMENT_ENTRY_RETURN       = "RETURN"
+	STATEMENT_ENTRY_TRANSFER_IN  = "TRANSFER_IN"
+	STATEMENT_ENTRY_TRANSFER_OUT = "TRANSFER_OUT"
+
+	TRACE_EVENT_DISTRIBUTION = "DISTRIBUTION"
+	TRACE_EVENT_SALE         = "SALE"
+	TRACE_EVENT_RETURN       = "RETURN"
+	TRACE_EVENT_TRANSFER_IN  = "TRANSFER_IN"
+	TRACE_EVENT_TRANSFER_OUT = "TRANSFER_OUT"
+
+	ANALYTICS_INTERVAL_DAY   = "day"
+	ANALYTICS_INTERVAL_WEEK  = "week"
+	ANALYTICS_INTERVAL_MONTH = "month"
+
+	ANALYTICS_GROUP_PRODUCT  = "product"
+	ANALYTICS_GROUP_CATEGORY = "category"
+	ANALYTICS_GROUP_RESELLER = "reseller"
+)
+
+type StatementLine struct {
+	EntryType   string    `json:"entry_type"`
+	ReferenceID uint32    `json:"reference_id"`
+	EntryDate   time.Time `json:"entry_date"`
+	Description string    `json:"description"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Balance     float64   `json:"balance"`
+}
+
+type ResellerStatement struct {
+	Reseller       UserShort        `json:"reseller"`
+	DateFrom       time.Time        `json:"date_from"`
+	DateTo         time.Time        `json:"date_to"`
+	OpeningBalance float64          `json:"opening_balance"`
+	TotalDebits    float64          `json:"total_debits"`
+	TotalCredits   float64          `json:"total_credits"`
+	ClosingBalance float64          `json:"closing_balance"`
+	AccountBalance float64          `json:"account_balance"`
+	Lines          []*StatementLine `json:"lines"`
+	GeneratedAt    time.Time        `json:"generated_at"`
+}
+
+type StatementFilter struct {
+	ResellerID uint32
+	DateFrom   time.Time
+	DateTo     time.Time
+}
+
+type ProfitAndLossLine struct {
+	Key         string  `json:"key"`
+	Name        string  `json:"name"`
+	Quantity    int64   `json:"quantity"`
+	Revenue     float64 `json:"revenue"`
+	Cogs        float64 `json:"cogs"`
+	GrossMargin float64 `json:"gross_margin"`
+	MarginPct   float64 `json:"margin_pct"`
+	Adjustments float64 `json:"adjustments"`
+	NetMargin   float64 `json:"net_margin"`
+}
+
+type ProfitAndLossSection struct {
+	Quantity    int64                `json:"quantity"`
+	Revenue     float64              `json:"revenue"`
+	Cogs        float64              `json:"cogs"`
+	GrossMargin float64              `json:"gross_margin"`
+	MarginPct   float64              `json:"margin_pct"`
+	Adjustments float64              `json:"adjustments"`
+	NetMargin   float64              `json:"net_margin"`
+	ByProduct   []*ProfitAndLossLine `json:"by_product"`
+	ByCategory  []*ProfitAndLossLine `json:"by_category"`
+}
+
+type ResellerProfitAndLoss struct {
+	Reseller UserShort `json:"reseller"`
+	ProfitAndLossSection
+}
+
+// ProfitAndLoss holds the company figures (distribution value against purchase cost)
+// and the per reseller figures (sales against distribution cost) for a period. Stock written
+// off through adjustments is taken off the gross margin as a separate line.
+type ProfitAndLoss struct {
+	DateFrom    time.Time                `json:"date_from"`
+	DateTo      time.Time                `json:"date_to"`
+	Company     *ProfitAndLossSection    `json:"company"`
+	Resellers   []*ResellerProfitAndLoss `json:"resellers"`
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+type ProfitAndLossFilter struct {
+	DateFrom  time.Time
+	DateTo    time.Time
+	Category  *string
+	ProductID *uint32
+}
+
+type InventoryValuationBatch struct {
+	BatchID     uint32  `json:"batch_id"`
+	BatchNumber string  `json:"batch_number"`
+	ProductID   uint32  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Category    string  `json:"category"`
+	Quantity    int64   `json:"quantity"`
+	UnitCost    float64 `json:"unit_cost"`
+	Value       float64 `json:"value"`
+}
+
+type InventoryValuationProduct struct {
+	ProductID   uint32  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Category    string  `json:"category"`
+	Quantity    int64   `json:"quantity"`
+	Value       float64 `json:"value"`
+}
+
+type InventoryValuationSection struct {
+	Quantity  int64                        `json:"quantity"`
+	Value     float64                      `json:"value"`
+	ByProduct []*InventoryValuationProduct `json:"by_product"`
+	ByBatch   []*InventoryValuationBatch   `json:"by_batch"`
+}
+
+type ResellerInventoryValuation struct {
+	Reseller UserShort `json:"reseller"`
+	InventoryValuationSection
+}
+
+// InventoryValuation is the stock on hand at AsOf by movement date, company batches valued at
+// their purchase price plus the landed costs booked by AsOf and reseller batches at the unit
+// cost they were distributed at.
+type InventoryValuation struct {
+	AsOf        time.Time                     `json:"as_of"`
+	Company     *InventoryValuationSection    `json:"company"`
+	Resellers   []*ResellerInventoryValuation `json:"resellers"`
+	TotalValue  float64                       `json:"total_value"`
+	GeneratedAt time.Time                     `json:"generated_at"`
+}
+
+type InventoryValuationFilter struct {
+	AsOf       time.Time
+	ResellerID *uint32
+	ProductID  *uint32
+}
+
+// ReceivablesAging buckets unpaid distribution value by days since distribution.
+type ReceivablesAging struct {
+	Days0To30        float64 `json:"days_0_30"`
+	Days31To60       float64 `json:"days_31_60"`
+	Days61To90       float64 `json:"days_61_90"`
+	DaysOver90       float64 `json:"days_over_90"`
+	TotalOutstanding float64 `json:"total_outstanding"`
+	DaysOverdue      int64   `json:"days_overdue"`
+}
+
+type ResellerReceivablesAging struct {
+	Reseller         UserShort  `json:"reseller"`
+	Balance          float64    `json:"balance"`
+	OldestUnpaidDate *time.Time `json:"oldest_unpaid_date"`
+	ReceivablesAging
+}
+
+type ReceivablesAgingReport struct {
+	Resellers   []*ResellerReceivablesAging `json:"resellers"`
+	Totals      ReceivablesAging            `json:"totals"`
+	GeneratedAt time.Time                   `json:"generated_at"`
+}
+
+type ReceivablesAgingFilter struct {
+	ResellerID *uint32
+}
+
+type BatchTraceEvent struct {
+	MovementID uint32    `json:"movement_id"`
+	EventType  string    `json:"event_type"`
+	Reseller   UserShort `json:"reseller"`
+	Quantity   int64     `json:"quantity"`
+	UnitCost   float64   `json:"unit_cost"`
+	UnitPrice  float64   `json:"unit_price"`
+	Date       time.Time `json:"date"`
+}
+
+type BatchResellerHolding struct {
+	Reseller UserShort `json:"reseller"`
+	Quantity int64     `json:"quantity"`
+}
+
+// BatchTrace is the trail of a single purchase batch from receipt to the resellers still holding it.
+type BatchTrace struct {
+	BatchID           uint32                  `json:"batch_id"`
+	BatchNumber       string                  `json:"batch_number"`
+	Product           ProductShort            `json:"product"`
+	QuantityReceived  int64                   `json:"quantity_received"`
+	PurchasePrice     float64                 `json:"purchase_price"`
+	UnitCost          float64                 `json:"unit_cost"`
+	DateReceived      time.Time               `json:"date_received"`
+	TotalDistributed  int64                   `json:"total_distributed"`
+	TotalSold         int64                   `json:"total_sold"`
+	TotalReturned     int64                   `json:"total_returned"`
+	CompanyRemaining  int64                   `json:"company_remaining"`
+	ResellerRemaining int64                   `json:"reseller_remaining"`
+	Trail             []*BatchTraceEvent      `json:"trail"`
+	ResellerHoldings  []*BatchResellerHolding `json:"reseller_holdings"`
+}
+
+// AnalyticsPoint holds the figures for one bucket. GrossMargin is the company margin on
+// distributions (distribution value less purchase cost) and ResellerMargin is the margin
+// resellers made on their sales (sales value less distribution cost). DistributionCost and
+// GrossMargin are left at zero unless the filter asks for CompanyCosts.
+type AnalyticsPoint struct {
+	Bucket            time.Time `json:"bucket"`
+	SalesValue        float64   `json:"sales_value"`
+	UnitsSold         int64     `json:"units_sold"`
+	SalesCogs         float64   `json:"sales_cogs"`
+	ResellerMargin    float64   `json:"reseller_margin"`
+	DistributionValue float64   `json:"distribution_value"`
+	DistributionCost  float64   `json:"distribution_cost"`
+	GrossMargin       float64   `json:"gross_margin"`
+	PaymentsReceived  float64   `json:"payments_received"`
+}
+
+type AnalyticsSeries struct {
+	Key    string            `json:"key"`
+	Name   string            `json:"name"`
+	Points []*AnalyticsPoint `json:"points"`
+}
+
+// Analytics holds the overall series and, when grouped, one series per group. Payments
+// are not tied to a product so they only count toward the totals in product or category
+// groups.
+type Analytics struct {
+	Interval string             `json:"interval"`
+	GroupBy  string             `json:"group_by,omitempty"`
+	DateFrom time.Time          `json:"date_from"`
+	DateTo   time.Time          `json:"date_to"`
+	Totals   *AnalyticsSeries   `json:"totals"`
+	Groups   []*AnalyticsSeries `json:"groups"`
+}
+
+type AnalyticsFilter struct {
+	Interval   string
+	GroupBy    *string
+	DateFrom   time.Time
+	DateTo     time.Time
+	ResellerID *uint32
+	ProductID  *uint32
+	Category   *string
+	// include the company cost of distributed stock, admins only
+	CompanyCosts bool
+}
+
+type ResellerBalance struct {
+	Reseller           UserShort `json:"reseller"`
+	TotalStockReceived int64     `json:"total_stock_received"`
+	TotalValueReceived float64   `json:"total_value_received"`
+	TotalPaid          float64   `json:"total_paid"`
+	Balance            float64   `json:"balance"`
+	DaysOverdue        int64     `json:"days_overdue"`
+}
+
+// ResellerBalances is a snapshot of every reseller account at GeneratedAt.
+type ResellerBalances struct {
+	Resellers          []*ResellerBalance `json:"resellers"`
+	TotalValueReceived float64            `json:"total_value_received"`
+	TotalPaid          float64            `json:"total_paid"`
+	TotalBalance       float64            `json:"total_balance"`
+	GeneratedAt        time.Time          `json:"generated_at"`
+}
+
+type PaymentsSummaryLine struct {
+	Reseller UserShort `json:"reseller"`
+	Method   string    `json:"method"`
+	Count    int64     `json:"count"`
+	Amount   float64   `json:"amount"`
+}
+
+type PaymentsSummary struct {
+	DateFrom    time.Time              `json:"date_from"`
+	DateTo      time.Time              `json:"date_to"`
+	Lines       []*PaymentsSummaryLine `json:"lines"`
+	ByMethod    map[string]float64     `json:"by_method"`
+	TotalCount  int64                  `json:"total_count"`
+	TotalAmount float64                `json:"total_amount"`
+	GeneratedAt time.Time              `json:"generated_at"`
+}
+
+type PaymentsSummaryFilter struct {
+	DateFrom time.Time
+	DateTo   time.Time
+}
+
+// FillRateFigures compare what was ordered from suppliers against what was received.
+// FillRatePct is on quantities and LineFillRatePct is the share of lines received in full.
+type FillRateFigures struct {
+	Lines            int64   `json:"lines"`
+	LinesFilled      int64   `json:"lines_filled"`
+	QuantityOrdered  int64   `json:"quantity_ordered"`
+	QuantityReceived int64   `json:"quantity_received"`
+	FillRatePct      float64 `json:"fill_rate_pct"`
+	LineFillRatePct  float64 `json:"line_fill_rate_pct"`
+}
+
+type PurchaseOrderFill struct {
+	PurchaseOrderID uint32        `json:"purchase_order_id"`
+	Status          string        `json:"status"`
+	SentAt          time.Time     `json:"sent_at"`
+	Supplier        SupplierShort `json:"supplier"`
+	FillRateFigures
+}
+
+type SupplierFillRate struct {
+	Supplier SupplierShort `json:"supplier"`
+	Orders   int64         `json:"orders"`
+	FillRateFigures
+}
+
+// PurchaseOrderFillRate covers the orders sent to suppliers in the period, drafts are left out.
+type PurchaseOrderFillRate struct {
+	DateFrom    time.Time            `json:"date_from"`
+	DateTo      time.Time            `json:"date_to"`
+	Orders      []*PurchaseOrderFill `json:"orders"`
+	BySupplier  []*SupplierFillRate  `json:"by_supplier"`
+	Total       FillRateFigures      `json:"total"`
+	GeneratedAt time.Time            `json:"generated_at"`
+}
+
+type PurchaseOrderFillRateFilter struct {
+	DateFrom   time.Time
+	DateTo     time.Time
+	SupplierID *uint32
+}
+
+// PurchasePriceVarianceLine is a batch received against a purchase order line. A positive
+// variance means the batch cost more than the price agreed on the order.
+type PurchasePriceVarianceLine struct {
+	PurchaseOrderID uint32        `json:"purchase_order_id"`
+	Supplier        SupplierShort `json:"supplier"`
+	Product         ProductShort  `json:"product"`
+	BatchID         uint32        `json:"batch_id"`
+	BatchNumber     string        `json:"batch_number"`
+	DateReceived    time.Time     `json:"date_received"`
+	Quantity        int64         `json:"quantity"`
+	ExpectedPrice   float64       `json:"expected_price"`
+	PurchasePrice   float64       `json:"purchase_price"`
+	UnitVariance    float64       `json:"unit_variance"`
+	Variance        float64       `json:"variance"`
+	VariancePct     float64       `json:"variance_pct"`
+}
+
+type PurchasePriceVariance struct {
+	DateFrom      time.Time                    `json:"date_from"`
+	DateTo        time.Time                    `json:"date_to"`
+	Lines         []*PurchasePriceVarianceLine `json:"lines"`
+	ExpectedValue float64                      `json:"expected_value"`
+	ActualValue   float64                      `json:"actual_value"`
+	TotalVariance float64                      `json:"total_variance"`
+	VariancePct   float64                      `json:"variance_pct"`
+	GeneratedAt   time.Time                    `json:"generated_at"`
+}
+
+type PurchasePriceVarianceFilter struct {
+	DateFrom   time.Time
+	DateTo     time.Time
+	SupplierID *uint32
+	ProductID  *uint32
+}
+
+type ReportRepository interface {
+	GetResellerStatement(ctx context.Context, filter *StatementFilter) (*ResellerStatement, error)
+	GetProfitAndLoss(ctx context.Context, filter *ProfitAndLossFilter) (*ProfitAndLoss, error)
+	GetInventoryValuation(ctx context.Context, filter *InventoryValuationFilter) (*InventoryValuation, error)
+	GetReceivablesAging(ctx context.Context, filter *ReceivablesAgingFilter) (*ReceivablesAgingReport, error)
+	TraceBatch(ctx context.Context, batchNumber string) ([]*BatchTrace, error)
+	GetAnalytics(ctx context.Context, filter *AnalyticsFilter) (*Analytics, error)
+	GetResellerBalances(ctx context.Context) (*ResellerBalances, error)
+	GetPaymentsSummary(ctx context.Context, filter *PaymentsSummaryFilter) (*PaymentsSummary, error)
+	GetPurchaseOrderFillRate(ctx context.Context, filter *PurchaseOrderFillRateFilter) (*PurchaseOrderFillRate, error)
+	GetPurchasePriceVariance(ctx context.Context, filter *PurchasePriceVarianceFilter) (*PurchasePriceVariance, error)
+}
@@ -42,6 +42,11 @@ type ResellerSale struct {
 	DateSold     time.Time `json:"date_sold"`
 	CreatedAt    time.Time `json:"created_at"`
 
+	// AllocationStrategy picks the batch order, STOCK_ALLOCATION_STRATEGY when empty, and
+	// Batches names the source batches to sell from instead
+	AllocationStrategy string            `json:"-"`
+	Batches            []BatchAllocation `json:"-"`
+
 	// expandable fields
 	User            *UserShort    `json:"user,omitempty"`
 	Product         *ProductShort `json:"product,omitempty"`
@@ -62,16 +67,24 @@ type ResellerAccount struct {
 	TotalPaid          float64 `json:"total_paid"`
 	TotalCogs          float64 `json:"total_cogs"`
 	Balance            float64 `json:"balance"`
+
+	// credit fields are nil when the reseller has no credit limit, headroom is negative
+	// once the balance is past the limit
+	CreditLimit       *float64 `json:"credit_limit"`
+	CreditHeadroom    *float64 `json:"credit_headroom"`
+	CreditUtilisation *float64 `json:"credit_utilisation"`
 }
 
 type Reseller struct {
-	User    User            `json:"user"`
-	Account ResellerAccount `json:"account"`
+	User    User              `json:"user"`
+	Account ResellerAccount   `json:"account"`
+	Aging   *ReceivablesAging `json:"aging,omitempty"`
 }
 
 type ResellerFilter struct {
 	Pagination *pkg.Pagination
 	Search     *string
+	Sort       *string
 }
 
 type ResellerRepository interface {
@@ -94,8 +107,23 @@ type ResellerRepository interface {
 	ListResellerStock(ctx context.Context, filter *ResellerStockFilter) ([]*ResellerStock, *pkg.Pagination, error)
 	UpdateResellerStockThreshold(ctx context.Context, update *ResellerStockUpdate) (*ResellerStock, error)
 
+	// Transfers
+	// TransferStock records a transfer between two resellers. It is completed straight away
+	// unless requireApproval is set, then it waits for the receiver to accept it or an admin
+	// to approve it.
+	TransferStock(ctx context.Context, transfer *StockTransfer, requireApproval bool) (*StockTransfer, error)
+	ApproveStockTransfer(ctx context.Context, id uint32, approvedBy uint32) (*StockTransfer, error)
+	RejectStockTransfer(ctx context.Context, id uint32, rejectedBy uint32, reason string) (*StockTransfer, error)
+	// AcceptStockTransfer and DeclineStockTransfer are the receiver's answer to a pending
+	// transfer, a transfer sent to someone else is not found.
+	AcceptStockTransfer(ctx context.Context, id uint32, resellerID uint32) (*StockTransfer, error)
+	DeclineStockTransfer(ctx context.Context, id uint32, resellerID uint32, reason string) (*StockTransfer, error)
+	ListStockTransfers(ctx context.Context, filter *StockTransferFilter) ([]*StockTransfer, *pkg.Pagination, error)
+
 	// Account
 	GetResellerAccount(ctx context.Context, resellerID uint32) (*ResellerAccount, error)
+	// UpdateCreditLimit sets the most the reseller can owe, nil removes the limit.
+	UpdateCreditLimit(ctx context.Context, resellerID uint32, creditLimit *float64) (*ResellerAccount, error)
 
 	// Helpers
 	GetResellerPageData(ctx context.Context, resellerID uint32, page string) (any, error)
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	STOCK_OWNER_COMPANY  = "COMPANY"
+	STOCK_OWNER_RESELLER = "RESELLER"
+
+	ADJUSTMENT_DAMAGED          = "DAMAGED"
+	ADJUSTMENT_EXPIRED          = "EXPIRED"
+	ADJUSTMENT_THEFT            = "THEFT"
+	ADJUSTMENT_COUNT_CORRECTION = "COUNT_CORRECTION"
+)
+
+// StockAdjustment writes company or reseller stock off outside of a sale. A negative
+// Quantity takes units out of the batch chosen in BatchID, or the oldest batches first,
+// while a positive Quantity is a count correction that puts units back into BatchID.
+// TotalValue is the stock's cost to its owner, the batch purchase price for the company
+// and the distribution price for a reseller, signed like Quantity.
+type StockAdjustment struct {
+	ID         uint32    `json:"id"`
+	OwnerType  string    `json:"owner_type"`
+	ResellerID *uint32   `json:"reseller_id"`
+	LocationID *uint32   `json:"location_id"`
+	ProductID  uint32    `json:"product_id"`
+	BatchID    *uint32   `json:"batch_id"`
+	Quantity   int32     `json:"quantity"`
+	TotalValue float64   `json:"total_value"`
+	Reason     string    `json:"reason"`
+	Note       string    `json:"note"`
+	CreatedBy  uint32    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// expandable fields
+	Batches []*StockAdjustmentBatch `json:"batches,omitempty"`
+	Product *ProductShort           `json:"product,omitempty"`
+	User    *UserShort              `json:"user,omitempty"`
+}
+
+// StockAdjustmentBatch is the part of an adjustment applied to one batch layer.
+type StockAdjustmentBatch struct {
+	BatchID     uint32  `json:"batch_id"`
+	BatchNumber string  `json:"batch_number"`
+	Quantity    int64   `json:"quantity"`
+	UnitCost    float64 `json:"unit_cost"`
+}
+
+type StockAdjustmentFilter struct {
+	Pagination *pkg.Pagination
+	OwnerType  *string
+	ResellerID *uint32
+	ProductID  *uint32
+	Reason     *string
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// admin_stats.total_company_stock against the sum of company_stock
+	STOCK_CHECK_ADMIN_STATS_COMPANY_STOCK = "ADMIN_STATS_COMPANY_STOCK"
+	// company_stock against the sum of batch_inventory per product and location
+	STOCK_CHECK_COMPANY_STOCK_BATCHES = "COMPANY_STOCK_BATCHES"
+	// reseller_stock against the sum of reseller_batch_inventory per reseller and product
+	STOCK_CHECK_RESELLER_STOCK_BATCHES = "RESELLER_STOCK_BATCHES"
+	// company_stock against the company stock movements per product and location
+	STOCK_CHECK_COMPANY_STOCK_MOVEMENTS = "COMPANY_STOCK_MOVEMENTS"
+	// reseller_stock against the reseller stock movements per reseller and product
+	STOCK_CHECK_RESELLER_STOCK_MOVEMENTS = "RESELLER_STOCK_MOVEMENTS"
+	// batch_inventory against the batch received less the batch lines issued from it
+	STOCK_CHECK_BATCH_INVENTORY_MOVEMENTS = "BATCH_INVENTORY_MOVEMENTS"
+	// reseller_batch_inventory against the reseller batch lines per batch and unit cost
+	STOCK_CHECK_RESELLER_BATCH_MOVEMENTS = "RESELLER_BATCH_MOVEMENTS"
+	// stock movements whose batch lines do not add up to the movement quantity
+	STOCK_CHECK_UNTRACED_MOVEMENTS = "UNTRACED_MOVEMENTS"
+)
+
+// StockDrift is a quantity that disagrees with the source it is derived from. Recorded is
+// the stored quantity and Expected is what the source adds up to.
+type StockDrift struct {
+	Check       string         `json:"check"`
+	Reseller    *UserShort     `json:"reseller,omitempty"`
+	Location    *LocationShort `json:"location,omitempty"`
+	Product     *ProductShort  `json:"product,omitempty"`
+	BatchID     *uint32        `json:"batch_id,omitempty"`
+	BatchNumber string         `json:"batch_number,omitempty"`
+	UnitCost    *float64       `json:"unit_cost,omitempty"`
+	Recorded    int64          `json:"recorded"`
+	Expected    int64          `json:"expected"`
+	Difference  int64          `json:"difference"`
+}
+
+// StockRebuild holds the number of rows each rebuilt table had corrected.
+type StockRebuild struct {
+	CompanyStock           int64 `json:"company_stock"`
+	ResellerStock          int64 `json:"reseller_stock"`
+	BatchInventory         int64 `json:"batch_inventory"`
+	ResellerBatchInventory int64 `json:"reseller_batch_inventory"`
+	AdminStats             int64 `json:"admin_stats"`
+}
+
+type StockAudit struct {
+	Consistent bool          `json:"consistent"`
+	Drifts     []*StockDrift `json:"drifts"`
+	Rebuilt    *StockRebuild `json:"rebuilt,omitempty"`
+	CheckedAt  time.Time     `json:"checked_at"`
+}
+
+type StockAuditRepository interface {
+	AuditStock(ctx context.Context) (*StockAudit, error)
+	// RebuildStock recomputes the derived stock tables from stock_movements and returns the
+	// audit taken afterwards. Batch level tables are left alone for products with untraced movements.
+	RebuildStock(ctx context.Context) (*StockAudit, error)
+}
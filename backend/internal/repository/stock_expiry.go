@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+// NearExpiryStock is a company or reseller batch layer that expires within the requested
+// window. Layers that are already expired are included with a negative DaysToExpiry so they
+// can be returned or written off.
+type NearExpiryStock struct {
+	OwnerType         string         `json:"owner_type"`
+	ResellerID        *uint32        `json:"reseller_id"`
+	LocationID        *uint32        `json:"location_id"`
+	ProductID         uint32         `json:"product_id"`
+	BatchID           uint32         `json:"batch_id"`
+	BatchNumber       string         `json:"batch_number"`
+	ExpiryDate        time.Time      `json:"expiry_date"`
+	DaysToExpiry      int32          `json:"days_to_expiry"`
+	Expired           bool           `json:"expired"`
+	RemainingQuantity int64          `json:"remaining_quantity"`
+	UnitCost          float64        `json:"unit_cost"`
+	TotalValue        float64        `json:"total_value"`
+	Product           *ProductShort  `json:"product,omitempty"`
+	User              *UserShort     `json:"user,omitempty"`
+	Location          *LocationShort `json:"location,omitempty"`
+}
+
+type NearExpiryStockFilter struct {
+	Pagination *pkg.Pagination
+	// Days is how far ahead of today to look for expiring layers
+	Days       int32
+	OwnerType  *string
+	ResellerID *uint32
+	ProductID  *uint32
+	LocationID *uint32
+}
@@ -8,21 +8,25 @@ import (
 )
 
 type StockMovement struct {
-	ID           uint32    `json:"id"`
-	ProductID    uint32    `json:"product_id"`
-	OwnerType    string    `json:"owner_type"`
-	OwnerID      *uint32   `json:"owner_id"`
-	MovementType string    `json:"movement_type"`
-	Quantity     int64     `json:"quantity"`
-	UnitPrice    float64   `json:"unit_price"`
-	Source       string    `json:"source"`
-	Note         string    `json:"note"`
+	ID           uint32  `json:"id"`
+	ProductID    uint32  `json:"product_id"`
+	OwnerType    string  `json:"owner_type"`
+	OwnerID      *uint32 `json:"owner_id"`
+	LocationID   *uint32 `json:"location_id"`
+	MovementType string  `json:"movement_type"`
+	Quantity     int64   `json:"quantity"`
+	UnitPrice    float64 `json:"unit_price"`
+	Source       string  `json:"source"`
+	Note         string  `json:"note"`
+	// the business date of the distribution, sale or return behind the movement
+	MovementDate time.Time `json:"movement_date"`
 	CreatedAt    time.Time `json:"created_at"`
 
 	// expandable fields
-	Product         *ProductShort `json:"product,omitempty"`
-	User            *UserShort    `json:"user,omitempty"`
-	ProductCategory string        `json:"product_category,omitempty"`
+	Product         *ProductShort  `json:"product,omitempty"`
+	User            *UserShort     `json:"user,omitempty"`
+	Location        *LocationShort `json:"location,omitempty"`
+	ProductCategory string         `json:"product_category,omitempty"`
 }
 
 type StockMovementFilter struct {
@@ -33,6 +37,7 @@ type StockMovementFilter struct {
 	OwnerID      *uint32
 	MovementType *string
 	Source       *string
+	LocationID   *uint32
 }
 
 type StockMovementRepository interface {
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+// StockReturn is stock a reseller hands back to the company. The units are taken from the
+// batch chosen in BatchID, or the batches the reseller received most recently, and go back
+// into company stock while the reseller is credited what they were charged for them.
+type StockReturn struct {
+	ID           uint32    `json:"id"`
+	ResellerID   uint32    `json:"reseller_id"`
+	ProductID    uint32    `json:"product_id"`
+	BatchID      *uint32   `json:"batch_id"`
+	Quantity     int32     `json:"quantity"`
+	TotalValue   float64   `json:"total_value"`
+	Reason       string    `json:"reason"`
+	LocationID   uint32    `json:"location_id"`
+	DateReturned time.Time `json:"date_returned"`
+	CreatedBy    uint32    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// expandable fields
+	Batches []*StockReturnBatch `json:"batches,omitempty"`
+	Product *ProductShort       `json:"product,omitempty"`
+	User    *UserShort          `json:"user,omitempty"`
+}
+
+// StockReturnBatch is the part of a return taken from one batch the reseller holds.
+type StockReturnBatch struct {
+	BatchID     uint32  `json:"batch_id"`
+	BatchNumber string  `json:"batch_number"`
+	Quantity    int64   `json:"quantity"`
+	UnitCost    float64 `json:"unit_cost"`
+}
+
+// StockReturnAllocation is the part of a return's credit that settles an invoice.
+type StockReturnAllocation struct {
+	ID            uint32    `json:"id"`
+	StockReturnID uint32    `json:"stock_return_id"`
+	InvoiceID     uint32    `json:"invoice_id"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// expandable fields
+	StockReturn *StockReturn `json:"stock_return,omitempty"`
+}
+
+type StockReturnFilter struct {
+	Pagination *pkg.Pagination
+	ResellerID *uint32
+	ProductID  *uint32
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	STOCK_TRANSFER_PENDING   = "PENDING"
+	STOCK_TRANSFER_COMPLETED = "COMPLETED"
+	STOCK_TRANSFER_REJECTED  = "REJECTED"
+)
+
+// StockTransfer is stock one reseller hands to another. The batch layers move across with
+// the unit cost the sender was charged, the sender is credited that value and the receiver
+// is invoiced for it. Transfers a reseller starts wait as PENDING until the receiver accepts
+// them or an admin approves them, and the stock only moves once they are COMPLETED.
+type StockTransfer struct {
+	ID              uint32     `json:"id"`
+	FromResellerID  uint32     `json:"from_reseller_id"`
+	ToResellerID    uint32     `json:"to_reseller_id"`
+	ProductID       uint32     `json:"product_id"`
+	BatchID         *uint32    `json:"batch_id"`
+	Quantity        int32      `json:"quantity"`
+	TotalValue      float64    `json:"total_value"`
+	Status          string     `json:"status"`
+	Note            string     `json:"note"`
+	RejectionReason string     `json:"rejection_reason"`
+	InvoiceID       *uint32    `json:"invoice_id"`
+	RequestedBy     uint32     `json:"requested_by"`
+	DecidedBy       *uint32    `json:"decided_by"`
+	DecidedAt       *time.Time `json:"decided_at"`
+	DateTransferred *time.Time `json:"date_transferred"`
+	CreatedAt       time.Time  `json:"created_at"`
+
+	// expandable fields
+	Batches      []*StockTransferBatch `json:"batches,omitempty"`
+	Product      *ProductShort         `json:"product,omitempty"`
+	FromReseller *UserShort            `json:"from_reseller,omitempty"`
+	ToReseller   *UserShort            `json:"to_reseller,omitempty"`
+}
+
+// StockTransferBatch is the part of a transfer taken from one batch the sender holds.
+type StockTransferBatch struct {
+	BatchID     uint32  `json:"batch_id"`
+	BatchNumber string  `json:"batch_number"`
+	Quantity    int64   `json:"quantity"`
+	UnitCost    float64 `json:"unit_cost"`
+}
+
+// StockTransferAllocation is the part of a transfer's credit that settles one of the
+// sender's invoices.
+type StockTransferAllocation struct {
+	ID              uint32    `json:"id"`
+	StockTransferID uint32    `json:"stock_transfer_id"`
+	InvoiceID       uint32    `json:"invoice_id"`
+	Amount          float64   `json:"amount"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	// expandable fields
+	StockTransfer *StockTransfer `json:"stock_transfer,omitempty"`
+}
+
+type StockTransferFilter struct {
+	Pagination *pkg.Pagination
+	// ResellerID matches transfers the reseller sent or received
+	ResellerID *uint32
+	ProductID  *uint32
+	Status     *string
+}
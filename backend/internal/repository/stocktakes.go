@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
+const (
+	STOCKTAKE_OPEN      = "OPEN"
+	STOCKTAKE_POSTED    = "POSTED"
+	STOCKTAKE_CANCELLED = "CANCELLED"
+)
+
+// Stocktake is a physical count of company stock or of one reseller's stock. Opening it
+// snapshots the owner's stock into Lines, counts are recorded against the lines and posting
+// writes every variance off or back as a count correction adjustment.
+type Stocktake struct {
+	ID         uint32     `json:"id"`
+	OwnerType  string     `json:"owner_type"`
+	ResellerID *uint32    `json:"reseller_id"`
+	LocationID *uint32    `json:"location_id"`
+	Status     string     `json:"status"`
+	Note       string     `json:"note"`
+	CreatedBy  uint32     `json:"created_by"`
+	ClosedBy   *uint32    `json:"closed_by"`
+	ClosedAt   *time.Time `json:"closed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// variance of the counted lines, valued at the snapshot cost
+	TotalLines       int64   `json:"total_lines"`
+	CountedLines     int64   `json:"counted_lines"`
+	VarianceQuantity int64   `json:"variance_quantity"`
+	VarianceValue    float64 `json:"variance_value"`
+
+	// expandable fields
+	Lines    []*StocktakeLine `json:"lines,omitempty"`
+	User     *UserShort       `json:"user,omitempty"`
+	Location *LocationShort   `json:"location,omitempty"`
+}
+
+// StocktakeLine is one product of a stocktake. ExpectedQuantity and UnitCost, the average
+// batch cost of the stock, are taken when the stocktake is opened. MovedQuantity is the stock
+// moved in and out between then and the count, so the variance is against ExpectedQuantity
+// plus MovedQuantity. PostedValue is the value of the adjustment the variance was posted as.
+type StocktakeLine struct {
+	ID               uint32     `json:"id"`
+	ProductID        uint32     `json:"product_id"`
+	ExpectedQuantity int64      `json:"expected_quantity"`
+	MovedQuantity    int64      `json:"moved_quantity"`
+	CountedQuantity  *int64     `json:"counted_quantity"`
+	UnitCost         float64    `json:"unit_cost"`
+	VarianceQuantity int64      `json:"variance_quantity"`
+	VarianceValue    float64    `json:"variance_value"`
+	AdjustmentID     *uint32    `json:"adjustment_id"`
+	PostedValue      *float64   `json:"posted_value"`
+	CountedAt        *time.Time `json:"counted_at"`
+
+	// expandable fields
+	Product *ProductShort `json:"product,omitempty"`
+}
+
+type StocktakeCount struct {
+	ProductID       uint32
+	CountedQuantity int64
+}
+
+type StocktakeFilter struct {
+	Pagination *pkg.Pagination
+	OwnerType  *string
+	ResellerID *uint32
+	LocationID *uint32
+	Status     *string
+}
+
+type StocktakeRepository interface {
+	// StartStocktake opens a stocktake and snapshots the owner's stock, an owner can only
+	// have one open stocktake.
+	StartStocktake(ctx context.Context, stocktake *Stocktake) (*Stocktake, error)
+	GetStocktake(ctx context.Context, id uint32) (*Stocktake, error)
+	ListStocktakes(ctx context.Context, filter *StocktakeFilter) ([]*Stocktake, *pkg.Pagination, error)
+	// RecordStocktakeCounts sets the counted quantity of the products, counting a product
+	// again replaces its count.
+	RecordStocktakeCounts(ctx context.Context, id uint32, counts []*StocktakeCount) (*Stocktake, error)
+	// PostStocktake adjusts the owner's stock by the variance of every counted line and
+	// closes the stocktake, uncounted lines are left as they are.
+	PostStocktake(ctx context.Context, id uint32, postedBy uint32) (*Stocktake, error)
+	CancelStocktake(ctx context.Context, id uint32, cancelledBy uint32) (*Stocktake, error)
+}
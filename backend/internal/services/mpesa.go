@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+)
+
+type StkPushRequest struct {
+	PhoneNumber      string
+	Amount           int64
+	AccountReference string
+	Description      string
+}
+
+type StkPushResponse struct {
+	MerchantRequestID   string
+	CheckoutRequestID   string
+	ResponseDescription string
+	CustomerMessage     string
+}
+
+type MpesaService interface {
+	// StkPush sends a payment prompt to the phone number, the outcome is delivered later
+	// to the configured callback url.
+	StkPush(ctx context.Context, req *StkPushRequest) (*StkPushResponse, error)
+	// ParseStkCallback reads the result out of a daraja stk push callback body.
+	ParseStkCallback(body []byte) (*repository.MpesaStkResult, error)
+	// ParseC2BTransaction reads a paybill payment out of a daraja c2b validation or
+	// confirmation body.
+	ParseC2BTransaction(body []byte) (*repository.MpesaC2BTransaction, error)
+}
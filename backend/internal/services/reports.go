@@ -1,4 +1,29 @@
 package services
 
+import (
+	"context"
+
+	"github.com/EmilioCliff/boffo/internal/repository"
+	"github.com/EmilioCliff/boffo/pkg"
+)
+
 type ReportService interface {
+	ResellerStatement(ctx context.Context, filter *repository.StatementFilter) (*repository.ResellerStatement, error)
+	ResellerStatementPDF(ctx context.Context, filter *repository.StatementFilter) ([]byte, error)
+	ProfitAndLoss(ctx context.Context, filter *repository.ProfitAndLossFilter) (*repository.ProfitAndLoss, error)
+	InventoryValuation(ctx context.Context, filter *repository.InventoryValuationFilter) (*repository.InventoryValuation, error)
+	ReceivablesAging(ctx context.Context, filter *repository.ReceivablesAgingFilter) (*repository.ReceivablesAgingReport, error)
+	TraceBatch(ctx context.Context, batchNumber string) ([]*repository.BatchTrace, error)
+	Analytics(ctx context.Context, filter *repository.AnalyticsFilter) (*repository.Analytics, error)
+	PurchaseOrderFillRate(ctx context.Context, filter *repository.PurchaseOrderFillRateFilter) (*repository.PurchaseOrderFillRate, error)
+	PurchasePriceVariance(ctx context.Context, filter *repository.PurchasePriceVarianceFilter) (*repository.PurchasePriceVariance, error)
+
+	// Report runs
+	CreateReportRun(ctx context.Context, reportType string, params repository.ReportRunParams, triggeredBy string) (*repository.ReportRun, error)
+	ExecuteReportRun(ctx context.Context, id uint32) (*repository.ReportRun, error)
+	RetryReportRuns(ctx context.Context) (int, error)
+	RecoverReportRuns(ctx context.Context) error
+	GetReportRun(ctx context.Context, id uint32) (*repository.ReportRun, error)
+	ListReportRuns(ctx context.Context, filter *repository.ReportRunFilter) ([]*repository.ReportRun, *pkg.Pagination, error)
+	ReportRunFile(ctx context.Context, id uint32) (*repository.ReportRun, string, error)
 }
@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"log"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -22,6 +23,25 @@ type Config struct {
 	TOKEN_SYMMETRIC_KEY     string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	TOKEN_ISSUER            string        `mapstructure:"TOKEN_ISSUER"`
 	DEFAULT_USER_PASSWORD   string        `mapstructure:"DEFAULT_USER_PASSWORD"`
+	REPORT_STORAGE_PATH     string        `mapstructure:"REPORT_STORAGE_PATH"`
+	MPESA_BASE_URL          string        `mapstructure:"MPESA_BASE_URL"`
+	MPESA_CONSUMER_KEY      string        `mapstructure:"MPESA_CONSUMER_KEY"`
+	MPESA_CONSUMER_SECRET   string        `mapstructure:"MPESA_CONSUMER_SECRET"`
+	MPESA_SHORTCODE         string        `mapstructure:"MPESA_SHORTCODE"`
+	MPESA_PASSKEY           string        `mapstructure:"MPESA_PASSKEY"`
+	MPESA_CALLBACK_URL      string        `mapstructure:"MPESA_CALLBACK_URL"`
+	MPESA_CALLBACK_TOKEN    string        `mapstructure:"MPESA_CALLBACK_TOKEN"`
+	INVOICE_DUE_DAYS        int           `mapstructure:"INVOICE_DUE_DAYS"`
+	// transfers a reseller starts wait for the receiver to accept them, when set only an admin
+	// can approve them
+	STOCK_TRANSFER_REQUIRES_APPROVAL bool `mapstructure:"STOCK_TRANSFER_REQUIRES_APPROVAL"`
+	// FEFO, FIFO or LIFO, the batch order distributions and sales take stock in when they
+	// do not pick one
+	STOCK_ALLOCATION_STRATEGY string `mapstructure:"STOCK_ALLOCATION_STRATEGY"`
+	// addresses or CIDR ranges daraja callbacks may come from, empty skips the check
+	MPESA_CALLBACK_IPS []string `mapstructure:"MPESA_CALLBACK_IPS"`
+	// proxies allowed to set the client address through X-Forwarded-For, none by default
+	TRUSTED_PROXIES []string `mapstructure:"TRUSTED_PROXIES"`
 }
 
 func LoadConfig(path string) (Config, error) {
@@ -41,8 +61,18 @@ func LoadConfig(path string) (Config, error) {
 	}
 
 	var config Config
+	if err := viper.Unmarshal(&config); err != nil {
+		return Config{}, err
+	}
+
+	config.STOCK_ALLOCATION_STRATEGY = strings.ToUpper(strings.TrimSpace(config.STOCK_ALLOCATION_STRATEGY))
+	switch config.STOCK_ALLOCATION_STRATEGY {
+	case "FEFO", "FIFO", "LIFO":
+	default:
+		return Config{}, Errorf(INVALID_ERROR, "STOCK_ALLOCATION_STRATEGY must be one of FEFO, FIFO or LIFO, got %q", config.STOCK_ALLOCATION_STRATEGY)
+	}
 
-	return config, viper.Unmarshal(&config)
+	return config, nil
 }
 
 func setDefaults() {
@@ -60,4 +90,22 @@ func setDefaults() {
 	viper.SetDefault("TOKEN_SYMMETRIC_KEY", "")
 	viper.SetDefault("TOKEN_ISSUER", "")
 	viper.SetDefault("DEFAULT_USER_PASSWORD", "")
+	viper.SetDefault("REPORT_STORAGE_PATH", "./storage/reports")
+	viper.SetDefault("MPESA_BASE_URL", "https://sandbox.safaricom.co.ke")
+	viper.SetDefault("MPESA_CONSUMER_KEY", "")
+	viper.SetDefault("MPESA_CONSUMER_SECRET", "")
+	viper.SetDefault("MPESA_SHORTCODE", "")
+	viper.SetDefault("MPESA_PASSKEY", "")
+	viper.SetDefault("MPESA_CALLBACK_URL", "")
+	viper.SetDefault("MPESA_CALLBACK_TOKEN", "")
+	// the addresses safaricom sends daraja callbacks from
+	viper.SetDefault("MPESA_CALLBACK_IPS", []string{
+		"196.201.214.200", "196.201.214.206", "196.201.213.114", "196.201.214.207",
+		"196.201.214.208", "196.201.213.44", "196.201.212.127", "196.201.212.138",
+		"196.201.212.129", "196.201.212.136", "196.201.212.74", "196.201.212.69",
+	})
+	viper.SetDefault("TRUSTED_PROXIES", []string{})
+	viper.SetDefault("INVOICE_DUE_DAYS", 30)
+	viper.SetDefault("STOCK_TRANSFER_REQUIRES_APPROVAL", false)
+	viper.SetDefault("STOCK_ALLOCATION_STRATEGY", "FEFO")
 }
@@ -373,6 +373,13 @@ export interface PaymentsStats {
 	mpesa_total: number;
 	total_payments: number;
 	total_received: number;
+	method_totals: PaymentMethodTotal[];
+}
+
+export interface PaymentMethodTotal {
+	method: string;
+	name: string;
+	total: number;
 }
 
 // Reseller page data type